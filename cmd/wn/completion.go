@@ -0,0 +1,175 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/kjhaber/wn/internal/wn"
+	"github.com/spf13/cobra"
+)
+
+// maxCompletions caps id candidates so completion stays fast on large stores; typing more of
+// the id narrows the list.
+const maxCompletions = 50
+
+// completionStore opens the store for a completion request. Completion runs without
+// PersistentPreRunE, so the --root override is applied here.
+func completionStore() (wn.Store, bool) {
+	wn.SetCLIRootOverride(rootFlag)
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return nil, false
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return nil, false
+	}
+	return store, true
+}
+
+// itemIDCompletions returns "id\tfirst line" candidates for ids starting with prefix and not in
+// exclude: undone items first, most recently updated first, at most maxCompletions. Ids come from
+// the index (wn.ListMeta); only the returned candidates are loaded for their descriptions.
+func itemIDCompletions(prefix string, exclude []string) []string {
+	store, ok := completionStore()
+	if !ok {
+		return nil
+	}
+	entries, err := wn.ListMeta(store)
+	if err != nil {
+		return nil
+	}
+	var matches []wn.IndexEntry
+	for _, e := range entries {
+		if strings.HasPrefix(e.ID, prefix) && !slices.Contains(exclude, e.ID) {
+			matches = append(matches, e)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b wn.IndexEntry) int {
+		if a.Done != b.Done {
+			if a.Done {
+				return 1
+			}
+			return -1
+		}
+		return b.Updated.Compare(a.Updated)
+	})
+	if len(matches) > maxCompletions {
+		matches = matches[:maxCompletions]
+	}
+	out := make([]string, 0, len(matches))
+	for _, e := range matches {
+		it, err := store.Get(e.ID)
+		if err != nil {
+			continue
+		}
+		desc := strings.ReplaceAll(wn.FirstLine(it.Description), "\t", " ")
+		out = append(out, e.ID+"\t"+desc)
+	}
+	return out
+}
+
+// tagCompletions returns the tags in use that start with prefix, sorted.
+func tagCompletions(prefix string) []string {
+	store, ok := completionStore()
+	if !ok {
+		return nil
+	}
+	entries, err := wn.ListMeta(store)
+	if err != nil {
+		return nil
+	}
+	var tags []string
+	for _, e := range entries {
+		for _, t := range e.Tags {
+			if strings.HasPrefix(t, prefix) && !slices.Contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// completeItemID completes the single optional [id] argument.
+func completeItemID(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return itemIDCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeItemIDs completes any number of id arguments, skipping ids already given.
+func completeItemIDs(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return itemIDCompletions(toComplete, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeStatusThenID completes wn status: the status name, then the item id.
+func completeStatusThenID(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		var out []string
+		for _, s := range []string{"undone", "claimed", "review", "done", "closed", "suspend"} {
+			if strings.HasPrefix(s, toComplete) {
+				out = append(out, s)
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return itemIDCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTag completes the tag argument of wn tag add/rm.
+func completeTag(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return tagCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTagFlag completes a flag whose value is a tag.
+func completeTagFlag(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return tagCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeIDFlag completes a flag whose value is an item id.
+func completeIDFlag(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return itemIDCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerCompletions attaches id and tag completion to commands and flags. It must run after
+// the flags are defined.
+func registerCompletions() {
+	for _, c := range []*cobra.Command{
+		showCmd, archiveCmd, editCmd, doneCmd, undoneCmd, duplicateCmd, claimCmd, releaseCmd,
+		reviewReadyCmd, orderCmd, logCmd, whyCmd, pickCmd, worktreeSetupCmd, noteListCmd,
+		promptCmd, respondCmd,
+	} {
+		c.ValidArgsFunction = completeItemID
+	}
+	rmCmd.ValidArgsFunction = completeItemIDs
+	mergeDuplicatesCmd.ValidArgsFunction = completeItemIDs
+	statusCmd.ValidArgsFunction = completeStatusThenID
+	tagRmCmd.ValidArgsFunction = completeTag
+	tagAddCmd.ValidArgsFunction = completeTag
+
+	idFlags := map[*cobra.Command][]string{
+		tagCmd:             {"wid"},
+		dependAddCmd:       {"on", "wid"},
+		dependRmCmd:        {"on", "wid"},
+		dependListCmd:      {"wid"},
+		duplicateCmd:       {"of"},
+		mergeDuplicatesCmd: {"into"},
+		mergeCmd:           {"wid"},
+	}
+	for c, names := range idFlags {
+		for _, name := range names {
+			_ = c.RegisterFlagCompletionFunc(name, completeIDFlag)
+		}
+	}
+	for _, c := range []*cobra.Command{addCmd, cleanupSetMergedReviewItemsDoneCmd, nextCmd, doCmd, launchCmd, worktreeSetupCmd, exportCmd, listCmd} {
+		_ = c.RegisterFlagCompletionFunc("tag", completeTagFlag)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kjhaber/wn/internal/wn"
+)
+
+// runComplete runs cobra's hidden __complete command and returns the candidate lines and the
+// directive line.
+func runComplete(t *testing.T, args ...string) ([]string, string) {
+	t.Helper()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs(append([]string{"__complete"}, args...))
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("__complete %v: %v", args, err)
+		}
+	})
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	var directive string
+	if n := len(lines); n > 0 && strings.HasPrefix(lines[n-1], ":") {
+		directive, lines = lines[n-1], lines[:n-1]
+	}
+	return lines, directive
+}
+
+func TestCompleteItemIDs(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&wn.Item{ID: "abd999", Description: "closed one", Done: true, Tags: []string{"api"}, Created: now, Updated: now}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	lines, directive := runComplete(t, "show", "ab")
+	if directive != ":4" {
+		t.Errorf("directive = %q, want :4 (no file completion)", directive)
+	}
+	want := []string{itemID + "\tfirst line", "abd999\tclosed one"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("show ab completions = %q, want %q (undone first)", lines, want)
+	}
+
+	if lines, _ := runComplete(t, "show", itemID, ""); len(lines) != 0 {
+		t.Errorf("show takes one id; second argument completions = %q", lines)
+	}
+	if lines, _ := runComplete(t, "rm", itemID, ""); len(lines) != 1 || !strings.HasPrefix(lines[0], "abd999\t") {
+		t.Errorf("rm completions should skip ids already given; got %q", lines)
+	}
+	if lines, _ := runComplete(t, "depend", "add", "--on", "abd"); len(lines) != 1 || !strings.HasPrefix(lines[0], "abd999\t") {
+		t.Errorf("depend add --on completions = %q", lines)
+	}
+	if lines, _ := runComplete(t, "list", "--tag", ""); strings.Join(lines, ",") != "api" {
+		t.Errorf("list --tag completions = %q, want [api]", lines)
+	}
+}
+
+func TestCompleteItemIDs_capped(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for i := range maxCompletions + 10 {
+		if err := store.Put(&wn.Item{ID: fmt.Sprintf("c%05d", i), Description: "bulk", Created: now, Updated: now}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if lines, _ := runComplete(t, "done", "c"); len(lines) != maxCompletions {
+		t.Errorf("got %d completions, want cap of %d", len(lines), maxCompletions)
+	}
+}
@@ -1,30 +1,128 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kjhaber/wn/internal/wn"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var version = "dev"
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// Process exit codes, so scripts can tell conditions apart (e.g. wn next; [ $? -eq 4 ]).
+const (
+	exitGeneric  = 1 // any other error
+	exitUsage    = 2 // invalid flags, arguments, or flag combinations
+	exitNotFound = 3 // the named item (or previous task) does not exist
+	exitEmpty    = 4 // no current task, or nothing to pick (wn next, wn done --next, wn pick)
+)
+
+// exitError is an error that exits with a specific code. A nil err exits without printing
+// anything, for commands that already printed their outcome (e.g. "No next task.").
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, args ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func notFoundErrorf(format string, args ...any) error {
+	return &exitError{code: exitNotFound, err: fmt.Errorf(format, args...)}
+}
+
+func emptyErrorf(format string, args ...any) error {
+	return &exitError{code: exitEmpty, err: fmt.Errorf(format, args...)}
+}
+
+// errEmpty exits with exitEmpty without a message, for commands that already printed why.
+var errEmpty = &exitError{code: exitEmpty}
+
+// exitCode returns the process exit code for err: the code of an exitError, exitNotFound for a
+// store lookup of a missing item (wn.ErrNotFound), else exitGeneric.
+func exitCode(err error) int {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	if errors.Is(err, wn.ErrNotFound) {
+		return exitNotFound
+	}
+	return exitGeneric
+}
+
+// classifyUsageErrors marks argument errors of c and its subcommands as usage errors, stops
+// cobra from printing usage for any other error, and keeps it from printing an empty message.
+func classifyUsageErrors(c *cobra.Command) {
+	if args := c.Args; args != nil {
+		c.Args = func(cmd *cobra.Command, a []string) error {
+			if err := args(cmd, a); err != nil {
+				return &exitError{code: exitUsage, err: err}
+			}
+			return nil
+		}
+	}
+	if run := c.RunE; run != nil {
+		c.RunE = func(cmd *cobra.Command, a []string) error {
+			err := run(cmd, a)
+			if err != nil && exitCode(err) != exitUsage {
+				cmd.SilenceUsage = true
+			}
+			if err != nil && err.Error() == "" {
+				cmd.SilenceErrors = true
+			}
+			return err
+		}
+	}
+	for _, sub := range c.Commands() {
+		classifyUsageErrors(sub)
 	}
 }
 
 var pickerFlag string
+var rootFlag string
+var noPagerFlag bool
+var tzFlag string
+var widthFlag int
+
+// outputWidth is the column budget for list rows and item titles: --width, else the terminal
+// width, else 0 (not a terminal), which keeps the fixed default column widths.
+var outputWidth int
 
 var rootCmd = &cobra.Command{
 	Use:   "wn",
@@ -32,33 +130,96 @@ var rootCmd = &cobra.Command{
 	Long:  `wn is a CLI for tracking work items. Use wn init to create a tracker in the current directory.`,
 	Args:  cobra.MaximumNArgs(1),
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		wn.SetCLIRootOverride(rootFlag)
 		// Determine effective picker mode: settings, overridden by --picker flag.
 		mode := ""
+		var settings wn.Settings
 		root, err := wn.FindRootForCLI()
 		if err == nil {
-			settings, _ := wn.ReadSettingsInRoot(root)
+			settings, _ = wn.ReadSettingsInRoot(root)
 			mode = settings.Picker
 		}
+		if err := wn.ApplyOrderRange(settings); err != nil {
+			// Keep going so wn settings (and --check) can still be used to fix it.
+			fmt.Fprintf(os.Stderr, "warning: %v; using the built-in range 0..%d (default %d)\n", err, wn.MaxOrder, wn.DefaultOrder)
+		}
+		if tzFlag != "" {
+			if err := wn.SetDisplayTimezone(tzFlag); err != nil {
+				return err
+			}
+		} else if err := wn.SetDisplayTimezone(settings.Timezone); err != nil {
+			// A bad setting shouldn't lock the user out; only an explicit --tz is fatal.
+			fmt.Fprintf(os.Stderr, "warning: %v; showing times in UTC\n", err)
+			_ = wn.SetDisplayTimezone("")
+		}
+		wn.SetEditorCommand(settings.Editor)
+		if widthFlag < 0 {
+			return usageErrorf("--width must be a positive number of columns")
+		}
+		// Measured here, before paged swaps stdout for a pipe.
+		outputWidth = widthFlag
+		if outputWidth == 0 {
+			outputWidth = wn.TerminalWidth(os.Stdout)
+		}
 		if cmd.Root().PersistentFlags().Changed("picker") {
 			mode = pickerFlag
 		}
 		return wn.SetPickerMode(mode)
 	},
-	RunE: runCurrent,
+	RunE: paged(runCurrent, nil),
 }
 
 func init() {
 	rootCmd.Version = version
 	rootCmd.SetVersionTemplate("wn version {{.Version}}\n")
 	rootCmd.PersistentFlags().StringVar(&pickerFlag, "picker", "", "Picker mode: fzf, numbered, or empty (auto-detect)")
-	rootCmd.AddCommand(initCmd, addCmd, rmCmd, archiveCmd, editCmd, tagCmd, dependCmd, doneCmd, undoneCmd, statusCmd, claimCmd, releaseCmd, reviewReadyCmd, cleanupCmd, mergeCmd, logCmd, showCmd, nextCmd, pickCmd, mcpCmd, doCmd, launchCmd, worktreeSetupCmd, settingsCmd, exportCmd, importCmd, listCmd, noteCmd, tuiCmd, promptCmd, respondCmd)
+	rootCmd.PersistentFlags().BoolVar(&noPagerFlag, "no-pager", false, "Do not pipe long show/list/log output through a pager")
+	rootCmd.PersistentFlags().StringVar(&tzFlag, "tz", "", "Time zone for displayed timestamps: IANA name (e.g. America/Chicago), local, or UTC (overrides settings timezone)")
+	rootCmd.PersistentFlags().IntVar(&widthFlag, "width", 0, "Output width in columns for list rows and item titles (default: terminal width; fixed columns when not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&rootFlag, "root", "", "Project directory containing .wn (overrides WN_DIR and the upward search)")
+	rootCmd.AddCommand(initCmd, addCmd, rmCmd, trashCmd, archiveCmd, editCmd, tagCmd, dependCmd, doneCmd, undoneCmd, statusCmd, duplicateCmd, mergeDuplicatesCmd, claimCmd, releaseCmd, reviewReadyCmd, orderCmd, cleanupCmd, mergeCmd, logCmd, showCmd, currentCmd, clearCurrentCmd, whyCmd, statsCmd, doctorCmd, reindexCmd, migrateCmd, nextCmd, pickCmd, mcpCmd, serveCmd, doCmd, launchCmd, worktreeSetupCmd, settingsCmd, exportCmd, importCmd, syncBackCmd, listCmd, noteCmd, tuiCmd, promptCmd, respondCmd)
 	rootCmd.CompletionOptions.DisableDefaultCmd = false
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &exitError{code: exitUsage, err: err}
+	})
 }
 
 // defaultShowFields is the built-in default for bare 'wn [id]' and 'wn show [id]'
 // when no --fields flag is given and settings.Show.DefaultFields is empty.
 const defaultShowFields = "title,body,deps,notes"
 
+// paged wraps a RunE so its stdout goes through wn.PageOutput (WN_PAGER, PAGER, or less -FRX)
+// when stdout is a terminal. Paging is skipped with --no-pager, when *json is true, or when
+// stdout is not a terminal. Used by show, bare wn, list, log, and note list.
+func paged(run func(*cobra.Command, []string) error, json *bool) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if noPagerFlag || (json != nil && *json) || !wn.IsTerminal(os.Stdout) {
+			return run(cmd, args)
+		}
+		r, w, err := os.Pipe()
+		if err != nil {
+			return run(cmd, args)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		var buf strings.Builder
+		done := make(chan struct{})
+		go func() {
+			_, _ = io.Copy(&buf, r)
+			close(done)
+		}()
+		runErr := run(cmd, args)
+		os.Stdout = stdout
+		w.Close()
+		<-done
+		r.Close()
+		if err := wn.PageOutput(buf.String()); err != nil && runErr == nil {
+			runErr = err
+		}
+		return runErr
+	}
+}
+
 func runCurrent(cmd *cobra.Command, args []string) error {
 	root, err := wn.FindRootForCLI()
 	if err != nil {
@@ -74,11 +235,11 @@ func runCurrent(cmd *cobra.Command, args []string) error {
 	} else {
 		if meta.CurrentID == "" {
 			fmt.Println("No current task. Use 'wn pick' to choose one or 'wn next' to advance.")
-			return nil
+			return errEmpty
 		}
 		id = meta.CurrentID
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -88,13 +249,122 @@ func runCurrent(cmd *cobra.Command, args []string) error {
 			fmt.Printf("Current task ID %s not found. Use 'wn pick' to choose one.\n", id)
 			return nil
 		}
-		return fmt.Errorf("item %s not found", id)
+		return notFoundErrorf("item %s not found", id)
 	}
 	settings, _ := wn.ReadSettingsInRoot(root)
 	fields := resolveShowFields(false, "", settings)
 	return renderItemHuman(item, fields, store)
 }
 
+var currentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the current task (scriptable form of bare 'wn')",
+	Long: `Show the current task. Prints the same output as running 'wn' with no arguments.
+
+Use --json in scripts: it prints id, description, status, tags, depends_on, and dependents,
+and exits with an error when there is no current task.`,
+	Args: cobra.NoArgs,
+	RunE: runCurrentCmd,
+}
+
+var currentJson bool
+
+func init() {
+	currentCmd.Flags().BoolVar(&currentJson, "json", false, "Output as JSON (id, description, status, tags, depends_on, dependents)")
+}
+
+// currentItemJSON is the JSON shape printed by 'wn current --json'.
+type currentItemJSON struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Tags        []string `json:"tags"`
+	DependsOn   []string `json:"depends_on"`
+	Dependents  []string `json:"dependents"`
+}
+
+func runCurrentCmd(cmd *cobra.Command, args []string) error {
+	if !currentJson {
+		return runCurrent(cmd, nil)
+	}
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	meta, err := wn.ReadMeta(root)
+	if err != nil {
+		return err
+	}
+	if meta.CurrentID == "" {
+		return emptyErrorf("no current task; use 'wn pick' or 'wn next'")
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	item, err := store.Get(meta.CurrentID)
+	if err != nil {
+		return notFoundErrorf("current task ID %s not found", meta.CurrentID)
+	}
+	all, err := store.List()
+	if err != nil {
+		return err
+	}
+	dependents, err := wn.Dependents(store, item.ID)
+	if err != nil {
+		return err
+	}
+	out := currentItemJSON{
+		ID:          item.ID,
+		Description: item.Description,
+		Status:      wn.ItemListStatus(item, time.Now().UTC(), wn.BlockedSet(all)[item.ID]),
+		Tags:        item.Tags,
+		DependsOn:   item.DependsOn,
+		Dependents:  dependents,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if out.DependsOn == nil {
+		out.DependsOn = []string{}
+	}
+	if out.Dependents == nil {
+		out.Dependents = []string{}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+var clearCurrentCmd = &cobra.Command{
+	Use:   "clear-current",
+	Short: "Unset the current task",
+	Long:  "Clears the current task so bare 'wn' reports no current task. The cleared task becomes the previous task.",
+	Args:  cobra.NoArgs,
+	RunE:  runClearCurrent,
+}
+
+func runClearCurrent(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	var cleared string
+	if err := wn.WithMetaLock(root, func(m wn.Meta) (wn.Meta, error) {
+		cleared = m.CurrentID
+		m.CurrentID = ""
+		return m, nil
+	}); err != nil {
+		return err
+	}
+	if cleared == "" {
+		fmt.Println("No current task.")
+		return nil
+	}
+	fmt.Printf("cleared current task (was %s)\n", cleared)
+	return nil
+}
+
 var showCmd = &cobra.Command{
 	Use:   "show [id]",
 	Short: "Show a work item",
@@ -104,25 +374,61 @@ Output modes:
   (default)  Human-readable; fields controlled by --fields or --all
   --plain    Description text only, suitable for pasting into an agent
   --json     Full item as machine-readable JSON
+  --web      Render to a temporary HTML file and open it in the default browser
+  --markdown Markdown block (heading, body, tags, dependency checklist, notes) for PR descriptions
+
+With --related (human-readable mode), also lists other undone items sharing at least one
+tag, most shared tags first. With --edited, also reports when the description was last
+changed and whether by a person (CLI/TUI) or an agent (MCP, with its worker id when claimed).
+
+With --plain, a warning is printed to stderr when the text is longer than --max-chars (default:
+agent.prompt_max_chars in settings); --truncate instead cuts it at the last line or sentence
+that fits and appends an ellipsis.
 
 Field selection (human-readable mode only):
   --fields title,body,status,deps,notes,log
   --all      Show all fields (equivalent to --fields title,body,status,deps,notes,log)`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: runShow,
+	RunE: paged(runShow, &showJson),
 }
 
-var showJson, showPlain, showAll bool
+var showJson, showPlain, showAll, showWeb, showRelated, showMarkdown, showEdited bool
 var showFields string
+var showMaxChars int
+var showTruncate bool
 
 func init() {
 	showCmd.Flags().BoolVar(&showJson, "json", false, "Output as JSON")
 	showCmd.Flags().BoolVar(&showPlain, "plain", false, "Output description text only (for agents/scripts)")
 	showCmd.Flags().BoolVar(&showAll, "all", false, "Show all fields including log")
 	showCmd.Flags().StringVar(&showFields, "fields", "", "Comma-separated fields: title,body,status,deps,notes,log")
+	showCmd.Flags().BoolVar(&showWeb, "web", false, "Render as HTML in a temporary file and open it in the default browser")
+	showCmd.Flags().BoolVar(&showMarkdown, "markdown", false, "Output as a Markdown block for pasting into a PR description")
+	showCmd.Flags().BoolVar(&showRelated, "related", false, "Also list undone items sharing a tag, most shared tags first")
+	showCmd.Flags().BoolVar(&showEdited, "edited", false, "Also report when the description was last edited, and by whom")
+	showCmd.Flags().IntVar(&showMaxChars, "max-chars", 0, "With --plain, warn on stderr when the text is longer than N characters (default: agent.prompt_max_chars)")
+	showCmd.Flags().BoolVar(&showTruncate, "truncate", false, "With --plain, cut the text to the character limit instead of warning")
 }
 
 func runShow(cmd *cobra.Command, args []string) error {
+	if showWeb && (showJson || showPlain) {
+		return usageErrorf("cannot use --web with --json or --plain; choose one")
+	}
+	if showMarkdown && (showJson || showPlain || showWeb) {
+		return usageErrorf("cannot use --markdown with --json, --plain, or --web; choose one")
+	}
+	if showRelated && (showJson || showPlain || showWeb || showMarkdown) {
+		return usageErrorf("cannot use --related with --json, --plain, --web, or --markdown; choose one")
+	}
+	if showEdited && (showJson || showPlain || showWeb || showMarkdown) {
+		return usageErrorf("cannot use --edited with --json, --plain, --web, or --markdown; choose one")
+	}
+	if (showMaxChars != 0 || showTruncate) && !showPlain {
+		return usageErrorf("--max-chars and --truncate require --plain")
+	}
+	if showMaxChars < 0 {
+		return usageErrorf("--max-chars must not be negative")
+	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
@@ -137,15 +443,15 @@ func runShow(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task; use 'wn pick' or 'wn next'")
+		return emptyErrorf("no id provided and no current task; use 'wn pick' or 'wn next'")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
 	item, err := store.Get(id)
 	if err != nil {
-		return fmt.Errorf("item %s not found", id)
+		return notFoundErrorf("item %s not found", id)
 	}
 	if showJson {
 		enc := json.NewEncoder(os.Stdout)
@@ -153,12 +459,79 @@ func runShow(cmd *cobra.Command, args []string) error {
 		return enc.Encode(item)
 	}
 	if showPlain {
-		fmt.Println(wn.PromptContent(item.Description))
+		return printPlainPrompt(root, wn.PromptContent(item.Description))
+	}
+	if showWeb {
+		return showItemWeb(store, item)
+	}
+	if showMarkdown {
+		md, err := wn.ItemMarkdown(store, item)
+		if err != nil {
+			return err
+		}
+		fmt.Print(md)
 		return nil
 	}
 	settings, _ := wn.ReadSettingsInRoot(root)
 	fields := resolveShowFields(showAll, showFields, settings)
-	return renderItemHuman(item, fields, store)
+	if err := renderItemHuman(item, fields, store); err != nil {
+		return err
+	}
+	if showEdited {
+		printLastEdit(item)
+	}
+	if showRelated {
+		return printRelatedItems(store, item)
+	}
+	return nil
+}
+
+// printRelatedItems prints the "related:" section of wn show --related: undone items sharing a
+// tag with item (see wn.RelatedItems) in list row format.
+func printRelatedItems(store wn.Store, item *wn.Item) error {
+	all, err := store.List()
+	if err != nil {
+		return err
+	}
+	related := wn.RelatedItems(all, item)
+	if len(related) == 0 {
+		fmt.Println("related: none")
+		return nil
+	}
+	fmt.Println("related:")
+	blocked := wn.BlockedSet(all)
+	now := time.Now().UTC()
+	descWidth := listDescWidth(related)
+	for _, it := range related {
+		printListRow(it, now, blocked[it.ID], descWidth)
+	}
+	return nil
+}
+
+// printLastEdit prints the "last edited:" line of wn show --edited from the item's log.
+func printLastEdit(item *wn.Item) {
+	e, ok := wn.LastDescriptionEdit(item)
+	if !ok {
+		fmt.Printf("last edited: never (created %s)\n", formatTime(item.Created))
+		return
+	}
+	fmt.Printf("last edited: %s by %s\n", formatTime(e.At), wn.DescribeEditSource(e))
+}
+
+// showItemWeb writes the item's HTML page and opens it in the browser. Without an opener the
+// page path is printed so it can be opened or shared by hand.
+func showItemWeb(store wn.Store, item *wn.Item) error {
+	path, err := wn.WriteItemHTML(store, item)
+	if err != nil {
+		return err
+	}
+	if err := wn.OpenInBrowser(path); err != nil {
+		fmt.Fprintf(os.Stderr, "wn: could not open browser (%v); open the file manually\n", err)
+		fmt.Println(path)
+		return nil
+	}
+	fmt.Printf("Opened %s\n", path)
+	return nil
 }
 
 // resolveShowFields returns the active field set for human-readable output.
@@ -188,10 +561,36 @@ func parseFieldSet(s string) map[string]bool {
 	return m
 }
 
+// formatTime formats a timestamp for human-readable output in the display time zone
+// (settings timezone or --tz; UTC by default).
+func formatTime(t time.Time) string {
+	return wn.DisplayTime(t).Format("2006-01-02 15:04:05")
+}
+
+// printPlainPrompt prints text for wn show --plain, checking it against --max-chars (or the
+// agent.prompt_max_chars setting): over the limit it warns on stderr, or with --truncate cuts it.
+func printPlainPrompt(root, text string) error {
+	limit := showMaxChars
+	if limit == 0 {
+		settings, _ := wn.ReadSettingsInRoot(root)
+		limit = settings.Agent.PromptMaxChars
+	}
+	if showTruncate && limit == 0 {
+		return usageErrorf("--truncate needs a limit: pass --max-chars or set agent.prompt_max_chars")
+	}
+	if wn.PromptTooLong(text, limit) {
+		if showTruncate {
+			text = wn.TruncatePrompt(text, limit)
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: text is %d characters, over the %d-character limit\n", utf8.RuneCountInString(text), limit)
+		}
+	}
+	fmt.Println(text)
+	return nil
+}
+
 // renderItemHuman prints a work item in human-readable format, showing only the requested fields.
 func renderItemHuman(item *wn.Item, fields map[string]bool, store wn.Store) error {
-	const timeFmt = "2006-01-02 15:04:05"
-
 	// Compute blocked state once: non-done items with unresolved deps.
 	blocked := false
 	if !item.Done && !item.ReviewReady && len(item.DependsOn) > 0 {
@@ -220,7 +619,7 @@ func renderItemHuman(item *wn.Item, fields map[string]bool, store wn.Store) erro
 		}
 		firstLine := wn.FirstLine(item.Description)
 		tagsStr := formatTags(item.Tags)
-		const titleWidth = 56 // pad so tags/state align on the right
+		titleWidth := showTitleWidth(len(tagsStr) + len(state)) // pad so tags/state align on the right
 		content := fmt.Sprintf("[%s] %s", item.ID, firstLine)
 		if tagsStr != "" {
 			if len(content) > titleWidth {
@@ -248,12 +647,19 @@ func renderItemHuman(item *wn.Item, fields map[string]bool, store wn.Store) erro
 		if item.Done && item.DoneMessage != "" {
 			status += " (" + item.DoneMessage + ")"
 		} else if !item.InProgressUntil.IsZero() && item.InProgressUntil.After(time.Now().UTC()) {
-			status = "in progress until " + item.InProgressUntil.Format(timeFmt)
+			status = "in progress until " + formatTime(item.InProgressUntil)
 			if item.InProgressBy != "" {
 				status += " (by " + item.InProgressBy + ")"
 			}
 		}
 		fmt.Printf("status: %s\n", status)
+		if d, ok := wn.ActualDuration(item); ok {
+			line := "actual duration: " + wn.FormatDuration(d.Actual)
+			if d.Claimed > 0 {
+				line += " (claimed for " + wn.FormatDuration(d.Claimed) + ")"
+			}
+			fmt.Println(line)
+		}
 	}
 
 	if fields["deps"] {
@@ -264,19 +670,26 @@ func renderItemHuman(item *wn.Item, fields map[string]bool, store wn.Store) erro
 		if err == nil && len(dependents) > 0 {
 			fmt.Printf("dependent tasks: %s\n", strings.Join(dependents, ", "))
 		}
+		if chain := wn.DuplicateOfChain(store, item); chain != nil {
+			fmt.Printf("duplicate of: %s\n", chain)
+		}
 	}
 
+	// The source-system link is shown with the notes, where it would otherwise be kept.
+	if ext := strings.TrimSpace(item.ExternalID + " " + item.ExternalURL); fields["notes"] && ext != "" {
+		fmt.Printf("external: %s\n", ext)
+	}
 	if fields["notes"] && len(item.Notes) > 0 {
 		fmt.Println("notes:")
 		for _, n := range item.Notes {
-			fmt.Printf("  %s\t%s\t%s\n", n.Name, n.Created.Format(timeFmt), n.Body)
+			fmt.Printf("  %s\t%s\t%s\n", n.Name, formatTime(n.Created), n.Body)
 		}
 	}
 
 	if fields["log"] && len(item.Log) > 0 {
 		fmt.Println("log:")
 		for _, e := range item.Log {
-			fmt.Printf("  %s %s", e.At.Format(timeFmt), e.Kind)
+			fmt.Printf("  %s %s", formatTime(e.At), e.Kind)
 			if e.Msg != "" {
 				fmt.Printf(" %s", e.Msg)
 			}
@@ -290,54 +703,172 @@ func renderItemHuman(item *wn.Item, fields map[string]bool, store wn.Store) erro
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize wn in the current directory",
+	Long:  "Initialize wn in the current directory. With --template, seed the new tracker with the items from an export file (or export --split directory); the template is validated before .wn is created.",
 	RunE:  runInit,
 }
+var initTemplate string
+var initGit bool
+var initBare bool
+var initBackend string
+
+func init() {
+	initCmd.Flags().StringVar(&initTemplate, "template", "", "Export file or directory whose items seed the new tracker")
+	initCmd.Flags().BoolVar(&initGit, "git", false, "Check that the directory is in a git work tree and print the default branch (needed by wn do and mark-merged)")
+	initCmd.Flags().BoolVar(&initBare, "bare", false, "Skip all git checks")
+	initCmd.Flags().StringVar(&initBackend, "backend", "", "Storage backend: file (default) or sqlite. Overrides settings backend.")
+}
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if initGit && initBare {
+		return usageErrorf("cannot use both --git and --bare; choose one")
+	}
 	dir, err := os.Getwd()
 	if err != nil {
 		return err
 	}
-	if err := wn.InitRoot(dir); err != nil {
+	backend := initBackend
+	if backend == "" {
+		settings, _ := wn.ReadSettingsInRoot(dir)
+		backend = settings.Backend
+	}
+	if err := wn.ValidateBackend(backend); err != nil {
+		return err
+	}
+	if initTemplate != "" {
+		if err := wn.ValidateExport(initTemplate); err != nil {
+			return fmt.Errorf("template %s: %w", initTemplate, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".wn", "items")); err == nil || wn.StoreBackend(dir) == wn.BackendSQLite {
+			store, err := wn.OpenStore(dir)
+			if err != nil {
+				return err
+			}
+			hasItems, err := wn.StoreHasItems(store)
+			if err != nil {
+				return err
+			}
+			if hasItems {
+				return fmt.Errorf("store already has items; use wn import --append or --replace instead of init --template")
+			}
+		}
+	}
+	if err := wn.InitRootBackend(dir, backend); err != nil {
 		return err
 	}
+	if initTemplate != "" {
+		store, err := wn.OpenStore(dir)
+		if err != nil {
+			return err
+		}
+		warnings, err := wn.ImportReplace(store, initTemplate)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
 	fmt.Println(`wn initialized at ".wn"`)
+	if initGit {
+		reportInitGit(dir)
+	}
 	return nil
 }
 
+// reportInitGit prints the detected default branch, or warns on stderr when dir is not a git
+// work tree or has no commits yet, so agent-orch and mark-merged problems surface at init.
+func reportInitGit(dir string) {
+	if !wn.IsGitWorkTree(dir) {
+		fmt.Fprintln(os.Stderr, "warning: not inside a git work tree; wn do, agent-orch, and mark-merged need git")
+		return
+	}
+	branch, err := wn.DefaultBranch(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not detect the default branch (no commits yet?): %v\n", err)
+		return
+	}
+	fmt.Printf("git default branch: %s\n", branch)
+}
+
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a work item",
+	Long:  "Add a work item. Without -m, the description is read from stdin when it is piped (e.g. echo \"fix the thing\" | wn add), otherwise from $EDITOR, pre-filled with the add_template setting when set (--title fills its {title} placeholder). Use --file to add one item per line (or per paragraph with --separator blank) of a plain-text task list; the items are created in order and their ids printed.",
 	RunE:  runAdd,
 }
 var addMessage string
+var addTitle string
 var addTags []string
+var addFile string
+var addSeparator string
+var addExternalID, addExternalURL string
 
 func init() {
 	addCmd.Flags().StringVarP(&addMessage, "message", "m", "", "Description of the work item")
+	addCmd.Flags().StringVar(&addTitle, "title", "", "Fill the {title} placeholder of the add_template editor buffer")
 	addCmd.Flags().StringSliceVarP(&addTags, "tag", "t", nil, "Tag (repeatable)")
+	addCmd.Flags().StringVar(&addFile, "file", "", "Add one item per task in this plain-text file (- for stdin)")
+	addCmd.Flags().StringVar(&addSeparator, "separator", "line", "With --file: line (each non-empty line is an item) or blank (paragraphs separated by blank lines)")
+	addCmd.Flags().StringVar(&addExternalID, "external-id", "", "Id of the item in a source system (e.g. GitHub issue number)")
+	addCmd.Flags().StringVar(&addExternalURL, "external-url", "", "URL of the item in a source system")
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
 	msg := addMessage
-	if msg == "" {
-		var err error
-		msg, err = wn.EditWithEditor("")
+	title := strings.TrimSpace(addTitle)
+	if title != "" && (msg != "" || addFile != "") {
+		return usageErrorf("--title fills the add_template editor buffer; cannot use it with --message or --file")
+	}
+	if addFile != "" && msg != "" {
+		return usageErrorf("cannot use --file with --message; choose one")
+	}
+	if addFile == "" && addSeparator != "line" {
+		return usageErrorf("--separator requires --file")
+	}
+	if addFile != "" && (addExternalID != "" || addExternalURL != "") {
+		return usageErrorf("cannot use --external-id or --external-url with --file; they describe a single item")
+	}
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	settings, _ := wn.ReadSettingsInRoot(root)
+	if title != "" && settings.AddTemplate == "" {
+		return usageErrorf("--title needs the add_template setting")
+	}
+	if addFile == "" && msg == "" && title == "" && !wn.IsTerminal(os.Stdin) {
+		data, err := io.ReadAll(cmd.InOrStdin())
 		if err != nil {
-			return err
+			return fmt.Errorf("read description from stdin: %w", err)
 		}
+		msg = strings.TrimRight(string(data), " \t\r\n")
 		if msg == "" {
 			return fmt.Errorf("empty description")
 		}
 	}
-	root, err := wn.FindRootForCLI()
+	if addFile == "" && msg == "" {
+		// The add_template scaffold pre-fills the buffer; saving it untouched adds nothing unless
+		// a title was given.
+		initial := wn.ExpandAddTemplate(settings.AddTemplate, title)
+		msg, err = wn.EditWithEditor(initial)
+		if err != nil {
+			return editorErr(err)
+		}
+		if strings.TrimSpace(msg) == "" || (title == "" && strings.TrimSpace(msg) == strings.TrimSpace(initial)) {
+			return fmt.Errorf("empty description")
+		}
+	}
+	tags, err := wn.NormalizeTags(addTags, settings.TagLowercase)
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	if addFile != "" {
+		return addFromFile(cmd, store, tags)
+	}
 	id, err := wn.GenerateID(store)
 	if err != nil {
 		return err
@@ -348,9 +879,11 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		Description: msg,
 		Created:     now,
 		Updated:     now,
-		Tags:        addTags,
+		Tags:        tags,
 		DependsOn:   nil,
 		Log:         []wn.LogEntry{{At: now, Kind: "created"}},
+		ExternalID:  strings.TrimSpace(addExternalID),
+		ExternalURL: strings.TrimSpace(addExternalURL),
 	}
 	if err := store.Put(item); err != nil {
 		return err
@@ -365,26 +898,115 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// addFromFile adds one item per task in --file (see wn.SplitTaskList), in order, printing each
+// new id. The current task is left unchanged.
+func addFromFile(cmd *cobra.Command, store wn.Store, tags []string) error {
+	var data []byte
+	var err error
+	if addFile == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(addFile)
+	}
+	if err != nil {
+		return fmt.Errorf("read task file: %w", err)
+	}
+	descs, err := wn.SplitTaskList(string(data), addSeparator)
+	if err != nil {
+		return err
+	}
+	if len(descs) == 0 {
+		return fmt.Errorf("no tasks in %s", addFile)
+	}
+	for _, desc := range descs {
+		id, err := wn.GenerateID(store)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		item := &wn.Item{
+			ID:          id,
+			Description: desc,
+			Created:     now,
+			Updated:     now,
+			Tags:        tags,
+			Log:         []wn.LogEntry{{At: now, Kind: "created"}},
+		}
+		if err := store.Put(item); err != nil {
+			return err
+		}
+		fmt.Printf("added entry %s\n", id)
+	}
+	return nil
+}
+
 var rmCmd = &cobra.Command{
 	Use:   "rm [id ...]",
 	Short: "Remove a work item",
-	Long:  "If no id is given, shows an interactive list (fzf or numbered) with multi-select to remove several items at once. Pass one or more ids to remove those directly.",
+	Long:  "If no id is given, shows an interactive list (fzf or numbered) with multi-select to remove several items at once. Pass one or more ids to remove those directly, or --where <filter> to remove every matching item. Removing more than one item by id or --where lists the items and asks for confirmation unless --yes is given. Removal is refused when another item depends on one being removed, unless --force. Removed items go to the trash (see wn trash) unless --purge.",
 	Args:  cobra.ArbitraryArgs,
 	RunE:  runRm,
 }
+var rmWhere string
+var rmYes bool
+var rmForce bool
+var rmPurge bool
+
+func init() {
+	rmCmd.Flags().StringVar(&rmWhere, "where", "", "Remove every item matching this filter (tag:X, status:S, overdue, has:NOTE, text:SUB)")
+	rmCmd.Flags().BoolVarP(&rmYes, "yes", "y", false, "Do not ask for confirmation when removing several items")
+	rmCmd.Flags().BoolVar(&rmForce, "force", false, "Remove even if other items depend on the removed items")
+	rmCmd.Flags().BoolVar(&rmPurge, "purge", false, "Delete permanently instead of moving to the trash")
+}
+
+// confirm prints prompt and reads a y/yes answer from stdin; anything else (including EOF) is no.
+func confirm(prompt string) (bool, error) {
+	fmt.Print(prompt + " [y/N]: ")
+	sc := bufio.NewScanner(os.Stdin)
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return false, err
+		}
+		return false, io.EOF
+	}
+	answer := strings.ToLower(strings.TrimSpace(sc.Text()))
+	return answer == "y" || answer == "yes", nil
+}
 
 func runRm(cmd *cobra.Command, args []string) error {
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	if rmWhere != "" && len(args) > 0 {
+		return usageErrorf("use either ids or --where, not both")
+	}
 
 	var idsToRemove []string
-	if len(args) == 0 {
+	needConfirm := false
+	switch {
+	case rmWhere != "":
+		f, err := wn.ParseFilter(rmWhere)
+		if err != nil {
+			return err
+		}
+		items, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, it := range wn.FilterItems(items, f, time.Now().UTC()) {
+			idsToRemove = append(idsToRemove, it.ID)
+		}
+		if len(idsToRemove) == 0 {
+			fmt.Println("No matching tasks.")
+			return nil
+		}
+		needConfirm = true
+	case len(args) == 0:
 		items, err := store.List()
 		if err != nil {
 			return err
@@ -401,8 +1023,59 @@ func runRm(cmd *cobra.Command, args []string) error {
 		if len(idsToRemove) == 0 {
 			return nil
 		}
-	} else {
-		idsToRemove = args
+	default:
+		// Repeated ids are removed once.
+		for _, id := range args {
+			if !slices.Contains(idsToRemove, id) {
+				idsToRemove = append(idsToRemove, id)
+			}
+		}
+		needConfirm = len(idsToRemove) > 1
+	}
+
+	removing := make(map[string]bool, len(idsToRemove))
+	for _, id := range idsToRemove {
+		removing[id] = true
+	}
+	var blockers []string
+	var toRemove []*wn.Item
+	for _, id := range idsToRemove {
+		it, err := store.Get(id)
+		if err != nil {
+			return notFoundErrorf("item %s not found", id)
+		}
+		toRemove = append(toRemove, it)
+		dependents, err := wn.Dependents(store, id)
+		if err != nil {
+			return err
+		}
+		for _, d := range dependents {
+			if !removing[d] {
+				blockers = append(blockers, fmt.Sprintf("%s is depended on by %s", id, d))
+			}
+		}
+	}
+	if len(blockers) > 0 {
+		if !rmForce {
+			return fmt.Errorf("%s; use --force to remove anyway", strings.Join(blockers, "; "))
+		}
+		for _, b := range blockers {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", b)
+		}
+	}
+	if needConfirm && !rmYes {
+		for _, it := range toRemove {
+			fmt.Printf("  %s: %s\n", it.ID, wn.FirstLine(it.Description))
+		}
+		ok, err := confirm(fmt.Sprintf("Remove %d items?", len(toRemove)))
+		if err != nil {
+			// No answer (stdin closed or not readable): don't let a script read this as success.
+			return usageErrorf("no confirmation read (%v); pass --yes to remove without asking", err)
+		}
+		if !ok {
+			fmt.Println("Nothing removed.")
+			return nil
+		}
 	}
 
 	meta, err := wn.ReadMeta(root)
@@ -411,13 +1084,15 @@ func runRm(cmd *cobra.Command, args []string) error {
 	}
 	clearCurrent := false
 	for _, id := range idsToRemove {
-		if _, err := store.Get(id); err != nil {
-			return fmt.Errorf("item %s not found", id)
-		}
 		if id == meta.CurrentID {
 			clearCurrent = true
 		}
-		if err := store.Delete(id); err != nil {
+		if rmPurge {
+			err = store.Delete(id)
+		} else {
+			err = wn.TrashItem(store, id)
+		}
+		if err != nil {
 			return err
 		}
 		fmt.Printf("removed entry %s\n", id)
@@ -431,6 +1106,88 @@ func runRm(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore, or empty removed work items",
+	Long:  "wn rm moves items to .wn/trash instead of deleting them (unless --purge). Use these subcommands to see what was removed, bring an item back, or delete the trash for good.",
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trashed items (most recently removed first)",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id> [id ...]",
+	Short: "Move trashed items back into the tracker",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete every trashed item",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashEmpty,
+}
+
+func init() {
+	trashCmd.AddCommand(trashListCmd, trashRestoreCmd, trashEmptyCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	entries, err := wn.ListTrash(root)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+	for _, te := range entries {
+		fmt.Printf("%s  %s  %s\n", te.Item.ID, te.DeletedAt.Local().Format("2006-01-02 15:04"), wn.FirstLine(te.Item.Description))
+	}
+	return nil
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	for _, id := range args {
+		item, err := wn.RestoreTrashItem(store, id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("restored %s: %s\n", item.ID, wn.FirstLine(item.Description))
+	}
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	n, err := wn.EmptyTrash(root)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("permanently deleted %d item(s)\n", n)
+	return nil
+}
+
 var archiveLocation string
 
 var archiveCmd = &cobra.Command{
@@ -454,7 +1211,7 @@ func runArchive(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -543,22 +1300,22 @@ func runEdit(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
-	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+	return editorErr(store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		edited, err := wn.EditWithEditor(it.Description)
 		if err != nil {
 			return nil, err
 		}
 		it.Description = edited
 		it.Updated = time.Now().UTC()
-		it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "updated"})
+		it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "updated", Msg: wn.EditSourceCLI})
 		return it, nil
-	})
+	}))
 }
 
 var tagCmd = &cobra.Command{
@@ -569,11 +1326,14 @@ var tagCmd = &cobra.Command{
 
 var tagWid string
 var tagAddInteractive bool
+var tagAddWhere string
+var tagAddJson bool
+var tagRmJson bool
 
 var tagAddCmd = &cobra.Command{
 	Use:   "add <tag-name>",
 	Short: "Add a tag to a work item",
-	Long:  "Add a tag. Use --wid <id> to specify the work item; when omitted, uses the current task. Use -i/--interactive to pick items with fzf and toggle the tag on each selected item.",
+	Long:  "Add a tag. Use --wid <id> to specify the work item; when omitted, uses the current task. Use -i/--interactive to pick items with fzf and toggle the tag on each selected item. Use --where to add the tag to every item matching a filter (e.g. --where \"tag:backend status:undone\" or --where overdue); affected ids are printed.",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runTagAdd,
 }
@@ -597,6 +1357,9 @@ var tagListCmd = &cobra.Command{
 func init() {
 	tagCmd.PersistentFlags().StringVar(&tagWid, "wid", "", "Work item id (default: current task)")
 	tagAddCmd.Flags().BoolVarP(&tagAddInteractive, "interactive", "i", false, "Pick work items with fzf (or numbered list); toggle tag on selected items")
+	tagAddCmd.Flags().StringVar(&tagAddWhere, "where", "", "Add the tag to every item matching this filter (tag:X, status:S, overdue, has:NOTE, text:SUB)")
+	tagAddCmd.Flags().BoolVar(&tagAddJson, "json", false, "Print the result as JSON (id, action, ...) for scripting")
+	tagRmCmd.Flags().BoolVar(&tagRmJson, "json", false, "Print the result as JSON (id, action, ...) for scripting")
 	tagCmd.AddCommand(tagAddCmd, tagRmCmd, tagListCmd)
 }
 
@@ -612,27 +1375,44 @@ func resolveTagWid() (string, error) {
 	return wn.ResolveItemID(meta.CurrentID, tagWid)
 }
 
+// normalizeTagArg trims and validates a tag from the command line, lowercasing it when
+// settings tag_lowercase is set.
+func normalizeTagArg(tag string) (string, error) {
+	lowercase := false
+	if root, err := wn.FindRootForCLI(); err == nil {
+		settings, _ := wn.ReadSettingsInRoot(root)
+		lowercase = settings.TagLowercase
+	}
+	return wn.NormalizeTag(tag, lowercase)
+}
+
 func runTagAdd(cmd *cobra.Command, args []string) error {
+	if tagAddWhere != "" {
+		if tagAddInteractive || tagWid != "" {
+			return usageErrorf("--where cannot be combined with -i or --wid")
+		}
+		return runTagAddWhere(args[0], tagAddWhere)
+	}
 	if tagAddInteractive {
 		return runTagInteractive(args)
 	}
-	tag := args[0]
-	if err := wn.ValidateTag(tag); err != nil {
+	tag, err := normalizeTagArg(args[0])
+	if err != nil {
 		return err
 	}
 	id, err := resolveTagWid()
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
-	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		for _, t := range it.Tags {
 			if t == tag {
 				return it, nil
@@ -642,22 +1422,74 @@ func runTagAdd(cmd *cobra.Command, args []string) error {
 		it.Updated = time.Now().UTC()
 		it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "tag_added", Msg: tag})
 		return it, nil
-	})
+	}); err != nil {
+		return err
+	}
+	if tagAddJson {
+		return printActionResult(actionResult{ID: id, Action: "tag_added", Tag: tag})
+	}
+	return nil
+}
+
+// runTagAddWhere adds tag to every item matching the filter expression and prints the ids it changed.
+func runTagAddWhere(tag, where string) error {
+	tag, err := normalizeTagArg(tag)
+	if err != nil {
+		return err
+	}
+	filter, err := wn.ParseFilter(where)
+	if err != nil {
+		return err
+	}
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, it := range wn.FilterItems(items, filter, now) {
+		if slices.Contains(it.Tags, tag) {
+			continue
+		}
+		if err := store.UpdateItem(it.ID, func(it *wn.Item) (*wn.Item, error) {
+			it.Tags = append(it.Tags, tag)
+			it.Updated = now
+			it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "tag_added", Msg: tag})
+			return it, nil
+		}); err != nil {
+			return err
+		}
+		if tagAddJson {
+			if err := printActionResult(actionResult{ID: it.ID, Action: "tag_added", Tag: tag}); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Println(it.ID)
+	}
+	return nil
 }
 
 func runTagInteractive(args []string) error {
 	if len(args) != 1 {
-		return fmt.Errorf("interactive tag requires exactly one argument: the tag name")
+		return usageErrorf("interactive tag requires exactly one argument: the tag name")
 	}
-	tag := args[0]
-	if err := wn.ValidateTag(tag); err != nil {
+	tag, err := normalizeTagArg(args[0])
+	if err != nil {
 		return err
 	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -707,49 +1539,66 @@ func runTagInteractive(args []string) error {
 }
 
 func runTagRm(cmd *cobra.Command, args []string) error {
-	tag := args[0]
+	// Match the tag as given or in its normalized form, so tags stored before normalization (or
+	// that no longer validate) can still be removed.
+	tag := strings.TrimSpace(args[0])
+	if tag == "" {
+		return usageErrorf("tag is empty")
+	}
+	normalized, err := normalizeTagArg(tag)
+	if err != nil {
+		normalized = tag
+	}
 	id, err := resolveTagWid()
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
-	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		var newTags []string
 		for _, t := range it.Tags {
-			if t != tag {
-				newTags = append(newTags, t)
+			if t == tag || t == normalized {
+				tag = t
+				continue
 			}
+			newTags = append(newTags, t)
 		}
 		it.Tags = newTags
 		it.Updated = time.Now().UTC()
 		it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "tag_removed", Msg: tag})
 		return it, nil
-	})
+	}); err != nil {
+		return err
+	}
+	if tagRmJson {
+		return printActionResult(actionResult{ID: id, Action: "tag_removed", Tag: tag})
+	}
+	return nil
 }
 
 func runTagList(cmd *cobra.Command, args []string) error {
 	id, err := resolveTagWid()
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
 	item, err := store.Get(id)
 	if err != nil {
-		return fmt.Errorf("item %s not found", id)
+		return notFoundErrorf("item %s not found", id)
 	}
 	out := cmd.Root().OutOrStdout()
 	for _, t := range item.Tags {
@@ -762,24 +1611,28 @@ func runTagList(cmd *cobra.Command, args []string) error {
 var dependCmd = &cobra.Command{
 	Use:   "depend",
 	Short: "Add, remove, or list dependencies on a work item",
-	Long:  "Use 'wn depend add --on <id> [--wid <id>]', 'wn depend rm --on <id> [--wid <id>]', and 'wn depend list [--wid <id>]'. Omit --wid to use the current task.",
+	Long:  "Use 'wn depend add --on <id> [--wid <id>]', 'wn depend rm --on <id> [--wid <id>]', and 'wn depend list [--wid <id>]'. Omit --wid to use the current task. 'wn depend check' looks for cycles across all items.",
 }
 
 var dependAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Mark an item as depending on another",
-	Long:  "Add a dependency. Use --on for the dependency id; omit --wid to use the current task. Use -i to pick the depended-on item interactively (fzf or numbered list).",
+	Long:  "Add a dependency. Use --on for the dependency id; omit --wid to use the current task. Use -i to pick the depended-on item interactively (fzf or numbered list). Use --on-tag to depend on every undone item with that tag; this is a one-time expansion into individual dependencies, not a live rule (items tagged later are not added).",
 	Args:  cobra.NoArgs,
 	RunE:  runDependAdd,
 }
 var dependAddOn string
+var dependAddOnTag string
 var dependAddWid string
 var dependAddInteractive bool
+var dependAddJson bool
 
 func init() {
 	dependAddCmd.Flags().StringVar(&dependAddOn, "on", "", "ID of the item this one will depend on")
+	dependAddCmd.Flags().StringVar(&dependAddOnTag, "on-tag", "", "Depend on every undone item with this tag (one-time expansion)")
 	dependAddCmd.Flags().StringVar(&dependAddWid, "wid", "", "Work item id (current task when omitted)")
 	dependAddCmd.Flags().BoolVarP(&dependAddInteractive, "interactive", "i", false, "Pick the depended-on item with fzf (undone items only)")
+	dependAddCmd.Flags().BoolVar(&dependAddJson, "json", false, "Print the result as JSON (id, action, ...) for scripting")
 	dependCmd.AddCommand(dependAddCmd)
 }
 
@@ -794,12 +1647,18 @@ func runDependAdd(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, dependAddWid)
 	if err != nil {
-		return fmt.Errorf("no work item (use --wid or set current task)")
+		return emptyErrorf("no work item (use --wid or set current task)")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	if dependAddOnTag != "" {
+		if dependAddOn != "" || dependAddInteractive {
+			return usageErrorf("--on-tag cannot be combined with --on or -i")
+		}
+		return runDependAddOnTag(store, id, dependAddOnTag)
+	}
 	var onID string
 	if dependAddInteractive {
 		onID, err = runDependInteractive(store, root, id)
@@ -811,10 +1670,13 @@ func runDependAdd(cmd *cobra.Command, args []string) error {
 		}
 	} else {
 		if dependAddOn == "" {
-			return fmt.Errorf("required flag \"on\" not set")
+			return usageErrorf("required flag \"on\" not set")
 		}
 		onID = dependAddOn
 	}
+	if onID == id {
+		return wn.ErrSelfDependency
+	}
 	items, err := store.List()
 	if err != nil {
 		return err
@@ -822,7 +1684,7 @@ func runDependAdd(cmd *cobra.Command, args []string) error {
 	if wn.WouldCreateCycle(items, id, onID) {
 		return fmt.Errorf("circular dependency detected, could not mark entry %s dependent on %s", id, onID)
 	}
-	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		for _, d := range it.DependsOn {
 			if d == onID {
 				return it, nil
@@ -832,7 +1694,64 @@ func runDependAdd(cmd *cobra.Command, args []string) error {
 		it.Updated = time.Now().UTC()
 		it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "depend_added", Msg: onID})
 		return it, nil
-	})
+	}); err != nil {
+		return err
+	}
+	if dependAddJson {
+		return printActionResult(actionResult{ID: id, Action: "depend_added", On: onID})
+	}
+	return nil
+}
+
+// runDependAddOnTag adds a dependency from id to each undone item carrying tag (excluding id itself),
+// skipping any edge that would create a cycle. Prints how many dependencies were added.
+func runDependAddOnTag(store wn.Store, id, tag string) error {
+	tag, err := normalizeTagArg(tag)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Get(id); err != nil {
+		return err
+	}
+	undone, err := wn.UndoneItems(store)
+	if err != nil {
+		return err
+	}
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+	var self *wn.Item
+	for _, it := range items {
+		if it.ID == id {
+			self = it
+			break
+		}
+	}
+	added := 0
+	for _, target := range wn.FilterByTag(undone, tag) {
+		if target.ID == id || slices.Contains(self.DependsOn, target.ID) {
+			continue
+		}
+		if wn.WouldCreateCycle(items, id, target.ID) {
+			fmt.Fprintf(os.Stderr, "skip %s: circular dependency\n", target.ID)
+			continue
+		}
+		onID := target.ID
+		if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+			it.DependsOn = append(it.DependsOn, onID)
+			it.Updated = time.Now().UTC()
+			it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "depend_added", Msg: onID})
+			return it, nil
+		}); err != nil {
+			return err
+		}
+		// Keep the in-memory graph current so later cycle checks see this edge.
+		self.DependsOn = append(self.DependsOn, onID)
+		added++
+	}
+	fmt.Printf("added %d dependencies on items tagged %s\n", added, tag)
+	return nil
 }
 
 func runDependInteractive(store wn.Store, root string, excludeID string) (string, error) {
@@ -856,18 +1775,22 @@ func runDependInteractive(store wn.Store, root string, excludeID string) (string
 var dependRmCmd = &cobra.Command{
 	Use:   "rm",
 	Short: "Remove a dependency",
-	Long:  "Remove a dependency. Use --on for the dependency id to remove; omit --wid to use the current task. Use -i to pick which dependency to remove (fzf or numbered list).",
+	Long:  "Remove a dependency. Use --on for the dependency id to remove; omit --wid to use the current task. Use -i to pick which dependency to remove (fzf or numbered list), or --all to remove every dependency of the item.",
 	Args:  cobra.NoArgs,
 	RunE:  runDependRm,
 }
 var dependRmOn string
 var dependRmWid string
 var dependRmInteractive bool
+var dependRmAll bool
+var dependRmJson bool
 
 func init() {
 	dependRmCmd.Flags().StringVar(&dependRmOn, "on", "", "ID of the dependency to remove")
 	dependRmCmd.Flags().StringVar(&dependRmWid, "wid", "", "Work item id (current task when omitted)")
 	dependRmCmd.Flags().BoolVarP(&dependRmInteractive, "interactive", "i", false, "Pick the dependency to remove with fzf")
+	dependRmCmd.Flags().BoolVar(&dependRmAll, "all", false, "Remove all dependencies of the work item")
+	dependRmCmd.Flags().BoolVar(&dependRmJson, "json", false, "Print the result as JSON (id, action, ...) for scripting")
 	dependCmd.AddCommand(dependRmCmd)
 }
 
@@ -882,12 +1805,26 @@ func runDependRm(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, dependRmWid)
 	if err != nil {
-		return fmt.Errorf("no work item (use --wid or set current task)")
+		return emptyErrorf("no work item (use --wid or set current task)")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	if dependRmAll {
+		if dependRmOn != "" || dependRmInteractive {
+			return usageErrorf("cannot use --all with --on or -i; choose one")
+		}
+		removed, err := wn.ClearDependencies(store, id)
+		if err != nil {
+			return err
+		}
+		if dependRmJson {
+			return printActionResult(actionResult{ID: id, Action: "depends_cleared"})
+		}
+		fmt.Printf("removed %d dependencies from %s\n", len(removed), id)
+		return nil
+	}
 	var onID string
 	if dependRmInteractive {
 		onID, err = runRmdependInteractive(store, root, id)
@@ -899,11 +1836,11 @@ func runDependRm(cmd *cobra.Command, args []string) error {
 		}
 	} else {
 		if dependRmOn == "" {
-			return fmt.Errorf("required flag \"on\" not set")
+			return usageErrorf("required flag \"on\" not set")
 		}
 		onID = dependRmOn
 	}
-	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		var newDeps []string
 		for _, d := range it.DependsOn {
 			if d != onID {
@@ -914,7 +1851,13 @@ func runDependRm(cmd *cobra.Command, args []string) error {
 		it.Updated = time.Now().UTC()
 		it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "depend_removed", Msg: onID})
 		return it, nil
-	})
+	}); err != nil {
+		return err
+	}
+	if dependRmJson {
+		return printActionResult(actionResult{ID: id, Action: "depend_removed", On: onID})
+	}
+	return nil
 }
 
 func runRmdependInteractive(store wn.Store, root string, id string) (string, error) {
@@ -962,15 +1905,15 @@ func runDependList(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, dependListWid)
 	if err != nil {
-		return fmt.Errorf("no work item (use --wid or set current task)")
+		return emptyErrorf("no work item (use --wid or set current task)")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
 	item, err := store.Get(id)
 	if err != nil {
-		return fmt.Errorf("item %s not found", id)
+		return notFoundErrorf("item %s not found", id)
 	}
 	out := cmd.OutOrStdout()
 	for _, depID := range item.DependsOn {
@@ -979,6 +1922,43 @@ func runDependList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var dependCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the whole dependency graph for cycles",
+	Long:  "Check every item's dependencies for cycles (e.g. left by imports or manual edits) and print each one as a chain like \"a → b → c → a\". Exits non-zero when a cycle is found. wn doctor reports the same cycles per item.",
+	Args:  cobra.NoArgs,
+	RunE:  runDependCheck,
+}
+
+func init() {
+	dependCmd.AddCommand(dependCheckCmd)
+}
+
+func runDependCheck(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+	cycles := wn.DependencyCycles(items)
+	out := cmd.OutOrStdout()
+	if len(cycles) == 0 {
+		fmt.Fprintln(out, "No dependency cycles.")
+		return nil
+	}
+	for _, chain := range cycles {
+		fmt.Fprintf(out, "cycle: %s\n", wn.FormatCycle(chain))
+	}
+	return fmt.Errorf("found %d dependency cycle(s)", len(cycles))
+}
+
 // interactiveSortSpec returns sort options from effective settings (user + project) for fzf/numbered lists. No CLI override.
 func interactiveSortSpec(root string) []wn.SortOption {
 	settings, err := wn.ReadSettingsInRoot(root)
@@ -988,24 +1968,79 @@ func interactiveSortSpec(root string) []wn.SortOption {
 	return wn.SortSpecFromSettings(settings)
 }
 
+// actionResult is printed by mutating commands with --json, e.g. {"id":"abc123","action":"done"}.
+// Action mirrors the log entry kind (done, claimed, released, next, tag_added, tag_removed,
+// depend_added, depend_removed). For next, id is empty when there is no next task.
+type actionResult struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Tag    string `json:"tag,omitempty"`
+	On     string `json:"on,omitempty"`
+	Until  string `json:"until,omitempty"`
+	Next   string `json:"next,omitempty"`
+}
+
+func printActionResult(r actionResult) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
 var doneCmd = &cobra.Command{
 	Use:   "done [id]",
 	Short: "Mark a work item complete",
-	Long:  "If id is omitted, marks the current task complete. Use --next to then set the next undone item as current (convenience for done + next).",
+	Long:  "If id is omitted, marks the current task complete. Use --next to then set the next undone item as current (convenience for done + next).\n\nWith --next and an empty queue, wn done prints \"No next task.\" on stderr (nothing with --quiet) and exits 4 even though the item was marked done, so scripts running under set -e should allow that code (e.g. wn done --next || [ $? -eq 4 ]).",
 	Args:  cobra.MaximumNArgs(1),
 	RunE:  runDone,
 }
 var doneMessage string
 var doneForce bool
 var doneNext bool
+var doneJson bool
+var doneMessageFile string
+var doneAmend bool
+var doneQuiet bool
 
 func init() {
 	doneCmd.Flags().StringVarP(&doneMessage, "message", "m", "", "Completion message (e.g. git commit)")
+	doneCmd.Flags().StringVar(&doneMessageFile, "message-file", "", "Read the completion message from this file (- for stdin)")
 	doneCmd.Flags().BoolVar(&doneForce, "force", false, "Mark complete even if dependencies are not done")
 	doneCmd.Flags().BoolVar(&doneNext, "next", false, "After marking done, set the next undone item as current (like running wn next)")
+	doneCmd.Flags().BoolVar(&doneJson, "json", false, "Print the result as JSON (id, action, ...) for scripting")
+	doneCmd.Flags().BoolVar(&doneAmend, "amend", false, "Replace the completion message of an already-done item (with -m or --message-file)")
+	doneCmd.Flags().BoolVar(&doneQuiet, "quiet", false, "With --next: print nothing when there is no next task (the exit code is still 4)")
+}
+
+// resolveMessage returns the -m message, or the contents of --message-file ("-" reads stdin)
+// with trailing whitespace trimmed. The two flags are mutually exclusive.
+func resolveMessage(cmd *cobra.Command, message, file string) (string, error) {
+	if file == "" {
+		return message, nil
+	}
+	if message != "" {
+		return "", usageErrorf("cannot use both --message and --message-file; choose one")
+	}
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read message file: %w", err)
+	}
+	return strings.TrimRight(string(data), " \t\r\n"), nil
 }
 
 func runDone(cmd *cobra.Command, args []string) error {
+	if doneQuiet && (!doneNext || doneJson) {
+		return usageErrorf("--quiet requires --next and cannot be used with --json")
+	}
+	msg, err := resolveMessage(cmd, doneMessage, doneMessageFile)
+	if err != nil {
+		return err
+	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
@@ -1020,30 +2055,87 @@ func runDone(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	if doneAmend {
+		return amendDone(store, id, msg)
+	}
 	item, err := store.Get(id)
 	if err != nil {
 		return err
 	}
 	if !doneForce {
-		for _, depID := range item.DependsOn {
-			dep, err := store.Get(depID)
-			if err != nil {
+		if err := checkDoneDeps(store, item, nil); err != nil {
+			return fmt.Errorf("%w, use --force to mark complete anyway", err)
+		}
+	}
+	if err := markItemDone(store, item, msg, time.Now().UTC()); err != nil {
+		return err
+	}
+	if !doneNext {
+		if doneJson {
+			return printActionResult(actionResult{ID: id, Action: "done"})
+		}
+		return nil
+	}
+	// Pick the next item the way wn next does.
+	settings, _ := wn.ReadSettingsInRoot(root)
+	next, err := wn.NextUndoneItemOrdered(store, "", wn.NextExcludeTags(settings, nil), wn.NextOrdering(settings, settings.Next.StrictOrder))
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		// The item is done; the exit code still tells a loop that the queue is empty.
+		if doneJson {
+			if err := printActionResult(actionResult{ID: id, Action: "done"}); err != nil {
 				return err
 			}
-			if dep.Done || dep.PromptReady {
-				continue
-			}
-			return fmt.Errorf("dependency %s not complete, use --force to mark complete anyway", depID)
+			return errEmpty
 		}
+		if !doneQuiet {
+			fmt.Fprintln(os.Stderr, "No next task.")
+		}
+		return errEmpty
 	}
-	now := time.Now().UTC()
-	// Auto-mark prompt deps as done.
+	if err := wn.WithMetaLock(root, func(m wn.Meta) (wn.Meta, error) {
+		m.CurrentID = next.ID
+		return m, nil
+	}); err != nil {
+		return err
+	}
+	if doneJson {
+		return printActionResult(actionResult{ID: id, Action: "done", Next: next.ID})
+	}
+	fmt.Printf("  %s: %s\n", next.ID, next.Description)
+	return nil
+}
+
+// checkDoneDeps returns an error naming the first dependency of item that is neither done nor
+// prompt-ready (prompt deps are closed along with it). Dependencies in also (e.g. the rest of a
+// batch being marked done) count as done.
+func checkDoneDeps(store wn.Store, item *wn.Item, also map[string]bool) error {
+	for _, depID := range item.DependsOn {
+		if also[depID] {
+			continue
+		}
+		dep, err := store.Get(depID)
+		if err != nil {
+			return err
+		}
+		if !dep.Done && !dep.PromptReady {
+			return fmt.Errorf("dependency %s not complete", depID)
+		}
+	}
+	return nil
+}
+
+// markItemDone marks item done with msg as its DoneMessage and "done" log message, first
+// auto-closing its prompt-ready dependencies. Shared by wn done and wn pick --mark-done.
+func markItemDone(store wn.Store, item *wn.Item, msg string, now time.Time) error {
 	for _, depID := range item.DependsOn {
 		dep, err := store.Get(depID)
 		if err != nil {
@@ -1062,37 +2154,40 @@ func runDone(cmd *cobra.Command, args []string) error {
 			}
 		}
 	}
-	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+	return store.UpdateItem(item.ID, func(it *wn.Item) (*wn.Item, error) {
 		it.Done = true
-		it.DoneMessage = doneMessage
+		it.DoneMessage = msg
 		it.DoneStatus = wn.DoneStatusDone
 		it.ReviewReady = false
 		it.Updated = now
-		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "done", Msg: doneMessage})
+		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "done", Msg: msg})
 		return it, nil
-	}); err != nil {
-		return err
-	}
-	if !doneNext {
-		return nil
-	}
-	undone, err := wn.UndoneItems(store)
-	if err != nil {
-		return err
+	})
+}
+
+// amendDone replaces the DoneMessage of a done item, logging "amended" instead of a second "done".
+func amendDone(store wn.Store, id, msg string) error {
+	if msg == "" {
+		return usageErrorf("--amend requires -m or --message-file")
 	}
-	ordered, acyclic := wn.TopoOrder(undone)
-	if !acyclic || len(ordered) == 0 {
-		fmt.Println("No next task.")
-		return nil
+	if doneNext || doneForce {
+		return usageErrorf("--amend cannot be combined with --next or --force")
 	}
-	next := ordered[0]
-	if err := wn.WithMetaLock(root, func(m wn.Meta) (wn.Meta, error) {
-		m.CurrentID = next.ID
-		return m, nil
+	now := time.Now().UTC()
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+		if !it.Done {
+			return nil, fmt.Errorf("item %s is not done; --amend only changes the message of a done item", id)
+		}
+		it.DoneMessage = msg
+		it.Updated = now
+		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "amended", Msg: msg})
+		return it, nil
 	}); err != nil {
 		return err
 	}
-	fmt.Printf("  %s: %s\n", next.ID, next.Description)
+	if doneJson {
+		return printActionResult(actionResult{ID: id, Action: "amended"})
+	}
 	return nil
 }
 
@@ -1119,9 +2214,9 @@ func runUndone(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -1148,11 +2243,13 @@ var statusFor string
 var statusMessage string
 var statusClaimBy string
 var statusDuplicateOf string
+var statusMessageFile string
 
 func init() {
 	statusCmd.Flags().StringVar(&statusFor, "for", "", "Claim duration when setting to claimed (e.g. 30m, 1h); default 1h")
 	statusCmd.Flags().StringVarP(&statusMessage, "message", "m", "", "Optional message when setting to done, closed, or suspend")
-	statusCmd.Flags().StringVar(&statusClaimBy, "by", "", "Optional worker ID when setting to claimed")
+	statusCmd.Flags().StringVar(&statusMessageFile, "message-file", "", "Read the done/closed/suspend message from this file (- for stdin)")
+	statusCmd.Flags().StringVar(&statusClaimBy, "by", "", "Worker ID when setting to claimed (default: settings worker_id, else hostname)")
 	statusCmd.Flags().StringVar(&statusDuplicateOf, "duplicate-of", "", "When setting to closed: mark item as duplicate of this work item id (adds duplicate-of note)")
 }
 
@@ -1175,16 +2272,27 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
 	if state != wn.StatusClosed && statusDuplicateOf != "" {
-		return fmt.Errorf("--duplicate-of is only valid when setting status to closed")
+		return usageErrorf("--duplicate-of is only valid when setting status to closed")
+	}
+	msg, err := resolveMessage(cmd, statusMessage, statusMessageFile)
+	if err != nil {
+		return err
+	}
+	opts := wn.StatusOpts{DoneMessage: msg, ClaimBy: statusClaimBy, DuplicateOf: statusDuplicateOf}
+	if state == wn.StatusClaimed {
+		settings, _ := wn.ReadSettingsInRoot(root)
+		opts.ClaimBy = wn.ClaimWorker(settings, statusClaimBy)
+		if statusFor == "" {
+			opts.ClaimFor = wn.DefaultClaimFor(settings)
+		}
 	}
-	opts := wn.StatusOpts{DoneMessage: statusMessage, ClaimBy: statusClaimBy, DuplicateOf: statusDuplicateOf}
 	if state == wn.StatusClaimed && statusFor != "" {
 		d, err := time.ParseDuration(statusFor)
 		if err != nil {
@@ -1206,25 +2314,135 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var duplicateCmd = &cobra.Command{
+	Use:   "duplicate [id] --of <original>",
+	Short: "Mark a work item as a duplicate of another",
+	Long:  "Mark the work item as a duplicate of --of: adds the duplicate-of note and closes the item so it leaves the queue (same as 'wn status closed --duplicate-of'). If id is omitted, uses the current task. Use --keep-open to only add the note and leave the status unchanged, e.g. to flag a suspected duplicate for review.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runDuplicate,
+}
+var duplicateOf string
+var duplicateKeepOpen bool
+
+func init() {
+	duplicateCmd.Flags().StringVar(&duplicateOf, "of", "", "Id of the original work item (required)")
+	duplicateCmd.Flags().BoolVar(&duplicateKeepOpen, "keep-open", false, "Add the duplicate-of note without closing the item")
+	_ = duplicateCmd.MarkFlagRequired("of")
+}
+
+func runDuplicate(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	meta, err := wn.ReadMeta(root)
+	if err != nil {
+		return err
+	}
+	explicitID := ""
+	if len(args) > 0 {
+		explicitID = args[0]
+	}
+	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
+	if err != nil {
+		return emptyErrorf("no id provided and no current task")
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	if duplicateKeepOpen {
+		if err := wn.FlagDuplicateOf(store, id, duplicateOf); err != nil {
+			return err
+		}
+		fmt.Printf("marked %s as duplicate of %s (kept open)\n", id, duplicateOf)
+		return nil
+	}
+	if err := wn.MarkDuplicateOf(store, id, duplicateOf); err != nil {
+		return err
+	}
+	fmt.Printf("marked %s as duplicate of %s\n", id, duplicateOf)
+	return nil
+}
+
+var mergeDuplicatesCmd = &cobra.Command{
+	Use:   "merge-duplicates <id>... --into <canonical>",
+	Short: "Fold duplicate work items into their canonical item",
+	Long:  "For each duplicate id: copy its notes onto --into (a note name already in use there gets a -<id> suffix; identical notes are skipped), repoint every item that depends on the duplicate to depend on --into instead, then close the duplicate with a duplicate-of note. A duplicate whose dependents cannot be repointed without a cycle is left unchanged.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runMergeDuplicates,
+}
+var mergeDuplicatesInto string
+
+func init() {
+	mergeDuplicatesCmd.Flags().StringVar(&mergeDuplicatesInto, "into", "", "Id of the canonical work item (required)")
+	_ = mergeDuplicatesCmd.MarkFlagRequired("into")
+}
+
+func runMergeDuplicates(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	for _, id := range args {
+		res, err := wn.MergeDuplicate(store, id, mergeDuplicatesInto)
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		fmt.Printf("merged %s into %s\n", id, mergeDuplicatesInto)
+		for _, n := range res.Notes {
+			if n.As != n.Name {
+				fmt.Printf("  note %s (as %s)\n", n.Name, n.As)
+			} else {
+				fmt.Printf("  note %s\n", n.Name)
+			}
+		}
+		for _, name := range res.SkippedNotes {
+			fmt.Printf("  note %s skipped (already on %s)\n", name, mergeDuplicatesInto)
+		}
+		for _, dep := range res.Repointed {
+			fmt.Printf("  dependency of %s repointed\n", dep)
+		}
+	}
+	return nil
+}
+
 var claimCmd = &cobra.Command{
 	Use:   "claim [id]",
 	Short: "Mark a work item in progress (exclusive until expiration)",
-	Long:  "Claims the item so it leaves the undone list until --for duration expires or you run wn done/release. If id is omitted, uses current task. Omit --for to use default (1h) and renew/extend a claim without losing context.",
+	Long:  "Claims the item so it leaves the undone list until --for duration (or the --until time) expires or you run wn done/release. If id is omitted, uses current task. Omit --for to use the default (settings default_claim, or 1h) and renew/extend a claim without losing context. If the item depends on items that are not done, the claim still succeeds but a warning listing them is printed to stderr (--no-warn to silence).",
 	Args:  cobra.MaximumNArgs(1),
 	RunE:  runClaim,
 }
 var claimFor string
+var claimUntil string
 var claimBy string
+var claimJson bool
+var claimNoWarn bool
 
 func init() {
-	claimCmd.Flags().StringVar(&claimFor, "for", "", "Duration the claim is held (e.g. 30m, 1h); default 1h so you can renew with just wn claim")
-	claimCmd.Flags().StringVar(&claimBy, "by", "", "Optional worker ID for logging")
+	claimCmd.Flags().StringVar(&claimFor, "for", "", "Duration the claim is held (e.g. 30m, 1h); default is settings default_claim or 1h, so you can renew with just wn claim")
+	claimCmd.Flags().StringVar(&claimUntil, "until", "", "Hold the claim until an absolute time: RFC 3339 or HH:MM today (local); cannot be combined with --for")
+	claimCmd.Flags().StringVar(&claimBy, "by", "", "Worker ID for logging (default: settings worker_id, else hostname)")
+	claimCmd.Flags().BoolVar(&claimJson, "json", false, "Print the result as JSON (id, action, ...) for scripting")
+	claimCmd.Flags().BoolVar(&claimNoWarn, "no-warn", false, "Do not warn when the item has dependencies that are not done")
 }
 
 func runClaim(cmd *cobra.Command, args []string) error {
-	d := wn.DefaultClaimDuration
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	if claimFor != "" && claimUntil != "" {
+		return usageErrorf("use either --for or --until, not both")
+	}
+	settings, _ := wn.ReadSettingsInRoot(root)
+	d := wn.DefaultClaimFor(settings)
 	if claimFor != "" {
-		var err error
 		d, err = time.ParseDuration(claimFor)
 		if err != nil {
 			return fmt.Errorf("invalid --for duration %q: %w", claimFor, err)
@@ -1237,9 +2455,15 @@ func runClaim(cmd *cobra.Command, args []string) error {
 	if claimForMsg == "" {
 		claimForMsg = d.String()
 	}
-	root, err := wn.FindRootForCLI()
-	if err != nil {
-		return err
+	now := time.Now().UTC()
+	until := now.Add(d)
+	if claimUntil != "" {
+		t, err := wn.ParseUntil(claimUntil, time.Now())
+		if err != nil {
+			return err
+		}
+		until = t.UTC()
+		claimForMsg = "until " + until.Format(time.RFC3339)
 	}
 	meta, err := wn.ReadMeta(root)
 	if err != nil {
@@ -1251,21 +2475,41 @@ func runClaim(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task; use wn pick or wn next")
+		return emptyErrorf("no id provided and no current task; use wn pick or wn next")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
-	now := time.Now().UTC()
-	until := now.Add(d)
-	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+	claimed, err := claimItem(store, id, until, wn.ClaimWorker(settings, claimBy), claimForMsg, now)
+	if err != nil {
+		return err
+	}
+	if !claimNoWarn {
+		if deps := wn.UnfinishedDependencies(store, &claimed); len(deps) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s depends on items that are not done: %s\n", id, wn.DescribeItems(deps))
+		}
+	}
+	if claimJson {
+		return printActionResult(actionResult{ID: id, Action: "claimed", Until: until.Format(time.RFC3339)})
+	}
+	return nil
+}
+
+// claimItem marks id in progress by worker until the given time, logging msg (the claim
+// duration as given) with the in_progress entry. Returns the claimed item. Shared by wn claim
+// and wn pick --claim.
+func claimItem(store wn.Store, id string, until time.Time, by, msg string, now time.Time) (wn.Item, error) {
+	var claimed wn.Item
+	err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		it.InProgressUntil = until
-		it.InProgressBy = claimBy
+		it.InProgressBy = by
 		it.Updated = now
-		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "in_progress", Msg: claimForMsg})
+		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "in_progress", Msg: msg})
+		claimed = *it
 		return it, nil
 	})
+	return claimed, err
 }
 
 var releaseCmd = &cobra.Command{
@@ -1276,7 +2520,60 @@ var releaseCmd = &cobra.Command{
 	RunE:  runRelease,
 }
 
-func runRelease(cmd *cobra.Command, args []string) error {
+var releaseJson bool
+
+func init() {
+	releaseCmd.Flags().BoolVar(&releaseJson, "json", false, "Print the result as JSON (id, action, ...) for scripting")
+}
+
+func runRelease(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	meta, err := wn.ReadMeta(root)
+	if err != nil {
+		return err
+	}
+	explicitID := ""
+	if len(args) > 0 {
+		explicitID = args[0]
+	}
+	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
+	if err != nil {
+		return emptyErrorf("no id provided and no current task")
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+		it.InProgressUntil = time.Time{}
+		it.InProgressBy = ""
+		it.ReviewReady = true
+		it.Updated = now
+		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "released"})
+		return it, nil
+	}); err != nil {
+		return err
+	}
+	if releaseJson {
+		return printActionResult(actionResult{ID: id, Action: "released"})
+	}
+	return nil
+}
+
+var reviewReadyCmd = &cobra.Command{
+	Use:     "review-ready [id]",
+	Aliases: []string{"rr"},
+	Short:   "Set work item to review-ready (excluded from wn next until marked done)",
+	Long:    "If id is omitted, uses the current task. Clears in-progress and marks the item review-ready.",
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runReviewReady,
+}
+
+func runReviewReady(cmd *cobra.Command, args []string) error {
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
@@ -1291,33 +2588,70 @@ func runRelease(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
 	now := time.Now().UTC()
 	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+		it.Done = false
 		it.InProgressUntil = time.Time{}
 		it.InProgressBy = ""
 		it.ReviewReady = true
 		it.Updated = now
-		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "released"})
+		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "review_ready"})
 		return it, nil
 	})
 }
 
-var reviewReadyCmd = &cobra.Command{
-	Use:     "review-ready [id]",
-	Aliases: []string{"rr"},
-	Short:   "Set work item to review-ready (excluded from wn next until marked done)",
-	Long:    "If id is omitted, uses the current task. Clears in-progress and marks the item review-ready.",
-	Args:    cobra.MaximumNArgs(1),
-	RunE:    runReviewReady,
+var orderCmd = &cobra.Command{
+	Use:   "order [id]",
+	Short: "Show or change a work item's order (lower = earlier)",
+	Long: `If id is omitted, uses the current task. With no flags, prints the item's order.
+
+  --set N    Set the order to N (0..order_max, default ` + strconv.Itoa(wn.MaxOrder) + `).
+  --up[=N]   Decrease the order by N (default 1) so the item comes earlier.
+  --down[=N] Increase the order by N (default 1) so the item comes later.
+
+An item without an order is treated as order_default (` + strconv.Itoa(wn.DefaultOrder) + ` unless set) before moving it;
+results are clamped to 0..order_max.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runOrder,
 }
 
-func runReviewReady(cmd *cobra.Command, args []string) error {
+var (
+	orderSet  int
+	orderUp   int
+	orderDown int
+)
+
+func init() {
+	orderCmd.Flags().IntVar(&orderSet, "set", -1, "Set the order to N")
+	orderCmd.Flags().IntVar(&orderUp, "up", 0, "Move earlier by N (default 1)")
+	orderCmd.Flags().Lookup("up").NoOptDefVal = "1"
+	orderCmd.Flags().IntVar(&orderDown, "down", 0, "Move later by N (default 1)")
+	orderCmd.Flags().Lookup("down").NoOptDefVal = "1"
+}
+
+func runOrder(cmd *cobra.Command, args []string) error {
+	setFlag := orderSet != -1
+	modes := 0
+	for _, on := range []bool{setFlag, orderUp != 0, orderDown != 0} {
+		if on {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return usageErrorf("cannot combine --set, --up, and --down; choose one")
+	}
+	if setFlag && !wn.ValidOrder(orderSet) {
+		return usageErrorf("order must be between 0 and %d", wn.OrderMax())
+	}
+	if orderUp < 0 || orderDown < 0 {
+		return usageErrorf("--up and --down take a positive count")
+	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
@@ -1332,22 +2666,44 @@ func runReviewReady(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	if modes == 0 {
+		item, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		if item.Order == nil {
+			fmt.Printf("%d (default)\n", wn.OrderDefault())
+		} else {
+			fmt.Println(*item.Order)
+		}
+		return nil
+	}
 	now := time.Now().UTC()
-	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
-		it.Done = false
-		it.InProgressUntil = time.Time{}
-		it.InProgressBy = ""
-		it.ReviewReady = true
+	var result int
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+		switch {
+		case setFlag:
+			result = orderSet
+		case orderUp != 0:
+			result = wn.ShiftOrder(it.Order, -orderUp)
+		default:
+			result = wn.ShiftOrder(it.Order, orderDown)
+		}
+		it.Order = &result
 		it.Updated = now
-		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "review_ready"})
+		it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "order_set", Msg: strconv.Itoa(result)})
 		return it, nil
-	})
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("%s: order %d\n", id, result)
+	return nil
 }
 
 var cleanupCmd = &cobra.Command{
@@ -1358,13 +2714,16 @@ var cleanupCmd = &cobra.Command{
 var cleanupSetMergedReviewItemsDoneCmd = &cobra.Command{
 	Use:   "set-merged-review-items-done",
 	Short: "Mark review items done when their work has been merged",
-	Long:  "Checks all review-ready work items, finds their 'branch' note, and marks them done if that branch (or recorded commit) has been merged into the current branch (or --branch). Use --dry-run to see what would be marked without making changes.",
+	Long:  "Checks all review-ready work items, finds their 'branch' note, and marks them done if that branch (or recorded commit) has been merged into the current branch (or --branch). Also checks the remote-tracking branch (--remote, default origin) and commits merged by content; items that may have been squash-merged are reported for manual review. Use --tag or --id to narrow which items are checked. Use --dry-run to see what would be marked without making changes.",
 	Args:  cobra.NoArgs,
 	RunE:  runCleanupSetMergedReviewItemsDone,
 }
 
 var cleanupMergedDryRun bool
 var cleanupMergedBranch string
+var cleanupMergedTag string
+var cleanupMergedID string
+var cleanupMergedRemote string
 
 var cleanupCloseDoneItemsCmd = &cobra.Command{
 	Use:   "close-done-items",
@@ -1380,6 +2739,9 @@ var cleanupCloseDoneItemsDryRun bool
 func init() {
 	cleanupSetMergedReviewItemsDoneCmd.Flags().BoolVar(&cleanupMergedDryRun, "dry-run", false, "Report what would be marked without making changes")
 	cleanupSetMergedReviewItemsDoneCmd.Flags().StringVarP(&cleanupMergedBranch, "branch", "b", "", "Check merged into this ref (default: current HEAD)")
+	cleanupSetMergedReviewItemsDoneCmd.Flags().StringVar(&cleanupMergedTag, "tag", "", "Only check review-ready items with this tag")
+	cleanupSetMergedReviewItemsDoneCmd.Flags().StringVar(&cleanupMergedID, "id", "", "Only check this item (must be review-ready)")
+	cleanupSetMergedReviewItemsDoneCmd.Flags().StringVar(&cleanupMergedRemote, "remote", "origin", "Also check <remote>/<branch> when the local branch is missing or unmerged (empty to disable)")
 	cleanupCloseDoneItemsCmd.Flags().StringVar(&cleanupCloseDoneItemsAge, "age", "", "Age threshold (e.g. 30d, 7d, 48h); items done longer ago are closed")
 	cleanupCloseDoneItemsCmd.Flags().BoolVar(&cleanupCloseDoneItemsDryRun, "dry-run", false, "Report what would be closed without making changes")
 	cleanupCmd.AddCommand(cleanupSetMergedReviewItemsDoneCmd, cleanupCloseDoneItemsCmd)
@@ -1390,12 +2752,17 @@ func runCleanupSetMergedReviewItemsDone(cmd *cobra.Command, args []string) error
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
-	intoRef := cleanupMergedBranch
-	results, err := wn.MarkMergedItems(store, root, intoRef, cleanupMergedDryRun)
+	results, err := wn.MarkMergedItems(store, root, wn.MarkMergedOpts{
+		IntoRef: cleanupMergedBranch,
+		DryRun:  cleanupMergedDryRun,
+		Tag:     cleanupMergedTag,
+		ID:      cleanupMergedID,
+		Remote:  cleanupMergedRemote,
+	})
 	if err != nil {
 		return err
 	}
@@ -1411,6 +2778,8 @@ func runCleanupSetMergedReviewItemsDone(cmd *cobra.Command, args []string) error
 			fmt.Printf("skip %s: %s\n", r.ID, r.Reason)
 		case "skipped_not_merged":
 			fmt.Printf("skip %s: %s\n", r.ID, r.Reason)
+		case "skipped_squash_ambiguous":
+			fmt.Printf("review %s: %s\n", r.ID, r.Reason)
 		case "skipped_error":
 			fmt.Fprintf(os.Stderr, "skip %s: %s\n", r.ID, r.Reason)
 		}
@@ -1423,7 +2792,7 @@ func runCleanupCloseDoneItems(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -1492,7 +2861,7 @@ func runMerge(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -1512,9 +2881,26 @@ func runMerge(cmd *cobra.Command, args []string) error {
 var logCmd = &cobra.Command{
 	Use:   "log [id]",
 	Short: "Show history of a work item",
-	Long:  "If id is omitted, shows log for the current task.",
+	Long:  "If id is omitted, shows log for the current task. The entries follow a \"log for <id>: <first line>\" header; use --no-header to print only the entries. With --follow, keep running after the existing entries and print new ones as they are appended (e.g. while an agent works the item) until interrupted.",
 	Args:  cobra.MaximumNArgs(1),
-	RunE:  runLog,
+	RunE:  runLogPaged,
+}
+var (
+	logNoHeader bool
+	logFollow   bool
+)
+
+func init() {
+	logCmd.Flags().BoolVar(&logNoHeader, "no-header", false, "Print only the log entries, without the item header line")
+	logCmd.Flags().BoolVarP(&logFollow, "follow", "f", false, "Print new log entries as they are appended until interrupted (Ctrl-C)")
+}
+
+// runLogPaged pages log output, except with --follow: a pager would hold the stream until exit.
+func runLogPaged(cmd *cobra.Command, args []string) error {
+	if logFollow {
+		return runLog(cmd, args)
+	}
+	return paged(runLog, nil)(cmd, args)
 }
 
 func runLog(cmd *cobra.Command, args []string) error {
@@ -1532,9 +2918,9 @@ func runLog(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -1542,30 +2928,370 @@ func runLog(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if !logNoHeader {
+		fmt.Printf("log for %s: %s\n", item.ID, wn.FirstLine(item.Description))
+	}
 	for _, e := range item.Log {
-		fmt.Printf("%s %s", e.At.Format("2006-01-02 15:04:05"), e.Kind)
-		if e.Msg != "" {
-			fmt.Printf(" %s", e.Msg)
+		printLogEntry(e)
+	}
+	if !logFollow {
+		return nil
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	return wn.FollowItemLog(ctx, store, item.ID, len(item.Log), wn.DefaultFollowPoll, func(e wn.LogEntry) error {
+		printLogEntry(e)
+		return nil
+	})
+}
+
+// printLogEntry prints one wn log line: time, kind, and message if any.
+func printLogEntry(e wn.LogEntry) {
+	fmt.Printf("%s %s", formatTime(e.At), e.Kind)
+	if e.Msg != "" {
+		fmt.Printf(" %s", e.Msg)
+	}
+	fmt.Println()
+}
+
+var whyCmd = &cobra.Command{
+	Use:   "why [id]",
+	Short: "Explain why an item is or isn't the next task",
+	Long:  "Reports the item's status and, if it is not what 'wn next' would pick, the reasons: done, review-ready, active claim, unfinished dependencies, or being behind other items in dependency/order sequence. If id is omitted, uses current task.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runWhy,
+}
+
+func runWhy(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	meta, err := wn.ReadMeta(root)
+	if err != nil {
+		return err
+	}
+	explicitID := ""
+	if len(args) > 0 {
+		explicitID = args[0]
+	}
+	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
+	if err != nil {
+		return emptyErrorf("no id provided and no current task; use 'wn pick' or 'wn next'")
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	rep, err := wn.ExplainNext(store, id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", rep.ID, rep.Status)
+	if rep.IsNext {
+		fmt.Println("  is the next task")
+		return nil
+	}
+	for _, r := range rep.Reasons {
+		fmt.Printf("  - %s\n", r)
+	}
+	if rep.NextID != "" {
+		fmt.Printf("  next task is %s\n", rep.NextID)
+	} else {
+		fmt.Println("  no next task")
+	}
+	return nil
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize work items by status, or how long done items took",
+	Long: `Without flags, prints the number of items in each status.
+
+With --durations, lists done items that were claimed, longest first: the actual duration from
+the first claim to completion and the length of that first claim. Items that ran past their
+claim are marked "over". Durations come from each item's log.
+
+With --by-tag, prints each tag's item count with its non-zero statuses, most items first;
+add --json for [{tag, total, undone, blocked, claimed, review, prompt, done, closed, suspend}].`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+var statsDurations bool
+var statsByTag bool
+var statsJson bool
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsDurations, "durations", false, "List actual durations (first claim to done) of completed items")
+	statsCmd.Flags().BoolVar(&statsByTag, "by-tag", false, "Count items by status for each tag, most items first")
+	statsCmd.Flags().BoolVar(&statsJson, "json", false, "With --by-tag, print the counts as JSON")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsByTag && statsDurations {
+		return usageErrorf("cannot use --by-tag with --durations; choose one")
+	}
+	if statsJson && !statsByTag {
+		return usageErrorf("--json requires --by-tag")
+	}
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+	if statsDurations {
+		durations := wn.ItemDurations(items)
+		if len(durations) == 0 {
+			fmt.Println("No completed items with a claim.")
+			return nil
+		}
+		byID := make(map[string]*wn.Item, len(items))
+		for _, it := range items {
+			byID[it.ID] = it
 		}
-		fmt.Println()
+		over := 0
+		for _, d := range durations {
+			claimed, mark := "-", ""
+			if d.Claimed > 0 {
+				claimed = wn.FormatDuration(d.Claimed)
+			}
+			if d.Over() {
+				mark = "over"
+				over++
+			}
+			fmt.Printf("  %-6s  %9s  %9s  %-4s  %s\n", d.ID, wn.FormatDuration(d.Actual), claimed, mark, wn.FirstLine(byID[d.ID].Description))
+		}
+		fmt.Printf("%d item(s), %d over their claim\n", len(durations), over)
+		return nil
+	}
+	if statsByTag {
+		return printTagStats(wn.TagStats(items, time.Now().UTC()))
+	}
+	now := time.Now().UTC()
+	blocked := wn.BlockedSet(items)
+	counts := make(map[string]int)
+	for _, it := range items {
+		counts[wn.ItemListStatus(it, now, blocked[it.ID])]++
+	}
+	for _, status := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Printf("  %-7s  %d\n", status, counts[status])
+	}
+	fmt.Printf("  %-7s  %d\n", "total", len(items))
+	return nil
+}
+
+// printTagStats prints wn stats --by-tag: JSON with --json, else one line per tag with its
+// total and non-zero status counts.
+func printTagStats(stats []wn.TagStat) error {
+	if statsJson {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(stats) == 0 {
+		fmt.Println("No tagged items.")
+		return nil
+	}
+	width := 0
+	for _, st := range stats {
+		width = max(width, len(st.Tag))
+	}
+	for _, st := range stats {
+		names, counts := st.Counts()
+		var parts []string
+		for i, n := range counts {
+			if n > 0 {
+				parts = append(parts, fmt.Sprintf("%s %d", names[i], n))
+			}
+		}
+		fmt.Printf("  %-*s  %3d  %s\n", width, st.Tag, st.Total, strings.Join(parts, ", "))
+	}
+	return nil
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the store for integrity problems",
+	Long:  "Scans items and meta for dependencies on missing items, items depending on themselves, duplicate-of notes pointing nowhere, a dangling current task, dependency cycles, and invalid tag or note names. Use --fix to remove dangling dependencies and self-dependencies and clear a dangling current task; other problems are reported for manual repair.",
+	Args:  cobra.NoArgs,
+	RunE:  runDoctor,
+}
+var doctorFix bool
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Remove dangling dependencies and self-dependencies and clear a dangling current task")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	issues, err := wn.Doctor(store, root)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Println("No problems found.")
+		return nil
+	}
+	for _, is := range issues {
+		prefix := "meta"
+		if is.ID != "" {
+			prefix = is.ID
+		}
+		fix := ""
+		if is.Fixable && !doctorFix {
+			fix = " (fixable with --fix)"
+		}
+		fmt.Printf("%s: %s%s\n", prefix, is.Detail, fix)
+	}
+	if doctorFix {
+		fixed, err := wn.DoctorFix(store, root, issues)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("fixed %d of %d problem(s)\n", fixed, len(issues))
+	}
+	return nil
+}
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the item index (.wn/index.json)",
+	Long:  "Rebuilds .wn/index.json, the per-item summary (id, done, updated, tags) that lets undone views skip reading done items. The index is kept current on every change and rebuilt automatically when missing or stale; run this after bulk manual edits or if it seems out of date.",
+	Args:  cobra.NoArgs,
+	RunE:  runReindex,
+}
+
+func runReindex(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	n, err := wn.Reindex(store)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("indexed %d items\n", n)
+	return nil
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [--to file|sqlite]",
+	Short: "Upgrade stored items to the current format, or move them to another storage backend",
+	Long:  "Without --to, rewrites items stored with an older item schema version (including items written before versioning) in the current format; wn reads older items either way, so this only makes the stored files current. With --to, copies every item into a new store of the given backend (sqlite: .wn/wn.db; file: .wn/items/*.json) and moves the old storage aside as a .migrated-<time> backup, which can be deleted once the new store looks right. The SQLite backend needs a wn built with -tags sqlite.",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrate,
+}
+
+var migrateTo string
+
+func init() {
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Target backend: file or sqlite (omit to upgrade items in place)")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	if migrateTo == "" {
+		store, err := wn.OpenStore(root)
+		if err != nil {
+			return err
+		}
+		n, err := wn.MigrateItems(store)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("upgraded %d items to schema version %d\n", n, wn.ItemSchemaVersion)
+		return nil
+	}
+	n, backup, err := wn.MigrateStore(root, migrateTo)
+	if err != nil {
+		return err
 	}
+	fmt.Printf("migrated %d items to %s; previous store kept at %s\n", n, migrateTo, backup)
 	return nil
 }
 
 var nextCmd = &cobra.Command{
 	Use:   "next",
 	Short: "Pick the next task (first undone in dependency order) and set as current",
-	Long:  "When --tag is provided, pick the next undone item that has that tag (dependency order). Use --claim <duration> to also claim the task (e.g. wn next --claim 30m).",
-	RunE:  runNext,
+	Long: `When --tag is provided, pick the next undone item that has that tag (dependency order). Use --claim <duration> to also claim the task (e.g. wn next --claim 30m).
+With next.autoclaim set in settings (e.g. "2h"), bare wn next claims for that duration; --claim
+overrides it and --no-claim skips the claim for one run.
+
+By default, items are taken in dependency rounds and ties within a round break by Order,
+then created time (older first), then id. With --strict-order (or next.strict_order in
+settings), the ready items (all dependencies done) are sorted purely by Order, then id;
+created time is ignored. Example: B (order 10, created first), A (order 10), and C (order 0,
+depends on A). Default picks B, the older of the two; --strict-order picks A (lower id).
+Either way C, once A is done, comes before B.`,
+	RunE: runNext,
 }
+var nextStrictOrder bool
 var nextClaimFor string
+var nextNoClaim bool
 var nextClaimBy string
 var nextTag string
+var nextExcludeTags []string
+var nextJson bool
+var nextQuiet bool
 
 func init() {
 	nextCmd.Flags().StringVar(&nextTag, "tag", "", "Only consider items with this tag (next undone in dependency order)")
+	nextCmd.Flags().StringSliceVar(&nextExcludeTags, "exclude-tag", nil, "Skip items with this tag (repeatable; added to settings next.exclude_tags)")
 	nextCmd.Flags().StringVar(&nextClaimFor, "claim", "", "Also claim the task for this duration (e.g. 30m, 1h)")
-	nextCmd.Flags().StringVar(&nextClaimBy, "claim-by", "", "Optional worker ID when using --claim")
+	nextCmd.Flags().BoolVar(&nextNoClaim, "no-claim", false, "Do not claim the task, even when next.autoclaim is set")
+	nextCmd.Flags().StringVar(&nextClaimBy, "claim-by", "", "Worker ID when using --claim (default: settings worker_id, else hostname)")
+	nextCmd.Flags().BoolVar(&nextStrictOrder, "strict-order", false, "Treat Order as a strict global priority (see help)")
+	nextCmd.Flags().BoolVar(&nextJson, "json", false, "Print the result as JSON (id, description, claimed, ...) for scripting")
+	nextCmd.Flags().BoolVar(&nextQuiet, "quiet", false, "Print nothing when there is no next task (the exit code is still 4)")
+}
+
+// nextResult is the wn next --json object: the MCP wn_next fields (id, description, claimed,
+// claim_for) plus action and until as in other --json results. id and description are null when
+// there is no next task.
+type nextResult struct {
+	ID          *string `json:"id"`
+	Action      string  `json:"action"`
+	Description *string `json:"description"`
+	Claimed     bool    `json:"claimed"`
+	ClaimFor    string  `json:"claim_for,omitempty"`
+	Until       string  `json:"until,omitempty"`
+}
+
+func printNextResult(next *wn.Item, claimFor, until string) error {
+	r := nextResult{Action: "next"}
+	if next != nil {
+		desc := wn.FirstLine(next.Description)
+		r.ID, r.Description = &next.ID, &desc
+		r.Claimed, r.ClaimFor, r.Until = claimFor != "", claimFor, until
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
 }
 
 func runNext(cmd *cobra.Command, args []string) error {
@@ -1573,17 +3299,47 @@ func runNext(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
-	next, err := wn.NextUndoneItem(store, nextTag)
+	settings, _ := wn.ReadSettingsInRoot(root)
+	if nextClaimFor != "" && nextNoClaim {
+		return usageErrorf("cannot use --claim with --no-claim; choose one")
+	}
+	if nextQuiet && nextJson {
+		return usageErrorf("cannot use --quiet with --json; choose one")
+	}
+	claimFor, claimSource := nextClaimFor, "--claim"
+	if claimFor == "" && !nextNoClaim {
+		claimFor, claimSource = settings.Next.AutoClaim, "next.autoclaim"
+	}
+	var d time.Duration
+	if claimFor != "" {
+		d, err = time.ParseDuration(claimFor)
+		if err != nil {
+			return fmt.Errorf("invalid %s duration %q: %w", claimSource, claimFor, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("%s duration must be positive, got %v", claimSource, d)
+		}
+	}
+	strict := settings.Next.StrictOrder || nextStrictOrder
+	next, err := wn.NextUndoneItemOrdered(store, nextTag, wn.NextExcludeTags(settings, nextExcludeTags), wn.NextOrdering(settings, strict))
 	if err != nil {
 		return err
 	}
 	if next == nil {
-		fmt.Println("No next task.")
-		return nil
+		if nextJson {
+			if err := printNextResult(nil, "", ""); err != nil {
+				return err
+			}
+			return errEmpty
+		}
+		if !nextQuiet {
+			fmt.Fprintln(os.Stderr, "No next task.")
+		}
+		return errEmpty
 	}
 	if err := wn.WithMetaLock(root, func(m wn.Meta) (wn.Meta, error) {
 		m.CurrentID = next.ID
@@ -1591,28 +3347,27 @@ func runNext(cmd *cobra.Command, args []string) error {
 	}); err != nil {
 		return err
 	}
-	if nextClaimFor != "" {
-		d, err := time.ParseDuration(nextClaimFor)
-		if err != nil {
-			return fmt.Errorf("invalid --claim duration %q: %w", nextClaimFor, err)
-		}
-		if d <= 0 {
-			return fmt.Errorf("--claim duration must be positive, got %v", d)
-		}
+	if claimFor != "" {
 		now := time.Now().UTC()
 		until := now.Add(d)
 		if err := store.UpdateItem(next.ID, func(it *wn.Item) (*wn.Item, error) {
 			it.InProgressUntil = until
-			it.InProgressBy = nextClaimBy
+			it.InProgressBy = wn.ClaimWorker(settings, nextClaimBy)
 			it.Updated = now
-			it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "in_progress", Msg: nextClaimFor})
+			it.Log = append(it.Log, wn.LogEntry{At: now, Kind: "in_progress", Msg: claimFor})
 			return it, nil
 		}); err != nil {
 			return err
 		}
-		fmt.Printf("  %s: %s (claimed for %s)\n", next.ID, next.Description, nextClaimFor)
+		if nextJson {
+			return printNextResult(next, claimFor, until.Format(time.RFC3339))
+		}
+		fmt.Printf("  %s: %s (claimed for %s)\n", next.ID, next.Description, claimFor)
 		return nil
 	}
+	if nextJson {
+		return printNextResult(next, "", "")
+	}
 	fmt.Printf("  %s: %s\n", next.ID, next.Description)
 	return nil
 }
@@ -1620,7 +3375,7 @@ func runNext(cmd *cobra.Command, args []string) error {
 var pickCmd = &cobra.Command{
 	Use:   "pick [id|.|−]",
 	Short: "Interactively pick a current task (uses fzf if available)",
-	Long:  "With no id, shows an interactive list to choose from. Pass an id to set current task directly. Pass '.' to select the item for the current directory's git branch (useful when switching between worktrees). Pass '-' to switch to the previously selected item (like git checkout -). Use --undone (default), --done, --all, or --rr/--review-ready to filter by state.",
+	Long:  "With no id, shows an interactive list to choose from. Pass an id to set current task directly. Pass '.' to select the item for the current directory's git branch (useful when switching between worktrees). Pass '-' to switch to the previously selected item (like git checkout -). Use --undone (default), --done, --all, or --rr/--review-ready to filter by state. With --multi, select several items and claim them (--claim 1h) or mark them done (--mark-done) in one go; current task is not changed.",
 	Args:  cobra.MaximumNArgs(1),
 	RunE:  runPick,
 }
@@ -1629,6 +3384,10 @@ var pickUndone bool
 var pickDone bool
 var pickAll bool
 var pickReviewReady bool
+var pickMulti bool
+var pickClaim string
+var pickMarkDone bool
+var pickMessage string
 
 func initPick() {
 	pickCmd.Flags().BoolVar(&pickUndone, "undone", false, "Pick from undone items only (default)")
@@ -1636,6 +3395,10 @@ func initPick() {
 	pickCmd.Flags().BoolVar(&pickAll, "all", false, "Pick from all items")
 	pickCmd.Flags().BoolVar(&pickReviewReady, "rr", false, "Pick from review-ready items only")
 	pickCmd.Flags().BoolVar(&pickReviewReady, "review-ready", false, "Pick from review-ready items only")
+	pickCmd.Flags().BoolVar(&pickMulti, "multi", false, "Select several items and apply --claim or --mark-done to each")
+	pickCmd.Flags().StringVar(&pickClaim, "claim", "", "With --multi: claim the selected items for this duration (e.g. 1h)")
+	pickCmd.Flags().BoolVar(&pickMarkDone, "mark-done", false, "With --multi: mark the selected items done")
+	pickCmd.Flags().StringVarP(&pickMessage, "message", "m", "", "With --mark-done: completion message recorded on each item")
 }
 
 func runPick(cmd *cobra.Command, args []string) error {
@@ -1643,10 +3406,24 @@ func runPick(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	if (pickClaim != "" || pickMarkDone) && !pickMulti {
+		return usageErrorf("--claim and --mark-done require --multi")
+	}
+	if pickMessage != "" && !pickMarkDone {
+		return usageErrorf("--message requires --mark-done")
+	}
+	if pickMulti {
+		if len(args) > 0 {
+			return usageErrorf("--multi does not take an id")
+		}
+		if (pickClaim == "") == !pickMarkDone {
+			return usageErrorf("--multi needs exactly one of --claim <duration> or --mark-done")
+		}
+	}
 
 	// If id passed, set current to that item (must exist)
 	if len(args) == 1 {
@@ -1658,11 +3435,11 @@ func runPick(cmd *cobra.Command, args []string) error {
 				return err
 			}
 			if meta.PreviousID == "" {
-				return fmt.Errorf("no previous task")
+				return emptyErrorf("no previous task")
 			}
 			item, err := store.Get(meta.PreviousID)
 			if err != nil {
-				return fmt.Errorf("previous task %s not found", meta.PreviousID)
+				return notFoundErrorf("previous task %s not found", meta.PreviousID)
 			}
 			if err := wn.WithMetaLock(root, func(m wn.Meta) (wn.Meta, error) {
 				m.CurrentID = meta.PreviousID
@@ -1688,7 +3465,7 @@ func runPick(cmd *cobra.Command, args []string) error {
 				return err
 			}
 			if item == nil {
-				return fmt.Errorf("no work item found for branch %q", branch)
+				return notFoundErrorf("no work item found for branch %q", branch)
 			}
 			if err := wn.WithMetaLock(root, func(m wn.Meta) (wn.Meta, error) {
 				m.CurrentID = item.ID
@@ -1700,7 +3477,7 @@ func runPick(cmd *cobra.Command, args []string) error {
 			return nil
 		}
 		if _, err := store.Get(id); err != nil {
-			return fmt.Errorf("item %s not found", id)
+			return notFoundErrorf("item %s not found", id)
 		}
 		return wn.WithMetaLock(root, func(m wn.Meta) (wn.Meta, error) {
 			m.CurrentID = id
@@ -1722,7 +3499,7 @@ func runPick(cmd *cobra.Command, args []string) error {
 		stateFlags++
 	}
 	if stateFlags > 1 {
-		return fmt.Errorf("only one of --undone, --done, --all, --review-ready may be set")
+		return usageErrorf("only one of --undone, --done, --all, --review-ready may be set")
 	}
 
 	var items []*wn.Item
@@ -1764,9 +3541,12 @@ func runPick(cmd *cobra.Command, args []string) error {
 			msg = "No review-ready tasks."
 		}
 		fmt.Println(msg)
-		return nil
+		return errEmpty
 	}
 	items = wn.ApplySort(items, interactiveSortSpec(root))
+	if pickMulti {
+		return runPickMulti(store, items)
+	}
 	id, err := wn.PickInteractive(items)
 	if err != nil {
 		return err
@@ -1780,18 +3560,81 @@ func runPick(cmd *cobra.Command, args []string) error {
 	})
 }
 
+// runPickMulti lets the user select several items and claims them (--claim) or marks them
+// done (--mark-done, with -m as the done message) the same way wn claim and wn done do.
+// Marking done is refused up front if a selected item depends on an undone item outside the
+// selection (prompt-ready deps excepted), so the batch is applied all or nothing.
+func runPickMulti(store wn.Store, items []*wn.Item) error {
+	settings, _ := wn.ReadSettingsInRoot(store.Root())
+	by := wn.ClaimWorker(settings, "")
+	var d time.Duration
+	if pickClaim != "" {
+		var err error
+		d, err = time.ParseDuration(pickClaim)
+		if err != nil {
+			return usageErrorf("invalid --claim duration %q: %w", pickClaim, err)
+		}
+		if d <= 0 {
+			return usageErrorf("--claim duration must be positive, got %v", d)
+		}
+	}
+	ids, err := wn.PickMultiInteractive(items)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	if !pickMarkDone {
+		for _, id := range ids {
+			if _, err := claimItem(store, id, now.Add(d), by, pickClaim, now); err != nil {
+				return err
+			}
+			fmt.Printf("claimed %s for %s\n", id, pickClaim)
+		}
+		return nil
+	}
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	picked := make([]*wn.Item, len(ids))
+	for i, id := range ids {
+		it, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := checkDoneDeps(store, it, selected); err != nil {
+			return fmt.Errorf("%s: %w; nothing marked done", id, err)
+		}
+		picked[i] = it
+	}
+	for _, it := range picked {
+		if err := markItemDone(store, it, pickMessage, now); err != nil {
+			return err
+		}
+		fmt.Printf("done %s\n", it.ID)
+	}
+	return nil
+}
+
 var mcpCmd = &cobra.Command{
 	Use:   "mcp [project_root]",
 	Short: "Run MCP server on stdio (for Cursor and other MCP clients)",
-	Long:  "Starts the Model Context Protocol server over stdin/stdout. Optional project_root is the directory containing .wn; when provided (or when WN_ROOT is set), the server is locked to that project and the per-request \"root\" parameter is ignored. No continuous process—exits when the client disconnects.",
+	Long:  "Starts the Model Context Protocol server over stdin/stdout. Optional project_root is the directory containing .wn; when provided (or when --root, WN_DIR, or WN_ROOT is set), the server is locked to that project and the per-request \"root\" parameter is ignored. No continuous process—exits when the client disconnects.",
 	Args:  cobra.MaximumNArgs(1),
 	RunE:  runMCP,
 }
 
 func runMCP(cmd *cobra.Command, args []string) error {
-	// Fixed root: spawn-time arg wins, then WN_ROOT env, else no lock (tools use cwd or request "root").
+	// Fixed root: spawn-time arg wins, then --root, WN_DIR, WN_ROOT env, else no lock (tools use cwd or request "root").
 	if len(args) > 0 {
 		wn.SetMCPFixedRoot(args[0])
+	} else if rootFlag != "" {
+		wn.SetMCPFixedRoot(rootFlag)
+	} else if r := os.Getenv("WN_DIR"); r != "" {
+		wn.SetMCPFixedRoot(r)
 	} else if r := os.Getenv("WN_ROOT"); r != "" {
 		wn.SetMCPFixedRoot(r)
 	}
@@ -1802,6 +3645,41 @@ func runMCP(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a small JSON API over HTTP (unauthenticated; local use only)",
+	Long: `Serves a read-mostly JSON API for dashboards over HTTP:
+
+  GET  /items            Undone items as wn_list returns them. Query: tag, status, has, text
+                         (repeatable), overdue, where (filter expression), limit, offset, cursor.
+  GET  /items/{id}       Full item JSON, as wn_item.
+  POST /items/{id}/done  Mark done; optional body {"message": "..."}. Returns the item.
+
+There is no authentication: anyone who can reach the address can read and complete items.
+The default address is 127.0.0.1:8099; only bind other interfaces on a trusted network.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var serveAddr string
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", wn.DefaultServeAddr, "Listen address (host:port)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Serving %s on http://%s (unauthenticated; local use only)\n", root, ln.Addr())
+	return http.Serve(ln, wn.NewHTTPHandler(root))
+}
+
 var doCmd = &cobra.Command{
 	Use:   "do [runner] [id]",
 	Short: "Run agent on a work item; optionally loop through the queue",
@@ -1828,6 +3706,10 @@ var (
 	doBranch       string
 	doBranchPrefix string
 	doTag          string
+	doCommitTpl    string
+	doPush         bool
+	doPRCmd        string
+	doSetupCmd     string
 )
 
 func init() {
@@ -1841,6 +3723,10 @@ func init() {
 	doCmd.Flags().StringVar(&doBranch, "branch", "", "Default branch override (e.g. main). Overrides settings.")
 	doCmd.Flags().StringVar(&doBranchPrefix, "branch-prefix", "", "Prefix for generated branch names (e.g. keith/). Overrides settings.")
 	doCmd.Flags().StringVar(&doTag, "tag", "", "Only consider items with this tag (queue modes). Overrides settings.")
+	doCmd.Flags().BoolVar(&doPush, "push", false, "Push the branch to origin (git push -u) after committing.")
+	doCmd.Flags().StringVar(&doPRCmd, "pr-cmd", "", "Command template run after a successful push (e.g. \"gh pr create --fill --head {{.Branch}}\"); a printed URL is saved as the pr-url note. Requires --push.")
+	doCmd.Flags().StringVar(&doCommitTpl, "commit-tpl", "", "Commit message template (e.g. \"feat: {{.FirstLine}}\"). Overrides settings.")
+	doCmd.Flags().StringVar(&doSetupCmd, "setup-cmd", "", "Command template run in the worktree before the agent (same fields as the runner cmd; WN_ROOT set). A non-zero exit aborts the item. Overrides settings.")
 }
 
 func runDo(cmd *cobra.Command, args []string) error {
@@ -1855,6 +3741,10 @@ func runDo(cmd *cobra.Command, args []string) error {
 	flagBranch, _ := cmd.Flags().GetString("branch")
 	flagBranchPrefix, _ := cmd.Flags().GetString("branch-prefix")
 	flagTag, _ := cmd.Flags().GetString("tag")
+	flagCommitTpl, _ := cmd.Flags().GetString("commit-tpl")
+	flagPush, _ := cmd.Flags().GetBool("push")
+	flagPRCmd, _ := cmd.Flags().GetString("pr-cmd")
+	flagSetupCmd, _ := cmd.Flags().GetString("setup-cmd")
 
 	_ = cmd.Flags().Set("next", "false")
 	_ = cmd.Flags().Set("loop", "false")
@@ -1866,9 +3756,16 @@ func runDo(cmd *cobra.Command, args []string) error {
 	_ = cmd.Flags().Set("branch", "")
 	_ = cmd.Flags().Set("branch-prefix", "")
 	_ = cmd.Flags().Set("tag", "")
+	_ = cmd.Flags().Set("commit-tpl", "")
+	_ = cmd.Flags().Set("push", "false")
+	_ = cmd.Flags().Set("pr-cmd", "")
+	_ = cmd.Flags().Set("setup-cmd", "")
 
 	if maxTasks != 0 && !isLoop {
-		return fmt.Errorf("-n / --max-tasks requires --loop")
+		return usageErrorf("-n / --max-tasks requires --loop")
+	}
+	if flagPRCmd != "" && !flagPush {
+		return usageErrorf("--pr-cmd requires --push")
 	}
 
 	root, err := wn.FindRootForCLI()
@@ -1900,15 +3797,16 @@ func runDo(cmd *cobra.Command, args []string) error {
 	}
 
 	if isNext && workID != "" {
-		return fmt.Errorf("use either an id argument or --next, not both")
+		return usageErrorf("use either an id argument or --next, not both")
 	}
 	if isLoop && workID != "" {
-		return fmt.Errorf("use either an id argument or --loop, not both")
+		return usageErrorf("use either an id argument or --loop, not both")
 	}
 
 	opts := wn.AgentOrchOpts{
-		Root:  root,
-		Audit: os.Stderr,
+		Root:    root,
+		Audit:   os.Stderr,
+		ClaimBy: wn.ClaimWorker(settings, ""),
 	}
 
 	// Apply settings defaults
@@ -1939,6 +3837,12 @@ func runDo(cmd *cobra.Command, args []string) error {
 	if ns.Tag != "" {
 		opts.Tag = ns.Tag
 	}
+	opts.ExcludeTags = wn.AgentExcludeTags(settings)
+	if as.CommitTpl != "" {
+		opts.CommitTpl = as.CommitTpl
+	}
+	opts.SetupCmd = as.SetupCmd
+	opts.PromptMaxChars = as.PromptMaxChars
 
 	// Flag overrides
 	if flagClaim != "" {
@@ -1974,6 +3878,14 @@ func runDo(cmd *cobra.Command, args []string) error {
 	if flagTag != "" {
 		opts.Tag = flagTag
 	}
+	if flagCommitTpl != "" {
+		opts.CommitTpl = flagCommitTpl
+	}
+	if flagSetupCmd != "" {
+		opts.SetupCmd = flagSetupCmd
+	}
+	opts.Push = flagPush
+	opts.PRCmd = flagPRCmd
 
 	// Defaults when still zero
 	if opts.ClaimFor == 0 {
@@ -2000,7 +3912,7 @@ func runDo(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		if meta.CurrentID == "" {
-			return fmt.Errorf("no current task (use wn pick or wn next first)")
+			return emptyErrorf("no current task (use wn pick or wn next first)")
 		}
 		opts.WorkID = meta.CurrentID
 	}
@@ -2092,7 +4004,7 @@ func runLaunch(cmd *cobra.Command, args []string) error {
 	}
 
 	if isNext && workID != "" {
-		return fmt.Errorf("use either an id argument or --next, not both")
+		return usageErrorf("use either an id argument or --next, not both")
 	}
 
 	// Determine the work item (or validate current task) before resolving runner.
@@ -2100,7 +4012,6 @@ func runLaunch(cmd *cobra.Command, args []string) error {
 	if flagTag != "" {
 		tag = flagTag
 	}
-	_ = as // suppress unused warning; orchestrator fields (delay/poll) not used for launch
 
 	var orchWorkID string
 	var orchFailIfEmpty bool
@@ -2117,7 +4028,7 @@ func runLaunch(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		if meta.CurrentID == "" {
-			return fmt.Errorf("no current task (use wn pick or wn next first)")
+			return emptyErrorf("no current task (use wn pick or wn next first)")
 		}
 		orchWorkID = meta.CurrentID
 	}
@@ -2128,16 +4039,20 @@ func runLaunch(cmd *cobra.Command, args []string) error {
 	}
 
 	opts := wn.AgentOrchOpts{
-		Root:          root,
-		Audit:         os.Stderr,
-		Async:         true,
-		AgentCmd:      runner.Cmd,
-		PromptTpl:     runner.Prompt,
-		LeaveWorktree: true, // always leave worktree for async dispatch
-		WorkID:        orchWorkID,
-		FailIfEmpty:   orchFailIfEmpty,
-		MaxTasks:      orchMaxTasks,
-		Tag:           tag,
+		Root:           root,
+		Audit:          os.Stderr,
+		Async:          true,
+		AgentCmd:       runner.Cmd,
+		PromptTpl:      runner.Prompt,
+		LeaveWorktree:  true, // always leave worktree for async dispatch
+		WorkID:         orchWorkID,
+		FailIfEmpty:    orchFailIfEmpty,
+		MaxTasks:       orchMaxTasks,
+		Tag:            tag,
+		ExcludeTags:    wn.AgentExcludeTags(settings),
+		SetupCmd:       as.SetupCmd,
+		PromptMaxChars: as.PromptMaxChars,
+		ClaimBy:        wn.ClaimWorker(settings, ""),
 	}
 
 	if ws.Claim != "" {
@@ -2236,7 +4151,7 @@ func runWorktreeSetup(cmd *cobra.Command, args []string) error {
 	_ = cmd.Flags().Set("tag", "")
 
 	if isNext && len(args) > 0 {
-		return fmt.Errorf("use either an id argument or --next, not both")
+		return usageErrorf("use either an id argument or --next, not both")
 	}
 
 	root, err := wn.FindRootForCLI()
@@ -2277,7 +4192,7 @@ func runWorktreeSetup(cmd *cobra.Command, args []string) error {
 		tag = flagTag
 	}
 
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -2290,17 +4205,18 @@ func runWorktreeSetup(cmd *cobra.Command, args []string) error {
 	}
 	mainDirname := filepath.Base(absRoot)
 
+	claimBy := wn.ClaimWorker(settings, "")
 	var item *wn.Item
 	switch {
 	case len(args) > 0:
 		item, err = store.Get(args[0])
 		if err != nil {
-			return fmt.Errorf("item %s not found", args[0])
+			return notFoundErrorf("item %s not found", args[0])
 		}
 		if item.Done {
 			return fmt.Errorf("item %s is already done", args[0])
 		}
-		if err := wn.ClaimItem(store, root, item.ID, claimFor, ""); err != nil {
+		if err := wn.ClaimItem(store, root, item.ID, claimFor, claimBy); err != nil {
 			return err
 		}
 		item, err = store.Get(item.ID)
@@ -2308,12 +4224,12 @@ func runWorktreeSetup(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	case isNext:
-		item, err = wn.ClaimNextItem(store, root, claimFor, "", tag)
+		item, err = wn.ClaimNextItem(store, root, claimFor, claimBy, tag, wn.NextExcludeTags(settings, nil))
 		if err != nil {
 			return err
 		}
 		if item == nil {
-			return fmt.Errorf("no items in queue")
+			return emptyErrorf("no items in queue")
 		}
 	default:
 		meta, err := wn.ReadMeta(root)
@@ -2321,7 +4237,7 @@ func runWorktreeSetup(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		if meta.CurrentID == "" {
-			return fmt.Errorf("no current task (use wn pick, wn next, or wn worktree --next)")
+			return emptyErrorf("no current task (use wn pick, wn next, or wn worktree --next)")
 		}
 		item, err = store.Get(meta.CurrentID)
 		if err != nil {
@@ -2330,7 +4246,7 @@ func runWorktreeSetup(cmd *cobra.Command, args []string) error {
 		if item.Done {
 			return fmt.Errorf("current item %s is already done", item.ID)
 		}
-		if err := wn.ClaimItem(store, root, item.ID, claimFor, ""); err != nil {
+		if err := wn.ClaimItem(store, root, item.ID, claimFor, claimBy); err != nil {
 			return err
 		}
 		item, err = store.Get(item.ID)
@@ -2351,16 +4267,20 @@ func runWorktreeSetup(cmd *cobra.Command, args []string) error {
 var settingsCmd = &cobra.Command{
 	Use:   "settings",
 	Short: "Open wn settings file in $EDITOR",
-	Long:  "Opens user-level settings (~/.config/wn/settings.json) in $EDITOR. Use --project to open project-level settings (.wn/settings.json) which override user settings when present.",
+	Long:  "Opens user-level settings (~/.config/wn/settings.json) in $EDITOR. Use --project to open project-level settings (.wn/settings.json) which override user settings when present. Use --check to validate the user and project settings files instead of editing: durations, templates, runner names, and unknown keys (which are otherwise silently ignored) are reported with their JSON path.",
 	RunE:  runSettings,
 }
-var settingsProject bool
+var settingsProject, settingsCheck bool
 
 func init() {
 	settingsCmd.Flags().BoolVar(&settingsProject, "project", false, "Edit project-level settings (.wn/settings.json) instead of user settings")
+	settingsCmd.Flags().BoolVar(&settingsCheck, "check", false, "Validate settings files and report problems instead of editing")
 }
 
 func runSettings(cmd *cobra.Command, args []string) error {
+	if settingsCheck {
+		return runSettingsCheck()
+	}
 	if settingsProject {
 		root, err := wn.FindRootForCLI()
 		if err != nil {
@@ -2376,7 +4296,7 @@ func runSettings(cmd *cobra.Command, args []string) error {
 				return err
 			}
 		}
-		return wn.RunEditorOnFile(settingsPath)
+		return editorErr(wn.RunEditorOnFile(settingsPath))
 	}
 	configDir, err := os.UserConfigDir()
 	if err != nil {
@@ -2395,7 +4315,51 @@ func runSettings(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
-	return wn.RunEditorOnFile(settingsPath)
+	return editorErr(wn.RunEditorOnFile(settingsPath))
+}
+
+// editorErr reports an editor abort (non-zero exit) as "aborted, no changes" rather than a
+// failure; other errors are returned unchanged.
+func editorErr(err error) error {
+	if errors.Is(err, wn.ErrEditorAborted) {
+		fmt.Println("aborted, no changes")
+		return nil
+	}
+	return err
+}
+
+// runSettingsCheck validates the user settings file and, inside a wn project, the project
+// settings file. Errors fail the command; unknown keys are only warnings.
+func runSettingsCheck() error {
+	userPath, err := wn.SettingsPath()
+	if err != nil {
+		return err
+	}
+	paths := []string{userPath}
+	if root, err := wn.FindRootForCLI(); err == nil {
+		paths = append(paths, wn.ProjectSettingsPath(root))
+	}
+	errCount, total := 0, 0
+	for _, path := range paths {
+		problems, err := wn.CheckSettingsFile(path)
+		if err != nil {
+			return err
+		}
+		total += len(problems)
+		for _, p := range problems {
+			fmt.Printf("%s: %s\n", path, p)
+			if !p.Warning {
+				errCount++
+			}
+		}
+	}
+	if errCount > 0 {
+		return fmt.Errorf("%d problem(s) in settings", errCount)
+	}
+	if total == 0 {
+		fmt.Println("Settings OK.")
+	}
+	return nil
 }
 
 var exportCmd = &cobra.Command{
@@ -2408,6 +4372,9 @@ var exportAll bool
 var exportUndone bool
 var exportDone bool
 var exportTag string
+var exportSince string
+var exportSplit string
+var exportPretty bool
 
 func init() {
 	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to file (default: stdout)")
@@ -2415,19 +4382,42 @@ func init() {
 	exportCmd.Flags().BoolVar(&exportUndone, "undone", false, "Export only undone items")
 	exportCmd.Flags().BoolVar(&exportDone, "done", false, "Export only done items")
 	exportCmd.Flags().StringVar(&exportTag, "tag", "", "Export only items with this tag")
+	exportCmd.Flags().StringVar(&exportSince, "since", "", "Export only items updated at/after this cutoff: a duration ago (e.g. 7d, 12h) or a timestamp (RFC 3339 or YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportSplit, "split", "", "Write one <id>.json per item plus manifest.json into this directory")
+	exportCmd.Flags().BoolVar(&exportPretty, "pretty", false, "Indent the JSON for reading (default: one line; --split files are always indented)")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
+	if exportSplit != "" && exportOutput != "" {
+		return usageErrorf("cannot use both --split and --output; choose one")
+	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
-	useCriteria := exportAll || exportUndone || exportDone || exportTag != ""
+	var since time.Time
+	if exportSince != "" {
+		since, err = wn.ParseSince(exportSince, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+	useCriteria := exportAll || exportUndone || exportDone || exportTag != "" || exportSince != ""
 	if !useCriteria {
+		if exportSplit != "" {
+			items, err := store.List()
+			if err != nil {
+				return err
+			}
+			return wn.ExportItemsSplit(items, exportSplit)
+		}
+		if exportPretty {
+			return wn.ExportIndent(store, exportOutput)
+		}
 		return wn.Export(store, exportOutput)
 	}
 	var items []*wn.Item
@@ -2465,34 +4455,73 @@ func runExport(cmd *cobra.Command, args []string) error {
 		}
 		items = filtered
 	}
+	if exportSince != "" {
+		var filtered []*wn.Item
+		for _, it := range items {
+			if !it.Updated.Before(since) {
+				filtered = append(filtered, it)
+			}
+		}
+		items = filtered
+	}
+	if exportSplit != "" {
+		return wn.ExportItemsSplit(items, exportSplit)
+	}
+	if exportPretty {
+		return wn.ExportItemsIndent(items, exportOutput)
+	}
 	return wn.ExportItems(items, exportOutput)
 }
 
 var importCmd = &cobra.Command{
-	Use:   "import [file]",
-	Short: "Import work items from an export file",
-	Long:  "Import work items from a JSON export file. When the store already has items, you must choose --append (add/merge from file) or --replace (delete all existing, then load file). When the store is empty, either flag is optional.",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runImport,
+	Use:   "import [file|dir]",
+	Short: "Import work items from an export file or GitHub issues",
+	Long: `Import work items from a JSON export file, or from a directory written by export --split (every <id>.json in it is loaded). When the store already has items, you must choose --append (add/merge from file) or --replace (delete all existing, then load file). When the store is empty, either flag is optional. --tag adds a tag to every imported item (on top of the tags they carry) so the batch can be found or removed later.
+
+With --github owner/repo, import the repository's issues instead of a file (pull requests are skipped). Each issue becomes an item whose first line is the issue title and whose external id is owner/repo#N; labels become tags and closed issues are imported as done. Issues already imported are skipped, so the command can be re-run to pick up new ones. --label and --state (open, closed, all; default open) filter the issues. The token is read from GH_TOKEN (or GITHUB_TOKEN) and the API base from GITHUB_API_URL for GitHub Enterprise.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runImport,
 }
 var importReplace bool
 var importAppend bool
+var importTag string
+var importGitHub, importLabel, importState string
 
 func init() {
 	importCmd.Flags().BoolVar(&importAppend, "append", false, "Add items from file to the store (merge by ID; same ID overwrites)")
 	importCmd.Flags().BoolVar(&importReplace, "replace", false, "Replace all existing items with the contents of the file")
+	importCmd.Flags().StringVar(&importTag, "tag", "", "Add this tag to every imported item")
+	importCmd.Flags().StringVar(&importGitHub, "github", "", "Import issues from this GitHub repository (owner/repo) instead of a file")
+	importCmd.Flags().StringVar(&importLabel, "label", "", "With --github: only issues with this label (comma-separated for several)")
+	importCmd.Flags().StringVar(&importState, "state", "open", "With --github: open, closed, or all")
 }
 
 func runImport(cmd *cobra.Command, args []string) error {
 	if importAppend && importReplace {
-		return fmt.Errorf("cannot use both --append and --replace; choose one")
+		return usageErrorf("cannot use both --append and --replace; choose one")
+	}
+	if importTag != "" {
+		tag, err := normalizeTagArg(importTag)
+		if err != nil {
+			return err
+		}
+		importTag = tag
+	}
+	if importGitHub != "" {
+		return runImportGitHub(cmd, args)
+	}
+	if importLabel != "" || importState != "open" {
+		return usageErrorf("--label and --state require --github")
+	}
+	if len(args) == 0 {
+		return usageErrorf("import needs a file or directory (or --github owner/repo)")
 	}
 	path := args[0]
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -2503,17 +4532,135 @@ func runImport(cmd *cobra.Command, args []string) error {
 	if hasItems && !importAppend && !importReplace {
 		return fmt.Errorf("store already has items; use --append to add to existing items or --replace to replace all")
 	}
+	var warnings []string
+	if importReplace {
+		warnings, err = wn.ImportReplace(store, path, wn.ImportOpts{Tag: importTag})
+	} else {
+		warnings, err = wn.ImportAppend(store, path, wn.ImportOpts{Tag: importTag})
+	}
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	return nil
+}
+
+// runImportGitHub runs wn import --github: issues are always added alongside the existing items,
+// skipping those already imported.
+func runImportGitHub(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("cannot use a file with --github; choose one")
+	}
 	if importReplace {
-		return wn.ImportReplace(store, path)
+		return usageErrorf("cannot use --replace with --github; issues are always appended")
+	}
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	settings, _ := wn.ReadSettingsInRoot(root)
+	token := os.Getenv("GH_TOKEN")
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	res, err := wn.ImportGitHubIssues(cmd.Context(), store, wn.GitHubImportOpts{
+		Repo:          importGitHub,
+		Label:         importLabel,
+		State:         importState,
+		Token:         token,
+		BaseURL:       os.Getenv("GITHUB_API_URL"),
+		Tag:           importTag,
+		LowercaseTags: settings.TagLowercase,
+	})
+	for _, id := range res.Added {
+		fmt.Printf("added entry %s\n", id)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d issue(s) from %s; %d already imported\n", len(res.Added), importGitHub, res.Skipped)
+	return nil
+}
+
+var syncBackCmd = &cobra.Command{
+	Use:   "sync-back",
+	Short: "Close the GitHub issues of items done in wn",
+	Long: `Close the GitHub issue of each item imported with wn import --github (external id owner/repo#N, or an issue external URL on github.com or the GITHUB_API_URL host) that was marked done or closed in wn since its last sync. Done items close the issue as completed, closed items as not planned; suspended items are left alone. With --comment, the item's done message is posted on the issue first (logged as github_commented, so a retry after a failed close does not post it again). Each sync is logged on the item as github_closed so it is not repeated.
+
+The token is read from GH_TOKEN (or GITHUB_TOKEN) and is required unless --dry-run. A failure on one item is reported and the others are still synced; the command exits non-zero if any item failed.`,
+	Args: cobra.NoArgs,
+	RunE: runSyncBack,
+}
+var syncBackDryRun, syncBackComment bool
+
+func init() {
+	syncBackCmd.Flags().BoolVar(&syncBackDryRun, "dry-run", false, "List the issues that would be closed without contacting GitHub")
+	syncBackCmd.Flags().BoolVar(&syncBackComment, "comment", false, "Post the item's done message as an issue comment before closing")
+}
+
+func runSyncBack(cmd *cobra.Command, args []string) error {
+	root, err := wn.FindRootForCLI()
+	if err != nil {
+		return err
+	}
+	store, err := wn.OpenStore(root)
+	if err != nil {
+		return err
+	}
+	token := os.Getenv("GH_TOKEN")
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
 	}
-	return wn.ImportAppend(store, path)
+	results, err := wn.SyncBackGitHub(cmd.Context(), store, wn.GitHubSyncOpts{
+		Token:   token,
+		BaseURL: os.Getenv("GITHUB_API_URL"),
+		Comment: syncBackComment,
+		DryRun:  syncBackDryRun,
+	})
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		reason := strings.ReplaceAll(r.Reason, "_", " ")
+		comment := ""
+		if r.Comment != "" {
+			comment = ", with comment"
+		}
+		switch {
+		case r.Err != nil:
+			failed++
+			fmt.Printf("failed %s (%s): %v\n", r.Issue, r.ItemID, r.Err)
+		case syncBackDryRun:
+			fmt.Printf("would close %s as %s (%s%s)\n", r.Issue, reason, r.ItemID, comment)
+		default:
+			fmt.Printf("closed %s as %s (%s%s)\n", r.Issue, reason, r.ItemID, comment)
+		}
+	}
+	if len(results) == 0 {
+		fmt.Println("nothing to sync")
+		return nil
+	}
+	if !syncBackDryRun {
+		fmt.Printf("closed %d issue(s), %d failed\n", len(results)-failed, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d item(s) failed to sync", failed, len(results))
+	}
+	return nil
 }
 
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List work items (default: undone, in dependency order)",
-	RunE:    runList,
+	RunE:    paged(runList, &listJson),
 }
 var listUndone bool
 var listDone bool
@@ -2526,6 +4673,10 @@ var listOffset int
 
 var listJson bool
 var listGroup string
+var listWhere string
+var listCount bool
+var listNoTruncate bool
+var listPretty bool
 
 func init() {
 	listCmd.Flags().BoolVar(&listUndone, "undone", false, "List undone items (default when no filter; includes both available and review-ready; excludes in-progress)")
@@ -2538,16 +4689,29 @@ func init() {
 	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Return at most N items (0 = no limit)")
 	listCmd.Flags().IntVar(&listOffset, "offset", 0, "Skip first N items")
 	listCmd.Flags().BoolVar(&listJson, "json", false, "Output as JSON (same format as export: version, exported_at, items with all attributes)")
-	listCmd.Flags().StringVar(&listGroup, "group", "", "Group items by key: tags, status")
+	listCmd.Flags().StringVar(&listGroup, "group", "", "Group items into sections: status, tag (each tag; multi-tagged items repeat), or tags (tag set); alias --group-by")
+	listCmd.Flags().StringVar(&listWhere, "where", "", "Filter expression (tag:X, status:S, overdue, has:NOTE, text:SUB); starts from all items unless a state flag is set")
+	listCmd.Flags().BoolVar(&listCount, "count", false, "Print only the number of matching items")
+	listCmd.Flags().BoolVar(&listNoTruncate, "no-truncate", false, "Print each full first line instead of truncating to an aligned column")
+	listCmd.Flags().BoolVar(&listPretty, "pretty", false, "With --json, indent the output instead of writing it on one line")
+	listCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "group-by" {
+			name = "group"
+		}
+		return pflag.NormalizedName(name)
+	})
 	initPick()
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	if listPretty && !listJson {
+		return usageErrorf("--pretty requires --json")
+	}
 	root, err := wn.FindRootForCLI()
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -2565,38 +4729,54 @@ func runList(cmd *cobra.Command, args []string) error {
 		stateFlags++
 	}
 	if stateFlags > 1 {
-		return fmt.Errorf("only one of --undone, --done, --all, --review-ready may be set")
+		return usageErrorf("only one of --undone, --done, --all, --review-ready may be set")
 	}
-	// Default when no filter: undone (available for next/claim)
-	useUndone := listUndone || stateFlags == 0
-	// Load all items once for blocked state computation.
-	allItems, err := store.List()
-	if err != nil {
-		return err
+	if listCount && (listJson || listGroup != "") {
+		return usageErrorf("--count cannot be combined with --json or --group")
+	}
+	var where wn.Filter
+	if listWhere != "" {
+		where, err = wn.ParseFilter(listWhere)
+		if err != nil {
+			return err
+		}
 	}
-	blockedSet := wn.BlockedSet(allItems)
+	// Default when no filter: undone (available for next/claim). With --where and no state flag,
+	// start from all items so the filter (e.g. status:done) decides.
+	useAll := listAll || (listWhere != "" && stateFlags == 0)
 	var items []*wn.Item
-	if listAll {
-		items = allItems
-	} else if listDone {
-		for _, it := range allItems {
-			if it.Done {
-				items = append(items, it)
+	var blockedSet map[string]bool
+	if useAll || listDone {
+		// Load all items once for blocked state computation.
+		allItems, err := store.List()
+		if err != nil {
+			return err
+		}
+		blockedSet = wn.BlockedSet(allItems)
+		if useAll {
+			items = allItems
+		} else {
+			for _, it := range allItems {
+				if it.Done {
+					items = append(items, it)
+				}
 			}
 		}
-	} else if listReviewReady {
-		items, err = wn.ReviewReadyItems(store)
+	} else {
+		if listReviewReady {
+			items, err = wn.ReviewReadyItems(store)
+		} else {
+			// --undone or default: all undone (including review-ready); exclude in-progress only
+			items, err = wn.ListableUndoneItems(store)
+		}
 		if err != nil {
 			return err
 		}
-	} else if useUndone {
-		// --undone or default: all undone (including review-ready); exclude in-progress only
-		items, err = wn.ListableUndoneItems(store)
+		// Undone views read done state from the index instead of loading every item.
+		blockedSet, err = wn.BlockedSetFromIndex(store, items)
 		if err != nil {
 			return err
 		}
-	} else {
-		items = nil
 	}
 	if listTag != "" {
 		var filtered []*wn.Item
@@ -2610,6 +4790,9 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 		items = filtered
 	}
+	if listWhere != "" {
+		items = wn.FilterItems(items, where, time.Now().UTC())
+	}
 	var ordered []*wn.Item
 	sortSpec := listSortSpec(root)
 	if len(sortSpec) > 0 {
@@ -2632,41 +4815,99 @@ func runList(cmd *cobra.Command, args []string) error {
 			}
 		}
 	}
+	if listCount {
+		fmt.Println(len(ordered))
+		return nil
+	}
 	if listGroup != "" {
 		switch listGroup {
-		case "tags", "status":
+		case "tag", "tags", "status":
 		default:
-			return fmt.Errorf("invalid --group key %q (use: tags, status)", listGroup)
+			return usageErrorf("invalid --group key %q (use: status, tag, tags)", listGroup)
 		}
+		now := time.Now().UTC()
+		groups := groupListItems(ordered, listGroup, now, blockedSet)
 		if listJson {
-			return fmt.Errorf("--group and --json are incompatible")
+			return printGroupedListJSON(groups, listGroup)
 		}
-		// Group-first sort: when grouping by tags, sort by tags as primary key.
-		// When grouping by status, sort by status (computed) as primary.
-		now := time.Now().UTC()
-		ordered = applyGroupSort(ordered, listGroup, now, blockedSet)
-		printGroupedList(ordered, listGroup, now, blockedSet)
+		printGroupedList(groups, listGroup, now, blockedSet, listRowDescWidth(ordered))
 		return nil
 	}
 	if listJson {
 		// Same format as wn export: version, exported_at, items (full attributes).
+		if listPretty {
+			return wn.ExportItemsIndent(ordered, "")
+		}
 		return wn.ExportItems(ordered, "")
 	}
 	now := time.Now().UTC()
-	const listStatusWidth = 7
-	const listDescWidth = 51 // so tags align on the right
+	descWidth := listRowDescWidth(ordered)
 	for _, it := range ordered {
-		status := itemListStatus(it, now, blockedSet[it.ID])
-		desc := wn.FirstLine(it.Description)
-		if len(desc) > listDescWidth {
-			desc = desc[:listDescWidth-3] + "..."
-		}
-		tagsStr := formatTags(it.Tags)
-		fmt.Printf("  %-6s  %-*s  %-*s  %s\n", it.ID, listStatusWidth, status, listDescWidth, desc, tagsStr)
+		printListRow(it, now, blockedSet[it.ID], descWidth)
 	}
 	return nil
 }
 
+const (
+	listStatusWidth   = 7
+	listDescStart     = 2 + 6 + 2 + listStatusWidth + 2 // "  "+id+"  "+status+"  "
+	defaultDescWidth  = 51                              // when not a terminal and no --width
+	minDescWidth      = 10
+	defaultTitleWidth = 56
+	minTitleWidth     = 20
+)
+
+// listDescWidth returns the description column width for list rows of items: fill outputWidth
+// while leaving room for the longest tag list, so tags stay aligned. Without an output width it
+// is defaultDescWidth.
+func listDescWidth(items []*wn.Item) int {
+	if outputWidth <= 0 {
+		return defaultDescWidth
+	}
+	tagsWidth := 0
+	for _, it := range items {
+		tagsWidth = max(tagsWidth, len(formatTags(it.Tags)))
+	}
+	return max(outputWidth-listDescStart-2-tagsWidth, minDescWidth)
+}
+
+// listRowDescWidth is listDescWidth for wn list, or 0 (full first lines) with --no-truncate.
+func listRowDescWidth(items []*wn.Item) int {
+	if listNoTruncate {
+		return 0
+	}
+	return listDescWidth(items)
+}
+
+// showTitleWidth returns the padded width of the "[id] title" line in wn show, leaving rest
+// columns (tags and state) on the right. Without an output width it is defaultTitleWidth.
+func showTitleWidth(rest int) int {
+	if outputWidth <= 0 {
+		return defaultTitleWidth
+	}
+	return max(outputWidth-2-rest, minTitleWidth)
+}
+
+// printListRow prints it in the compact wn list row format: id, status, first line truncated to
+// descWidth (see listDescWidth), tags. A descWidth of 0 prints the full first line unpadded
+// (wn list --no-truncate).
+func printListRow(it *wn.Item, now time.Time, blocked bool, descWidth int) {
+	status := itemListStatus(it, now, blocked)
+	desc := wn.FirstLine(it.Description)
+	if descWidth <= 0 {
+		line := fmt.Sprintf("  %-6s  %-*s  %s", it.ID, listStatusWidth, status, desc)
+		if tags := formatTags(it.Tags); tags != "" {
+			line += "  " + tags
+		}
+		fmt.Println(line)
+		return
+	}
+	if len(desc) > descWidth {
+		desc = desc[:descWidth-3] + "..."
+	}
+	fmt.Printf("  %-6s  %-*s  %-*s  %s\n", it.ID, listStatusWidth, status, descWidth, desc, formatTags(it.Tags))
+}
+
 // applyGroupSort sorts items so that items with the same group key are adjacent.
 // For "tags", uses the canonical tag string. For "status", uses the computed status string.
 func applyGroupSort(items []*wn.Item, by string, now time.Time, blockedSet map[string]bool) []*wn.Item {
@@ -2705,9 +4946,17 @@ func itemGroupKey(it *wn.Item, by string, now time.Time, blockedSet map[string]b
 	return ""
 }
 
+// noTagGroup is the --group tag section for untagged items.
+const noTagGroup = "(none)"
+
 // itemGroupHeader returns the formatted section header for a group key.
 func itemGroupHeader(key, by string) string {
 	switch by {
+	case "tag":
+		if key == noTagGroup {
+			return "--- " + noTagGroup + " ---"
+		}
+		return "--- #" + key + " ---"
 	case "tags":
 		if key == "" {
 			return "--- (no tags) ---"
@@ -2725,25 +4974,82 @@ func itemGroupHeader(key, by string) string {
 	return "--- " + key + " ---"
 }
 
-// printGroupedList prints items with section headers between groups.
-func printGroupedList(items []*wn.Item, by string, now time.Time, blockedSet map[string]bool) {
-	const listStatusWidth = 7
-	const listDescWidth = 51
-	var currentGroup *string
-	for _, it := range items {
+// listItemGroup is one section of grouped list output.
+type listItemGroup struct {
+	Key   string
+	Items []*wn.Item
+}
+
+// groupListItems splits items into sections. For "tag", an item appears under each of its tags
+// (sections in tag order, untagged items last under "(none)") and keeps its position from items.
+// For "tags" and "status", items are sorted by group key (applyGroupSort) and split where it changes.
+func groupListItems(items []*wn.Item, by string, now time.Time, blockedSet map[string]bool) []listItemGroup {
+	if by == "tag" {
+		byTag := make(map[string][]*wn.Item)
+		var untagged []*wn.Item
+		for _, it := range items {
+			if len(it.Tags) == 0 {
+				untagged = append(untagged, it)
+				continue
+			}
+			for _, t := range it.Tags {
+				byTag[t] = append(byTag[t], it)
+			}
+		}
+		var groups []listItemGroup
+		for _, t := range slices.Sorted(maps.Keys(byTag)) {
+			groups = append(groups, listItemGroup{Key: t, Items: byTag[t]})
+		}
+		if len(untagged) > 0 {
+			groups = append(groups, listItemGroup{Key: noTagGroup, Items: untagged})
+		}
+		return groups
+	}
+	var groups []listItemGroup
+	for _, it := range applyGroupSort(items, by, now, blockedSet) {
 		key := itemGroupKey(it, by, now, blockedSet)
-		if currentGroup == nil || *currentGroup != key {
-			currentGroup = &key
-			fmt.Println(itemGroupHeader(key, by))
+		if len(groups) == 0 || groups[len(groups)-1].Key != key {
+			groups = append(groups, listItemGroup{Key: key})
+		}
+		groups[len(groups)-1].Items = append(groups[len(groups)-1].Items, it)
+	}
+	return groups
+}
+
+// printGroupedList prints each group's items under a section header (see printListRow for descWidth).
+func printGroupedList(groups []listItemGroup, by string, now time.Time, blockedSet map[string]bool, descWidth int) {
+	for _, g := range groups {
+		fmt.Println(itemGroupHeader(g.Key, by))
+		for _, it := range g.Items {
+			printListRow(it, now, blockedSet[it.ID], descWidth)
 		}
-		status := itemListStatus(it, now, blockedSet[it.ID])
-		desc := wn.FirstLine(it.Description)
-		if len(desc) > listDescWidth {
-			desc = desc[:listDescWidth-3] + "..."
+	}
+}
+
+// printGroupedListJSON prints {"group_by": by, "groups": [{"key": ..., "items": [...]}]} with
+// items in the export format (all attributes).
+func printGroupedListJSON(groups []listItemGroup, by string) error {
+	type groupJSON struct {
+		Key   string           `json:"key"`
+		Items []*wn.ExportItem `json:"items"`
+	}
+	out := struct {
+		GroupBy string      `json:"group_by"`
+		Groups  []groupJSON `json:"groups"`
+	}{GroupBy: by, Groups: []groupJSON{}}
+	for _, g := range groups {
+		gj := groupJSON{Key: g.Key, Items: make([]*wn.ExportItem, len(g.Items))}
+		for i, it := range g.Items {
+			gj.Items[i] = wn.ItemToExportItem(it)
 		}
-		tagsStr := formatTags(it.Tags)
-		fmt.Printf("  %-6s  %-*s  %-*s  %s\n", it.ID, listStatusWidth, status, listDescWidth, desc, tagsStr)
+		out.Groups = append(out.Groups, gj)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
 	}
+	fmt.Println(string(data))
+	return nil
 }
 
 // listSortSpec returns sort options from --sort flag or effective settings (user + project). Invalid spec returns nil.
@@ -2767,19 +5073,22 @@ func listSortSpec(root string) []wn.SortOption {
 var noteCmd = &cobra.Command{
 	Use:   "note",
 	Short: "Add, list, edit, remove, or show notes (attachments) on a work item",
-	Long:  "Notes attach text by logical name (e.g. pr-url, issue-number). Use 'wn note add <name> [id] -m \"...\"', 'wn note list [id]', 'wn note show [id] <name>', 'wn note edit [id] <name> -m \"...\"', and 'wn note rm [id] <name>'. Names are alphanumeric, slash, underscore, or hyphen, up to 32 chars.",
+	Long:  "Notes attach text by logical name (e.g. pr-url, issue-number). Use 'wn note add <name> [id] -m \"...\"', 'wn note list [id]', 'wn note get [id] <name>' (or show), 'wn note edit [id] <name> -m \"...\"', and 'wn note rm [id] <name>'. Names are alphanumeric, slash, underscore, or hyphen, up to 32 chars.",
 }
 
 var noteAddCmd = &cobra.Command{
 	Use:   "add <name> [id]",
 	Short: "Add or update a note by name on a work item",
+	Long:  "Add a note, or replace the body of the note with that name. Use --append to add the text on a new line after the existing body instead (creating the note if it doesn't exist).",
 	Args:  cobra.RangeArgs(1, 2),
 	RunE:  runNoteAdd,
 }
 var noteAddMessage string
+var noteAddAppend bool
 
 func init() {
 	noteAddCmd.Flags().StringVarP(&noteAddMessage, "message", "m", "", "Note text (or open $EDITOR if omitted)")
+	noteAddCmd.Flags().BoolVar(&noteAddAppend, "append", false, "Append the text on a new line when the note exists instead of replacing it")
 	noteCmd.AddCommand(noteAddCmd, noteListCmd, noteShowCmd, noteEditCmd, noteRmCmd)
 }
 
@@ -2793,7 +5102,7 @@ func runNoteAdd(cmd *cobra.Command, args []string) error {
 		var err error
 		body, err = wn.EditWithEditor("")
 		if err != nil {
-			return err
+			return editorErr(err)
 		}
 		if strings.TrimSpace(body) == "" {
 			return fmt.Errorf("empty note")
@@ -2813,12 +5122,13 @@ func runNoteAdd(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
+	settings, _ := wn.ReadSettingsInRoot(root)
 	now := time.Now().UTC()
 	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		if it.Notes == nil {
@@ -2826,8 +5136,10 @@ func runNoteAdd(cmd *cobra.Command, args []string) error {
 		}
 		idx := it.NoteIndexByName(name)
 		trimmed := strings.TrimSpace(body)
-		if idx >= 0 {
-			it.Notes[idx].Body = trimmed
+		if idx >= 0 && noteAddAppend {
+			it.SetNoteBody(idx, it.Notes[idx].Body+"\n"+trimmed, now, settings.NoteHistory)
+		} else if idx >= 0 {
+			it.SetNoteBody(idx, trimmed, now, settings.NoteHistory)
 		} else {
 			it.Notes = append(it.Notes, wn.Note{Name: name, Created: now, Body: trimmed})
 		}
@@ -2837,10 +5149,17 @@ func runNoteAdd(cmd *cobra.Command, args []string) error {
 }
 
 var noteShowCmd = &cobra.Command{
-	Use:   "show [id] <name>",
-	Short: "Print the body of a named note",
-	Args:  cobra.RangeArgs(1, 2),
-	RunE:  runNoteShow,
+	Use:     "show [id] <name>",
+	Aliases: []string{"get"},
+	Short:   "Print the body of a named note",
+	Long:    "Print only the body of the named note (newline-terminated); fails if the item has no such note. Use --json for {name, body, created}. 'wn note get' is the same command.",
+	Args:    cobra.RangeArgs(1, 2),
+	RunE:    runNoteShow,
+}
+var noteShowJson bool
+
+func init() {
+	noteShowCmd.Flags().BoolVar(&noteShowJson, "json", false, "Output as JSON: name, body, created")
 }
 
 func runNoteShow(cmd *cobra.Command, args []string) error {
@@ -2859,31 +5178,47 @@ func runNoteShow(cmd *cobra.Command, args []string) error {
 	} else {
 		id, err = wn.ResolveItemID(meta.CurrentID, "")
 		if err != nil {
-			return fmt.Errorf("no id provided and no current task")
+			return emptyErrorf("no id provided and no current task")
 		}
 		nameArg = args[0]
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
 	item, err := store.Get(id)
 	if err != nil {
-		return fmt.Errorf("item %s not found", id)
+		return notFoundErrorf("item %s not found", id)
 	}
 	idx := item.NoteIndexByName(nameArg)
 	if idx < 0 {
-		return fmt.Errorf("no note named %q", nameArg)
+		return fmt.Errorf("item %s has no note named %q", id, nameArg)
+	}
+	n := item.Notes[idx]
+	if noteShowJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(struct {
+			Name    string    `json:"name"`
+			Body    string    `json:"body"`
+			Created time.Time `json:"created"`
+		}{n.Name, n.Body, n.Created})
 	}
-	fmt.Println(item.Notes[idx].Body)
+	fmt.Println(n.Body)
 	return nil
 }
 
 var noteListCmd = &cobra.Command{
 	Use:   "list [id]",
 	Short: "List notes on a work item (ordered by create time)",
+	Long:  "List notes on a work item, one per line: name, created time, body. Use --history <name> to show how one note evolved instead: each kept prior body and then the current body, oldest first (prior bodies are kept only when settings note_history is on).",
 	Args:  cobra.MaximumNArgs(1),
-	RunE:  runNoteList,
+	RunE:  paged(runNoteList, nil),
+}
+var noteListHistory string
+
+func init() {
+	noteListCmd.Flags().StringVar(&noteListHistory, "history", "", "Show the prior bodies of the named note, oldest first")
 }
 
 func runNoteList(cmd *cobra.Command, args []string) error {
@@ -2901,18 +5236,34 @@ func runNoteList(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
 	item, err := store.Get(id)
 	if err != nil {
-		return fmt.Errorf("item %s not found", id)
+		return notFoundErrorf("item %s not found", id)
+	}
+	if noteListHistory != "" {
+		idx := item.NoteIndexByName(noteListHistory)
+		if idx < 0 {
+			return fmt.Errorf("no note named %q", noteListHistory)
+		}
+		n := item.Notes[idx]
+		for _, v := range n.History {
+			fmt.Printf("%s\t%s\n", formatTime(v.At), v.Body)
+		}
+		at := n.Updated
+		if at.IsZero() {
+			at = n.Created
+		}
+		fmt.Printf("%s\t%s\n", formatTime(at), n.Body)
+		return nil
 	}
 	for _, n := range item.Notes {
-		fmt.Printf("%s\t%s\t%s\n", n.Name, n.Created.Format("2006-01-02 15:04:05"), n.Body)
+		fmt.Printf("%s\t%s\t%s\n", n.Name, formatTime(n.Created), n.Body)
 	}
 	return nil
 }
@@ -2945,11 +5296,11 @@ func runNoteEdit(cmd *cobra.Command, args []string) error {
 	} else {
 		id, err = wn.ResolveItemID(meta.CurrentID, "")
 		if err != nil {
-			return fmt.Errorf("no id provided and no current task")
+			return emptyErrorf("no id provided and no current task")
 		}
 		nameArg = args[0]
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -2957,7 +5308,7 @@ func runNoteEdit(cmd *cobra.Command, args []string) error {
 	if body == "" {
 		item, err := store.Get(id)
 		if err != nil {
-			return fmt.Errorf("item %s not found", id)
+			return notFoundErrorf("item %s not found", id)
 		}
 		idx := item.NoteIndexByName(nameArg)
 		if idx < 0 {
@@ -2966,7 +5317,7 @@ func runNoteEdit(cmd *cobra.Command, args []string) error {
 		var errEdit error
 		body, errEdit = wn.EditWithEditor(item.Notes[idx].Body)
 		if errEdit != nil {
-			return errEdit
+			return editorErr(errEdit)
 		}
 		if strings.TrimSpace(body) == "" {
 			return fmt.Errorf("empty note")
@@ -2975,13 +5326,14 @@ func runNoteEdit(cmd *cobra.Command, args []string) error {
 	} else {
 		body = strings.TrimSpace(body)
 	}
+	settings, _ := wn.ReadSettingsInRoot(root)
 	now := time.Now().UTC()
 	return store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
 		idx := it.NoteIndexByName(nameArg)
 		if idx < 0 {
 			return nil, fmt.Errorf("no note named %q", nameArg)
 		}
-		it.Notes[idx].Body = body
+		it.SetNoteBody(idx, body, now, settings.NoteHistory)
 		it.Updated = now
 		return it, nil
 	})
@@ -3010,11 +5362,11 @@ func runNoteRm(cmd *cobra.Command, args []string) error {
 	} else {
 		id, err = wn.ResolveItemID(meta.CurrentID, "")
 		if err != nil {
-			return fmt.Errorf("no id provided and no current task")
+			return emptyErrorf("no id provided and no current task")
 		}
 		nameArg = args[0]
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -3066,7 +5418,7 @@ func runPrompt(cmd *cobra.Command, args []string) error {
 		var err error
 		msg, err = wn.EditWithEditor("")
 		if err != nil {
-			return err
+			return editorErr(err)
 		}
 		if strings.TrimSpace(msg) == "" {
 			return fmt.Errorf("empty question")
@@ -3086,9 +5438,9 @@ func runPrompt(cmd *cobra.Command, args []string) error {
 	}
 	parentID, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -3167,9 +5519,9 @@ func runRespond(cmd *cobra.Command, args []string) error {
 	}
 	id, err := wn.ResolveItemID(meta.CurrentID, explicitID)
 	if err != nil {
-		return fmt.Errorf("no id provided and no current task")
+		return emptyErrorf("no id provided and no current task")
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -3185,7 +5537,7 @@ func runRespond(cmd *cobra.Command, args []string) error {
 		var err error
 		msg, err = wn.EditWithEditor("")
 		if err != nil {
-			return err
+			return editorErr(err)
 		}
 		if strings.TrimSpace(msg) == "" {
 			return fmt.Errorf("empty response")
@@ -3216,3 +5568,9 @@ func runRespond(cmd *cobra.Command, args []string) error {
 	fmt.Printf("responded to %s; prompt marked done\n", id)
 	return nil
 }
+
+// This init is last in the file so it runs after the ones above have built the command tree.
+func init() {
+	registerCompletions()
+	classifyUsageErrors(rootCmd)
+}
@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -23,6 +28,16 @@ func resetShowFlags() {
 	showPlain = false
 	showAll = false
 	showFields = ""
+	showWeb = false
+	showRelated = false
+	showMarkdown = false
+	showEdited = false
+	showMaxChars = 0
+	showTruncate = false
+}
+
+func resetCurrentFlags() {
+	currentJson = false
 }
 
 func resetPickFlags() {
@@ -30,6 +45,10 @@ func resetPickFlags() {
 	pickDone = false
 	pickAll = false
 	pickReviewReady = false
+	pickMulti = false
+	pickClaim = ""
+	pickMarkDone = false
+	pickMessage = ""
 }
 
 // resetTagFlags clears tag flags to avoid Cobra's flag persistence across
@@ -37,6 +56,9 @@ func resetPickFlags() {
 func resetTagFlags() {
 	tagWid = ""
 	tagAddInteractive = false
+	tagAddWhere = ""
+	tagAddJson = false
+	tagRmJson = false
 }
 
 // resetListFlags clears list flags to avoid Cobra's flag persistence across
@@ -53,22 +75,51 @@ func resetListFlags() {
 	listOffset = 0
 	listJson = false
 	listGroup = ""
+	listWhere = ""
+	listCount = false
+	listNoTruncate = false
+	listPretty = false
 }
 
 // resetDependFlags clears depend subcommand flags to avoid Cobra's flag persistence
 // across Execute() calls. Call before each test that invokes "depend" with different flags.
+// resetCleanupMergedFlags clears cleanup set-merged-review-items-done flags to avoid
+// Cobra's flag persistence across Execute() calls.
+func resetCleanupMergedFlags() {
+	cleanupMergedDryRun = false
+	cleanupMergedBranch = ""
+	cleanupMergedTag = ""
+	cleanupMergedID = ""
+	cleanupMergedRemote = "origin"
+}
+
+// resetNextFlags clears next flags to avoid Cobra's flag persistence across Execute() calls.
+func resetNextFlags() {
+	nextTag = ""
+	nextClaimFor = ""
+	nextNoClaim = false
+	nextClaimBy = ""
+	nextStrictOrder = false
+	nextJson = false
+	nextExcludeTags = nil
+	nextQuiet = false
+}
+
 func resetDependFlags() {
 	dependAddOn = ""
+	dependAddOnTag = ""
 	dependAddWid = ""
 	dependAddInteractive = false
 	dependRmOn = ""
 	dependRmWid = ""
 	dependRmInteractive = false
+	dependRmAll = false
 	dependListWid = ""
+	dependAddJson = false
+	dependRmJson = false
 }
 
-// listStatusWidth and listIDWidth must match runList formatting for alignment tests.
-const listStatusWidth = 7
+// listIDWidth must match runList formatting (with listStatusWidth) for alignment tests.
 const listIDWidth = 6
 const listDescriptionStart = 2 + listIDWidth + 2 + listStatusWidth + 2 // "  "+id+"  "+status+"  "
 
@@ -147,6 +198,22 @@ func captureStdout(t *testing.T, fn func()) string {
 	return buf.String()
 }
 
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	os.Stderr = w
+	defer func() { os.Stderr = old }()
+	fn()
+	_ = w.Close()
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	return buf.String()
+}
+
 func TestListJSON(t *testing.T) {
 	dir, _ := setupWnRoot(t)
 	cwd, _ := os.Getwd()
@@ -275,6 +342,200 @@ func TestShowAll(t *testing.T) {
 	}
 }
 
+func TestShowDuplicateOf(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	resetShowFlags()
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "mid222", Description: "middle copy", Created: now, Updated: now, Notes: []wn.Note{{Name: wn.NoteNameDuplicateOf, Created: now, Body: itemID}}},
+		{ID: "dup111", Description: "latest copy", Created: now, Updated: now, Notes: []wn.Note{{Name: wn.NoteNameDuplicateOf, Created: now, Body: "mid222"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "dup111"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if want := "duplicate of: mid222 → " + itemID + " (first line)"; !strings.Contains(out, want) {
+		t.Errorf("show should contain %q; got %q", want, out)
+	}
+}
+
+func TestShowRelated(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetShowFlags()
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "src111", Description: "source item", Tags: []string{"api", "auth"}, Created: now, Updated: now},
+		{ID: "one222", Description: "shares one tag", Tags: []string{"api"}, Created: now, Updated: now},
+		{ID: "two333", Description: "shares two tags", Tags: []string{"auth", "api"}, Created: now, Updated: now},
+		{ID: "don444", Description: "done sibling", Tags: []string{"api"}, Done: true, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	resetShowFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "src111", "--related"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	_, related, ok := strings.Cut(out, "related:\n")
+	if !ok {
+		t.Fatalf("show --related should print a related section; got %q", out)
+	}
+	if i, j := strings.Index(related, "two333"), strings.Index(related, "one222"); i < 0 || j < 0 || i > j {
+		t.Errorf("related should list two333 before one222; got %q", related)
+	}
+	if strings.Contains(related, "don444") || strings.Contains(related, "src111") {
+		t.Errorf("related should exclude done items and the item itself; got %q", related)
+	}
+
+	resetShowFlags()
+	rootCmd.SetArgs([]string{"show", "src111", "--related", "--json"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("show --related --json should fail")
+	}
+}
+
+func TestShowMarkdown(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetShowFlags()
+
+	resetShowFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", itemID, "--markdown"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if want := "### first line\n\nsecond line\n"; !strings.HasPrefix(out, want) {
+		t.Errorf("show --markdown = %q, want prefix %q", out, want)
+	}
+
+	resetShowFlags()
+	rootCmd.SetArgs([]string{"show", itemID, "--markdown", "--json"})
+	if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+		t.Errorf("show --markdown --json: exit code %d, want %d", exitCode(err), exitUsage)
+	}
+}
+
+func TestShowPlainMaxChars(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	resetShowFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", itemID, "--plain", "--max-chars", "15", "--truncate"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if out != "first line…\n" {
+		t.Errorf("show --plain --truncate = %q, want %q", out, "first line…\n")
+	}
+
+	// Over the limit without --truncate still prints the full text (the warning goes to stderr).
+	resetShowFlags()
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", itemID, "--plain", "--max-chars", "15"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if out != "first line\nsecond line\n" {
+		t.Errorf("show --plain --max-chars = %q, want the full text", out)
+	}
+
+	for _, args := range [][]string{
+		{"show", itemID, "--max-chars", "15"},
+		{"show", itemID, "--plain", "--truncate"},
+	} {
+		resetShowFlags()
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+			t.Errorf("%v: exit code %d, want %d", args, exitCode(err), exitUsage)
+		}
+	}
+	resetShowFlags()
+}
+
+func TestShowEdited(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetShowFlags()
+	show := func() string {
+		resetShowFlags()
+		return captureStdout(t, func() {
+			rootCmd.SetArgs([]string{"show", itemID, "--edited", "--fields", "title"})
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("Execute: %v", err)
+			}
+		})
+	}
+	if out := show(); !strings.Contains(out, "last edited: never (created ") {
+		t.Errorf("show --edited on an unedited item = %q, want never", out)
+	}
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	if err := store.UpdateItem(itemID, func(it *wn.Item) (*wn.Item, error) {
+		it.Log = append(it.Log, wn.LogEntry{At: at, Kind: "updated", Msg: wn.EditSourceMCP + " by worker-7"})
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if out, want := show(), "last edited: 2026-03-04 05:06:07 by agent worker-7 (mcp)\n"; !strings.HasSuffix(out, want) {
+		t.Errorf("show --edited = %q, want suffix %q", out, want)
+	}
+}
+
 func TestBareWnAcceptsID(t *testing.T) {
 	dir, itemID := setupWnRoot(t)
 	cwd, _ := os.Getwd()
@@ -555,6 +816,68 @@ func TestListJSONRespectsDoneFilter(t *testing.T) {
 	}
 }
 
+func TestListWhere(t *testing.T) {
+	resetListFlags()
+	defer resetListFlags()
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, item := range []*wn.Item{
+		{ID: "rr1", Description: "review backend", Tags: []string{"backend"}, ReviewReady: true, Created: now, Updated: now},
+		{ID: "rr2", Description: "review frontend", Tags: []string{"frontend"}, ReviewReady: true, Created: now, Updated: now},
+		{ID: "done1", Description: "done backend", Tags: []string{"backend"}, Done: true, Created: now, Updated: now},
+		{ID: "undone1", Description: "undone backend", Tags: []string{"backend"}, Created: now, Updated: now},
+	} {
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"list", "--json", "--where", "status:review-ready tag:backend"}, []string{"rr1"}},
+		// --where alone starts from all items, so done items can match.
+		{[]string{"list", "--json", "--where", "status:done"}, []string{"done1"}},
+		// An explicit state flag still narrows the base set first.
+		{[]string{"list", "--json", "--undone", "--where", "tag:backend"}, []string{"rr1", "undone1"}},
+		{[]string{"list", "--json", "--where", "tag:backend", "--sort", "alpha", "--limit", "1", "--offset", "1"}, []string{"rr1"}},
+	}
+	for _, tt := range tests {
+		resetListFlags()
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(tt.args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("%v: %v", tt.args, err)
+			}
+		})
+		list := parseListJSON(t, out)
+		got := itemIDs(list.Items)
+		slices.Sort(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%v = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	resetListFlags()
+	rootCmd.SetArgs([]string{"list", "--where", "status:nope"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "status must be one of") {
+		t.Errorf("list --where invalid: want parse error, got %v", err)
+	}
+}
+
 func TestListUndoneIncludesReviewReady(t *testing.T) {
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
@@ -697,6 +1020,94 @@ func TestPickWithDoneFlag(t *testing.T) {
 	}
 }
 
+func TestPickMulti(t *testing.T) {
+	origPath := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	t.Cleanup(func() { os.Setenv("PATH", origPath) })
+	defer resetPickFlags()
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "aaa111", Description: "one", Created: now, Updated: now},
+		{ID: "bbb222", Description: "two", Created: now.Add(time.Second), Updated: now},
+		{ID: "ccc333", Description: "three", Created: now.Add(2 * time.Second), Updated: now, DependsOn: []string{"ppp999"}},
+		{ID: "ppp999", Description: "question", Created: now, Updated: now, PromptReady: true},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	feed := func(input string) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		origStdin := os.Stdin
+		os.Stdin = r
+		t.Cleanup(func() { os.Stdin = origStdin })
+		if _, err := w.WriteString(input); err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+
+	resetPickFlags()
+	rootCmd.SetArgs([]string{"pick", "--multi"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("pick --multi without an action should fail")
+	}
+	resetPickFlags()
+	rootCmd.SetArgs([]string{"pick", "--mark-done"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("pick --mark-done without --multi should fail")
+	}
+
+	resetPickFlags()
+	feed("1 2\n")
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"pick", "--multi", "--claim", "1h"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("pick --multi --claim: %v", err)
+		}
+	})
+	for _, id := range []string{"aaa111", "bbb222"} {
+		it, _ := store.Get(id)
+		if !wn.IsInProgress(it, time.Now().UTC()) {
+			t.Errorf("%s should be claimed", id)
+		}
+	}
+
+	// Only ccc333 is still available; mark it done like wn done would: the message is recorded
+	// and its prompt-ready dependency is closed with it.
+	resetPickFlags()
+	feed("1\n")
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"pick", "--multi", "--mark-done", "-m", "shipped"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("pick --multi --mark-done: %v", err)
+		}
+	})
+	it, _ := store.Get("ccc333")
+	if !it.Done || it.DoneMessage != "shipped" || it.Log[len(it.Log)-1].Msg != "shipped" {
+		t.Errorf("ccc333 = done %v, message %q, log %+v; want done with message shipped", it.Done, it.DoneMessage, it.Log)
+	}
+	if dep, _ := store.Get("ppp999"); !dep.Done || dep.PromptReady {
+		t.Error("prompt-ready dependency ppp999 should be auto-closed")
+	}
+}
+
 func TestPickWithAllFlag(t *testing.T) {
 	origPath := os.Getenv("PATH")
 	os.Setenv("PATH", "")
@@ -1008,21 +1419,21 @@ func TestExportWithCriteria(t *testing.T) {
 	}
 }
 
-func itemIDs(items []*wn.Item) []string {
-	ids := make([]string, len(items))
-	for i, it := range items {
-		ids[i] = it.ID
-	}
-	return ids
-}
-
-// resetImportFlags clears import flags so tests get consistent behavior.
-func resetImportFlags() {
-	importReplace = false
-	importAppend = false
+// resetExportFlags clears export flags to avoid Cobra's flag persistence across Execute() calls.
+func resetExportFlags() {
+	exportOutput = ""
+	exportAll = false
+	exportUndone = false
+	exportDone = false
+	exportTag = ""
+	exportSince = ""
+	exportSplit = ""
+	exportPretty = false
 }
 
-func TestImport_StoreHasItemsNoFlagErrors(t *testing.T) {
+func TestExportSince(t *testing.T) {
+	resetExportFlags()
+	defer resetExportFlags()
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1032,373 +1443,2257 @@ func TestImport_StoreHasItemsNoFlagErrors(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	if err := store.Put(&wn.Item{ID: "abc123", Description: "existing", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}); err != nil {
-		t.Fatal(err)
-	}
-	path := dir + "/export.json"
-	if err := wn.Export(store, path); err != nil {
-		t.Fatalf("Export: %v", err)
+	old := now.Add(-10 * 24 * time.Hour)
+	for _, item := range []*wn.Item{
+		{ID: "new111", Description: "recent", Created: old, Updated: now, Tags: []string{"prio"}},
+		{ID: "new222", Description: "recent done", Created: old, Updated: now, Done: true},
+		{ID: "old111", Description: "stale", Created: old, Updated: old, Tags: []string{"prio"}},
+	} {
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
+		}
 	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
-	resetImportFlags()
-	rootCmd.SetArgs([]string{"import", path})
-	err = rootCmd.Execute()
-	if err == nil {
-		t.Fatal("expected error when store has items and no --append/--replace")
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"export", "--since", "7d"}, []string{"new111", "new222"}},
+		{[]string{"export", "--since", "7d", "--undone"}, []string{"new111"}},
+		{[]string{"export", "--since", "7d", "--tag", "prio"}, []string{"new111"}},
+		{[]string{"export", "--since", old.Add(-time.Hour).Format(time.RFC3339)}, []string{"new111", "new222", "old111"}},
+	}
+	for _, tt := range tests {
+		resetExportFlags()
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(tt.args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("%v: %v", tt.args, err)
+			}
+		})
+		got := itemIDs(parseListJSON(t, out).Items)
+		slices.Sort(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%v = %v, want %v", tt.args, got, tt.want)
+		}
 	}
-	if !strings.Contains(err.Error(), "--append") || !strings.Contains(err.Error(), "--replace") {
-		t.Errorf("error should mention --append and --replace: %v", err)
+
+	resetExportFlags()
+	rootCmd.SetArgs([]string{"export", "--since", "yesterday"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("export --since yesterday should fail")
 	}
 }
 
-func TestImport_Replace(t *testing.T) {
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
-	}
+func TestExportSplitAndImportDir(t *testing.T) {
+	resetExportFlags()
+	defer resetExportFlags()
+	resetImportFlags()
+	defer resetImportFlags()
+	dir, _ := setupWnRoot(t)
 	store, err := wn.NewFileStore(dir)
 	if err != nil {
-		t.Fatalf("NewFileStore: %v", err)
+		t.Fatal(err)
 	}
 	now := time.Now().UTC()
-	for _, it := range []*wn.Item{
-		{ID: "aaa111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bbb222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-	} {
-		if err := store.Put(it); err != nil {
-			t.Fatal(err)
-		}
-	}
-	path := dir + "/export.json"
-	if err := wn.Export(store, path); err != nil {
-		t.Fatalf("Export: %v", err)
-	}
-	// Add another item so store has 3
-	if err := store.Put(&wn.Item{ID: "ccc333", Description: "third", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}); err != nil {
+	if err := store.Put(&wn.Item{ID: "def456", Description: "second", Created: now, Updated: now, DependsOn: []string{"abc123"}}); err != nil {
 		t.Fatal(err)
 	}
 	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
 	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+		t.Fatal(err)
 	}
-	defer func() { _ = os.Chdir(cwd) }()
-	resetImportFlags()
-	rootCmd.SetArgs([]string{"import", "--replace", path})
+	splitDir := filepath.Join(t.TempDir(), "items")
+	rootCmd.SetArgs([]string{"export", "--split", splitDir})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("import --replace: %v", err)
+		t.Fatalf("export --split: %v", err)
 	}
-	store2, _ := wn.NewFileStore(dir)
-	all, err := store2.List()
-	if err != nil {
-		t.Fatalf("List: %v", err)
+	for _, name := range []string{"abc123.json", "def456.json", wn.ExportManifestName} {
+		if _, err := os.Stat(filepath.Join(splitDir, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
 	}
-	if len(all) != 2 {
-		t.Fatalf("after --replace: len(List) = %d, want 2 (file had 2 items)", len(all))
+
+	resetExportFlags()
+	rootCmd.SetArgs([]string{"export", "--split", splitDir, "-o", "x.json"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("export --split with --output should fail")
 	}
-}
 
-func TestImport_Append(t *testing.T) {
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
+	dir2 := t.TempDir()
+	if err := wn.InitRoot(dir2); err != nil {
+		t.Fatal(err)
 	}
-	store, err := wn.NewFileStore(dir)
+	if err := os.Chdir(dir2); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.SetArgs([]string{"import", splitDir})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import dir: %v", err)
+	}
+	store2, err := wn.NewFileStore(dir2)
 	if err != nil {
-		t.Fatalf("NewFileStore: %v", err)
+		t.Fatal(err)
+	}
+	got, err := store2.Get("def456")
+	if err != nil {
+		t.Fatalf("def456 not imported: %v", err)
+	}
+	if len(got.DependsOn) != 1 || got.DependsOn[0] != "abc123" {
+		t.Errorf("DependsOn = %v, want [abc123]", got.DependsOn)
 	}
+	if _, err := store2.Get("abc123"); err != nil {
+		t.Errorf("abc123 not imported: %v", err)
+	}
+}
+
+func TestInitTemplate(t *testing.T) {
+	defer func() { initTemplate = "" }()
 	now := time.Now().UTC()
-	if err := store.Put(&wn.Item{ID: "old111", Description: "existing", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}); err != nil {
+	tmpl := filepath.Join(t.TempDir(), "starter.json")
+	if err := wn.ExportItems([]*wn.Item{{ID: "onb111", Description: "onboarding", Created: now, Updated: now}}, tmpl); err != nil {
 		t.Fatal(err)
 	}
-	path := dir + "/new.json"
-	if err := wn.ExportItems([]*wn.Item{
-		{ID: "new222", Description: "from file", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-	}, path); err != nil {
-		t.Fatalf("ExportItems: %v", err)
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
 	}
 	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
+
+	dir := t.TempDir()
 	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+		t.Fatal(err)
 	}
-	defer func() { _ = os.Chdir(cwd) }()
-	resetImportFlags()
-	rootCmd.SetArgs([]string{"import", "--append", path})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("import --append: %v", err)
+	initTemplate = ""
+	rootCmd.SetArgs([]string{"init", "--template", bad})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("init --template with an invalid file should fail")
 	}
-	store2, _ := wn.NewFileStore(dir)
-	all, err := store2.List()
+	if _, err := os.Stat(filepath.Join(dir, ".wn")); !os.IsNotExist(err) {
+		t.Errorf(".wn should not be created for a bad template (stat err = %v)", err)
+	}
+
+	initTemplate = ""
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"init", "--template", tmpl})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("init --template: %v", err)
+		}
+	})
+	store, err := wn.NewFileStore(dir)
 	if err != nil {
-		t.Fatalf("List: %v", err)
+		t.Fatal(err)
 	}
-	if len(all) != 2 {
-		t.Fatalf("after --append: len(List) = %d, want 2", len(all))
+	if _, err := store.Get("onb111"); err != nil {
+		t.Errorf("template item not imported: %v", err)
 	}
-	got, _ := store2.Get("new222")
-	if got.Description != "from file" {
-		t.Errorf("new222 description = %q, want from file", got.Description)
+
+	initTemplate = ""
+	rootCmd.SetArgs([]string{"init", "--template", tmpl})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("init --template into a store with items should fail")
 	}
 }
 
-func TestImport_BothAppendAndReplaceErrors(t *testing.T) {
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
-	}
-	path := dir + "/export.json"
-	if err := wn.ExportItems(nil, path); err != nil {
-		t.Fatalf("ExportItems: %v", err)
-	}
+func TestInitGit(t *testing.T) {
+	defer func() { initGit, initBare = false, false }()
 	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
+	dir := t.TempDir()
+	execIn(t, dir, "git", "init")
+	writeFile(t, filepath.Join(dir, "readme"), "x")
+	execIn(t, dir, "git", "add", "readme")
+	execIn(t, dir, "git", "commit", "-m", "init")
+	def, _ := wn.DefaultBranch(dir)
 	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+		t.Fatal(err)
 	}
-	defer func() { _ = os.Chdir(cwd) }()
-	resetImportFlags()
-	rootCmd.SetArgs([]string{"import", "--append", "--replace", path})
-	err := rootCmd.Execute()
-	if err == nil {
-		t.Fatal("expected error when both --append and --replace")
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"init", "--git"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("init --git: %v", err)
+		}
+	})
+	if !strings.Contains(out, "git default branch: "+def) {
+		t.Errorf("init --git output = %q, want default branch %s", out, def)
 	}
-	if !strings.Contains(err.Error(), "append") || !strings.Contains(err.Error(), "replace") {
-		t.Errorf("error should mention append and replace: %v", err)
+
+	initGit, initBare = false, false
+	rootCmd.SetArgs([]string{"init", "--git", "--bare"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("init --git --bare should fail")
 	}
 }
 
-func TestImport_EmptyStoreNoFlagSucceeds(t *testing.T) {
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
-	}
-	path := dir + "/export.json"
-	if err := wn.ExportItems([]*wn.Item{
-		{ID: "only1", Description: "only item", Created: time.Now().UTC(), Updated: time.Now().UTC(), Log: []wn.LogEntry{{At: time.Now().UTC(), Kind: "created"}}},
-	}, path); err != nil {
-		t.Fatalf("ExportItems: %v", err)
-	}
+func TestRootFlag(t *testing.T) {
+	defer func() { rootFlag = ""; wn.SetCLIRootOverride("") }()
+	dir, id := setupWnRoot(t)
 	cwd, _ := os.Getwd()
-	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
-	}
 	defer func() { _ = os.Chdir(cwd) }()
-	resetImportFlags()
-	rootCmd.SetArgs([]string{"import", path})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("import into empty store: %v", err)
-	}
-	store, _ := wn.NewFileStore(dir)
-	got, err := store.Get("only1")
-	if err != nil {
-		t.Fatalf("Get: %v", err)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
 	}
-	if got.Description != "only item" {
-		t.Errorf("description = %q, want only item", got.Description)
+	resetShowFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"--root", dir, "show", id})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("wn --root show: %v", err)
+		}
+	})
+	if !strings.Contains(out, "first line") {
+		t.Errorf("show via --root = %q, want item description", out)
 	}
 }
-func TestListShowsStatusWithAlignment(t *testing.T) {
-	resetListFlags()
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
+
+func TestOrderUpDown(t *testing.T) {
+	defer func() { orderSet, orderUp, orderDown = -1, 0, 0 }()
+	dir, id := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
 	}
 	store, err := wn.NewFileStore(dir)
 	if err != nil {
-		t.Fatalf("NewFileStore: %v", err)
+		t.Fatal(err)
 	}
-	now := time.Now().UTC()
-	for _, item := range []*wn.Item{
-		{ID: "done11", Description: "done task", Created: now, Updated: now, Done: true, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "undone1", Description: "undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, tc := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"order", id, "--up"}, wn.DefaultOrder - 1},
+		{[]string{"order", id, "--down=5"}, wn.DefaultOrder + 4},
+		{[]string{"order", id, "--set", "3"}, 3},
+		{[]string{"order", "--up=10"}, 0},
 	} {
-		if err := store.Put(item); err != nil {
+		orderSet, orderUp, orderDown = -1, 0, 0
+		rootCmd.SetArgs(tc.args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		it, err := store.Get(id)
+		if err != nil {
 			t.Fatal(err)
 		}
+		if it.Order == nil || *it.Order != tc.want {
+			t.Errorf("%v: order = %v, want %d", tc.args, it.Order, tc.want)
+		}
+		if last := it.Log[len(it.Log)-1]; last.Kind != "order_set" || last.Msg != strconv.Itoa(tc.want) {
+			t.Errorf("%v: last log = %+v, want order_set %d", tc.args, last, tc.want)
+		}
+	}
+
+	orderSet, orderUp, orderDown = -1, 0, 0
+	rootCmd.SetArgs([]string{"order", id, "--up", "--down"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("order with --up and --down should fail")
 	}
+	orderSet, orderUp, orderDown = -1, 0, 0
+	rootCmd.SetArgs([]string{"order", id, "--set", "300"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("order --set above MaxOrder should fail")
+	}
+}
+
+func TestDoneAmend(t *testing.T) {
+	defer func() { doneMessage, doneAmend = "", false }()
+	dir, id := setupWnRoot(t)
 	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
 	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+		t.Fatal(err)
 	}
-	defer func() { _ = os.Chdir(cwd) }()
 
-	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--all"})
-		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("Execute: %v", err)
-		}
-	})
+	doneMessage, doneAmend = "", false
+	rootCmd.SetArgs([]string{"done", id, "--amend", "-m", "too early"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("done --amend on an undone item should fail")
+	}
 
-	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
-	if len(lines) < 2 {
-		t.Fatalf("list --all should show at least 2 lines; got %q", out)
+	doneMessage, doneAmend = "", false
+	rootCmd.SetArgs([]string{"done", id, "-m", "fix tpyo"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("done: %v", err)
 	}
-	// Status column shows done/undone (and claimed when applicable); include default undone.
-	if !strings.Contains(out, "done") {
-		t.Errorf("list output should contain status 'done'; got %q", out)
+	doneMessage, doneAmend = "", false
+	rootCmd.SetArgs([]string{"done", id, "--amend", "-m", "fix typo (abc1234)"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("done --amend: %v", err)
 	}
-	if !strings.Contains(out, "undone") {
-		t.Errorf("list output should contain status 'undone'; got %q", out)
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
 	}
-	// Descriptions aligned: each line has status then "  " then description at fixed column.
-	for i, line := range lines {
-		if len(line) < listDescriptionStart {
-			t.Errorf("line %d too short for aligned format: %q", i, line)
-			continue
-		}
-		if line[listDescriptionStart-2:listDescriptionStart] != "  " {
-			t.Errorf("line %d: expected two spaces before description at col %d; got %q", i, listDescriptionStart, line[listDescriptionStart-2:listDescriptionStart])
-		}
+	it, err := store.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.Done || it.DoneMessage != "fix typo (abc1234)" {
+		t.Errorf("Done=%v DoneMessage=%q, want done with amended message", it.Done, it.DoneMessage)
+	}
+	var kinds []string
+	for _, e := range it.Log {
+		kinds = append(kinds, e.Kind)
+	}
+	if got := strings.Join(kinds, ","); !strings.HasSuffix(got, "done,amended") || strings.Count(got, "done") != 1 {
+		t.Errorf("log kinds = %s, want a single done followed by amended", got)
+	}
+
+	doneMessage, doneAmend = "", false
+	rootCmd.SetArgs([]string{"done", id, "--amend"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("done --amend without a message should fail")
 	}
 }
 
-// TestCurrentTaskShowsState verifies that running "wn" (no args) prints the current task's state: done, undone, or claimed.
-func TestCurrentTaskShowsState(t *testing.T) {
-	t.Run("undone", func(t *testing.T) {
-		dir, _ := setupWnRoot(t)
-		cwd, _ := os.Getwd()
-		if err := os.Chdir(dir); err != nil {
+func TestDoneMessageFile(t *testing.T) {
+	defer func() { doneMessage, doneMessageFile = "", ""; statusMessage, statusMessageFile = "", "" }()
+	dir, id := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	msgPath := filepath.Join(t.TempDir(), "msg.txt")
+	writeFile(t, msgPath, "Fix the thing\n\nLonger body.\n")
+
+	doneMessage, doneMessageFile = "", ""
+	rootCmd.SetArgs([]string{"done", id, "--message-file", msgPath, "-m", "short"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("done with both -m and --message-file should fail")
+	}
+
+	doneMessage, doneMessageFile = "", ""
+	rootCmd.SetArgs([]string{"done", id, "--message-file", msgPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("done --message-file: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.DoneMessage != "Fix the thing\n\nLonger body." {
+		t.Errorf("DoneMessage = %q, want file contents without trailing newline", it.DoneMessage)
+	}
+
+	rootCmd.SetIn(strings.NewReader("shelved for now\n"))
+	defer rootCmd.SetIn(nil)
+	rootCmd.SetArgs([]string{"status", "suspend", id, "--message-file", "-"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("status suspend --message-file -: %v", err)
+	}
+	it, err = store.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.DoneMessage != "shelved for now" {
+		t.Errorf("DoneMessage = %q, want stdin message", it.DoneMessage)
+	}
+}
+
+func TestLogTimezone(t *testing.T) {
+	defer func() { tzFlag = ""; _ = wn.SetDisplayTimezone("") }()
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	dir, id := setupWnRoot(t)
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	at := time.Date(2026, 1, 15, 18, 30, 0, 0, time.UTC)
+	if err := store.UpdateItem(id, func(it *wn.Item) (*wn.Item, error) {
+		it.Log = []wn.LogEntry{{At: at, Kind: "created"}}
+		return it, nil
+	}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	runLog := func(args ...string) string {
+		return captureStdout(t, func() {
+			rootCmd.SetArgs(append(args, "log", id, "--no-header"))
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("log: %v", err)
+			}
+		})
+	}
+	defer func() { logNoHeader = false }()
+
+	if out := runLog(); !strings.HasPrefix(out, "2026-01-15 18:30:00 created") {
+		t.Errorf("default log = %q, want UTC timestamp", out)
+	}
+	writeFile(t, filepath.Join(dir, ".wn", "settings.json"), `{"timezone": "America/Chicago"}`)
+	if out := runLog(); !strings.HasPrefix(out, "2026-01-15 12:30:00 created") {
+		t.Errorf("log with settings timezone = %q, want America/Chicago timestamp", out)
+	}
+	if out := runLog("--tz", "Asia/Tokyo"); !strings.HasPrefix(out, "2026-01-16 03:30:00 created") {
+		t.Errorf("log --tz Asia/Tokyo = %q, want Tokyo timestamp", out)
+	}
+	tzFlag = ""
+
+	writeFile(t, filepath.Join(dir, ".wn", "settings.json"), `{"timezone": "Mars/Olympus"}`)
+	if out := runLog(); !strings.HasPrefix(out, "2026-01-15 18:30:00 created") {
+		t.Errorf("log with invalid settings timezone = %q, want UTC fallback", out)
+	}
+
+	rootCmd.SetArgs([]string{"--tz", "Mars/Olympus", "log", id})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid timezone") {
+		t.Errorf("--tz Mars/Olympus = %v, want invalid timezone error", err)
+	}
+	tzFlag = ""
+	item, _ := store.Get(id)
+	if !item.Log[0].At.Equal(at) || item.Log[0].At.Location() != time.UTC {
+		t.Errorf("stored log time changed: %v", item.Log[0].At)
+	}
+}
+
+func TestInvalidOrderRangeSettingWarns(t *testing.T) {
+	defer func() { _ = wn.SetOrderRange(wn.MaxOrder, wn.DefaultOrder) }()
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, ".wn", "settings.json"), `{"order_max": 9, "order_default": 20}`)
+	resetListFlags()
+	_ = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("list with invalid order range: %v, want a warning only", err)
+		}
+	})
+	if wn.OrderMax() != wn.MaxOrder || wn.OrderDefault() != wn.DefaultOrder {
+		t.Errorf("order range = %d/%d, want built-in %d/%d", wn.OrderMax(), wn.OrderDefault(), wn.MaxOrder, wn.DefaultOrder)
+	}
+}
+
+func TestLogHeader(t *testing.T) {
+	defer func() { logNoHeader = false }()
+	dir, id := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(cwd) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	logNoHeader = false
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"log", id})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("log: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != "log for abc123: first line" {
+		t.Errorf("log header = %q, want %q", lines[0], "log for abc123: first line")
+	}
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], " created") {
+		t.Errorf("log output = %q, want header then the created entry", out)
+	}
+
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"log", id, "--no-header"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("log --no-header: %v", err)
+		}
+	})
+	if strings.Contains(out, "log for") {
+		t.Errorf("log --no-header output = %q, want no header", out)
+	}
+}
+
+func TestDependCheck(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"depend", "check"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("depend check (acyclic): %v", err)
+		}
+	})
+	if !strings.Contains(out, "No dependency cycles.") {
+		t.Errorf("depend check = %q, want no cycles", out)
+	}
+
+	store, _ := wn.NewFileStore(dir)
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "bb2222", Description: "b", DependsOn: []string{"cc3333"}, Created: now, Updated: now},
+		{ID: "cc3333", Description: "c", DependsOn: []string{"bb2222"}, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var err error
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"depend", "check"})
+		err = rootCmd.Execute()
+	})
+	if err == nil {
+		t.Error("depend check with a cycle should fail")
+	}
+	if !strings.Contains(out, "cycle: bb2222 → cc3333 → bb2222") {
+		t.Errorf("depend check = %q, want the cycle chain", out)
+	}
+}
+
+func itemIDs(items []*wn.Item) []string {
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.ID
+	}
+	return ids
+}
+
+// resetImportFlags clears import flags so tests get consistent behavior.
+func resetImportFlags() {
+	importReplace = false
+	importAppend = false
+	importTag = ""
+	importGitHub, importLabel, importState = "", "", "open"
+}
+
+// TestImportGitHub verifies wn import --github against a fake API (GITHUB_API_URL) and its flag
+// conflicts.
+func TestImportGitHub(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"number": 7, "title": "Imported issue", "html_url": "https://github.com/o/r/issues/7", "state": "open", "labels": [{"name": "bug"}]}]`)
+	}))
+	defer srv.Close()
+	dir, _ := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	t.Setenv("GITHUB_API_URL", srv.URL)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetImportFlags()
+
+	for i, want := range []string{"imported 1 issue(s) from o/r; 0 already imported", "imported 0 issue(s) from o/r; 1 already imported"} {
+		resetImportFlags()
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs([]string{"import", "--github", "o/r"})
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("import --github (run %d): %v", i+1, err)
+			}
+		})
+		if !strings.Contains(out, want) {
+			t.Errorf("import --github (run %d) = %q, want %q", i+1, out, want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"import", "--github", "o/r", "export.json"},
+		{"import", "--github", "o/r", "--replace"},
+		{"import", "--label", "bug", "export.json"},
+		{"import"},
+	} {
+		resetImportFlags()
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+			t.Errorf("%v: exit code %d, want %d", args, exitCode(err), exitUsage)
+		}
+	}
+}
+
+func TestImport_StoreHasItemsNoFlagErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&wn.Item{ID: "abc123", Description: "existing", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}); err != nil {
+		t.Fatal(err)
+	}
+	path := dir + "/export.json"
+	if err := wn.Export(store, path); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	resetImportFlags()
+	rootCmd.SetArgs([]string{"import", path})
+	err = rootCmd.Execute()
+	if err == nil {
+		t.Fatal("expected error when store has items and no --append/--replace")
+	}
+	if !strings.Contains(err.Error(), "--append") || !strings.Contains(err.Error(), "--replace") {
+		t.Errorf("error should mention --append and --replace: %v", err)
+	}
+}
+
+func TestImport_Replace(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "aaa111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bbb222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := dir + "/export.json"
+	if err := wn.Export(store, path); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	// Add another item so store has 3
+	if err := store.Put(&wn.Item{ID: "ccc333", Description: "third", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	resetImportFlags()
+	rootCmd.SetArgs([]string{"import", "--replace", path})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import --replace: %v", err)
+	}
+	store2, _ := wn.NewFileStore(dir)
+	all, err := store2.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("after --replace: len(List) = %d, want 2 (file had 2 items)", len(all))
+	}
+}
+
+func TestImport_Append(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&wn.Item{ID: "old111", Description: "existing", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}); err != nil {
+		t.Fatal(err)
+	}
+	path := dir + "/new.json"
+	if err := wn.ExportItems([]*wn.Item{
+		{ID: "new222", Description: "from file", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	}, path); err != nil {
+		t.Fatalf("ExportItems: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	resetImportFlags()
+	rootCmd.SetArgs([]string{"import", "--append", path})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import --append: %v", err)
+	}
+	store2, _ := wn.NewFileStore(dir)
+	all, err := store2.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("after --append: len(List) = %d, want 2", len(all))
+	}
+	got, _ := store2.Get("new222")
+	if got.Description != "from file" {
+		t.Errorf("new222 description = %q, want from file", got.Description)
+	}
+
+	// --tag is normalized like other tag arguments, including tag_lowercase.
+	writeFile(t, filepath.Join(dir, ".wn", "settings.json"), `{"tag_lowercase": true}`)
+	resetImportFlags()
+	defer resetImportFlags()
+	rootCmd.SetArgs([]string{"import", "--append", "--tag", " Batch-1 ", path})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import --append --tag: %v", err)
+	}
+	if got, _ := store2.Get("new222"); !slices.Equal(got.Tags, []string{"batch-1"}) {
+		t.Errorf("import --tag ' Batch-1 ' tags = %v, want [batch-1]", got.Tags)
+	}
+}
+
+func TestImport_BothAppendAndReplaceErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	path := dir + "/export.json"
+	if err := wn.ExportItems(nil, path); err != nil {
+		t.Fatalf("ExportItems: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	resetImportFlags()
+	rootCmd.SetArgs([]string{"import", "--append", "--replace", path})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("expected error when both --append and --replace")
+	}
+	if !strings.Contains(err.Error(), "append") || !strings.Contains(err.Error(), "replace") {
+		t.Errorf("error should mention append and replace: %v", err)
+	}
+}
+
+func TestImport_EmptyStoreNoFlagSucceeds(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	path := dir + "/export.json"
+	if err := wn.ExportItems([]*wn.Item{
+		{ID: "only1", Description: "only item", Created: time.Now().UTC(), Updated: time.Now().UTC(), Log: []wn.LogEntry{{At: time.Now().UTC(), Kind: "created"}}},
+	}, path); err != nil {
+		t.Fatalf("ExportItems: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	resetImportFlags()
+	rootCmd.SetArgs([]string{"import", path})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import into empty store: %v", err)
+	}
+	store, _ := wn.NewFileStore(dir)
+	got, err := store.Get("only1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Description != "only item" {
+		t.Errorf("description = %q, want only item", got.Description)
+	}
+}
+func TestListShowsStatusWithAlignment(t *testing.T) {
+	resetListFlags()
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, item := range []*wn.Item{
+		{ID: "done11", Description: "done task", Created: now, Updated: now, Done: true, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "undone1", Description: "undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list", "--all"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("list --all should show at least 2 lines; got %q", out)
+	}
+	// Status column shows done/undone (and claimed when applicable); include default undone.
+	if !strings.Contains(out, "done") {
+		t.Errorf("list output should contain status 'done'; got %q", out)
+	}
+	if !strings.Contains(out, "undone") {
+		t.Errorf("list output should contain status 'undone'; got %q", out)
+	}
+	// Descriptions aligned: each line has status then "  " then description at fixed column.
+	for i, line := range lines {
+		if len(line) < listDescriptionStart {
+			t.Errorf("line %d too short for aligned format: %q", i, line)
+			continue
+		}
+		if line[listDescriptionStart-2:listDescriptionStart] != "  " {
+			t.Errorf("line %d: expected two spaces before description at col %d; got %q", i, listDescriptionStart, line[listDescriptionStart-2:listDescriptionStart])
+		}
+	}
+}
+
+// TestListWidth verifies that --width sizes the description column to fill the given width
+// with tags still aligned, and that wn show pads its title line to the same width.
+func TestListWidth(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { widthFlag = 0 }()
+	defer resetListFlags()
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	long := strings.Repeat("long description ", 10)
+	for _, it := range []*wn.Item{
+		{ID: "wid111", Description: long, Tags: []string{"backend"}, Created: now, Updated: now},
+		{ID: "wid222", Description: "short", Tags: []string{"a", "b"}, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	const width = 100
+	resetListFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list", "--width", "100"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	tagCol := -1
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if !strings.Contains(line, "wid") {
+			continue
+		}
+		if len(line) > width {
+			t.Errorf("line longer than %d columns: %q", width, line)
+		}
+		col := strings.LastIndex(line, "[")
+		if tagCol >= 0 && col != tagCol {
+			t.Errorf("tags not aligned: column %d, want %d in %q", col, tagCol, line)
+		}
+		tagCol = col
+	}
+	if want := width - len("[backend]"); tagCol != want {
+		t.Errorf("tags at column %d, want %d (description column filling --width)", tagCol, want)
+	}
+
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "wid111", "--fields", "title", "--width", "100"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	resetShowFlags()
+	if line := strings.TrimSuffix(out, "\n"); len(line) != width {
+		t.Errorf("show title line is %d columns, want %d: %q", len(line), width, line)
+	}
+}
+
+// TestListNoTruncate verifies that --no-truncate prints the full first line followed by tags.
+func TestListNoTruncate(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetListFlags()
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	long := strings.TrimSpace(strings.Repeat("long description ", 10))
+	if err := store.Put(&wn.Item{ID: "full11", Description: long + "\nbody", Tags: []string{"backend"}, Created: now, Updated: now}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	resetListFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list", "--no-truncate"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if !strings.Contains(out, long+"  [backend]\n") {
+		t.Errorf("list --no-truncate should print the full first line then tags; got %q", out)
+	}
+	if strings.Contains(out, "body") {
+		t.Errorf("list --no-truncate should print only the first line; got %q", out)
+	}
+}
+
+// TestCurrentTaskShowsState verifies that running "wn" (no args) prints the current task's state: done, undone, or claimed.
+func TestCurrentTaskShowsState(t *testing.T) {
+	t.Run("undone", func(t *testing.T) {
+		dir, _ := setupWnRoot(t)
+		cwd, _ := os.Getwd()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+		defer func() { _ = os.Chdir(cwd) }()
+
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(nil)
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("Execute: %v", err)
+			}
+		})
+		// Undone is default: no state suffix
+		if bytes.Contains([]byte(out), []byte("(undone)")) {
+			t.Errorf("current task output should not show (undone); got %q", out)
+		}
+		if !bytes.Contains([]byte(out), []byte("abc123")) {
+			t.Errorf("current task output should show item id; got %q", out)
+		}
+	})
+
+	t.Run("done", func(t *testing.T) {
+		dir, _ := setupWnRoot(t)
+		cwd, _ := os.Getwd()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Chdir: %v", err)
+		}
+		defer func() { _ = os.Chdir(cwd) }()
+
+		rootCmd.SetArgs([]string{"done"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("wn done: %v", err)
+		}
+
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(nil)
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("Execute: %v", err)
+			}
+		})
+		if !bytes.Contains([]byte(out), []byte("done")) {
+			t.Errorf("current task output should contain state 'done'; got %q", out)
+		}
+	})
+
+	t.Run("claimed", func(t *testing.T) {
+		dir, _ := setupWnRoot(t)
+		cwd, _ := os.Getwd()
+		if err := os.Chdir(dir); err != nil {
 			t.Fatalf("Chdir: %v", err)
 		}
-		defer func() { _ = os.Chdir(cwd) }()
+		defer func() { _ = os.Chdir(cwd) }()
+
+		rootCmd.SetArgs([]string{"claim", "--for", "1h"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("wn claim: %v", err)
+		}
+
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(nil)
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("Execute: %v", err)
+			}
+		})
+		if !bytes.Contains([]byte(out), []byte("claimed")) {
+			t.Errorf("current task output should contain state 'claimed'; got %q", out)
+		}
+	})
+}
+
+func TestNextWithClaim(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"next", "--claim", "30m"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn next --claim: %v", err)
+		}
+	})
+	if !strings.Contains(out, itemID) || !strings.Contains(out, "claimed") {
+		t.Errorf("wn next --claim output should contain id and claimed; got %q", out)
+	}
+	// Verify item is actually claimed: show --json should have in_progress_until set
+	showOut := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "--json", itemID})
+		_ = rootCmd.Execute()
+	})
+	if !strings.Contains(showOut, "in_progress_until") || strings.Contains(showOut, "\"in_progress_until\":\"0001-01-01T00:00:00Z\"") {
+		t.Errorf("wn show after next --claim should show in_progress_until; got %s", showOut)
+	}
+}
+
+// TestNextAutoClaim verifies that next.autoclaim makes bare "wn next" claim, --claim overrides
+// the duration, and --no-claim skips the claim.
+func TestNextAutoClaim(t *testing.T) {
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetNextFlags()
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runNextWith := func(args ...string) (string, error) {
+		resetNextFlags()
+		var runErr error
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(append([]string{"next"}, args...))
+			runErr = rootCmd.Execute()
+		})
+		return out, runErr
+	}
+	release := func() {
+		if err := store.UpdateItem(itemID, func(it *wn.Item) (*wn.Item, error) {
+			it.InProgressUntil, it.InProgressBy = time.Time{}, ""
+			return it, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without the setting, bare next does not claim.
+	if out, err := runNextWith(); err != nil || strings.Contains(out, "claimed") {
+		t.Errorf("wn next without autoclaim = %q, %v; want no claim", out, err)
+	}
+	if item, _ := store.Get(itemID); !item.InProgressUntil.IsZero() {
+		t.Errorf("wn next without autoclaim should not claim; until=%v", item.InProgressUntil)
+	}
+
+	writeFile(t, filepath.Join(dir, ".wn", "settings.json"), `{"next": {"autoclaim": "2h"}}`)
+	if out, err := runNextWith(); err != nil || !strings.Contains(out, "claimed for 2h") {
+		t.Errorf("wn next with autoclaim = %q, %v; want claimed for 2h", out, err)
+	}
+	if item, _ := store.Get(itemID); time.Until(item.InProgressUntil) < 90*time.Minute {
+		t.Errorf("autoclaim should claim for 2h; until=%v", item.InProgressUntil)
+	}
+	release()
+
+	if out, err := runNextWith("--claim", "30m"); err != nil || !strings.Contains(out, "claimed for 30m") {
+		t.Errorf("wn next --claim 30m with autoclaim = %q, %v; want claimed for 30m", out, err)
+	}
+	release()
+
+	if out, err := runNextWith("--no-claim"); err != nil || strings.Contains(out, "claimed") {
+		t.Errorf("wn next --no-claim = %q, %v; want no claim", out, err)
+	}
+	if item, _ := store.Get(itemID); !item.InProgressUntil.IsZero() {
+		t.Errorf("wn next --no-claim should not claim; until=%v", item.InProgressUntil)
+	}
+
+	if _, err := runNextWith("--claim", "30m", "--no-claim"); err == nil || !strings.Contains(err.Error(), "choose one") {
+		t.Errorf("wn next --claim --no-claim = %v, want conflict error", err)
+	}
+}
+
+func TestStatusCommand(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	// wn status suspend [id] marks item done with done_status suspend
+	rootCmd.SetArgs([]string{"status", "suspend", itemID, "-m", "deferred"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("wn status suspend: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := store.Get(itemID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !item.Done || item.DoneStatus != wn.DoneStatusSuspend {
+		t.Errorf("after status suspend: Done=%v DoneStatus=%q", item.Done, item.DoneStatus)
+	}
+
+	// wn status undone [id] clears done/suspend
+	rootCmd.SetArgs([]string{"status", "undone", itemID})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("wn status undone: %v", err)
+	}
+	item, _ = store.Get(itemID)
+	if item.Done || item.DoneStatus != "" {
+		t.Errorf("after status undone: Done=%v DoneStatus=%q", item.Done, item.DoneStatus)
+	}
+
+	// invalid status returns error
+	rootCmd.SetArgs([]string{"status", "invalid", itemID})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("wn status invalid should fail")
+	}
+}
+
+// TestExitCodes verifies the documented exit codes: usage errors, missing items, and no current
+// task or empty queue each have their own code; other errors exit 1.
+func TestExitCodes(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetShowFlags()
+	defer resetPickFlags()
+	defer resetNextFlags()
+	defer resetDependFlags()
+	defer resetTagFlags()
+	defer resetRmFlags()
+	defer resetDoFlags()
+	defer resetListFlags()
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"list", "--bogus"}, exitUsage},
+		{[]string{"show", "a", "b"}, exitUsage},
+		{[]string{"show", "abc123", "--web", "--json"}, exitUsage},
+		{[]string{"show", "zzz999"}, exitNotFound},
+		{[]string{"pick", "zzz999"}, exitNotFound},
+		{[]string{"depend", "add", "--on", "abc123"}, exitGeneric},
+		{[]string{"depend", "add"}, exitUsage},
+		{[]string{"done", "zzz999"}, exitNotFound},
+		{[]string{"claim", "zzz999", "--for", "1h"}, exitNotFound},
+		{[]string{"release", "zzz999"}, exitNotFound},
+		{[]string{"tag", "add", "x", "--wid", "zzz999"}, exitNotFound},
+		{[]string{"note", "add", "n", "zzz999", "-m", "x"}, exitNotFound},
+		{[]string{"log", "zzz999"}, exitNotFound},
+		{[]string{"why", "zzz999"}, exitNotFound},
+		{[]string{"rm", "abc123", "--where", "tag:x"}, exitUsage},
+		{[]string{"do", "-n", "2"}, exitUsage},
+		{[]string{"do", "abc123", "--next"}, exitUsage},
+		{[]string{"list", "--group", "bogus"}, exitUsage},
+		{[]string{"clear-current"}, 0},
+		{[]string{"show"}, exitEmpty},
+		{[]string{"depend", "add", "--on", "abc123"}, exitEmpty},
+		{[]string{"pick", "--review-ready"}, exitEmpty},
+		{[]string{"next", "--tag", "nonexistent"}, exitEmpty},
+	}
+	for _, tt := range tests {
+		resetShowFlags()
+		resetPickFlags()
+		resetNextFlags()
+		resetDependFlags()
+		resetTagFlags()
+		resetRmFlags()
+		resetDoFlags()
+		resetListFlags()
+		var err error
+		captureStdout(t, func() {
+			rootCmd.SetArgs(tt.args)
+			err = rootCmd.Execute()
+		})
+		got := 0
+		if err != nil {
+			got = exitCode(err)
+		}
+		if got != tt.want {
+			t.Errorf("wn %s: exit code %d (err %v), want %d", strings.Join(tt.args, " "), got, err, tt.want)
+		}
+	}
+}
+
+// TestNextWithTag verifies that "wn next --tag X" sets current to the next undone item that has tag X (dependency order).
+func TestNextWithTag(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	ord0, ord1 := 0, 1
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "no tag", Created: now, Updated: now, Order: &ord0, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "has agent tag", Created: now, Updated: now, Order: &ord1, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	// wn next --tag agent should set current to bb2222
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"next", "--tag", "agent"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn next --tag agent: %v", err)
+		}
+	})
+	if !strings.Contains(out, "bb2222") || !strings.Contains(out, "has agent tag") {
+		t.Errorf("wn next --tag agent should output bb2222 and description; got %q", out)
+	}
+	meta, err := wn.ReadMeta(dir)
+	if err != nil {
+		t.Fatalf("ReadMeta: %v", err)
+	}
+	if meta.CurrentID != "bb2222" {
+		t.Errorf("after wn next --tag agent: CurrentID = %q, want bb2222", meta.CurrentID)
+	}
+
+	// wn next --tag nonexistent should print "No next task." on stderr and exit with exitEmpty.
+	var out2 string
+	errOut := captureStderr(t, func() {
+		out2 = captureStdout(t, func() {
+			rootCmd.SetArgs([]string{"next", "--tag", "nonexistent"})
+			if err := rootCmd.Execute(); exitCode(err) != exitEmpty {
+				t.Errorf("wn next --tag nonexistent: err = %v, want exit code %d", err, exitEmpty)
+			}
+		})
+	})
+	if !strings.Contains(errOut, "No next task.") || out2 != "" {
+		t.Errorf("wn next --tag nonexistent: stdout %q, stderr %q; want No next task. on stderr only", out2, errOut)
+	}
+}
+
+// TestNextExcludeTag verifies that wn next skips items with a tag from --exclude-tag or settings
+// next.exclude_tags.
+func TestNextExcludeTag(t *testing.T) {
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetNextFlags()
+
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	if err := store.UpdateItem("abc123", func(it *wn.Item) (*wn.Item, error) {
+		it.Tags = []string{"manual-only"}
+		return it, nil
+	}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&wn.Item{ID: "hum222", Description: "needs a human", Tags: []string{"human"}, Created: now, Updated: now}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(&wn.Item{ID: "zzz333", Description: "agent work", Created: now, Updated: now}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	writeFile(t, wn.ProjectSettingsPath(dir), `{"next": {"exclude_tags": ["manual-only"]}}`)
+
+	next := func(args ...string) string {
+		t.Helper()
+		resetNextFlags()
+		captureStdout(t, func() {
+			rootCmd.SetArgs(append([]string{"next"}, args...))
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("next %v: %v", args, err)
+			}
+		})
+		meta, err := wn.ReadMeta(dir)
+		if err != nil {
+			t.Fatalf("ReadMeta: %v", err)
+		}
+		return meta.CurrentID
+	}
+	if got := next(); got != "hum222" {
+		t.Errorf("next with settings exclude_tags: current = %q, want hum222", got)
+	}
+	if got := next("--exclude-tag", "human"); got != "zzz333" {
+		t.Errorf("next --exclude-tag human: current = %q, want zzz333", got)
+	}
+}
+
+// TestDoneNext_oneItem verifies that "wn done --next" with only one (current) item marks it done,
+// prints "No next task." (nothing with --quiet), and exits with exitEmpty.
+func TestDoneNext_oneItem(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { doneNext, doneQuiet = false, false }()
+
+	var out string
+	errOut := captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			rootCmd.SetArgs([]string{"done", "--next"})
+			if err := rootCmd.Execute(); exitCode(err) != exitEmpty {
+				t.Errorf("wn done --next: exit code %d, want %d", exitCode(err), exitEmpty)
+			}
+		})
+	})
+	if !strings.Contains(errOut, "No next task.") || out != "" {
+		t.Errorf("wn done --next with one item: stdout %q, stderr %q; want No next task. on stderr only", out, errOut)
+	}
+	store, _ := wn.NewFileStore(dir)
+	if it, _ := store.Get(itemID); !it.Done {
+		t.Error("wn done --next with an empty queue should still mark the item done")
+	}
+
+	if err := store.UpdateItem(itemID, func(it *wn.Item) (*wn.Item, error) {
+		it.Done = false
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	doneNext, doneQuiet = false, false
+	errOut = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			rootCmd.SetArgs([]string{"done", itemID, "--next", "--quiet"})
+			if err := rootCmd.Execute(); exitCode(err) != exitEmpty {
+				t.Errorf("wn done --next --quiet: exit code %d, want %d", exitCode(err), exitEmpty)
+			}
+		})
+	})
+	if out != "" || errOut != "" {
+		t.Errorf("wn done --next --quiet should print nothing; got stdout %q, stderr %q", out, errOut)
+	}
+}
+
+// TestNextQuiet verifies that "wn next --quiet" prints nothing on an empty queue but still exits
+// with exitEmpty, and prints the item as usual otherwise.
+func TestNextQuiet(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetNextFlags()
+
+	resetNextFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"next", "--quiet"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn next --quiet: %v", err)
+		}
+	})
+	if !strings.Contains(out, itemID) {
+		t.Errorf("wn next --quiet with a task should print it; got %q", out)
+	}
+
+	resetNextFlags()
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"next", "--quiet", "--tag", "nonexistent"})
+		if err := rootCmd.Execute(); exitCode(err) != exitEmpty {
+			t.Errorf("wn next --quiet on an empty queue: exit code %d, want %d", exitCode(err), exitEmpty)
+		}
+	})
+	if out != "" {
+		t.Errorf("wn next --quiet on an empty queue should print nothing; got %q", out)
+	}
+}
+
+// TestDoneNext_twoItems verifies that "wn done --next" marks current done and sets next undone as current.
+func TestDoneNext_twoItems(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "abc123", Description: "first task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "def456", Description: "second task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "abc123"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"done", "--next"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn done --next: %v", err)
+		}
+	})
+	if !strings.Contains(out, "def456") || !strings.Contains(out, "second task") {
+		t.Errorf("wn done --next with two items should print next item id and description; got %q", out)
+	}
+	meta, err := wn.ReadMeta(dir)
+	if err != nil {
+		t.Fatalf("ReadMeta: %v", err)
+	}
+	if meta.CurrentID != "def456" {
+		t.Errorf("after done --next: CurrentID = %q, want def456", meta.CurrentID)
+	}
+
+	// next.exclude_tags applies as it does for wn next.
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	writeFile(t, filepath.Join(dir, ".wn", "settings.json"), `{"next": {"exclude_tags": ["manual"]}}`)
+	for _, it := range []*wn.Item{
+		{ID: "eee555", Description: "manual task", Tags: []string{"manual"}, Created: now, Updated: now},
+		{ID: "fff666", Description: "third task", Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"done", "--next"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn done --next: %v", err)
+		}
+	})
+	if meta, _ = wn.ReadMeta(dir); meta.CurrentID != "fff666" {
+		t.Errorf("after done --next with next.exclude_tags: CurrentID = %q, want fff666", meta.CurrentID)
+	}
+}
+
+// TestStatus_closed_duplicate_of verifies that "wn status closed [id] --duplicate-of <id2>" adds the standard duplicate-of note and marks the item closed.
+func TestStatus_closed_duplicate_of(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "abc123", Description: "original", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "def456", Description: "duplicate", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "abc123"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"status", "closed", "def456", "--duplicate-of", "abc123"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn status closed --duplicate-of: %v", err)
+		}
+	})
+	if !strings.Contains(out, "marked def456 as duplicate of abc123") {
+		t.Errorf("wn status closed --duplicate-of should print confirmation; got %q", out)
+	}
+	item, err := store.Get("def456")
+	if err != nil {
+		t.Fatalf("Get def456: %v", err)
+	}
+	if !item.Done || item.DoneStatus != wn.DoneStatusClosed {
+		t.Errorf("item should be closed after status closed --duplicate-of: Done=%v DoneStatus=%q", item.Done, item.DoneStatus)
+	}
+	idx := item.NoteIndexByName(wn.NoteNameDuplicateOf)
+	if idx < 0 {
+		t.Fatalf("note %q not found", wn.NoteNameDuplicateOf)
+	}
+	if item.Notes[idx].Body != "abc123" {
+		t.Errorf("duplicate-of body = %q, want abc123", item.Notes[idx].Body)
+	}
+}
+
+// TestStatus_duplicate_of_only_with_closed verifies that --duplicate-of is rejected when status is not closed.
+func TestStatus_duplicate_of_only_with_closed(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	rootCmd.SetArgs([]string{"status", "done", "--duplicate-of", "abc123"})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Error("wn status done --duplicate-of should error")
+	}
+	if err != nil && !strings.Contains(err.Error(), "only valid when setting status to closed") {
+		t.Errorf("expected error about --duplicate-of only with closed; got %v", err)
+	}
+}
+
+// TestDuplicate_keep_open verifies that "wn duplicate --keep-open" adds the duplicate-of note
+// and log entry but leaves the item open, while the default closes it.
+func TestDuplicate_keep_open(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, id := range []string{"def456", "ghi789"} {
+		if err := store.Put(&wn.Item{ID: id, Description: "copy", Created: now, Updated: now, ReviewReady: id == "def456"}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { duplicateOf, duplicateKeepOpen = "", false }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"duplicate", "def456", "--of", "abc123", "--keep-open"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn duplicate --keep-open: %v", err)
+		}
+	})
+	if !strings.Contains(out, "marked def456 as duplicate of abc123 (kept open)") {
+		t.Errorf("wn duplicate --keep-open output = %q", out)
+	}
+	item, err := store.Get("def456")
+	if err != nil {
+		t.Fatalf("Get def456: %v", err)
+	}
+	if item.Done || !item.ReviewReady {
+		t.Errorf("--keep-open should leave status untouched: Done=%v ReviewReady=%v", item.Done, item.ReviewReady)
+	}
+	if idx := item.NoteIndexByName(wn.NoteNameDuplicateOf); idx < 0 || item.Notes[idx].Body != "abc123" {
+		t.Errorf("duplicate-of note missing or wrong: %v", item.Notes)
+	}
+	if last := item.Log[len(item.Log)-1]; last.Kind != "duplicate_of" || last.Msg != "abc123" {
+		t.Errorf("last log entry = %+v, want duplicate_of abc123", last)
+	}
+
+	duplicateKeepOpen = false
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"duplicate", "ghi789", "--of", "abc123"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn duplicate: %v", err)
+		}
+	})
+	item, err = store.Get("ghi789")
+	if err != nil {
+		t.Fatalf("Get ghi789: %v", err)
+	}
+	if !item.Done || item.DoneStatus != wn.DoneStatusClosed {
+		t.Errorf("wn duplicate should close the item by default: Done=%v DoneStatus=%q", item.Done, item.DoneStatus)
+	}
+}
+
+// TestMergeDuplicates verifies that "wn merge-duplicates <id> --into <id>" moves notes and
+// dependents onto the canonical item, closes the duplicate, and prints what moved.
+func TestMergeDuplicates(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "def456", Description: "copy", Created: now, Updated: now, Notes: []wn.Note{{Name: "pr-url", Created: now, Body: "https://example.com/2"}}},
+		{ID: "ghi789", Description: "needs copy", Created: now, Updated: now, DependsOn: []string{"def456"}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { mergeDuplicatesInto = "" }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"merge-duplicates", "def456", "--into", "abc123"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn merge-duplicates: %v", err)
+		}
+	})
+	for _, want := range []string{"merged def456 into abc123", "note pr-url", "dependency of ghi789 repointed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q; got %q", want, out)
+		}
+	}
+	canon, _ := store.Get("abc123")
+	if canon.NoteIndexByName("pr-url") < 0 {
+		t.Errorf("canonical should have pr-url note: %v", canon.Notes)
+	}
+	user, _ := store.Get("ghi789")
+	if len(user.DependsOn) != 1 || user.DependsOn[0] != "abc123" {
+		t.Errorf("ghi789 DependsOn = %v, want [abc123]", user.DependsOn)
+	}
+	dup, _ := store.Get("def456")
+	if !dup.Done {
+		t.Error("duplicate should be closed")
+	}
+}
+
+// TestAddFromStdin verifies that "wn add" without -m reads the description from piped stdin.
+func TestAddFromStdin(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	t.Setenv("WN_EDITOR", "false") // must not be launched
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	addMessage, addTags, addFile = "", nil, ""
+	pipeStdin := func(text string) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		origStdin := os.Stdin
+		os.Stdin = r
+		t.Cleanup(func() { os.Stdin = origStdin })
+		if _, err := w.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+
+	pipeStdin("fix the thing\nsee main.go:42\n\n")
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"add"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn add from stdin: %v", err)
+		}
+	})
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, it := range items {
+		found = found || it.Description == "fix the thing\nsee main.go:42"
+	}
+	if len(items) != 2 || !found {
+		t.Errorf("want a new item with the piped description; got %d items", len(items))
+	}
+
+	pipeStdin("  \n")
+	rootCmd.SetArgs([]string{"add"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "empty description") {
+		t.Errorf("wn add with blank stdin = %v, want empty description error", err)
+	}
+}
+
+// TestAddTitleFlag verifies that "wn add --title" opens the editor on add_template with {title}
+// filled in, and is refused without the setting or with -m.
+func TestAddTitleFlag(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor script needs a unix shell")
+	}
+	dir, _ := setupWnRoot(t)
+	editor := filepath.Join(t.TempDir(), "keep.sh")
+	writeFile(t, editor, "#!/bin/sh\nexit 0\n")
+	if err := os.Chmod(editor, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	t.Setenv("WN_EDITOR", editor)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	addMessage, addTags, addFile = "", nil, ""
+	defer func() { addTitle = "" }()
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.SetArgs([]string{"add", "--title", "Quick fix"})
+	if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+		t.Errorf("add --title without add_template: exit code %d, want %d", exitCode(err), exitUsage)
+	}
+
+	writeFile(t, filepath.Join(dir, ".wn", "settings.json"), `{"add_template": "{title}\n\n## Acceptance criteria\n"}`)
+	rootCmd.SetArgs([]string{"add", "--title", "Fix login"})
+	captureStdout(t, func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("wn add --title: %v", err)
+		}
+	})
+	m, _ := wn.ReadMeta(dir)
+	if it, err := store.Get(m.CurrentID); err != nil || it.Description != "Fix login\n\n## Acceptance criteria" {
+		t.Errorf("description = %+v, %v; want the template with the title filled in", it, err)
+	}
+
+	rootCmd.SetArgs([]string{"add", "-m", "x", "--title", "t"})
+	if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+		t.Errorf("add -m with --title: exit code %d, want %d", exitCode(err), exitUsage)
+	}
+	addMessage = ""
+}
+
+// TestAddFromFile verifies that "wn add --file" adds one item per line or paragraph, in order,
+// and prints the new ids without changing the current task.
+func TestAddFromFile(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { addMessage, addTags, addFile, addSeparator = "", nil, "", "line" }()
+	tasks := filepath.Join(t.TempDir(), "tasks.txt")
+	writeFile(t, tasks, "set up CI\n\nwrite the parser\nhandle comments too\n")
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
 
+	for _, tc := range []struct {
+		sep  string
+		want []string
+	}{
+		{"line", []string{"set up CI", "write the parser", "handle comments too"}},
+		{"blank", []string{"set up CI", "write the parser\nhandle comments too"}},
+	} {
+		addMessage, addTags, addFile, addSeparator = "", nil, "", "line"
 		out := captureStdout(t, func() {
-			rootCmd.SetArgs(nil)
+			rootCmd.SetArgs([]string{"add", "--file", tasks, "--separator", tc.sep, "-t", "seed-" + tc.sep})
 			if err := rootCmd.Execute(); err != nil {
-				t.Errorf("Execute: %v", err)
+				t.Errorf("wn add --file --separator %s: %v", tc.sep, err)
 			}
 		})
-		// Undone is default: no state suffix
-		if bytes.Contains([]byte(out), []byte("(undone)")) {
-			t.Errorf("current task output should not show (undone); got %q", out)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != len(tc.want) {
+			t.Fatalf("--separator %s printed %q, want %d ids", tc.sep, out, len(tc.want))
 		}
-		if !bytes.Contains([]byte(out), []byte("abc123")) {
-			t.Errorf("current task output should show item id; got %q", out)
+		for i, line := range lines {
+			id := strings.TrimPrefix(line, "added entry ")
+			it, err := store.Get(id)
+			if err != nil {
+				t.Fatalf("Get %s: %v", id, err)
+			}
+			if it.Description != tc.want[i] || !slices.Contains(it.Tags, "seed-"+tc.sep) {
+				t.Errorf("item %d = %q %v, want %q tagged seed-%s", i, it.Description, it.Tags, tc.want[i], tc.sep)
+			}
+		}
+	}
+	if meta, _ := wn.ReadMeta(dir); meta.CurrentID != itemID {
+		t.Errorf("current = %q, want unchanged %s", meta.CurrentID, itemID)
+	}
+
+	addMessage, addTags, addFile, addSeparator = "", nil, "", "line"
+	rootCmd.SetArgs([]string{"add", "--file", tasks, "-m", "x"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "choose one") {
+		t.Errorf("--file with -m = %v, want conflict error", err)
+	}
+}
+
+// TestAddExternal verifies that wn add --external-id/--external-url set the source-system fields,
+// which wn show displays and list --where external: matches.
+func TestAddExternal(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	reset := func() {
+		addMessage, addTags, addFile, addSeparator = "", nil, "", "line"
+		addExternalID, addExternalURL = "", ""
+		resetShowFlags()
+		resetListFlags()
+	}
+	defer reset()
+
+	reset()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"add", "-m", "imported issue", "--external-id", "42", "--external-url", "https://github.com/o/r/issues/42"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("add: %v", err)
 		}
 	})
+	id := strings.TrimSpace(strings.TrimPrefix(out, "added entry "))
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get(id)
+	if err != nil {
+		t.Fatalf("Get %s: %v", id, err)
+	}
+	if it.ExternalID != "42" || it.ExternalURL != "https://github.com/o/r/issues/42" {
+		t.Errorf("external = %q %q, want 42 and the issue URL", it.ExternalID, it.ExternalURL)
+	}
 
-	t.Run("done", func(t *testing.T) {
-		dir, _ := setupWnRoot(t)
-		cwd, _ := os.Getwd()
-		if err := os.Chdir(dir); err != nil {
-			t.Fatalf("Chdir: %v", err)
+	reset()
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", id})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("show: %v", err)
 		}
-		defer func() { _ = os.Chdir(cwd) }()
+	})
+	if !strings.Contains(out, "external: 42 https://github.com/o/r/issues/42\n") {
+		t.Errorf("show should print the external line; got %q", out)
+	}
 
-		rootCmd.SetArgs([]string{"done"})
+	reset()
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list", "--where", "external:42"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Fatalf("wn done: %v", err)
+			t.Errorf("list: %v", err)
 		}
+	})
+	if !strings.Contains(out, id) || strings.Contains(out, "abc123") {
+		t.Errorf("list --where external:42 should list only %s; got %q", id, out)
+	}
+
+	reset()
+	rootCmd.SetArgs([]string{"add", "--file", "-", "--external-id", "7"})
+	if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+		t.Errorf("add --file --external-id: exit code %d, want %d", exitCode(err), exitUsage)
+	}
+}
+
+// TestEditorAborted verifies that a non-zero editor exit makes wn edit and wn note add print
+// "aborted, no changes" and leave the store unchanged.
+func TestEditorAborted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor script needs a unix shell")
+	}
+	dir, itemID := setupWnRoot(t)
+	editor := filepath.Join(t.TempDir(), "abort.sh")
+	writeFile(t, editor, "#!/bin/sh\nprintf 'discarded' > \"$1\"\nexit 1\n")
+	if err := os.Chmod(editor, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	t.Setenv("WN_EDITOR", editor)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	noteAddMessage = ""
 
+	for _, args := range [][]string{{"edit", itemID}, {"note", "add", "context", itemID}} {
 		out := captureStdout(t, func() {
-			rootCmd.SetArgs(nil)
+			rootCmd.SetArgs(args)
 			if err := rootCmd.Execute(); err != nil {
-				t.Errorf("Execute: %v", err)
+				t.Errorf("wn %s: %v", args[0], err)
 			}
 		})
-		if !bytes.Contains([]byte(out), []byte("done")) {
-			t.Errorf("current task output should contain state 'done'; got %q", out)
+		if !strings.Contains(out, "aborted, no changes") {
+			t.Errorf("wn %s output = %q, want aborted, no changes", args[0], out)
 		}
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Description != "first line\nsecond line" || len(items[0].Log) != 1 || len(items[0].Notes) != 0 {
+		t.Errorf("aborted edits should leave the store unchanged; got %+v", items[0])
+	}
+}
+
+// TestClaimWithoutForUsesDefault verifies that "wn claim" without --for uses the default duration
+// so agents can renew (extend) a claim without passing a duration.
+func TestClaimWithoutForUsesDefault(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	rootCmd.SetArgs([]string{"claim"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("wn claim (no --for): %v", err)
+	}
+
+	showOut := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "--json", itemID})
+		_ = rootCmd.Execute()
 	})
+	if !strings.Contains(showOut, "in_progress_until") || strings.Contains(showOut, "\"in_progress_until\":\"0001-01-01T00:00:00Z\"") {
+		t.Errorf("wn claim without --for should set in_progress_until (default duration); got %s", showOut)
+	}
+}
 
-	t.Run("claimed", func(t *testing.T) {
-		dir, _ := setupWnRoot(t)
-		cwd, _ := os.Getwd()
-		if err := os.Chdir(dir); err != nil {
-			t.Fatalf("Chdir: %v", err)
+func TestClaimWithoutForUsesSettingsDefault(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	if err := os.WriteFile(filepath.Join(dir, ".wn", "settings.json"), []byte(`{"default_claim":"4h"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	claimFor = ""
+	before := time.Now().UTC()
+	rootCmd.SetArgs([]string{"claim"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("wn claim (no --for): %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get(itemID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.InProgressUntil.Before(before.Add(4*time.Hour)) || it.InProgressUntil.After(time.Now().UTC().Add(4*time.Hour)) {
+		t.Errorf("in_progress_until = %v, want about now+4h", it.InProgressUntil)
+	}
+}
+
+func TestStatsDurations(t *testing.T) {
+	defer func() { statsDurations = false }()
+	dir, _ := setupWnRoot(t)
+	store, _ := wn.NewFileStore(dir)
+	t0 := time.Now().UTC().Add(-24 * time.Hour)
+	if err := store.Put(&wn.Item{ID: "bb2222", Description: "slow task", Done: true, Created: t0, Updated: t0, Log: []wn.LogEntry{
+		{At: t0, Kind: "in_progress", Msg: "1h"},
+		{At: t0.Add(3 * time.Hour), Kind: "done"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	statsDurations = false
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"stats", "--durations"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("stats --durations: %v", err)
 		}
-		defer func() { _ = os.Chdir(cwd) }()
+	})
+	if !strings.Contains(out, "bb2222") || !strings.Contains(out, "3h0m0s") || !strings.Contains(out, "over") || strings.Contains(out, "abc123") {
+		t.Errorf("stats --durations output:\n%s", out)
+	}
 
-		rootCmd.SetArgs([]string{"claim", "--for", "1h"})
+	resetShowFlags()
+	defer resetShowFlags()
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "bb2222", "--fields", "status"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Fatalf("wn claim: %v", err)
+			t.Fatalf("show: %v", err)
 		}
+	})
+	if !strings.Contains(out, "actual duration: 3h0m0s (claimed for 1h0m0s)") {
+		t.Errorf("show output missing actual duration:\n%s", out)
+	}
 
-		out := captureStdout(t, func() {
-			rootCmd.SetArgs(nil)
-			if err := rootCmd.Execute(); err != nil {
-				t.Errorf("Execute: %v", err)
-			}
-		})
-		if !bytes.Contains([]byte(out), []byte("claimed")) {
-			t.Errorf("current task output should contain state 'claimed'; got %q", out)
+	statsDurations = false
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"stats"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("stats: %v", err)
 		}
 	})
+	if !strings.Contains(out, "done") || !strings.Contains(out, "undone") || !strings.Contains(out, "total    2") {
+		t.Errorf("stats output:\n%s", out)
+	}
 }
 
-func TestNextWithClaim(t *testing.T) {
-	dir, itemID := setupWnRoot(t)
+func TestStatsByTag(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+		t.Fatal(err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { statsByTag, statsJson, statsDurations = false, false, false }()
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "one", Tags: []string{"backend"}, Created: now, Updated: now},
+		{ID: "bb2222", Description: "two", Tags: []string{"backend", "ui"}, Done: true, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
 
+	statsByTag, statsJson = true, false
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"next", "--claim", "30m"})
+		rootCmd.SetArgs([]string{"stats", "--by-tag"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("wn next --claim: %v", err)
+			t.Fatalf("stats --by-tag: %v", err)
 		}
 	})
-	if !strings.Contains(out, itemID) || !strings.Contains(out, "claimed") {
-		t.Errorf("wn next --claim output should contain id and claimed; got %q", out)
+	if want := "  backend    2  undone 1, done 1\n  ui         1  done 1\n"; out != want {
+		t.Errorf("stats --by-tag output = %q, want %q", out, want)
 	}
-	// Verify item is actually claimed: show --json should have in_progress_until set
-	showOut := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"show", "--json", itemID})
-		_ = rootCmd.Execute()
+
+	statsJson = true
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"stats", "--by-tag", "--json"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("stats --by-tag --json: %v", err)
+		}
 	})
-	if !strings.Contains(showOut, "in_progress_until") || strings.Contains(showOut, "\"in_progress_until\":\"0001-01-01T00:00:00Z\"") {
-		t.Errorf("wn show after next --claim should show in_progress_until; got %s", showOut)
+	var stats []wn.TagStat
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("stats --by-tag --json: %v\n%s", err, out)
+	}
+	if len(stats) != 2 || stats[0].Tag != "backend" || stats[0].Undone != 1 || stats[0].Done != 1 || stats[1].Tag != "ui" {
+		t.Errorf("stats --by-tag --json = %+v", stats)
+	}
+
+	statsByTag, statsJson = false, false
+	rootCmd.SetArgs([]string{"stats", "--json"})
+	if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+		t.Errorf("stats --json without --by-tag: exit code %d, want %d", exitCode(err), exitUsage)
 	}
 }
 
-func TestStatusCommand(t *testing.T) {
+func TestClaimByDefaultsToWorkerID(t *testing.T) {
+	defer func() { claimBy = "" }()
 	dir, itemID := setupWnRoot(t)
+	if err := os.WriteFile(filepath.Join(dir, ".wn", "settings.json"), []byte(`{"worker_id":"box-7"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	// wn status suspend [id] marks item done with done_status suspend
-	rootCmd.SetArgs([]string{"status", "suspend", itemID, "-m", "deferred"})
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"claim"}, "box-7"},
+		{[]string{"claim", "--by", "agent-2"}, "agent-2"},
+	} {
+		claimFor, claimBy = "", ""
+		rootCmd.SetArgs(tc.args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		it, err := store.Get(itemID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if it.InProgressBy != tc.want {
+			t.Errorf("%v: InProgressBy = %q, want %q", tc.args, it.InProgressBy, tc.want)
+		}
+	}
+}
+
+func TestClaimUntil(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { claimFor, claimUntil = "", "" }()
+
+	deadline := time.Now().UTC().Add(3 * time.Hour).Truncate(time.Second)
+	claimFor = ""
+	rootCmd.SetArgs([]string{"claim", "--until", deadline.Format(time.RFC3339)})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("wn status suspend: %v", err)
+		t.Fatalf("claim --until: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get(itemID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.InProgressUntil.Equal(deadline) {
+		t.Errorf("in_progress_until = %v, want %v", it.InProgressUntil, deadline)
+	}
+
+	rootCmd.SetArgs([]string{"claim", "--until", time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("claim --until in the past should fail")
+	}
+	rootCmd.SetArgs([]string{"claim", "--for", "1h", "--until", deadline.Format(time.RFC3339)})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("claim with both --for and --until should fail")
+	}
+}
+
+func TestCurrentTaskShowsTags(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	item := &wn.Item{
+		ID:          "abc123",
+		Description: "first line",
+		Created:     now,
+		Updated:     now,
+		Tags:        []string{"urgent", "backend"},
+		Log:         []wn.LogEntry{{At: now, Kind: "created"}},
+	}
+	if err := store.Put(item); err != nil {
+		t.Fatal(err)
+	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "abc123"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs(nil)
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if !strings.Contains(out, "urgent") || !strings.Contains(out, "backend") {
+		t.Errorf("current task output should show tags on the right; got %q", out)
+	}
+	// First line should contain both description and tags
+	firstLine := strings.Split(out, "\n")[0]
+	if !strings.Contains(firstLine, "first line") || !strings.Contains(firstLine, "urgent") {
+		t.Errorf("first line should contain description and tags; got %q", firstLine)
+	}
+}
+
+func TestListShowsTags(t *testing.T) {
+	resetListFlags()
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
 	}
 	store, err := wn.NewFileStore(dir)
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("NewFileStore: %v", err)
 	}
-	item, err := store.Get(itemID)
-	if err != nil {
+	now := time.Now().UTC()
+	item := &wn.Item{
+		ID:          "xyz789",
+		Description: "task with tags",
+		Created:     now,
+		Updated:     now,
+		Tags:        []string{"foo", "bar"},
+		Log:         []wn.LogEntry{{At: now, Kind: "created"}},
+	}
+	if err := store.Put(item); err != nil {
 		t.Fatal(err)
 	}
-	if !item.Done || item.DoneStatus != wn.DoneStatusSuspend {
-		t.Errorf("after status suspend: Done=%v DoneStatus=%q", item.Done, item.DoneStatus)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
 	}
+	defer func() { _ = os.Chdir(cwd) }()
 
-	// wn status undone [id] clears done/suspend
-	rootCmd.SetArgs([]string{"status", "undone", itemID})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("wn status undone: %v", err)
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list", "--all"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if !strings.Contains(out, "foo") || !strings.Contains(out, "bar") {
+		t.Errorf("list output should show tags on the right; got %q", out)
 	}
-	item, _ = store.Get(itemID)
-	if item.Done || item.DoneStatus != "" {
-		t.Errorf("after status undone: Done=%v DoneStatus=%q", item.Done, item.DoneStatus)
+	// Tags should appear on the same line as the item (right of description)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line; got %d: %q", len(lines), out)
 	}
-
-	// invalid status returns error
-	rootCmd.SetArgs([]string{"status", "invalid", itemID})
-	if err := rootCmd.Execute(); err == nil {
-		t.Error("wn status invalid should fail")
+	if !strings.Contains(lines[0], "task with tags") || !strings.Contains(lines[0], "foo") {
+		t.Errorf("line should contain description and tags; got %q", lines[0])
 	}
 }
 
-// TestNextWithTag verifies that "wn next --tag X" sets current to the next undone item that has tag X (dependency order).
-func TestNextWithTag(t *testing.T) {
+func TestListSortFlag(t *testing.T) {
+	resetListFlags()
+	listJson = true
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1408,76 +3703,83 @@ func TestNextWithTag(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	ord0, ord1 := 0, 1
-	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "no tag", Created: now, Updated: now, Order: &ord0, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bb2222", Description: "has agent tag", Created: now, Updated: now, Order: &ord1, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, item := range []*wn.Item{
+		{ID: "bbb", Description: "second alpha", Created: now.Add(1 * time.Hour), Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "aaa", Description: "first alpha", Created: now, Updated: now.Add(1 * time.Hour), Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
-		if err := store.Put(it); err != nil {
-			t.Fatalf("Put: %v", err)
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
 		}
 	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
-		t.Fatalf("WriteMeta: %v", err)
-	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	// wn next --tag agent should set current to bb2222
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"next", "--tag", "agent"})
+		rootCmd.SetArgs([]string{"list", "--json", "--sort", "alpha"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("wn next --tag agent: %v", err)
+			t.Errorf("Execute: %v", err)
 		}
 	})
-	if !strings.Contains(out, "bb2222") || !strings.Contains(out, "has agent tag") {
-		t.Errorf("wn next --tag agent should output bb2222 and description; got %q", out)
-	}
-	meta, err := wn.ReadMeta(dir)
-	if err != nil {
-		t.Fatalf("ReadMeta: %v", err)
+	list := parseListJSON(t, out)
+	if len(list.Items) != 2 {
+		t.Fatalf("len(list.Items) = %d, want 2", len(list.Items))
 	}
-	if meta.CurrentID != "bb2222" {
-		t.Errorf("after wn next --tag agent: CurrentID = %q, want bb2222", meta.CurrentID)
+	// alpha asc: first alpha (aaa) then second alpha (bbb)
+	if list.Items[0].ID != "aaa" || list.Items[1].ID != "bbb" {
+		t.Errorf("list --sort alpha = %v, %v; want aaa then bbb", list.Items[0].ID, list.Items[1].ID)
 	}
 
-	// wn next --tag nonexistent should print "No next task."
 	out2 := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"next", "--tag", "nonexistent"})
+		rootCmd.SetArgs([]string{"list", "--json", "--sort", "updated:desc"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("wn next --tag nonexistent: %v", err)
+			t.Errorf("Execute: %v", err)
 		}
 	})
-	if !strings.Contains(out2, "No next task.") {
-		t.Errorf("wn next --tag nonexistent should print No next task.; got %q", out2)
+	list2 := parseListJSON(t, out2)
+	// updated desc: aaa (Updated: now+1h) then bbb (Updated: now)
+	if list2.Items[0].ID != "aaa" || list2.Items[1].ID != "bbb" {
+		t.Errorf("list --sort updated:desc = %v, %v; want aaa then bbb", list2.Items[0].ID, list2.Items[1].ID)
 	}
+	listJson = false
 }
 
-// TestDoneNext_oneItem verifies that "wn done --next" with only one (current) item prints "No next task."
-func TestDoneNext_oneItem(t *testing.T) {
-	dir, _ := setupWnRoot(t)
+func TestListPretty(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+		t.Fatal(err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
+	resetListFlags()
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"done", "--next"})
+		rootCmd.SetArgs([]string{"list", "--json", "--pretty"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("wn done --next: %v", err)
+			t.Errorf("Execute: %v", err)
 		}
 	})
-	if !strings.Contains(out, "No next task.") {
-		t.Errorf("wn done --next with one item should print 'No next task.'; got %q", out)
+	if !strings.Contains(out, "\n  \"items\": [\n") {
+		t.Errorf("list --json --pretty is not indented:\n%s", out)
+	}
+	if list := parseListJSON(t, out); len(list.Items) != 1 || list.Items[0].ID != itemID {
+		t.Errorf("list --json --pretty items = %+v, want [%s]", list.Items, itemID)
 	}
+
+	resetListFlags()
+	rootCmd.SetArgs([]string{"list", "--pretty"})
+	if err := rootCmd.Execute(); exitCode(err) != exitUsage {
+		t.Errorf("list --pretty without --json: exit code %d, want %d", exitCode(err), exitUsage)
+	}
+	resetListFlags()
 }
 
-// TestDoneNext_twoItems verifies that "wn done --next" marks current done and sets next undone as current.
-func TestDoneNext_twoItems(t *testing.T) {
+func TestListLimit(t *testing.T) {
+	resetListFlags()
+	listJson = true
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1487,17 +3789,15 @@ func TestDoneNext_twoItems(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, it := range []*wn.Item{
-		{ID: "abc123", Description: "first task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "def456", Description: "second task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, item := range []*wn.Item{
+		{ID: "aaa", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bbb", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "ccc", Description: "third", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
-		if err := store.Put(it); err != nil {
-			t.Fatalf("Put: %v", err)
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
 		}
 	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "abc123"}); err != nil {
-		t.Fatalf("WriteMeta: %v", err)
-	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
@@ -1505,25 +3805,24 @@ func TestDoneNext_twoItems(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"done", "--next"})
+		rootCmd.SetArgs([]string{"list", "--json", "--sort", "alpha", "--limit", "2"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("wn done --next: %v", err)
+			t.Errorf("Execute: %v", err)
 		}
 	})
-	if !strings.Contains(out, "def456") || !strings.Contains(out, "second task") {
-		t.Errorf("wn done --next with two items should print next item id and description; got %q", out)
-	}
-	meta, err := wn.ReadMeta(dir)
-	if err != nil {
-		t.Fatalf("ReadMeta: %v", err)
+	list := parseListJSON(t, out)
+	if len(list.Items) != 2 {
+		t.Fatalf("list --limit 2: len = %d, want 2", len(list.Items))
 	}
-	if meta.CurrentID != "def456" {
-		t.Errorf("after done --next: CurrentID = %q, want def456", meta.CurrentID)
+	if list.Items[0].ID != "aaa" || list.Items[1].ID != "bbb" {
+		t.Errorf("list --limit 2 = %v, %v; want aaa, bbb", list.Items[0].ID, list.Items[1].ID)
 	}
+	listJson = false
 }
 
-// TestStatus_closed_duplicate_of verifies that "wn status closed [id] --duplicate-of <id2>" adds the standard duplicate-of note and marks the item closed.
-func TestStatus_closed_duplicate_of(t *testing.T) {
+func TestListLimitOffset(t *testing.T) {
+	resetListFlags()
+	listJson = true
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1533,17 +3832,15 @@ func TestStatus_closed_duplicate_of(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, it := range []*wn.Item{
-		{ID: "abc123", Description: "original", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "def456", Description: "duplicate", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, item := range []*wn.Item{
+		{ID: "aaa", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bbb", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "ccc", Description: "third", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
-		if err := store.Put(it); err != nil {
-			t.Fatalf("Put: %v", err)
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
 		}
 	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "abc123"}); err != nil {
-		t.Fatalf("WriteMeta: %v", err)
-	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
@@ -1551,74 +3848,23 @@ func TestStatus_closed_duplicate_of(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"status", "closed", "def456", "--duplicate-of", "abc123"})
+		rootCmd.SetArgs([]string{"list", "--json", "--sort", "alpha", "--limit", "1", "--offset", "1"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("wn status closed --duplicate-of: %v", err)
+			t.Errorf("Execute: %v", err)
 		}
 	})
-	if !strings.Contains(out, "marked def456 as duplicate of abc123") {
-		t.Errorf("wn status closed --duplicate-of should print confirmation; got %q", out)
-	}
-	item, err := store.Get("def456")
-	if err != nil {
-		t.Fatalf("Get def456: %v", err)
-	}
-	if !item.Done || item.DoneStatus != wn.DoneStatusClosed {
-		t.Errorf("item should be closed after status closed --duplicate-of: Done=%v DoneStatus=%q", item.Done, item.DoneStatus)
-	}
-	idx := item.NoteIndexByName(wn.NoteNameDuplicateOf)
-	if idx < 0 {
-		t.Fatalf("note %q not found", wn.NoteNameDuplicateOf)
-	}
-	if item.Notes[idx].Body != "abc123" {
-		t.Errorf("duplicate-of body = %q, want abc123", item.Notes[idx].Body)
-	}
-}
-
-// TestStatus_duplicate_of_only_with_closed verifies that --duplicate-of is rejected when status is not closed.
-func TestStatus_duplicate_of_only_with_closed(t *testing.T) {
-	dir, _ := setupWnRoot(t)
-	cwd, _ := os.Getwd()
-	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
-	}
-	defer func() { _ = os.Chdir(cwd) }()
-
-	rootCmd.SetArgs([]string{"status", "done", "--duplicate-of", "abc123"})
-	err := rootCmd.Execute()
-	if err == nil {
-		t.Error("wn status done --duplicate-of should error")
-	}
-	if err != nil && !strings.Contains(err.Error(), "only valid when setting status to closed") {
-		t.Errorf("expected error about --duplicate-of only with closed; got %v", err)
-	}
-}
-
-// TestClaimWithoutForUsesDefault verifies that "wn claim" without --for uses the default duration
-// so agents can renew (extend) a claim without passing a duration.
-func TestClaimWithoutForUsesDefault(t *testing.T) {
-	dir, itemID := setupWnRoot(t)
-	cwd, _ := os.Getwd()
-	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
-	}
-	defer func() { _ = os.Chdir(cwd) }()
-
-	rootCmd.SetArgs([]string{"claim"})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("wn claim (no --for): %v", err)
+	list := parseListJSON(t, out)
+	if len(list.Items) != 1 {
+		t.Fatalf("list --limit 1 --offset 1: len = %d, want 1", len(list.Items))
 	}
-
-	showOut := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"show", "--json", itemID})
-		_ = rootCmd.Execute()
-	})
-	if !strings.Contains(showOut, "in_progress_until") || strings.Contains(showOut, "\"in_progress_until\":\"0001-01-01T00:00:00Z\"") {
-		t.Errorf("wn claim without --for should set in_progress_until (default duration); got %s", showOut)
+	if list.Items[0].ID != "bbb" {
+		t.Errorf("list --limit 1 --offset 1 = %v; want bbb", list.Items[0].ID)
 	}
+	listJson = false
 }
 
-func TestCurrentTaskShowsTags(t *testing.T) {
+func TestListGroupByTags(t *testing.T) {
+	resetListFlags()
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1628,19 +3874,14 @@ func TestCurrentTaskShowsTags(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	item := &wn.Item{
-		ID:          "abc123",
-		Description: "first line",
-		Created:     now,
-		Updated:     now,
-		Tags:        []string{"urgent", "backend"},
-		Log:         []wn.LogEntry{{At: now, Kind: "created"}},
-	}
-	if err := store.Put(item); err != nil {
-		t.Fatal(err)
-	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "abc123"}); err != nil {
-		t.Fatalf("WriteMeta: %v", err)
+	for _, item := range []*wn.Item{
+		{ID: "aaa111", Description: "agent task", Created: now, Updated: now, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bbb222", Description: "backend task", Created: now, Updated: now, Tags: []string{"backend"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "ccc333", Description: "another agent task", Created: now, Updated: now, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
+		}
 	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
@@ -1649,22 +3890,44 @@ func TestCurrentTaskShowsTags(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs(nil)
+		rootCmd.SetArgs([]string{"list", "--group", "tags"})
 		if err := rootCmd.Execute(); err != nil {
 			t.Errorf("Execute: %v", err)
 		}
 	})
-	if !strings.Contains(out, "urgent") || !strings.Contains(out, "backend") {
-		t.Errorf("current task output should show tags on the right; got %q", out)
+
+	// Should have group headers for each tag set
+	if !strings.Contains(out, "#agent") {
+		t.Errorf("output should contain #agent group header; got:\n%s", out)
 	}
-	// First line should contain both description and tags
-	firstLine := strings.Split(out, "\n")[0]
-	if !strings.Contains(firstLine, "first line") || !strings.Contains(firstLine, "urgent") {
-		t.Errorf("first line should contain description and tags; got %q", firstLine)
+	if !strings.Contains(out, "#backend") {
+		t.Errorf("output should contain #backend group header; got:\n%s", out)
+	}
+	// Items in the same group (agent) should be adjacent — header appears once before both agent items
+	agentHeaderIdx := strings.Index(out, "--- #agent ---")
+	if agentHeaderIdx < 0 {
+		t.Fatalf("expected '--- #agent ---' header; got:\n%s", out)
+	}
+	backendHeaderIdx := strings.Index(out, "--- #backend ---")
+	if backendHeaderIdx < 0 {
+		t.Fatalf("expected '--- #backend ---' header; got:\n%s", out)
+	}
+	// Both agent items should appear before the backend header
+	aaa111Idx := strings.Index(out, "aaa111")
+	ccc333Idx := strings.Index(out, "ccc333")
+	bbb222Idx := strings.Index(out, "bbb222")
+	if aaa111Idx < 0 || ccc333Idx < 0 || bbb222Idx < 0 {
+		t.Fatalf("all item ids should appear in output; got:\n%s", out)
+	}
+	if aaa111Idx > backendHeaderIdx || ccc333Idx > backendHeaderIdx {
+		t.Errorf("agent items should appear before backend header; got:\n%s", out)
+	}
+	if bbb222Idx < backendHeaderIdx {
+		t.Errorf("backend item should appear after backend header; got:\n%s", out)
 	}
 }
 
-func TestListShowsTags(t *testing.T) {
+func TestListGroupByTagsNoTagsGroup(t *testing.T) {
 	resetListFlags()
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
@@ -1675,16 +3938,13 @@ func TestListShowsTags(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	item := &wn.Item{
-		ID:          "xyz789",
-		Description: "task with tags",
-		Created:     now,
-		Updated:     now,
-		Tags:        []string{"foo", "bar"},
-		Log:         []wn.LogEntry{{At: now, Kind: "created"}},
-	}
-	if err := store.Put(item); err != nil {
-		t.Fatal(err)
+	for _, item := range []*wn.Item{
+		{ID: "aaa111", Description: "tagged task", Created: now, Updated: now, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bbb222", Description: "untagged task", Created: now, Updated: now, Tags: nil, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
+		}
 	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
@@ -1693,27 +3953,26 @@ func TestListShowsTags(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--all"})
+		resetListFlags()
+		rootCmd.SetArgs([]string{"list", "--group", "tags"})
 		if err := rootCmd.Execute(); err != nil {
 			t.Errorf("Execute: %v", err)
 		}
 	})
-	if !strings.Contains(out, "foo") || !strings.Contains(out, "bar") {
-		t.Errorf("list output should show tags on the right; got %q", out)
+
+	if !strings.Contains(out, "(no tags)") {
+		t.Errorf("output should contain '(no tags)' group header for untagged items; got:\n%s", out)
 	}
-	// Tags should appear on the same line as the item (right of description)
-	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
-	if len(lines) != 1 {
-		t.Fatalf("expected 1 line; got %d: %q", len(lines), out)
+	if !strings.Contains(out, "#agent") {
+		t.Errorf("output should contain '#agent' group header; got:\n%s", out)
 	}
-	if !strings.Contains(lines[0], "task with tags") || !strings.Contains(lines[0], "foo") {
-		t.Errorf("line should contain description and tags; got %q", lines[0])
+	if !strings.Contains(out, "bbb222") {
+		t.Errorf("output should contain untagged item id; got:\n%s", out)
 	}
 }
 
-func TestListSortFlag(t *testing.T) {
+func TestListGroupByStatus(t *testing.T) {
 	resetListFlags()
-	listJson = true
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1724,8 +3983,9 @@ func TestListSortFlag(t *testing.T) {
 	}
 	now := time.Now().UTC()
 	for _, item := range []*wn.Item{
-		{ID: "bbb", Description: "second alpha", Created: now.Add(1 * time.Hour), Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "aaa", Description: "first alpha", Created: now, Updated: now.Add(1 * time.Hour), Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "aaa111", Description: "done task", Created: now, Updated: now, Done: true, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bbb222", Description: "undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "ccc333", Description: "another undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
 		if err := store.Put(item); err != nil {
 			t.Fatal(err)
@@ -1738,37 +3998,182 @@ func TestListSortFlag(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--json", "--sort", "alpha"})
+		rootCmd.SetArgs([]string{"list", "--all", "--group", "status"})
 		if err := rootCmd.Execute(); err != nil {
 			t.Errorf("Execute: %v", err)
 		}
 	})
-	list := parseListJSON(t, out)
-	if len(list.Items) != 2 {
-		t.Fatalf("len(list.Items) = %d, want 2", len(list.Items))
+
+	// Group headers for done and undone
+	if !strings.Contains(out, "--- done ---") {
+		t.Errorf("output should contain '--- done ---' header; got:\n%s", out)
 	}
-	// alpha asc: first alpha (aaa) then second alpha (bbb)
-	if list.Items[0].ID != "aaa" || list.Items[1].ID != "bbb" {
-		t.Errorf("list --sort alpha = %v, %v; want aaa then bbb", list.Items[0].ID, list.Items[1].ID)
+	if !strings.Contains(out, "--- undone ---") {
+		t.Errorf("output should contain '--- undone ---' header; got:\n%s", out)
+	}
+	// The two undone items should both appear after the undone header
+	undoneHeaderIdx := strings.Index(out, "--- undone ---")
+	bbb222Idx := strings.Index(out, "bbb222")
+	ccc333Idx := strings.Index(out, "ccc333")
+	if bbb222Idx < undoneHeaderIdx || ccc333Idx < undoneHeaderIdx {
+		t.Errorf("undone items should appear after '--- undone ---' header; got:\n%s", out)
 	}
+}
 
-	out2 := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--json", "--sort", "updated:desc"})
+func TestListGroupInvalidKey(t *testing.T) {
+	resetListFlags()
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	rootCmd.SetArgs([]string{"list", "--group", "bogus"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("list --group bogus should return an error")
+	}
+}
+
+func TestListGroupWithJSON(t *testing.T) {
+	resetListFlags()
+	defer resetListFlags()
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list", "--group", "status", "--json"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("Execute: %v", err)
+			t.Fatalf("list --group status --json: %v", err)
 		}
 	})
-	list2 := parseListJSON(t, out2)
-	// updated desc: aaa (Updated: now+1h) then bbb (Updated: now)
-	if list2.Items[0].ID != "aaa" || list2.Items[1].ID != "bbb" {
-		t.Errorf("list --sort updated:desc = %v, %v; want aaa then bbb", list2.Items[0].ID, list2.Items[1].ID)
+	var got struct {
+		GroupBy string `json:"group_by"`
+		Groups  []struct {
+			Key   string     `json:"key"`
+			Items []*wn.Item `json:"items"`
+		} `json:"groups"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("grouped JSON: %v\n%s", err, out)
+	}
+	if got.GroupBy != "status" || len(got.Groups) != 1 || got.Groups[0].Key != "undone" || len(got.Groups[0].Items) != 1 || got.Groups[0].Items[0].ID != "abc123" {
+		t.Errorf("grouped JSON = %+v, want one undone group with abc123", got)
 	}
-	listJson = false
 }
 
-func TestListLimit(t *testing.T) {
+func TestListCount(t *testing.T) {
 	resetListFlags()
-	listJson = true
+	defer resetListFlags()
+	dir, _ := setupWnRoot(t)
+	store, _ := wn.NewFileStore(dir)
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "bb2222", Description: "tagged", Tags: []string{"api"}, Created: now, Updated: now},
+		{ID: "cc3333", Description: "finished", Tags: []string{"api"}, Done: true, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "--count"}, "2"},
+		{[]string{"list", "--count", "--tag", "api"}, "1"},
+		{[]string{"list", "--count", "--all"}, "3"},
+		{[]string{"list", "--count", "--done"}, "1"},
+		{[]string{"list", "--count", "--where", "status:done tag:api"}, "1"},
+		{[]string{"list", "--count", "--tag", "missing"}, "0"},
+	} {
+		resetListFlags()
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(tc.args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("%v: %v", tc.args, err)
+			}
+		})
+		if strings.TrimSpace(out) != tc.want {
+			t.Errorf("%v = %q, want %s", tc.args, out, tc.want)
+		}
+	}
+
+	resetListFlags()
+	rootCmd.SetArgs([]string{"list", "--count", "--json"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("list --count --json should fail")
+	}
+}
+
+func TestListGroupByTag(t *testing.T) {
+	resetListFlags()
+	defer resetListFlags()
+	dir, _ := setupWnRoot(t)
+	store, _ := wn.NewFileStore(dir)
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "bb2222", Description: "both", Tags: []string{"api", "ui"}, Created: now, Updated: now},
+		{ID: "cc3333", Description: "ui only", Tags: []string{"ui"}, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"list", "--group-by", "tag"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("list --group-by tag: %v", err)
+		}
+	})
+	api := strings.Index(out, "--- #api ---")
+	ui := strings.Index(out, "--- #ui ---")
+	none := strings.Index(out, "--- (none) ---")
+	if api < 0 || ui < api || none < ui {
+		t.Fatalf("sections out of order or missing:\n%s", out)
+	}
+	if strings.Count(out, "bb2222") != 2 {
+		t.Errorf("bb2222 has two tags and should appear under each:\n%s", out)
+	}
+	if !strings.Contains(out[none:], "abc123") {
+		t.Errorf("untagged abc123 should be under (none):\n%s", out)
+	}
+}
+
+func TestTagInteractive_Toggle(t *testing.T) {
+	resetTagFlags()
+	origPath := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	t.Cleanup(func() { os.Setenv("PATH", origPath) })
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = origStdin })
+	if _, err := w.WriteString("1 2\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1778,12 +4183,11 @@ func TestListLimit(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, item := range []*wn.Item{
-		{ID: "aaa", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bbb", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "ccc", Description: "third", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now, Tags: []string{"mytag"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
-		if err := store.Put(item); err != nil {
+		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -1793,25 +4197,54 @@ func TestListLimit(t *testing.T) {
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--json", "--sort", "alpha", "--limit", "2"})
-		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("Execute: %v", err)
-		}
-	})
-	list := parseListJSON(t, out)
-	if len(list.Items) != 2 {
-		t.Fatalf("list --limit 2: len = %d, want 2", len(list.Items))
+	rootCmd.SetArgs([]string{"tag", "add", "-i", "mytag"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag add -i mytag: %v", err)
+	}
+
+	it1, _ := store.Get("aa1111")
+	it2, _ := store.Get("bb2222")
+	has1 := false
+	for _, tag := range it1.Tags {
+		if tag == "mytag" {
+			has1 = true
+			break
+		}
+	}
+	has2 := false
+	for _, tag := range it2.Tags {
+		if tag == "mytag" {
+			has2 = true
+			break
+		}
+	}
+	if !has1 {
+		t.Error("item aa1111 should have mytag after toggle (was added)")
+	}
+	if has2 {
+		t.Error("item bb2222 should not have mytag after toggle (was removed)")
+	}
+}
+
+func TestTagInteractive_OnlyUndoneItems(t *testing.T) {
+	resetTagFlags()
+	// wn tag -i should list only undone items; done items must not appear in fzf/numbered list
+	origPath := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	t.Cleanup(func() { os.Setenv("PATH", origPath) })
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
 	}
-	if list.Items[0].ID != "aaa" || list.Items[1].ID != "bbb" {
-		t.Errorf("list --limit 2 = %v, %v; want aaa, bbb", list.Items[0].ID, list.Items[1].ID)
+	origStdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = origStdin })
+	if _, err := w.WriteString("1\n"); err != nil {
+		t.Fatal(err)
 	}
-	listJson = false
-}
+	w.Close()
 
-func TestListLimitOffset(t *testing.T) {
-	resetListFlags()
-	listJson = true
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1821,12 +4254,10 @@ func TestListLimitOffset(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, item := range []*wn.Item{
-		{ID: "aaa", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bbb", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "ccc", Description: "third", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-	} {
-		if err := store.Put(item); err != nil {
+	doneItem := &wn.Item{ID: "aa1111", Description: "done task", Done: true, Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}
+	undoneItem := &wn.Item{ID: "bb2222", Description: "undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}
+	for _, it := range []*wn.Item{doneItem, undoneItem} {
+		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -1836,24 +4267,38 @@ func TestListLimitOffset(t *testing.T) {
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--json", "--sort", "alpha", "--limit", "1", "--offset", "1"})
-		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("Execute: %v", err)
+	rootCmd.SetArgs([]string{"tag", "add", "-i", "mytag"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag add -i mytag: %v", err)
+	}
+
+	// Only undone item (bb2222) should be in the list; selecting "1" must tag bb2222, not aa1111
+	gotDone, _ := store.Get("aa1111")
+	gotUndone, _ := store.Get("bb2222")
+	doneHasTag := false
+	for _, tag := range gotDone.Tags {
+		if tag == "mytag" {
+			doneHasTag = true
+			break
 		}
-	})
-	list := parseListJSON(t, out)
-	if len(list.Items) != 1 {
-		t.Fatalf("list --limit 1 --offset 1: len = %d, want 1", len(list.Items))
 	}
-	if list.Items[0].ID != "bbb" {
-		t.Errorf("list --limit 1 --offset 1 = %v; want bbb", list.Items[0].ID)
+	undoneHasTag := false
+	for _, tag := range gotUndone.Tags {
+		if tag == "mytag" {
+			undoneHasTag = true
+			break
+		}
+	}
+	if doneHasTag {
+		t.Error("tag -i must not list done items; aa1111 (done) should not have been selectable and must not have mytag")
+	}
+	if !undoneHasTag {
+		t.Error("selecting the only listed item (bb2222, undone) should have added mytag")
 	}
-	listJson = false
 }
 
-func TestListGroupByTags(t *testing.T) {
-	resetListFlags()
+func TestTagAdd(t *testing.T) {
+	resetTagFlags()
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1863,61 +4308,61 @@ func TestListGroupByTags(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, item := range []*wn.Item{
-		{ID: "aaa111", Description: "agent task", Created: now, Updated: now, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bbb222", Description: "backend task", Created: now, Updated: now, Tags: []string{"backend"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "ccc333", Description: "another agent task", Created: now, Updated: now, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
-		if err := store.Put(item); err != nil {
+		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatal(err)
+	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--group", "tags"})
-		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("Execute: %v", err)
-		}
-	})
-
-	// Should have group headers for each tag set
-	if !strings.Contains(out, "#agent") {
-		t.Errorf("output should contain #agent group header; got:\n%s", out)
-	}
-	if !strings.Contains(out, "#backend") {
-		t.Errorf("output should contain #backend group header; got:\n%s", out)
+	// wn tag add mytag (current item)
+	rootCmd.SetArgs([]string{"tag", "add", "mytag"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag add mytag: %v", err)
 	}
-	// Items in the same group (agent) should be adjacent — header appears once before both agent items
-	agentHeaderIdx := strings.Index(out, "--- #agent ---")
-	if agentHeaderIdx < 0 {
-		t.Fatalf("expected '--- #agent ---' header; got:\n%s", out)
+	it, _ := store.Get("aa1111")
+	hasTag := false
+	for _, tag := range it.Tags {
+		if tag == "mytag" {
+			hasTag = true
+			break
+		}
 	}
-	backendHeaderIdx := strings.Index(out, "--- #backend ---")
-	if backendHeaderIdx < 0 {
-		t.Fatalf("expected '--- #backend ---' header; got:\n%s", out)
+	if !hasTag {
+		t.Error("tag add mytag should add tag to current item aa1111")
 	}
-	// Both agent items should appear before the backend header
-	aaa111Idx := strings.Index(out, "aaa111")
-	ccc333Idx := strings.Index(out, "ccc333")
-	bbb222Idx := strings.Index(out, "bbb222")
-	if aaa111Idx < 0 || ccc333Idx < 0 || bbb222Idx < 0 {
-		t.Fatalf("all item ids should appear in output; got:\n%s", out)
+
+	// wn tag add other --wid bb2222
+	rootCmd.SetArgs([]string{"tag", "add", "other", "--wid", "bb2222"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag add other --wid bb2222: %v", err)
 	}
-	if aaa111Idx > backendHeaderIdx || ccc333Idx > backendHeaderIdx {
-		t.Errorf("agent items should appear before backend header; got:\n%s", out)
+	it2, _ := store.Get("bb2222")
+	hasOther := false
+	for _, tag := range it2.Tags {
+		if tag == "other" {
+			hasOther = true
+			break
+		}
 	}
-	if bbb222Idx < backendHeaderIdx {
-		t.Errorf("backend item should appear after backend header; got:\n%s", out)
+	if !hasOther {
+		t.Error("tag add other --wid bb2222 should add tag to bb2222")
 	}
 }
 
-func TestListGroupByTagsNoTagsGroup(t *testing.T) {
-	resetListFlags()
+func TestTagAddWhere(t *testing.T) {
+	resetTagFlags()
+	defer resetTagFlags()
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1927,11 +4372,13 @@ func TestListGroupByTagsNoTagsGroup(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, item := range []*wn.Item{
-		{ID: "aaa111", Description: "tagged task", Created: now, Updated: now, Tags: []string{"agent"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bbb222", Description: "untagged task", Created: now, Updated: now, Tags: nil, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "backend", Tags: []string{"backend"}, Created: now, Updated: now},
+		{ID: "bb2222", Description: "backend done", Tags: []string{"backend"}, Done: true, Created: now, Updated: now},
+		{ID: "cc3333", Description: "stale claim", InProgressUntil: now.Add(-time.Hour), Created: now, Updated: now},
+		{ID: "dd4444", Description: "other", Created: now, Updated: now},
 	} {
-		if err := store.Put(item); err != nil {
+		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -1942,26 +4389,40 @@ func TestListGroupByTagsNoTagsGroup(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	out := captureStdout(t, func() {
-		resetListFlags()
-		rootCmd.SetArgs([]string{"list", "--group", "tags"})
+		rootCmd.SetArgs([]string{"tag", "add", "urgent", "--where", "tag:backend status:undone"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("Execute: %v", err)
+			t.Fatalf("tag add --where: %v", err)
 		}
 	})
-
-	if !strings.Contains(out, "(no tags)") {
-		t.Errorf("output should contain '(no tags)' group header for untagged items; got:\n%s", out)
+	if strings.TrimSpace(out) != "aa1111" {
+		t.Errorf("tag add --where output = %q, want aa1111", out)
 	}
-	if !strings.Contains(out, "#agent") {
-		t.Errorf("output should contain '#agent' group header; got:\n%s", out)
+	resetTagFlags()
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"tag", "add", "urgent", "--where", "overdue"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("tag add --where overdue: %v", err)
+		}
+	})
+	if strings.TrimSpace(out) != "cc3333" {
+		t.Errorf("tag add --where overdue output = %q, want cc3333", out)
 	}
-	if !strings.Contains(out, "bbb222") {
-		t.Errorf("output should contain untagged item id; got:\n%s", out)
+	for id, want := range map[string]bool{"aa1111": true, "bb2222": false, "cc3333": true, "dd4444": false} {
+		it, _ := store.Get(id)
+		if got := slices.Contains(it.Tags, "urgent"); got != want {
+			t.Errorf("%s has urgent = %v, want %v", id, got, want)
+		}
+	}
+
+	resetTagFlags()
+	rootCmd.SetArgs([]string{"tag", "add", "urgent", "--where", "bogus:x"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("tag add --where with invalid filter should fail")
 	}
 }
 
-func TestListGroupByStatus(t *testing.T) {
-	resetListFlags()
+func TestTagRm(t *testing.T) {
+	resetTagFlags()
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -1971,76 +4432,106 @@ func TestListGroupByStatus(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, item := range []*wn.Item{
-		{ID: "aaa111", Description: "done task", Created: now, Updated: now, Done: true, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bbb222", Description: "undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "ccc333", Description: "another undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, Tags: []string{"mytag", "other"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now, Tags: []string{"mytag"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
-		if err := store.Put(item); err != nil {
+		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatal(err)
+	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"list", "--all", "--group", "status"})
-		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("Execute: %v", err)
+	// wn tag rm mytag (current item)
+	rootCmd.SetArgs([]string{"tag", "rm", "mytag"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag rm mytag: %v", err)
+	}
+	it, _ := store.Get("aa1111")
+	for _, tag := range it.Tags {
+		if tag == "mytag" {
+			t.Error("tag rm mytag should remove tag from current item aa1111")
+			break
 		}
-	})
-
-	// Group headers for done and undone
-	if !strings.Contains(out, "--- done ---") {
-		t.Errorf("output should contain '--- done ---' header; got:\n%s", out)
 	}
-	if !strings.Contains(out, "--- undone ---") {
-		t.Errorf("output should contain '--- undone ---' header; got:\n%s", out)
+
+	// wn tag rm mytag --wid bb2222
+	rootCmd.SetArgs([]string{"tag", "rm", "mytag", "--wid", "bb2222"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag rm mytag --wid bb2222: %v", err)
 	}
-	// The two undone items should both appear after the undone header
-	undoneHeaderIdx := strings.Index(out, "--- undone ---")
-	bbb222Idx := strings.Index(out, "bbb222")
-	ccc333Idx := strings.Index(out, "ccc333")
-	if bbb222Idx < undoneHeaderIdx || ccc333Idx < undoneHeaderIdx {
-		t.Errorf("undone items should appear after '--- undone ---' header; got:\n%s", out)
+	it2, _ := store.Get("bb2222")
+	if len(it2.Tags) != 0 {
+		t.Errorf("tag rm mytag --wid bb2222 should remove tag from bb2222; remaining tags: %v", it2.Tags)
 	}
 }
 
-func TestListGroupInvalidKey(t *testing.T) {
-	resetListFlags()
-	dir, _ := setupWnRoot(t)
-	cwd, _ := os.Getwd()
-	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+func TestTagList(t *testing.T) {
+	resetTagFlags()
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
 	}
-	defer func() { _ = os.Chdir(cwd) }()
-
-	rootCmd.SetArgs([]string{"list", "--group", "bogus"})
-	if err := rootCmd.Execute(); err == nil {
-		t.Error("list --group bogus should return an error")
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, Tags: []string{"foo", "bar"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now, Tags: []string{"baz"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatal(err)
 	}
-}
-
-func TestListGroupWithJSON(t *testing.T) {
-	resetListFlags()
-	dir, _ := setupWnRoot(t)
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	rootCmd.SetArgs([]string{"list", "--group", "tags", "--json"})
-	if err := rootCmd.Execute(); err == nil {
-		t.Error("list --group with --json should return an error")
+	// wn tag list (current item) — one per line
+	rootCmd.SetArgs([]string{"tag", "list"})
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag list: %v", err)
+	}
+	rootCmd.SetOut(nil)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Errorf("tag list: want 2 lines (foo, bar), got %d: %q", len(lines), lines)
+	}
+	if lines[0] != "foo" || lines[1] != "bar" {
+		t.Errorf("tag list: want lines foo, bar; got %q", lines)
+	}
+
+	// wn tag list --wid bb2222
+	rootCmd.SetArgs([]string{"tag", "list", "--wid", "bb2222"})
+	out.Reset()
+	rootCmd.SetOut(&out)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag list --wid bb2222: %v", err)
+	}
+	rootCmd.SetOut(nil)
+	lines2 := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines2) != 1 || lines2[0] != "baz" {
+		t.Errorf("tag list --wid bb2222: want one line 'baz'; got %q", lines2)
 	}
 }
 
-func TestTagInteractive_Toggle(t *testing.T) {
-	resetTagFlags()
+func TestDependInteractive(t *testing.T) {
 	origPath := os.Getenv("PATH")
 	os.Setenv("PATH", "")
 	t.Cleanup(func() { os.Setenv("PATH", origPath) })
@@ -2052,7 +4543,7 @@ func TestTagInteractive_Toggle(t *testing.T) {
 	origStdin := os.Stdin
 	os.Stdin = r
 	t.Cleanup(func() { os.Stdin = origStdin })
-	if _, err := w.WriteString("1 2\n"); err != nil {
+	if _, err := w.WriteString("1\n"); err != nil {
 		t.Fatal(err)
 	}
 	w.Close()
@@ -2068,50 +4559,40 @@ func TestTagInteractive_Toggle(t *testing.T) {
 	now := time.Now().UTC()
 	for _, it := range []*wn.Item{
 		{ID: "aa1111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bb2222", Description: "second", Created: now, Updated: now, Tags: []string{"mytag"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
 		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	rootCmd.SetArgs([]string{"tag", "add", "-i", "mytag"})
+	rootCmd.SetArgs([]string{"depend", "add", "-i"})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag add -i mytag: %v", err)
+		t.Fatalf("depend add -i: %v", err)
 	}
 
-	it1, _ := store.Get("aa1111")
-	it2, _ := store.Get("bb2222")
-	has1 := false
-	for _, tag := range it1.Tags {
-		if tag == "mytag" {
-			has1 = true
-			break
-		}
-	}
-	has2 := false
-	for _, tag := range it2.Tags {
-		if tag == "mytag" {
-			has2 = true
+	it, _ := store.Get("aa1111")
+	found := false
+	for _, dep := range it.DependsOn {
+		if dep == "bb2222" {
+			found = true
 			break
 		}
 	}
-	if !has1 {
-		t.Error("item aa1111 should have mytag after toggle (was added)")
-	}
-	if has2 {
-		t.Error("item bb2222 should not have mytag after toggle (was removed)")
+	if !found {
+		t.Errorf("aa1111 should depend on bb2222 after depend -i; DependsOn = %v", it.DependsOn)
 	}
 }
 
-func TestTagInteractive_OnlyUndoneItems(t *testing.T) {
-	resetTagFlags()
-	// wn tag -i should list only undone items; done items must not appear in fzf/numbered list
+func TestRmdependInteractive(t *testing.T) {
 	origPath := os.Getenv("PATH")
 	os.Setenv("PATH", "")
 	t.Cleanup(func() { os.Setenv("PATH", origPath) })
@@ -2137,51 +4618,77 @@ func TestTagInteractive_OnlyUndoneItems(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	doneItem := &wn.Item{ID: "aa1111", Description: "done task", Done: true, Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}
-	undoneItem := &wn.Item{ID: "bb2222", Description: "undone task", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}}
-	for _, it := range []*wn.Item{doneItem, undoneItem} {
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, DependsOn: []string{"bb2222"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
 		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	rootCmd.SetArgs([]string{"tag", "add", "-i", "mytag"})
+	rootCmd.SetArgs([]string{"depend", "rm", "-i"})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag add -i mytag: %v", err)
+		t.Fatalf("depend rm -i: %v", err)
 	}
 
-	// Only undone item (bb2222) should be in the list; selecting "1" must tag bb2222, not aa1111
-	gotDone, _ := store.Get("aa1111")
-	gotUndone, _ := store.Get("bb2222")
-	doneHasTag := false
-	for _, tag := range gotDone.Tags {
-		if tag == "mytag" {
-			doneHasTag = true
-			break
-		}
+	it, _ := store.Get("aa1111")
+	if len(it.DependsOn) != 0 {
+		t.Errorf("aa1111 should have no dependencies after rmdepend -i; DependsOn = %v", it.DependsOn)
 	}
-	undoneHasTag := false
-	for _, tag := range gotUndone.Tags {
-		if tag == "mytag" {
-			undoneHasTag = true
-			break
+}
+
+// TestDependAddWithOnAndWid tests "wn depend add --on <id> [--wid <id>]"
+func TestDependAddWithOnAndWid(t *testing.T) {
+	dir := t.TempDir()
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
 		}
 	}
-	if doneHasTag {
-		t.Error("tag -i must not list done items; aa1111 (done) should not have been selectable and must not have mytag")
+	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
 	}
-	if !undoneHasTag {
-		t.Error("selecting the only listed item (bb2222, undone) should have added mytag")
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	resetDependFlags()
+	// depend add --on bb2222 --wid aa1111
+	rootCmd.SetArgs([]string{"depend", "add", "--on", "bb2222", "--wid", "aa1111"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("depend add --on bb2222 --wid aa1111: %v", err)
+	}
+	it, _ := store.Get("aa1111")
+	if len(it.DependsOn) != 1 || it.DependsOn[0] != "bb2222" {
+		t.Errorf("after depend add: DependsOn = %v, want [bb2222]", it.DependsOn)
 	}
 }
 
-func TestTagAdd(t *testing.T) {
-	resetTagFlags()
+// TestDependAddOnTag tests "wn depend add --on-tag <tag>" expands to one edge per undone tagged item,
+// skipping the item itself, done items, and edges that would create a cycle.
+func TestDependAddOnTag(t *testing.T) {
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -2192,77 +4699,263 @@ func TestTagAdd(t *testing.T) {
 	}
 	now := time.Now().UTC()
 	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "aa1111", Description: "main", Tags: []string{"infra"}, Created: now, Updated: now},
+		{ID: "bb2222", Description: "infra one", Tags: []string{"infra"}, Created: now, Updated: now},
+		{ID: "cc3333", Description: "infra two", Tags: []string{"infra"}, Created: now, Updated: now},
+		{ID: "dd4444", Description: "infra done", Tags: []string{"infra"}, Done: true, Created: now, Updated: now},
+		{ID: "ee5555", Description: "infra cyclic", Tags: []string{"infra"}, DependsOn: []string{"aa1111"}, Created: now, Updated: now},
+		{ID: "ff6666", Description: "other", Tags: []string{"ui"}, Created: now, Updated: now},
 	} {
 		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetDependFlags()
+
+	resetDependFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"depend", "add", "--on-tag", "infra", "--wid", "aa1111"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("depend add --on-tag infra: %v", err)
+		}
+	})
+	if !strings.Contains(out, "added 2 dependencies") {
+		t.Errorf("output should report 2 added; got %q", out)
+	}
+	it, _ := store.Get("aa1111")
+	if strings.Join(it.DependsOn, ",") != "bb2222,cc3333" {
+		t.Errorf("DependsOn = %v, want [bb2222 cc3333]", it.DependsOn)
+	}
+
+	resetDependFlags()
+	rootCmd.SetArgs([]string{"depend", "add", "--on-tag", "infra", "--on", "bb2222", "--wid", "aa1111"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("depend add --on-tag with --on should fail")
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&wn.Item{ID: "dd4444", Description: "waits", DependsOn: []string{"abc123"}, Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	resetCurrentFlags()
+	bare := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("wn: %v", err)
+		}
+	})
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"current"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("current: %v", err)
+		}
+	})
+	if out != bare {
+		t.Errorf("current output = %q, want same as bare wn %q", out, bare)
+	}
+
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"current", "--json"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("current --json: %v", err)
+		}
+	})
+	resetCurrentFlags()
+	var got struct {
+		ID          string   `json:"id"`
+		Description string   `json:"description"`
+		Status      string   `json:"status"`
+		Tags        []string `json:"tags"`
+		DependsOn   []string `json:"depends_on"`
+		Dependents  []string `json:"dependents"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	if got.ID != "abc123" || got.Status != "undone" || got.Tags == nil || len(got.DependsOn) != 0 {
+		t.Errorf("current --json = %+v", got)
+	}
+	if len(got.Dependents) != 1 || got.Dependents[0] != "dd4444" {
+		t.Errorf("dependents = %v, want [dd4444]", got.Dependents)
+	}
+
+	if err := wn.WithMetaLock(dir, func(m wn.Meta) (wn.Meta, error) {
+		m.CurrentID = ""
+		return m, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.SetArgs([]string{"current", "--json"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("current --json with no current task should fail")
+	}
+	resetCurrentFlags()
+}
+
+func TestClearCurrent(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"clear-current"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("clear-current: %v", err)
+		}
+	})
+	if !strings.Contains(out, "was abc123") {
+		t.Errorf("clear-current output = %q", out)
+	}
+	meta, err := wn.ReadMeta(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.CurrentID != "" || meta.PreviousID != "abc123" {
+		t.Errorf("meta = %+v, want no current and previous abc123", meta)
+	}
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{})
+		if err := rootCmd.Execute(); exitCode(err) != exitEmpty {
+			t.Fatalf("wn: err = %v, want exit code %d", err, exitEmpty)
+		}
+	})
+	if !strings.Contains(out, "No current task") {
+		t.Errorf("bare wn after clear-current = %q", out)
+	}
+	// Clearing again is a no-op.
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"clear-current"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("clear-current again: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No current task") {
+		t.Errorf("second clear-current output = %q", out)
+	}
+}
+
+func TestTagNormalization(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	if err := os.WriteFile(filepath.Join(dir, ".wn", "settings.json"), []byte(`{"tag_lowercase":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	resetTagFlags()
+	rootCmd.SetArgs([]string{"tag", "add", " Backend "})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag add: %v", err)
+	}
+	rootCmd.SetArgs([]string{"tag", "add", "backend"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag add again: %v", err)
+	}
+	rootCmd.SetArgs([]string{"tag", "add", "priority: high"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("tag add with interior space should fail")
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get("abc123")
+	if err != nil {
 		t.Fatal(err)
 	}
-	cwd, _ := os.Getwd()
-	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Chdir: %v", err)
+	if len(it.Tags) != 1 || it.Tags[0] != "backend" {
+		t.Errorf("tags = %v, want [backend]", it.Tags)
 	}
-	defer func() { _ = os.Chdir(cwd) }()
 
-	// wn tag add mytag (current item)
-	rootCmd.SetArgs([]string{"tag", "add", "mytag"})
+	rootCmd.SetArgs([]string{"add", "-m", "tagged on add", "-t", "API", "-t", " api"})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag add mytag: %v", err)
+		t.Fatalf("add -t: %v", err)
 	}
-	it, _ := store.Get("aa1111")
-	hasTag := false
-	for _, tag := range it.Tags {
-		if tag == "mytag" {
-			hasTag = true
-			break
-		}
+	addTags = nil
+	meta, err := wn.ReadMeta(dir)
+	if err != nil {
+		t.Fatal(err)
 	}
-	if !hasTag {
-		t.Error("tag add mytag should add tag to current item aa1111")
+	added, err := store.Get(meta.CurrentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added.Tags) != 1 || added.Tags[0] != "api" {
+		t.Errorf("add -t tags = %v, want [api]", added.Tags)
 	}
 
-	// wn tag add other --wid bb2222
-	rootCmd.SetArgs([]string{"tag", "add", "other", "--wid", "bb2222"})
+	// tag rm and depend add --on-tag normalize the same way.
+	defer resetDependFlags()
+	resetDependFlags()
+	rootCmd.SetArgs([]string{"depend", "add", "--wid", "abc123", "--on-tag", " API "})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag add other --wid bb2222: %v", err)
+		t.Fatalf("depend add --on-tag API: %v", err)
 	}
-	it2, _ := store.Get("bb2222")
-	hasOther := false
-	for _, tag := range it2.Tags {
-		if tag == "other" {
-			hasOther = true
-			break
+	resetTagFlags()
+	rootCmd.SetArgs([]string{"tag", "rm", "BACKEND", "--wid", "abc123"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tag rm BACKEND: %v", err)
+	}
+	resetTagFlags()
+	it, err = store.Get("abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(it.Tags) != 0 || len(it.DependsOn) != 1 || it.DependsOn[0] != added.ID {
+		t.Errorf("after tag rm / depend add --on-tag: tags %v, deps %v; want no tags and [%s]", it.Tags, it.DependsOn, added.ID)
+	}
+
+	// tag rm still removes tags stored before normalization: mixed case, or no longer valid.
+	if err := store.UpdateItem("abc123", func(it *wn.Item) (*wn.Item, error) {
+		it.Tags = []string{"Legacy", "old tag"}
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"Legacy", " old tag "} {
+		rootCmd.SetArgs([]string{"tag", "rm", tag, "--wid", "abc123"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("tag rm %q: %v", tag, err)
 		}
+		resetTagFlags()
 	}
-	if !hasOther {
-		t.Error("tag add other --wid bb2222 should add tag to bb2222")
+	if it, _ = store.Get("abc123"); len(it.Tags) != 0 {
+		t.Errorf("after tag rm of legacy tags: tags %v, want none", it.Tags)
 	}
 }
 
-func TestTagRm(t *testing.T) {
-	resetTagFlags()
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
-	}
+func TestMutatingCommandsJSON(t *testing.T) {
+	dir, _ := setupWnRoot(t)
 	store, err := wn.NewFileStore(dir)
 	if err != nil {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "first", Created: now, Updated: now, Tags: []string{"mytag", "other"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bb2222", Description: "second", Created: now, Updated: now, Tags: []string{"mytag"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-	} {
-		if err := store.Put(it); err != nil {
-			t.Fatal(err)
-		}
-	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+	if err := store.Put(&wn.Item{ID: "dd4444", Description: "second", Created: now, Updated: now}); err != nil {
 		t.Fatal(err)
 	}
 	cwd, _ := os.Getwd()
@@ -2270,51 +4963,92 @@ func TestTagRm(t *testing.T) {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
-
-	// wn tag rm mytag (current item)
-	rootCmd.SetArgs([]string{"tag", "rm", "mytag"})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag rm mytag: %v", err)
+	resetAll := func() {
+		doneJson, claimJson, releaseJson = false, false, false
+		claimFor, claimUntil, doneNext = "", "", false
+		resetTagFlags()
+		resetDependFlags()
+		resetNextFlags()
+	}
+	resetAll()
+	defer resetAll()
+
+	run := func(args ...string) map[string]any {
+		t.Helper()
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("%v: %v", args, err)
+			}
+		})
+		var got map[string]any
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("%v: output %q is not JSON: %v", args, out, err)
+		}
+		return got
 	}
-	it, _ := store.Get("aa1111")
-	for _, tag := range it.Tags {
-		if tag == "mytag" {
-			t.Error("tag rm mytag should remove tag from current item aa1111")
-			break
+	check := func(got map[string]any, want map[string]any) {
+		t.Helper()
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%s = %q, want %q (result %v)", k, got[k], v, got)
+			}
 		}
 	}
 
-	// wn tag rm mytag --wid bb2222
-	rootCmd.SetArgs([]string{"tag", "rm", "mytag", "--wid", "bb2222"})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag rm mytag --wid bb2222: %v", err)
-	}
-	it2, _ := store.Get("bb2222")
-	if len(it2.Tags) != 0 {
-		t.Errorf("tag rm mytag --wid bb2222 should remove tag from bb2222; remaining tags: %v", it2.Tags)
+	check(run("tag", "add", "backend", "--json"), map[string]any{"id": "abc123", "action": "tag_added", "tag": "backend"})
+	check(run("tag", "rm", "backend", "--json"), map[string]any{"id": "abc123", "action": "tag_removed", "tag": "backend"})
+	check(run("depend", "add", "--wid", "dd4444", "--on", "abc123", "--json"), map[string]any{"id": "dd4444", "action": "depend_added", "on": "abc123"})
+	check(run("depend", "rm", "--wid", "dd4444", "--on", "abc123", "--json"), map[string]any{"id": "dd4444", "action": "depend_removed", "on": "abc123"})
+	claimed := run("claim", "--for", "30m", "--json")
+	check(claimed, map[string]any{"id": "abc123", "action": "claimed"})
+	if claimed["until"] == "" {
+		t.Errorf("claim --json should include until: %v", claimed)
+	}
+	check(run("release", "--json"), map[string]any{"id": "abc123", "action": "released"})
+	check(run("done", "--json"), map[string]any{"id": "abc123", "action": "done"})
+	check(run("next", "--json"), map[string]any{"id": "dd4444", "action": "next", "description": "second", "claimed": false})
+	nextClaimed := run("next", "--claim", "30m", "--json")
+	check(nextClaimed, map[string]any{"id": "dd4444", "claimed": true, "claim_for": "30m"})
+	if nextClaimed["until"] == nil {
+		t.Errorf("next --claim --json should include until: %v", nextClaimed)
+	}
+	resetNextFlags()
+	// done --next on an empty queue prints the result, then exits with exitEmpty.
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"done", "--next", "--json"})
+		if err := rootCmd.Execute(); exitCode(err) != exitEmpty {
+			t.Errorf("done --next --json with empty queue: err = %v, want exit code %d", err, exitEmpty)
+		}
+	})
+	var doneResult map[string]any
+	if err := json.Unmarshal([]byte(out), &doneResult); err != nil {
+		t.Fatalf("done --next --json output %q is not JSON: %v", out, err)
+	}
+	check(doneResult, map[string]any{"id": "dd4444", "action": "done", "next": nil})
+	doneNext = false
+	// An empty queue still prints the result object (id null), then exits with exitEmpty.
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"next", "--json"})
+		if err := rootCmd.Execute(); exitCode(err) != exitEmpty {
+			t.Errorf("next --json with empty queue: err = %v, want exit code %d", err, exitEmpty)
+		}
+	})
+	var empty map[string]any
+	if err := json.Unmarshal([]byte(out), &empty); err != nil {
+		t.Fatalf("next --json output %q is not JSON: %v", out, err)
 	}
+	check(empty, map[string]any{"id": nil, "description": nil, "action": "next"})
 }
 
-func TestTagList(t *testing.T) {
-	resetTagFlags()
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
-	}
+func TestWhy(t *testing.T) {
+	dir, _ := setupWnRoot(t)
 	store, err := wn.NewFileStore(dir)
 	if err != nil {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "first", Created: now, Updated: now, Tags: []string{"foo", "bar"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bb2222", Description: "second", Created: now, Updated: now, Tags: []string{"baz"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-	} {
-		if err := store.Put(it); err != nil {
-			t.Fatal(err)
-		}
-	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
+	if err := store.Put(&wn.Item{ID: "dd4444", Description: "waits", DependsOn: []string{"abc123"}, Created: now, Updated: now}); err != nil {
 		t.Fatal(err)
 	}
 	cwd, _ := os.Getwd()
@@ -2323,114 +5057,76 @@ func TestTagList(t *testing.T) {
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	// wn tag list (current item) — one per line
-	rootCmd.SetArgs([]string{"tag", "list"})
-	var out strings.Builder
-	rootCmd.SetOut(&out)
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag list: %v", err)
-	}
-	rootCmd.SetOut(nil)
-	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
-	if len(lines) != 2 {
-		t.Errorf("tag list: want 2 lines (foo, bar), got %d: %q", len(lines), lines)
-	}
-	if lines[0] != "foo" || lines[1] != "bar" {
-		t.Errorf("tag list: want lines foo, bar; got %q", lines)
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"why", "dd4444"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("why dd4444: %v", err)
+		}
+	})
+	if !strings.Contains(out, "dd4444: blocked") || !strings.Contains(out, "depends on abc123") || !strings.Contains(out, "next task is abc123") {
+		t.Errorf("why output = %q", out)
 	}
 
-	// wn tag list --wid bb2222
-	rootCmd.SetArgs([]string{"tag", "list", "--wid", "bb2222"})
-	out.Reset()
-	rootCmd.SetOut(&out)
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("tag list --wid bb2222: %v", err)
-	}
-	rootCmd.SetOut(nil)
-	lines2 := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
-	if len(lines2) != 1 || lines2[0] != "baz" {
-		t.Errorf("tag list --wid bb2222: want one line 'baz'; got %q", lines2)
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"why"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("why (current): %v", err)
+		}
+	})
+	if !strings.Contains(out, "abc123: undone") || !strings.Contains(out, "is the next task") {
+		t.Errorf("why current output = %q", out)
 	}
 }
 
-func TestDependInteractive(t *testing.T) {
-	origPath := os.Getenv("PATH")
-	os.Setenv("PATH", "")
-	t.Cleanup(func() { os.Setenv("PATH", origPath) })
-
-	r, w, err := os.Pipe()
-	if err != nil {
-		t.Fatal(err)
-	}
-	origStdin := os.Stdin
-	os.Stdin = r
-	t.Cleanup(func() { os.Stdin = origStdin })
-	if _, err := w.WriteString("1\n"); err != nil {
-		t.Fatal(err)
-	}
-	w.Close()
-
-	dir := t.TempDir()
-	if err := wn.InitRoot(dir); err != nil {
-		t.Fatalf("InitRoot: %v", err)
-	}
+func TestDoctor(t *testing.T) {
+	dir, _ := setupWnRoot(t)
 	store, err := wn.NewFileStore(dir)
 	if err != nil {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
-	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-	} {
-		if err := store.Put(it); err != nil {
-			t.Fatal(err)
-		}
-	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
-		t.Fatalf("WriteMeta: %v", err)
+	if err := store.Put(&wn.Item{ID: "dd4444", Description: "dangling", DependsOn: []string{"gone01"}, Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
 	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { doctorFix = false }()
 
-	rootCmd.SetArgs([]string{"depend", "add", "-i"})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("depend add -i: %v", err)
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"doctor"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("doctor: %v", err)
+		}
+	})
+	if !strings.Contains(out, "dd4444: depends on missing item gone01 (fixable with --fix)") {
+		t.Errorf("doctor output = %q", out)
 	}
 
-	it, _ := store.Get("aa1111")
-	found := false
-	for _, dep := range it.DependsOn {
-		if dep == "bb2222" {
-			found = true
-			break
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"doctor", "--fix"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("doctor --fix: %v", err)
 		}
+	})
+	if !strings.Contains(out, "fixed 1 of 1") {
+		t.Errorf("doctor --fix output = %q", out)
 	}
-	if !found {
-		t.Errorf("aa1111 should depend on bb2222 after depend -i; DependsOn = %v", it.DependsOn)
+	doctorFix = false
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"doctor"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("doctor: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No problems found.") {
+		t.Errorf("doctor after fix output = %q", out)
 	}
 }
 
-func TestRmdependInteractive(t *testing.T) {
-	origPath := os.Getenv("PATH")
-	os.Setenv("PATH", "")
-	t.Cleanup(func() { os.Setenv("PATH", origPath) })
-
-	r, w, err := os.Pipe()
-	if err != nil {
-		t.Fatal(err)
-	}
-	origStdin := os.Stdin
-	os.Stdin = r
-	t.Cleanup(func() { os.Stdin = origStdin })
-	if _, err := w.WriteString("1\n"); err != nil {
-		t.Fatal(err)
-	}
-	w.Close()
-
+func TestNext_strictOrder(t *testing.T) {
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -2440,36 +5136,67 @@ func TestRmdependInteractive(t *testing.T) {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 	now := time.Now().UTC()
+	// The example from wn next --help: the default breaks the order-10 tie by created time,
+	// --strict-order by id.
+	zero, ten := 0, 10
 	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "first", Created: now, Updated: now, DependsOn: []string{"bb2222"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
-		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "aa1111", Description: "prereq", Order: &ten, Created: now, Updated: now},
+		{ID: "bb2222", Description: "independent", Order: &ten, Created: now.Add(-time.Hour), Updated: now},
+		{ID: "cc3333", Description: "urgent", Order: &zero, DependsOn: []string{"aa1111"}, Created: now, Updated: now},
 	} {
 		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
-		t.Fatalf("WriteMeta: %v", err)
-	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
-
-	rootCmd.SetArgs([]string{"depend", "rm", "-i"})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("depend rm -i: %v", err)
+	defer resetNextFlags()
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"next"}, "bb2222"},
+		{[]string{"next", "--strict-order"}, "aa1111"},
+	} {
+		resetNextFlags()
+		rootCmd.SetArgs(tt.args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		meta, _ := wn.ReadMeta(dir)
+		if meta.CurrentID != tt.want {
+			t.Errorf("%v: current = %q, want %q", tt.args, meta.CurrentID, tt.want)
+		}
 	}
+}
 
-	it, _ := store.Get("aa1111")
-	if len(it.DependsOn) != 0 {
-		t.Errorf("aa1111 should have no dependencies after rmdepend -i; DependsOn = %v", it.DependsOn)
+func TestReindex(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"reindex"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("reindex: %v", err)
+		}
+	})
+	if !strings.Contains(out, "indexed 1 items") {
+		t.Errorf("reindex output = %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".wn", "index.json")); err != nil {
+		t.Errorf("index.json not written: %v", err)
 	}
 }
 
-// TestDependAddWithOnAndWid tests "wn depend add --on <id> [--wid <id>]"
-func TestDependAddWithOnAndWid(t *testing.T) {
+// TestDependAddWithOnCurrent tests "wn depend add --on <id>" without --wid uses current task
+func TestDependAddWithOnCurrent(t *testing.T) {
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -2497,19 +5224,18 @@ func TestDependAddWithOnAndWid(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	resetDependFlags()
-	// depend add --on bb2222 --wid aa1111
-	rootCmd.SetArgs([]string{"depend", "add", "--on", "bb2222", "--wid", "aa1111"})
+	rootCmd.SetArgs([]string{"depend", "add", "--on", "bb2222"})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("depend add --on bb2222 --wid aa1111: %v", err)
+		t.Fatalf("depend add --on bb2222: %v", err)
 	}
 	it, _ := store.Get("aa1111")
 	if len(it.DependsOn) != 1 || it.DependsOn[0] != "bb2222" {
-		t.Errorf("after depend add: DependsOn = %v, want [bb2222]", it.DependsOn)
+		t.Errorf("after depend add (current): DependsOn = %v, want [bb2222]", it.DependsOn)
 	}
 }
 
-// TestDependAddWithOnCurrent tests "wn depend add --on <id>" without --wid uses current task
-func TestDependAddWithOnCurrent(t *testing.T) {
+// TestDependRmWithOnAndWid tests "wn depend rm --on <id> [--wid <id>]"
+func TestDependRmWithOnAndWid(t *testing.T) {
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -2520,7 +5246,7 @@ func TestDependAddWithOnCurrent(t *testing.T) {
 	}
 	now := time.Now().UTC()
 	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "first", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, DependsOn: []string{"bb2222"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
 		if err := store.Put(it); err != nil {
@@ -2537,18 +5263,40 @@ func TestDependAddWithOnCurrent(t *testing.T) {
 	defer func() { _ = os.Chdir(cwd) }()
 
 	resetDependFlags()
-	rootCmd.SetArgs([]string{"depend", "add", "--on", "bb2222"})
+	rootCmd.SetArgs([]string{"depend", "rm", "--on", "bb2222", "--wid", "aa1111"})
 	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("depend add --on bb2222: %v", err)
+		t.Fatalf("depend rm --on bb2222 --wid aa1111: %v", err)
 	}
 	it, _ := store.Get("aa1111")
-	if len(it.DependsOn) != 1 || it.DependsOn[0] != "bb2222" {
-		t.Errorf("after depend add (current): DependsOn = %v, want [bb2222]", it.DependsOn)
+	if len(it.DependsOn) != 0 {
+		t.Errorf("after depend rm: DependsOn = %v, want []", it.DependsOn)
 	}
 }
 
-// TestDependRmWithOnAndWid tests "wn depend rm --on <id> [--wid <id>]"
-func TestDependRmWithOnAndWid(t *testing.T) {
+// TestDependAddSelf tests that "wn depend add" rejects an item depending on itself.
+func TestDependAddSelf(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetDependFlags()
+
+	resetDependFlags()
+	rootCmd.SetArgs([]string{"depend", "add", "--on", itemID})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "an item cannot depend on itself") {
+		t.Errorf("depend add --on self = %v, want self-dependency error", err)
+	}
+	store, _ := wn.NewFileStore(dir)
+	if it, _ := store.Get(itemID); len(it.DependsOn) != 0 {
+		t.Errorf("DependsOn = %v, want none", it.DependsOn)
+	}
+}
+
+// TestDependRmAll tests "wn depend rm --all" removes every dependency and logs each edge.
+func TestDependRmAll(t *testing.T) {
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
@@ -2559,30 +5307,49 @@ func TestDependRmWithOnAndWid(t *testing.T) {
 	}
 	now := time.Now().UTC()
 	for _, it := range []*wn.Item{
-		{ID: "aa1111", Description: "first", Created: now, Updated: now, DependsOn: []string{"bb2222"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "aa1111", Description: "first", Created: now, Updated: now, DependsOn: []string{"bb2222", "cc3333"}, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 		{ID: "bb2222", Description: "second", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
+		{ID: "cc3333", Description: "third", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "created"}}},
 	} {
 		if err := store.Put(it); err != nil {
 			t.Fatal(err)
 		}
 	}
-	if err := wn.WriteMeta(dir, wn.Meta{CurrentID: "aa1111"}); err != nil {
-		t.Fatalf("WriteMeta: %v", err)
-	}
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
+	defer resetDependFlags()
 
 	resetDependFlags()
-	rootCmd.SetArgs([]string{"depend", "rm", "--on", "bb2222", "--wid", "aa1111"})
-	if err := rootCmd.Execute(); err != nil {
-		t.Fatalf("depend rm --on bb2222 --wid aa1111: %v", err)
+	rootCmd.SetArgs([]string{"depend", "rm", "--all", "--on", "bb2222", "--wid", "aa1111"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "choose one") {
+		t.Errorf("depend rm --all --on = %v, want conflict error", err)
+	}
+
+	resetDependFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"depend", "rm", "--all", "--wid", "aa1111"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("depend rm --all: %v", err)
+		}
+	})
+	if !strings.Contains(out, "removed 2 dependencies from aa1111") {
+		t.Errorf("depend rm --all output = %q", out)
 	}
 	it, _ := store.Get("aa1111")
 	if len(it.DependsOn) != 0 {
-		t.Errorf("after depend rm: DependsOn = %v, want []", it.DependsOn)
+		t.Errorf("after depend rm --all: DependsOn = %v, want []", it.DependsOn)
+	}
+	var removed []string
+	for _, e := range it.Log {
+		if e.Kind == "depend_removed" {
+			removed = append(removed, e.Msg)
+		}
+	}
+	if strings.Join(removed, ",") != "bb2222,cc3333" {
+		t.Errorf("depend_removed log entries = %v, want bb2222, cc3333", removed)
 	}
 }
 
@@ -2701,6 +5468,88 @@ func TestNoteAddAndList(t *testing.T) {
 	}
 }
 
+func TestNoteAddAppend(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { noteAddAppend = false }()
+
+	// --append on a missing note creates it
+	rootCmd.SetArgs([]string{"note", "add", "log", itemID, "--append", "-m", "first"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("note add --append (new): %v", err)
+	}
+	rootCmd.SetArgs([]string{"note", "add", "log", itemID, "--append", "-m", "second"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("note add --append (existing): %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	item, err := store.Get(itemID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(item.Notes) != 1 || item.Notes[0].Body != "first\nsecond" {
+		t.Errorf("after append: notes = %v, want one note with body first\\nsecond", item.Notes)
+	}
+
+	// Without --append the body is replaced
+	noteAddAppend = false
+	rootCmd.SetArgs([]string{"note", "add", "log", itemID, "-m", "replaced"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("note add: %v", err)
+	}
+	item, _ = store.Get(itemID)
+	if item.Notes[0].Body != "replaced" {
+		t.Errorf("after replace: body = %q, want replaced", item.Notes[0].Body)
+	}
+}
+
+func TestNoteListHistory(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	if err := os.WriteFile(filepath.Join(dir, ".wn", "settings.json"), []byte(`{"note_history":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { noteListHistory = "" }()
+
+	for _, body := range []string{"planning", "implementing"} {
+		rootCmd.SetArgs([]string{"note", "add", "status", itemID, "-m", body})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("note add: %v", err)
+		}
+	}
+	rootCmd.SetArgs([]string{"note", "edit", itemID, "status", "-m", "done"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("note edit: %v", err)
+	}
+	noteEditMessage = ""
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"note", "list", itemID, "--history", "status"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("note list --history: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "\tplanning") || !strings.HasSuffix(lines[1], "\timplementing") || !strings.HasSuffix(lines[2], "\tdone") {
+		t.Errorf("note list --history = %q, want planning, implementing, done", out)
+	}
+
+	rootCmd.SetArgs([]string{"note", "list", itemID, "--history", "missing"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("note list --history for a missing note should fail")
+	}
+}
+
 func TestNoteListOrderedByCreateTime(t *testing.T) {
 	dir, itemID := setupWnRoot(t)
 	cwd, _ := os.Getwd()
@@ -2870,6 +5719,54 @@ func TestNoteShow_NotFound(t *testing.T) {
 	}
 }
 
+func TestNoteGet(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { noteShowJson = false }()
+
+	rootCmd.SetArgs([]string{"note", "add", "pr-url", itemID, "-m", "https://example.com/pull/7"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("note add: %v", err)
+	}
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"note", "get", "pr-url"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("note get: %v", err)
+		}
+	})
+	if out != "https://example.com/pull/7\n" {
+		t.Errorf("note get = %q, want body with trailing newline", out)
+	}
+
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"note", "get", itemID, "pr-url", "--json"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("note get --json: %v", err)
+		}
+	})
+	var got struct {
+		Name    string    `json:"name"`
+		Body    string    `json:"body"`
+		Created time.Time `json:"created"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	if got.Name != "pr-url" || got.Body != "https://example.com/pull/7" || got.Created.IsZero() {
+		t.Errorf("note get --json = %+v", got)
+	}
+
+	noteShowJson = false
+	rootCmd.SetArgs([]string{"note", "get", "missing"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("note get for a missing note should fail")
+	}
+}
+
 func TestRmWithExplicitId(t *testing.T) {
 	dir, itemID := setupWnRoot(t)
 	cwd, _ := os.Getwd()
@@ -2909,7 +5806,9 @@ func TestRmMultipleIds(t *testing.T) {
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	rootCmd.SetArgs([]string{"rm", "abc123", "bb2222", "cc3333"})
+	defer resetRmFlags()
+	resetRmFlags()
+	rootCmd.SetArgs([]string{"rm", "abc123", "bb2222", "cc3333", "--yes"})
 	if err := rootCmd.Execute(); err != nil {
 		t.Fatalf("rm multiple: %v", err)
 	}
@@ -2920,6 +5819,158 @@ func TestRmMultipleIds(t *testing.T) {
 	}
 }
 
+// resetRmFlags clears rm flags so tests get consistent behavior.
+func resetRmFlags() {
+	rmWhere = ""
+	rmYes = false
+	rmForce = false
+	rmPurge = false
+}
+
+func TestRmTrashRestore(t *testing.T) {
+	defer resetRmFlags()
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	store, _ := wn.NewFileStore(dir)
+
+	resetRmFlags()
+	rootCmd.SetArgs([]string{"rm", itemID})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("rm: %v", err)
+	}
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"trash", "list"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("trash list: %v", err)
+		}
+	})
+	if !strings.Contains(out, itemID) || !strings.Contains(out, "first line") {
+		t.Errorf("trash list = %q, want removed item", out)
+	}
+	rootCmd.SetArgs([]string{"trash", "restore", itemID})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("trash restore: %v", err)
+	}
+	if _, err := store.Get(itemID); err != nil {
+		t.Errorf("item should be restored: %v", err)
+	}
+
+	resetRmFlags()
+	rootCmd.SetArgs([]string{"rm", itemID, "--purge"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("rm --purge: %v", err)
+	}
+	if entries, _ := wn.ListTrash(dir); len(entries) != 0 {
+		t.Errorf("rm --purge should not use the trash; trash = %v", entries)
+	}
+}
+
+func TestRmWhereConfirmAndDependents(t *testing.T) {
+	defer resetRmFlags()
+	dir, _ := setupWnRoot(t)
+	store, _ := wn.NewFileStore(dir)
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "bb2222", Description: "old one", Tags: []string{"stale"}, Created: now, Updated: now},
+		{ID: "cc3333", Description: "old two", Tags: []string{"stale"}, Created: now, Updated: now},
+		{ID: "dd4444", Description: "keeper", DependsOn: []string{"cc3333"}, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	feed := func(input string) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		origStdin := os.Stdin
+		os.Stdin = r
+		t.Cleanup(func() { os.Stdin = origStdin })
+		if _, err := w.WriteString(input); err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+
+	// dd4444 depends on cc3333, so removal is refused without --force.
+	resetRmFlags()
+	rootCmd.SetArgs([]string{"rm", "--where", "tag:stale", "--yes"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "dd4444") {
+		t.Fatalf("rm --where with a dependent: err = %v, want refusal naming dd4444", err)
+	}
+
+	// Declining the confirmation removes nothing.
+	resetRmFlags()
+	feed("n\n")
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"rm", "--where", "tag:stale", "--force"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("rm --where (declined): %v", err)
+		}
+	})
+	if !strings.Contains(out, "bb2222: old one") || !strings.Contains(out, "Nothing removed.") {
+		t.Errorf("declined rm output = %q, want item list and Nothing removed.", out)
+	}
+	if _, err := store.Get("bb2222"); err != nil {
+		t.Error("bb2222 should still exist after declining")
+	}
+
+	// No answer at all (EOF) is a usage error, not a silent success.
+	resetRmFlags()
+	feed("")
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"rm", "--where", "tag:stale", "--force"})
+		if err := rootCmd.Execute(); exitCode(err) != exitUsage || !strings.Contains(err.Error(), "--yes") {
+			t.Errorf("rm --where with no answer: err = %v (exit %d), want exit %d mentioning --yes", err, exitCode(err), exitUsage)
+		}
+	})
+	if _, err := store.Get("bb2222"); err != nil {
+		t.Error("bb2222 should still exist when no confirmation was read")
+	}
+
+	resetRmFlags()
+	feed("y\n")
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"rm", "--where", "tag:stale", "--force"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("rm --where (confirmed): %v", err)
+		}
+	})
+	for _, id := range []string{"bb2222", "cc3333"} {
+		if _, err := store.Get(id); err == nil {
+			t.Errorf("%s should be removed", id)
+		}
+		if !strings.Contains(out, "removed entry "+id) {
+			t.Errorf("output = %q, want removed entry %s", out, id)
+		}
+	}
+	if _, err := store.Get("dd4444"); err != nil {
+		t.Error("dd4444 should not be removed")
+	}
+
+	// A repeated id is removed once, without a confirmation prompt.
+	resetRmFlags()
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"rm", "dd4444", "dd4444"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("rm with a repeated id: %v", err)
+		}
+	})
+	if _, err := store.Get("dd4444"); err == nil {
+		t.Error("dd4444 should be removed")
+	}
+}
+
 func TestRmInteractiveMultiSelect(t *testing.T) {
 	origPath := os.Getenv("PATH")
 	os.Setenv("PATH", "")
@@ -3188,35 +6239,117 @@ func TestDoWithArgInvokesAgentOrch(t *testing.T) {
 	}
 }
 
-func TestDoWithoutArgUsesCurrent(t *testing.T) {
-	dir, _ := setupWnRoot(t) // has current task set
+func TestDoWithoutArgUsesCurrent(t *testing.T) {
+	dir, _ := setupWnRoot(t) // has current task set
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	// wn do with no arg should use current task and reach agent-orch. Fails before running.
+	rootCmd.SetArgs([]string{"do"})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Error("wn do without full setup should fail")
+	}
+	if strings.Contains(err.Error(), "unknown command") || strings.Contains(err.Error(), "no current task") {
+		t.Errorf("wn do (no arg) should use current and reach agent-orch; got: %v", err)
+	}
+}
+
+func TestCleanupSetMergedReviewItemsDone_MarksDoneWhenBranchMerged(t *testing.T) {
+	dir := t.TempDir()
+	// Create git repo
+	execIn(t, dir, "git", "init")
+	writeFile(t, filepath.Join(dir, "readme"), "x")
+	execIn(t, dir, "git", "add", "readme")
+	execIn(t, dir, "git", "commit", "-m", "init")
+	def, _ := wn.DefaultBranch(dir)
+
+	// Init wn in same dir
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	item := &wn.Item{
+		ID:          "abc123",
+		Description: "feature task",
+		Created:     now,
+		Updated:     now,
+		ReviewReady: true,
+		Notes:       []wn.Note{{Name: "branch", Created: now, Body: "wn-abc-feature"}},
+		Log:         []wn.LogEntry{{At: now, Kind: "created"}},
+	}
+	if err := store.Put(item); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create feature branch, commit, merge to main
+	execIn(t, dir, "git", "checkout", "-b", "wn-abc-feature")
+	writeFile(t, filepath.Join(dir, "feature.txt"), "feature")
+	execIn(t, dir, "git", "add", "feature.txt")
+	execIn(t, dir, "git", "commit", "-m", "add feature")
+	execIn(t, dir, "git", "checkout", def)
+	execIn(t, dir, "git", "merge", "wn-abc-feature", "-m", "merge")
+
 	cwd, _ := os.Getwd()
 	if err := os.Chdir(dir); err != nil {
 		t.Fatalf("Chdir: %v", err)
 	}
 	defer func() { _ = os.Chdir(cwd) }()
 
-	// wn do with no arg should use current task and reach agent-orch. Fails before running.
-	rootCmd.SetArgs([]string{"do"})
-	err := rootCmd.Execute()
-	if err == nil {
-		t.Error("wn do without full setup should fail")
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"cleanup", "set-merged-review-items-done"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("cleanup set-merged-review-items-done: %v", err)
+		}
+	})
+	if !strings.Contains(out, "marked abc123") {
+		t.Errorf("cleanup set-merged-review-items-done output should contain 'marked abc123'; got %q", out)
 	}
-	if strings.Contains(err.Error(), "unknown command") || strings.Contains(err.Error(), "no current task") {
-		t.Errorf("wn do (no arg) should use current and reach agent-orch; got: %v", err)
+	got, err := store.Get("abc123")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !got.Done {
+		t.Error("item should be done after mark-merged")
+	}
+	if got.ReviewReady {
+		t.Error("item should not be review-ready after marked done")
+	}
+	idx := got.NoteIndexByName(wn.NoteNameMergedInto)
+	if idx < 0 || got.Notes[idx].Body != def {
+		t.Errorf("merged-into note = %v, want body %q", got.Notes, def)
+	}
+	cmd := exec.Command("git", "rev-parse", "wn-abc-feature")
+	cmd.Dir = dir
+	tip, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git rev-parse: %v", err)
+	}
+	var mergedLog *wn.LogEntry
+	for i := range got.Log {
+		if got.Log[i].Kind == "merged" {
+			mergedLog = &got.Log[i]
+		}
+	}
+	if mergedLog == nil || mergedLog.Msg != "merge-base "+strings.TrimSpace(string(tip)) {
+		t.Errorf("merged log entry = %+v, want Msg %q", mergedLog, "merge-base "+strings.TrimSpace(string(tip)))
 	}
 }
 
-func TestCleanupSetMergedReviewItemsDone_MarksDoneWhenBranchMerged(t *testing.T) {
+func TestCleanupSetMergedReviewItemsDone_DryRunReportsMergeWithoutWriting(t *testing.T) {
 	dir := t.TempDir()
-	// Create git repo
 	execIn(t, dir, "git", "init")
 	writeFile(t, filepath.Join(dir, "readme"), "x")
 	execIn(t, dir, "git", "add", "readme")
 	execIn(t, dir, "git", "commit", "-m", "init")
 	def, _ := wn.DefaultBranch(dir)
-
-	// Init wn in same dir
 	if err := wn.InitRoot(dir); err != nil {
 		t.Fatalf("InitRoot: %v", err)
 	}
@@ -3237,8 +6370,6 @@ func TestCleanupSetMergedReviewItemsDone_MarksDoneWhenBranchMerged(t *testing.T)
 	if err := store.Put(item); err != nil {
 		t.Fatal(err)
 	}
-
-	// Create feature branch, commit, merge to main
 	execIn(t, dir, "git", "checkout", "-b", "wn-abc-feature")
 	writeFile(t, filepath.Join(dir, "feature.txt"), "feature")
 	execIn(t, dir, "git", "add", "feature.txt")
@@ -3253,23 +6384,21 @@ func TestCleanupSetMergedReviewItemsDone_MarksDoneWhenBranchMerged(t *testing.T)
 	defer func() { _ = os.Chdir(cwd) }()
 
 	out := captureStdout(t, func() {
-		rootCmd.SetArgs([]string{"cleanup", "set-merged-review-items-done"})
+		rootCmd.SetArgs([]string{"cleanup", "set-merged-review-items-done", "--dry-run"})
 		if err := rootCmd.Execute(); err != nil {
-			t.Errorf("cleanup set-merged-review-items-done: %v", err)
+			t.Errorf("cleanup set-merged-review-items-done --dry-run: %v", err)
 		}
 	})
-	if !strings.Contains(out, "marked abc123") {
-		t.Errorf("cleanup set-merged-review-items-done output should contain 'marked abc123'; got %q", out)
+	resetCleanupMergedFlags()
+	if !strings.Contains(out, "would mark abc123") || !strings.Contains(out, "merged into "+def+", merge base ") {
+		t.Errorf("dry-run output should report merge ref and base; got %q", out)
 	}
 	got, err := store.Get("abc123")
 	if err != nil {
 		t.Fatalf("Get: %v", err)
 	}
-	if !got.Done {
-		t.Error("item should be done after mark-merged")
-	}
-	if got.ReviewReady {
-		t.Error("item should not be review-ready after marked done")
+	if got.Done || got.NoteIndexByName(wn.NoteNameMergedInto) >= 0 || len(got.Log) != 1 {
+		t.Errorf("dry-run should not modify item; got Done=%v Notes=%v Log=%v", got.Done, got.Notes, got.Log)
 	}
 }
 
@@ -3360,6 +6489,197 @@ func TestCleanupSetMergedReviewItemsDone_BranchDeletedUsesCommitNote(t *testing.
 	}
 }
 
+func TestCleanupSetMergedReviewItemsDone_TagAndIDFilter(t *testing.T) {
+	dir := t.TempDir()
+	execIn(t, dir, "git", "init")
+	writeFile(t, filepath.Join(dir, "readme"), "x")
+	execIn(t, dir, "git", "add", "readme")
+	execIn(t, dir, "git", "commit", "-m", "init")
+	def, _ := wn.DefaultBranch(dir)
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "aaa111", Description: "team a", Tags: []string{"team-a"}, Created: now, Updated: now, ReviewReady: true,
+			Notes: []wn.Note{{Name: "branch", Created: now, Body: "wn-aaa"}}},
+		{ID: "bbb222", Description: "team b", Tags: []string{"team-b"}, Created: now, Updated: now, ReviewReady: true,
+			Notes: []wn.Note{{Name: "branch", Created: now, Body: "wn-bbb"}}},
+		{ID: "ccc333", Description: "not review-ready", Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, b := range []string{"wn-aaa", "wn-bbb"} {
+		execIn(t, dir, "git", "checkout", "-b", b)
+		writeFile(t, filepath.Join(dir, b+".txt"), b)
+		execIn(t, dir, "git", "add", b+".txt")
+		execIn(t, dir, "git", "commit", "-m", b)
+		execIn(t, dir, "git", "checkout", def)
+		execIn(t, dir, "git", "merge", b, "-m", "merge "+b)
+	}
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetCleanupMergedFlags()
+
+	resetCleanupMergedFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"cleanup", "set-merged-review-items-done", "--tag", "team-a"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("cleanup set-merged-review-items-done --tag: %v", err)
+		}
+	})
+	if !strings.Contains(out, "marked aaa111") || strings.Contains(out, "bbb222") {
+		t.Errorf("--tag team-a should only check aaa111; got %q", out)
+	}
+	if got, _ := store.Get("bbb222"); got.Done {
+		t.Error("bbb222 should not be marked when filtering by team-a")
+	}
+
+	resetCleanupMergedFlags()
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"cleanup", "set-merged-review-items-done", "--id", "bbb222"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("cleanup set-merged-review-items-done --id: %v", err)
+		}
+	})
+	if !strings.Contains(out, "marked bbb222") {
+		t.Errorf("--id bbb222 should mark bbb222; got %q", out)
+	}
+
+	resetCleanupMergedFlags()
+	rootCmd.SetArgs([]string{"cleanup", "set-merged-review-items-done", "--id", "ccc333"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "not review-ready") {
+		t.Errorf("--id on non-review-ready item want error, got %v", err)
+	}
+}
+
+func TestCleanupSetMergedReviewItemsDone_RemoteBranchAndSquashAmbiguous(t *testing.T) {
+	dir := t.TempDir()
+	execIn(t, dir, "git", "init")
+	writeFile(t, filepath.Join(dir, "readme"), "x")
+	execIn(t, dir, "git", "add", "readme")
+	execIn(t, dir, "git", "commit", "-m", "init")
+	def, _ := wn.DefaultBranch(dir)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	execIn(t, dir, "git", "init", "--bare", remote)
+	execIn(t, dir, "git", "remote", "add", "origin", remote)
+	if err := wn.InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*wn.Item{
+		{ID: "aaa111", Description: "remote only", Created: now, Updated: now, ReviewReady: true,
+			Notes: []wn.Note{{Name: "branch", Created: now, Body: "wn-remote"}}},
+		{ID: "bbb222", Description: "squashed", Created: now, Updated: now, ReviewReady: true,
+			Notes: []wn.Note{
+				{Name: "branch", Created: now, Body: "wn-squash"},
+				{Name: wn.NoteNamePRURL, Created: now, Body: "https://example.com/pr/1"},
+			}},
+		{ID: "ccc333", Description: "pushed squash", Created: now, Updated: now, ReviewReady: true,
+			Notes: []wn.Note{{Name: "branch", Created: now, Body: "wn-pushed"}}},
+		{ID: "ddd444", Description: "remote deleted", Created: now, Updated: now, ReviewReady: true,
+			Notes: []wn.Note{{Name: "branch", Created: now, Body: "wn-gone"}}},
+		{ID: "eee555", Description: "local only", Created: now, Updated: now, ReviewReady: true,
+			Notes: []wn.Note{{Name: "branch", Created: now, Body: "wn-local"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// twoCommits commits two files on a new branch, then returns to the default branch.
+	twoCommits := func(branch string) {
+		execIn(t, dir, "git", "checkout", "-b", branch)
+		for _, f := range []string{branch + "-1.txt", branch + "-2.txt"} {
+			writeFile(t, filepath.Join(dir, f), f)
+			execIn(t, dir, "git", "add", f)
+			execIn(t, dir, "git", "commit", "-m", f)
+		}
+		execIn(t, dir, "git", "checkout", def)
+	}
+
+	// wn-remote: pushed to origin, merged locally, local branch deleted.
+	execIn(t, dir, "git", "checkout", "-b", "wn-remote")
+	writeFile(t, filepath.Join(dir, "remote.txt"), "remote")
+	execIn(t, dir, "git", "add", "remote.txt")
+	execIn(t, dir, "git", "commit", "-m", "remote work")
+	execIn(t, dir, "git", "push", "origin", "wn-remote")
+	execIn(t, dir, "git", "checkout", def)
+	execIn(t, dir, "git", "merge", "wn-remote", "-m", "merge remote")
+	execIn(t, dir, "git", "branch", "-D", "wn-remote")
+
+	// wn-squash: two commits squash-merged into the default branch.
+	execIn(t, dir, "git", "checkout", "-b", "wn-squash")
+	writeFile(t, filepath.Join(dir, "s1.txt"), "s1")
+	execIn(t, dir, "git", "add", "s1.txt")
+	execIn(t, dir, "git", "commit", "-m", "s1")
+	writeFile(t, filepath.Join(dir, "s2.txt"), "s2")
+	execIn(t, dir, "git", "add", "s2.txt")
+	execIn(t, dir, "git", "commit", "-m", "s2")
+	execIn(t, dir, "git", "checkout", def)
+	execIn(t, dir, "git", "merge", "--squash", "wn-squash")
+	execIn(t, dir, "git", "commit", "-m", "squash")
+
+	// wn-pushed: two commits pushed and squash-merged, no pr-url note.
+	twoCommits("wn-pushed")
+	execIn(t, dir, "git", "push", "origin", "wn-pushed")
+	execIn(t, dir, "git", "merge", "--squash", "wn-pushed")
+	execIn(t, dir, "git", "commit", "-m", "squash pushed")
+
+	// wn-gone: pushed with upstream, squash-merged, then deleted on the remote.
+	twoCommits("wn-gone")
+	execIn(t, dir, "git", "push", "-u", "origin", "wn-gone")
+	execIn(t, dir, "git", "merge", "--squash", "wn-gone")
+	execIn(t, dir, "git", "commit", "-m", "squash gone")
+	execIn(t, dir, "git", "push", "origin", "--delete", "wn-gone")
+
+	// wn-local: never pushed and not merged.
+	twoCommits("wn-local")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer resetCleanupMergedFlags()
+
+	resetCleanupMergedFlags()
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"cleanup", "set-merged-review-items-done", "--remote", "origin"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("cleanup set-merged-review-items-done: %v", err)
+		}
+	})
+	if !strings.Contains(out, "marked aaa111") {
+		t.Errorf("remote-only merged branch should be marked; got %q", out)
+	}
+	if !strings.Contains(out, "review bbb222") {
+		t.Errorf("squash-merged branch with PR note should be reported for review; got %q", out)
+	}
+	if got, _ := store.Get("bbb222"); got.Done {
+		t.Error("squash-ambiguous item should not be marked done")
+	}
+	if !strings.Contains(out, "review ccc333") || !strings.Contains(out, "review ddd444") {
+		t.Errorf("two-commit squash of a pushed or remote-deleted branch should be reported for review; got %q", out)
+	}
+	if !strings.Contains(out, "skip eee555") {
+		t.Errorf("unmerged local-only branch should be skipped as not merged; got %q", out)
+	}
+}
+
 func TestMerge_noBranchNote(t *testing.T) {
 	dir := t.TempDir()
 	if err := wn.InitRoot(dir); err != nil {
@@ -4321,7 +7641,7 @@ func TestArchiveCmd_includesPromptDepsInArchive(t *testing.T) {
 	archivePath := filepath.Join(dir, ".wn", "archive", parentID+".json")
 	root2 := t.TempDir()
 	store2, _ := wn.NewFileStore(root2)
-	if err := wn.ImportAppend(store2, archivePath); err != nil {
+	if _, err := wn.ImportAppend(store2, archivePath); err != nil {
 		t.Fatalf("ImportAppend: %v", err)
 	}
 	if _, err := store2.Get(parentID); err != nil {
@@ -4331,3 +7651,180 @@ func TestArchiveCmd_includesPromptDepsInArchive(t *testing.T) {
 		t.Error("prompt dep not found in archive")
 	}
 }
+
+// TestDo_invalidCommitTplFailsFast verifies that a bad --commit-tpl is rejected before any item is claimed.
+func TestDo_invalidCommitTplFailsFast(t *testing.T) {
+	dir, itemID := setupGitWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(cwd)
+		resetDoFlags()
+	}()
+
+	writeRunnerSettings(t, dir, "echo-runner", "echo hello")
+	rootCmd.SetArgs([]string{"do", itemID, "--commit-tpl", "{{.NoSuchField}}"})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("wn do with invalid --commit-tpl should fail")
+	}
+	if !strings.Contains(err.Error(), "commit template") {
+		t.Errorf("want commit template error; got: %v", err)
+	}
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := store.Get(itemID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !item.InProgressUntil.IsZero() {
+		t.Error("item should not be claimed when commit template is invalid")
+	}
+}
+
+// TestDo_prCmdRequiresPush verifies that --pr-cmd without --push is rejected.
+func TestDo_prCmdRequiresPush(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(cwd)
+		resetDoFlags()
+	}()
+
+	rootCmd.SetArgs([]string{"do", itemID, "--pr-cmd", "gh pr create --fill"})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("wn do --pr-cmd without --push should fail")
+	}
+	if !strings.Contains(err.Error(), "--push") {
+		t.Errorf("want error mentioning --push; got: %v", err)
+	}
+}
+
+func TestShowWeb(t *testing.T) {
+	dir, itemID := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	t.Setenv("TMPDIR", t.TempDir())
+	resetShowFlags()
+	defer resetShowFlags()
+
+	t.Setenv("WN_BROWSER", "true")
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "--web", itemID})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	if !strings.HasPrefix(out, "Opened ") || !strings.HasSuffix(strings.TrimSpace(out), ".html") {
+		t.Errorf("show --web = %q, want Opened <path>.html", out)
+	}
+
+	// Without an opener the page is still written and its path printed.
+	t.Setenv("WN_BROWSER", "wn-no-such-opener")
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"show", "--web", itemID})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("Execute: %v", err)
+		}
+	})
+	data, err := os.ReadFile(strings.TrimSpace(out))
+	if err != nil {
+		t.Fatalf("show --web without opener should print the page path, got %q: %v", out, err)
+	}
+	if !strings.Contains(string(data), "["+itemID+"] first line") {
+		t.Errorf("page does not contain the item title:\n%s", data)
+	}
+
+	resetShowFlags()
+	rootCmd.SetArgs([]string{"show", "--web", "--json", itemID})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "--web") {
+		t.Errorf("show --web --json: err = %v, want conflict error", err)
+	}
+}
+
+func TestSettingsCheck(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	configDir := t.TempDir()
+	t.Setenv("WN_CONFIG_DIR", configDir)
+	defer func() { settingsCheck = false }()
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"settings", "--check"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("settings --check with no files: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Settings OK") {
+		t.Errorf("settings --check = %q, want Settings OK", out)
+	}
+
+	writeFile(t, filepath.Join(configDir, "settings.json"), `{"colour": "blue"}`)
+	writeFile(t, wn.ProjectSettingsPath(dir), `{"agent": {"poll": "soon"}}`)
+	var err error
+	out = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"settings", "--check"})
+		err = rootCmd.Execute()
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 problem") {
+		t.Errorf("settings --check err = %v, want 1 problem", err)
+	}
+	if !strings.Contains(out, "warning: colour: unknown key") || !strings.Contains(out, "error: agent.poll: invalid duration") {
+		t.Errorf("settings --check output = %q, want colour warning and agent.poll error", out)
+	}
+}
+
+// TestSyncBack verifies wn sync-back --dry-run output and that a real sync needs a token.
+func TestSyncBack(t *testing.T) {
+	dir, _ := setupWnRoot(t)
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	defer func() { syncBackDryRun, syncBackComment = false, false }()
+	store, err := wn.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	it := &wn.Item{ID: "gh1111", Description: "from github", ExternalID: "o/r#12", Done: true, DoneMessage: "shipped", Created: now, Updated: now, Log: []wn.LogEntry{{At: now, Kind: "done", Msg: "shipped"}}}
+	if err := store.Put(it); err != nil {
+		t.Fatal(err)
+	}
+
+	syncBackDryRun, syncBackComment = false, false
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"sync-back", "--dry-run", "--comment"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("sync-back --dry-run: %v", err)
+		}
+	})
+	if want := "would close o/r#12 as completed (gh1111, with comment)\n"; out != want {
+		t.Errorf("sync-back --dry-run = %q, want %q", out, want)
+	}
+
+	syncBackDryRun, syncBackComment = false, false
+	rootCmd.SetArgs([]string{"sync-back"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "GH_TOKEN") {
+		t.Errorf("sync-back without a token = %v, want a GH_TOKEN error", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -350,7 +351,7 @@ func (m tuiModel) handleKey(msg tea.KeyMsg) (tuiModel, tea.Cmd) {
 	case "D":
 		if it := m.selected(); it != nil {
 			id := it.ID
-			if err := m.store.Delete(id); err != nil {
+			if err := wn.TrashItem(m.store, id); err != nil {
 				m.err = err
 			} else {
 				_ = wn.WithMetaLock(m.root, func(meta wn.Meta) (wn.Meta, error) {
@@ -359,7 +360,7 @@ func (m tuiModel) handleKey(msg tea.KeyMsg) (tuiModel, tea.Cmd) {
 					}
 					return meta, nil
 				})
-				m.msg = "deleted: " + id
+				m.msg = "deleted: " + id + " (wn trash restore " + id + " to undo)"
 				return m, m.cmdLoad()
 			}
 		}
@@ -480,22 +481,25 @@ func (m tuiModel) openEditor(action tuiEditorAction, id string) (tuiModel, tea.C
 	}
 	_ = f.Close()
 
-	edStr := strings.TrimSpace(os.Getenv("EDITOR"))
-	if edStr == "" {
+	parts, err := wn.EditorCommand()
+	if err != nil {
 		_ = os.Remove(tmpFile)
-		m.err = wn.ErrEditorUnset
+		m.err = err
 		return m, nil
 	}
-	parts := tuiSplitArgs(edStr)
 	args := append(parts[1:], tmpFile)
 	cmd := exec.Command(parts[0], args...)
 	return m, tea.ExecProcess(cmd, func(execErr error) tea.Msg {
-		return tuiEditorMsg{action: action, tmpFile: tmpFile, id: id, err: execErr}
+		return tuiEditorMsg{action: action, tmpFile: tmpFile, id: id, err: wn.EditorRunError(execErr)}
 	})
 }
 
 func (m tuiModel) handleEditor(msg tuiEditorMsg) (tuiModel, tea.Cmd) {
 	defer os.Remove(msg.tmpFile)
+	if errors.Is(msg.err, wn.ErrEditorAborted) {
+		m.msg = "aborted, no changes"
+		return m, nil
+	}
 	if msg.err != nil {
 		m.err = msg.err
 		return m, nil
@@ -539,7 +543,7 @@ func (m tuiModel) handleEditor(msg tuiEditorMsg) (tuiModel, tea.Cmd) {
 		err := m.store.UpdateItem(msg.id, func(it *wn.Item) (*wn.Item, error) {
 			it.Description = content
 			it.Updated = time.Now().UTC()
-			it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "updated"})
+			it.Log = append(it.Log, wn.LogEntry{At: it.Updated, Kind: "updated", Msg: wn.EditSourceTUI})
 			return it, nil
 		})
 		if err != nil {
@@ -757,14 +761,14 @@ func tuiItemDetail(item *wn.Item, blocked bool, store wn.Store) string {
 	if len(item.Notes) > 0 {
 		b.WriteString("\nnotes:\n")
 		for _, n := range item.Notes {
-			b.WriteString(fmt.Sprintf("  %-20s  %s\n  %s\n", n.Name, n.Created.Format(timeFmt), n.Body))
+			b.WriteString(fmt.Sprintf("  %-20s  %s\n  %s\n", n.Name, wn.DisplayTime(n.Created).Format(timeFmt), n.Body))
 		}
 	}
 
 	if len(item.Log) > 0 {
 		b.WriteString("\nlog:\n")
 		for _, e := range item.Log {
-			line := fmt.Sprintf("  %s  %s", e.At.Format(timeFmt), e.Kind)
+			line := fmt.Sprintf("  %s  %s", wn.DisplayTime(e.At).Format(timeFmt), e.Kind)
 			if e.Msg != "" {
 				line += "  " + e.Msg
 			}
@@ -774,31 +778,6 @@ func tuiItemDetail(item *wn.Item, blocked bool, store wn.Store) string {
 	return b.String()
 }
 
-// tuiSplitArgs splits an editor command string, handling simple quoting.
-func tuiSplitArgs(s string) []string {
-	var parts []string
-	var b strings.Builder
-	quote := false
-	for _, r := range s {
-		if r == '"' || r == '\'' {
-			quote = !quote
-			continue
-		}
-		if !quote && (r == ' ' || r == '\t') {
-			if b.Len() > 0 {
-				parts = append(parts, b.String())
-				b.Reset()
-			}
-			continue
-		}
-		b.WriteRune(r)
-	}
-	if b.Len() > 0 {
-		parts = append(parts, b.String())
-	}
-	return parts
-}
-
 var tuiCmd = &cobra.Command{
 	Use:   "tui",
 	Short: "Interactive TUI for managing work items",
@@ -811,7 +790,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	store, err := wn.NewFileStore(root)
+	store, err := wn.OpenStore(root)
 	if err != nil {
 		return err
 	}
@@ -10,34 +10,6 @@ import (
 	"github.com/kjhaber/wn/internal/wn"
 )
 
-// --- tuiSplitArgs ---
-
-func TestTUISplitArgs(t *testing.T) {
-	tests := []struct {
-		in   string
-		want []string
-	}{
-		{"vim", []string{"vim"}},
-		{"vim -f", []string{"vim", "-f"}},
-		{"code --wait", []string{"code", "--wait"}},
-		{`"my editor" --flag`, []string{"my editor", "--flag"}},
-		{"  vim  ", []string{"vim"}},
-		{"", nil},
-	}
-	for _, tc := range tests {
-		got := tuiSplitArgs(tc.in)
-		if len(got) != len(tc.want) {
-			t.Errorf("tuiSplitArgs(%q) = %v, want %v", tc.in, got, tc.want)
-			continue
-		}
-		for i := range got {
-			if got[i] != tc.want[i] {
-				t.Errorf("tuiSplitArgs(%q)[%d] = %q, want %q", tc.in, i, got[i], tc.want[i])
-			}
-		}
-	}
-}
-
 // --- tuiItemDetail ---
 
 func TestTUIItemDetail_BasicFields(t *testing.T) {
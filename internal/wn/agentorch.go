@@ -12,6 +12,7 @@ import (
 	"strings"
 	"text/template"
 	"time"
+	"unicode/utf8"
 )
 
 const branchSlugMaxLen = 30
@@ -38,16 +39,20 @@ func BranchSlug(description string) string {
 
 // ClaimNextItem atomically selects the next available item (by UndoneItems, optional tag filter, then TopoOrder),
 // sets it as current under the meta lock, and claims it for the given duration.
-// Selection: dependencies are honored (prerequisites first); within each tier, Order field is the tiebreaker (lower = earlier).
-// If tag is non-empty, only items that have that tag are considered. claimBy is optional (e.g. worker id).
+// Selection: dependencies are honored (prerequisites first); within each tier, Order field is the tiebreaker (lower = earlier),
+// or the sort setting when next.uses_sort is set in root's settings; with next.strict_order, ready items go by
+// Order then id (see NextOrdering).
+// If tag is non-empty, only items that have that tag are considered; items with any tag in exclude are skipped.
+// claimBy is optional (e.g. worker id).
 // Returns the claimed item, or nil if the queue is empty.
-func ClaimNextItem(store Store, root string, claimFor time.Duration, claimBy string, tag string) (*Item, error) {
+func ClaimNextItem(store Store, root string, claimFor time.Duration, claimBy string, tag string, exclude []string) (*Item, error) {
 	undone, err := UndoneItems(store)
 	if err != nil {
 		return nil, err
 	}
-	undone = FilterByTag(undone, tag)
-	ordered, acyclic := TopoOrder(undone)
+	undone = ExcludeByTags(FilterByTag(undone, tag), exclude)
+	settings, _ := ReadSettingsInRoot(root)
+	ordered, acyclic := NextOrdering(settings, settings.Next.StrictOrder)(undone)
 	if !acyclic || len(ordered) == 0 {
 		return nil, nil
 	}
@@ -100,23 +105,29 @@ func ClaimItem(store Store, root string, itemID string, claimFor time.Duration,
 
 // AgentOrchOpts configures the agent orchestrator loop.
 type AgentOrchOpts struct {
-	Root          string        // project root (contains .wn)
-	ClaimFor      time.Duration // claim duration per item
-	ClaimBy       string        // optional worker id
-	Delay         time.Duration // delay between runs (after each item)
-	Poll          time.Duration // poll interval when queue empty
-	MaxTasks      int           // max tasks to process before exiting (0 = indefinite)
-	WorkID        string        // if non-empty, run only this item then exit (use with --work-id or --current)
-	AgentCmd      string        // command template, e.g. `cursor agent --print "{{.Prompt}}"`
-	PromptTpl     string        // prompt template, e.g. "{{.Description}}"
-	WorktreesBase string        // base path for worktrees
-	LeaveWorktree bool          // if true, leave worktree after run; else remove
-	DefaultBranch string        // override default branch (empty = detect)
-	BranchPrefix  string        // prefix for generated branch names (e.g. "keith/"); not applied when reusing branch note
-	Tag           string        // if non-empty, only consider items that have this tag
-	FailIfEmpty   bool          // if true, return error immediately when queue is empty instead of polling
-	Async         bool          // if true, dispatch cmd without waiting; skip commit/release (for wn launch)
-	Audit         io.Writer     // timestamped command log (can be nil)
+	Root           string        // project root (contains .wn)
+	ClaimFor       time.Duration // claim duration per item
+	ClaimBy        string        // optional worker id
+	Delay          time.Duration // delay between runs (after each item)
+	Poll           time.Duration // poll interval when queue empty
+	MaxTasks       int           // max tasks to process before exiting (0 = indefinite)
+	WorkID         string        // if non-empty, run only this item then exit (use with --work-id or --current)
+	AgentCmd       string        // command template, e.g. `cursor agent --print "{{.Prompt}}"`
+	PromptTpl      string        // prompt template, e.g. "{{.Description}}"
+	WorktreesBase  string        // base path for worktrees
+	LeaveWorktree  bool          // if true, leave worktree after run; else remove
+	DefaultBranch  string        // override default branch (empty = detect)
+	BranchPrefix   string        // prefix for generated branch names (e.g. "keith/"); not applied when reusing branch note
+	Tag            string        // if non-empty, only consider items that have this tag
+	ExcludeTags    []string      // skip items that have any of these tags
+	FailIfEmpty    bool          // if true, return error immediately when queue is empty instead of polling
+	Async          bool          // if true, dispatch cmd without waiting; skip commit/release (for wn launch)
+	CommitTpl      string        // commit message template (empty = DefaultCommitTpl)
+	Push           bool          // if true, push the branch to origin after commit
+	PRCmd          string        // command template run after a successful push, e.g. `gh pr create --fill --head {{.Branch}}`
+	SetupCmd       string        // command template run in the worktree before the agent, e.g. `npm ci && cp ../.env .`
+	PromptMaxChars int           // warn in the audit log when the expanded prompt is longer (0 = no limit)
+	Audit          io.Writer     // timestamped command log (can be nil)
 }
 
 // PromptData is passed to the prompt template.
@@ -126,6 +137,16 @@ type PromptData struct {
 	FirstLine   string
 	Worktree    string
 	Branch      string
+	Notes       string // the item's notes as "## <name>\n<body>" sections, blank-line separated
+}
+
+// promptNotes formats an item's notes for the prompt template, in item order.
+func promptNotes(notes []Note) string {
+	parts := make([]string, len(notes))
+	for i, n := range notes {
+		parts[i] = "## " + n.Name + "\n" + n.Body
+	}
+	return strings.Join(parts, "\n\n")
 }
 
 // ExpandPromptTemplate executes the prompt template with item and optional worktree/branch.
@@ -139,6 +160,7 @@ func ExpandPromptTemplate(tpl string, item *Item, worktree, branch string) (stri
 		FirstLine:   FirstLine(item.Description),
 		Worktree:    worktree,
 		Branch:      branch,
+		Notes:       promptNotes(item.Notes),
 	}
 	tm, err := template.New("prompt").Parse(tpl)
 	if err != nil {
@@ -151,6 +173,100 @@ func ExpandPromptTemplate(tpl string, item *Item, worktree, branch string) (stri
 	return buf.String(), nil
 }
 
+// DefaultHoldTag is the tag that keeps agents off an item when agent.hold_tag is not set.
+const DefaultHoldTag = "hold"
+
+// AgentExcludeTags returns the tags agent-orch skips when claiming the next item: the hold tag
+// (agent.hold_tag, else DefaultHoldTag) plus next.exclude_tags. Applied after the next.tag / --tag
+// include filter.
+func AgentExcludeTags(settings Settings) []string {
+	hold := settings.Agent.HoldTag
+	if hold == "" {
+		hold = DefaultHoldTag
+	}
+	return NextExcludeTags(settings, []string{hold})
+}
+
+// DefaultCommitTpl is the commit message template used when none is configured.
+const DefaultCommitTpl = "wn {{.ItemID}}: {{.FirstLine}}"
+
+// CommitData is passed to the commit message template.
+type CommitData struct {
+	ItemID      string
+	FirstLine   string
+	Description string
+	Branch      string
+}
+
+// ExpandCommitTemplate executes the commit message template for item on branch.
+// If tpl is empty, DefaultCommitTpl is used.
+func ExpandCommitTemplate(tpl string, item *Item, branch string) (string, error) {
+	if tpl == "" {
+		tpl = DefaultCommitTpl
+	}
+	data := CommitData{
+		ItemID:      item.ID,
+		FirstLine:   FirstLine(item.Description),
+		Description: item.Description,
+		Branch:      branch,
+	}
+	tm, err := template.New("commit").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tm.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// ValidateCommitTemplate returns an error if tpl does not parse or fails to execute against
+// sample data, so a typo in a field name fails before any item is claimed.
+func ValidateCommitTemplate(tpl string) error {
+	sample := &Item{ID: "abc123", Description: "sample"}
+	msg, err := ExpandCommitTemplate(tpl, sample, "wn-abc123-sample")
+	if err != nil {
+		return err
+	}
+	if msg == "" {
+		return fmt.Errorf("template produces an empty commit message")
+	}
+	return nil
+}
+
+// prURLRe matches an http(s) URL in PR command output.
+var prURLRe = regexp.MustCompile(`https?://\S+`)
+
+// ExpandPRCommandTemplate executes the PR command template for item on branch.
+// FirstLine is escaped for double-quoted context; ItemID and Branch are escaped as single-quoted
+// shell words, as in ExpandCommandTemplate.
+func ExpandPRCommandTemplate(tpl string, item *Item, branch string) (string, error) {
+	data := struct {
+		ItemID    string
+		Branch    string
+		FirstLine string
+	}{shellEscapeForShWord(item.ID), shellEscapeForShWord(branch), shellEscapeForDoubleQuoted(FirstLine(item.Description))}
+	tm, err := template.New("pr").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tm.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// prURLFromOutput returns the last URL printed in out (e.g. by gh pr create), or "" if none.
+func prURLFromOutput(out string) string {
+	urls := prURLRe.FindAllString(out, -1)
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[len(urls)-1]
+}
+
 // shellEscapeForDoubleQuoted escapes a string for safe embedding inside a
 // double-quoted string in sh. Escapes \, ", `, and $ so the result can be used
 // in templates like `cursor agent "{{.Prompt}}"` without breaking sh -c.
@@ -186,6 +302,8 @@ func resumeFlag(sessionID string) string {
 // commands when the result is passed to sh -c.
 // sessionID is the Claude Code session ID for resume support (from the "claude-session" note);
 // ResumeFlag is "--resume <sessionID>" if sessionID is non-empty, else "".
+// {{env "VAR"}} inserts an environment variable as a single-quoted shell word (empty if unset).
+// Expansion happens in wn before sh -c runs, so the shell never re-expands the value.
 func ExpandCommandTemplate(tpl string, prompt, itemID, worktree, branch, sessionID string) (string, error) {
 	escapedPrompt := shellEscapeForDoubleQuoted(prompt)
 	data := struct {
@@ -196,7 +314,7 @@ func ExpandCommandTemplate(tpl string, prompt, itemID, worktree, branch, session
 		ResumeFlag string
 		SessionID  string
 	}{escapedPrompt, shellEscapeForShWord(itemID), shellEscapeForShWord(worktree), shellEscapeForShWord(branch), resumeFlag(sessionID), sessionID}
-	tm, err := template.New("cmd").Parse(tpl)
+	tm, err := template.New("cmd").Funcs(commandTemplateFuncs).Parse(tpl)
 	if err != nil {
 		return "", err
 	}
@@ -207,6 +325,11 @@ func ExpandCommandTemplate(tpl string, prompt, itemID, worktree, branch, session
 	return buf.String(), nil
 }
 
+// commandTemplateFuncs are the functions available in command templates.
+var commandTemplateFuncs = template.FuncMap{
+	"env": func(name string) string { return shellEscapeForShWord(os.Getenv(name)) },
+}
+
 func auditLogAgent(w io.Writer, mainRoot, worktreePath, expandedCmd string) {
 	if w == nil {
 		return
@@ -261,6 +384,24 @@ func addItemNote(store Store, itemID, name, body string) error {
 	})
 }
 
+// recordAgentStart sets the "agent-cmd" note to the agent command template and logs
+// "agent_started". The template is stored rather than the expanded command so the full prompt
+// is not copied into the item.
+func recordAgentStart(store Store, itemID, agentCmd string) error {
+	now := time.Now().UTC()
+	return store.UpdateItem(itemID, func(it *Item) (*Item, error) {
+		body := strings.TrimSpace(agentCmd)
+		if idx := it.NoteIndexByName("agent-cmd"); idx >= 0 {
+			it.Notes[idx].Body = body
+		} else {
+			it.Notes = append(it.Notes, Note{Name: "agent-cmd", Created: now, Body: body})
+		}
+		it.Updated = now
+		it.Log = append(it.Log, LogEntry{At: now, Kind: "agent_started"})
+		return it, nil
+	})
+}
+
 // releaseItemClaim clears in-progress and sets review-ready (same as wn release).
 func releaseItemClaim(store Store, itemID string) error {
 	now := time.Now().UTC()
@@ -339,6 +480,69 @@ func itemSessionID(item *Item) string {
 	return strings.TrimSpace(item.Notes[idx].Body)
 }
 
+// pushAndOpenPR pushes the item's branch to origin and, when opts.PRCmd is set, runs it in the
+// worktree. A URL printed on the command's stdout is recorded as the item's pr-url note.
+// Failures are written to the audit log; the caller still releases the item.
+func pushAndOpenPR(store Store, opts AgentOrchOpts, item *Item, worktreePath, branchName string) {
+	if err := PushBranch(worktreePath, "origin", branchName, opts.Audit); err != nil {
+		auditLog(opts.Audit, "push failed: %v", err)
+		return
+	}
+	if opts.PRCmd == "" {
+		return
+	}
+	expanded, err := ExpandPRCommandTemplate(opts.PRCmd, item, branchName)
+	if err != nil {
+		auditLog(opts.Audit, "pr command template failed: %v", err)
+		return
+	}
+	auditLog(opts.Audit, "exec (Dir=%s): %s", worktreePath, expanded)
+	cmd := exec.Command("sh", "-c", expanded)
+	cmd.Dir = worktreePath
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	_, _ = os.Stdout.Write(out)
+	if err != nil {
+		auditLog(opts.Audit, "pr command failed: %v", err)
+		return
+	}
+	if url := prURLFromOutput(string(out)); url != "" {
+		if err := addItemNote(store, item.ID, NoteNamePRURL, url); err != nil {
+			auditLog(opts.Audit, "add pr-url note failed: %v", err)
+		}
+	}
+}
+
+// runSetupCommand runs opts.SetupCmd in the worktree with WN_ROOT set, using the same template
+// fields as the agent command. On failure the item's claim is cleared (it returns to the queue)
+// and a setup_failed log entry records the error.
+func runSetupCommand(store Store, opts AgentOrchOpts, item *Item, prompt, mainRoot, worktreePath, branchName, sessionID string) error {
+	expanded, err := ExpandCommandTemplate(opts.SetupCmd, prompt, item.ID, worktreePath, branchName, sessionID)
+	if err == nil {
+		auditLog(opts.Audit, "setup (Dir=%s WN_ROOT=%s): %s", worktreePath, mainRoot, expanded)
+		cmd := exec.Command("sh", "-c", expanded)
+		cmd.Dir = worktreePath
+		cmd.Env = append(os.Environ(), "WN_ROOT="+mainRoot)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+	}
+	if err == nil {
+		return nil
+	}
+	err = fmt.Errorf("setup command for %s: %w", item.ID, err)
+	auditLog(opts.Audit, "%v", err)
+	now := time.Now().UTC()
+	_ = store.UpdateItem(item.ID, func(it *Item) (*Item, error) {
+		it.InProgressUntil = time.Time{}
+		it.InProgressBy = ""
+		it.Updated = now
+		it.Log = append(it.Log, LogEntry{At: now, Kind: "setup_failed", Msg: err.Error()})
+		return it, nil
+	})
+	return err
+}
+
 // runOneItem runs the full flow for one item: worktree, note, subagent, commit, release, optional remove worktree.
 func runOneItem(store Store, opts AgentOrchOpts, item *Item, mainRoot, worktreesBase, mainDirname, promptTpl, agentCmd string) error {
 	worktreePath, branchName, err := SetupItemWorktree(store, opts.Root, item, worktreesBase, mainDirname, opts.BranchPrefix, opts.Audit)
@@ -352,11 +556,20 @@ func runOneItem(store Store, opts AgentOrchOpts, item *Item, mainRoot, worktrees
 		_ = releaseItemClaim(store, item.ID)
 		return fmt.Errorf("prompt template: %w", err)
 	}
+	if PromptTooLong(prompt, opts.PromptMaxChars) {
+		auditLog(opts.Audit, "warning: prompt for %s is %d characters (agent.prompt_max_chars %d)", item.ID, utf8.RuneCountInString(prompt), opts.PromptMaxChars)
+	}
 	expandedCmd, err := ExpandCommandTemplate(agentCmd, prompt, item.ID, worktreePath, branchName, sessionID)
 	if err != nil {
 		_ = releaseItemClaim(store, item.ID)
 		return fmt.Errorf("command template: %w", err)
 	}
+	if opts.SetupCmd != "" {
+		if err := runSetupCommand(store, opts, item, prompt, mainRoot, worktreePath, branchName, sessionID); err != nil {
+			return err
+		}
+	}
+	_ = recordAgentStart(store, item.ID, agentCmd)
 	auditLogAgent(opts.Audit, mainRoot, worktreePath, expandedCmd)
 	cmd := exec.Command("sh", "-c", expandedCmd)
 	cmd.Dir = worktreePath
@@ -373,12 +586,19 @@ func runOneItem(store Store, opts AgentOrchOpts, item *Item, mainRoot, worktrees
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	_ = cmd.Run() // ignore exit code; we release claim either way
-	commitMsg := "wn " + item.ID + ": " + FirstLine(item.Description)
+	commitMsg, err := ExpandCommitTemplate(opts.CommitTpl, item, branchName)
+	if err != nil {
+		auditLog(opts.Audit, "commit template failed, using default: %v", err)
+		commitMsg, _ = ExpandCommitTemplate(DefaultCommitTpl, item, branchName)
+	}
 	if err := CommitWorktreeChanges(worktreePath, commitMsg, opts.Audit); err != nil {
 		if opts.Audit != nil {
 			fmt.Fprintf(opts.Audit, "%s commit worktree changes failed: %v\n", time.Now().UTC().Format("2006-01-02 15:04:05"), err)
 		}
 	}
+	if opts.Push {
+		pushAndOpenPR(store, opts, item, worktreePath, branchName)
+	}
 	// Post-run: if item is now blocked (e.g. agent created prompt deps), clear claim only.
 	// Otherwise release normally (sets review-ready).
 	allItems, listErr := store.List()
@@ -399,7 +619,7 @@ func runOneItem(store Store, opts AgentOrchOpts, item *Item, mainRoot, worktrees
 
 // RunAgentOrch runs the orchestrator loop until ctx is cancelled, or runs a single item and exits if opts.WorkID is set.
 func RunAgentOrch(ctx context.Context, opts AgentOrchOpts) error {
-	store, err := NewFileStore(opts.Root)
+	store, err := OpenStore(opts.Root)
 	if err != nil {
 		return err
 	}
@@ -416,6 +636,24 @@ func RunAgentOrch(ctx context.Context, opts AgentOrchOpts) error {
 	if agentCmd == "" {
 		return fmt.Errorf("agent_cmd is required")
 	}
+	if !opts.Async {
+		if err := ValidateCommitTemplate(opts.CommitTpl); err != nil {
+			return fmt.Errorf("commit template: %w", err)
+		}
+	}
+	if opts.PRCmd != "" {
+		if !opts.Push {
+			return fmt.Errorf("pr command requires push")
+		}
+		if _, err := ExpandPRCommandTemplate(opts.PRCmd, &Item{}, ""); err != nil {
+			return fmt.Errorf("pr command template: %w", err)
+		}
+	}
+	if opts.SetupCmd != "" {
+		if _, err := ExpandCommandTemplate(opts.SetupCmd, "", "", "", "", ""); err != nil {
+			return fmt.Errorf("setup command template: %w", err)
+		}
+	}
 	if opts.DefaultBranch == "" {
 		if _, err = DefaultBranch(opts.Root); err != nil {
 			return fmt.Errorf("default branch: %w", err)
@@ -448,7 +686,7 @@ func RunAgentOrch(ctx context.Context, opts AgentOrchOpts) error {
 			return ctx.Err()
 		default:
 		}
-		item, err := ClaimNextItem(store, opts.Root, opts.ClaimFor, opts.ClaimBy, opts.Tag)
+		item, err := ClaimNextItem(store, opts.Root, opts.ClaimFor, opts.ClaimBy, opts.Tag, opts.ExcludeTags)
 		if err != nil {
 			return err
 		}
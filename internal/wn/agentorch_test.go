@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -40,7 +41,7 @@ func TestClaimNextItem_emptyReturnsNil(t *testing.T) {
 	if err != nil {
 		t.Fatalf("NewFileStore: %v", err)
 	}
-	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "")
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem: %v", err)
 	}
@@ -67,7 +68,7 @@ func TestClaimNextItem_claimsFirstUndone(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	got, err := ClaimNextItem(store, root, 30*time.Minute, "runner1", "")
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "runner1", "", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem: %v", err)
 	}
@@ -92,6 +93,76 @@ func TestClaimNextItem_claimsFirstUndone(t *testing.T) {
 	}
 }
 
+func TestClaimNextItem_usesSortSetting(t *testing.T) {
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "aaa111", Description: "older, higher priority", Order: orderVal(1), Created: now.Add(-time.Hour), Updated: now},
+		{ID: "bbb222", Description: "newer", Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "", nil)
+	if err != nil || got == nil {
+		t.Fatalf("ClaimNextItem = %v, %v", got, err)
+	}
+	if got.ID != "aaa111" {
+		t.Errorf("default ClaimNextItem = %s, want aaa111 (Order tiebreaker)", got.ID)
+	}
+	if err := store.UpdateItem("aaa111", func(it *Item) (*Item, error) {
+		it.InProgressUntil = time.Time{}
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(ProjectSettingsPath(root), []byte(`{"sort":"created:desc","next":{"uses_sort":true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "", nil)
+	if err != nil || got == nil {
+		t.Fatalf("ClaimNextItem = %v, %v", got, err)
+	}
+	if got.ID != "bbb222" {
+		t.Errorf("ClaimNextItem with next.uses_sort = %s, want bbb222 (created:desc)", got.ID)
+	}
+}
+
+func TestClaimNextItem_strictOrderSetting(t *testing.T) {
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "aaa111", Description: "newer", Created: now, Updated: now},
+		{ID: "bbb222", Description: "older", Created: now.Add(-time.Hour), Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(ProjectSettingsPath(root), []byte(`{"next":{"strict_order":true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "", nil)
+	if err != nil || got == nil {
+		t.Fatalf("ClaimNextItem = %v, %v", got, err)
+	}
+	if got.ID != "aaa111" {
+		t.Errorf("ClaimNextItem with next.strict_order = %s, want aaa111 (order, then id)", got.ID)
+	}
+}
+
 func TestClaimNextItem_skipsReviewReady(t *testing.T) {
 	root := t.TempDir()
 	store, err := NewFileStore(root)
@@ -111,7 +182,7 @@ func TestClaimNextItem_skipsReviewReady(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "")
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem: %v", err)
 	}
@@ -138,6 +209,96 @@ func TestExpandPromptTemplate(t *testing.T) {
 	}
 }
 
+func TestExpandPromptTemplate_notes(t *testing.T) {
+	item := &Item{ID: "abc123", Description: "Add feature", Notes: []Note{
+		{Name: "acceptance", Body: "tests pass"},
+		{Name: "link", Body: "https://example.com/spec"},
+	}}
+	got, err := ExpandPromptTemplate("{{.Description}}\n\n{{.Notes}}", item, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Add feature\n\n## acceptance\ntests pass\n\n## link\nhttps://example.com/spec"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got, err = ExpandPromptTemplate("{{.FirstLine}}{{with .Notes}}\n{{.}}{{end}}", &Item{ID: "x", Description: "No notes"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "No notes" {
+		t.Errorf("no notes: got %q", got)
+	}
+}
+
+func TestExpandCommitTemplate(t *testing.T) {
+	item := &Item{ID: "abc123", Description: "Add feature\nWith details"}
+	got, err := ExpandCommitTemplate("", item, "wn-abc123-add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "wn abc123: Add feature" {
+		t.Errorf("default template: got %q", got)
+	}
+	got, err = ExpandCommitTemplate("feat: {{.FirstLine}} ({{.ItemID}}, {{.Branch}})", item, "wn-abc123-add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "feat: Add feature (abc123, wn-abc123-add-feature)" {
+		t.Errorf("custom template: got %q", got)
+	}
+}
+
+func TestValidateCommitTemplate(t *testing.T) {
+	if err := ValidateCommitTemplate(""); err != nil {
+		t.Errorf("empty (default) template: %v", err)
+	}
+	if err := ValidateCommitTemplate("JIRA-1 {{.FirstLine}}"); err != nil {
+		t.Errorf("valid template: %v", err)
+	}
+	if err := ValidateCommitTemplate("{{.FirstLien}}"); err == nil {
+		t.Error("unknown field should fail validation")
+	}
+	if err := ValidateCommitTemplate("{{.FirstLine"); err == nil {
+		t.Error("unparseable template should fail validation")
+	}
+	if err := ValidateCommitTemplate("   "); err == nil {
+		t.Error("blank template should fail validation")
+	}
+}
+
+func TestExpandPRCommandTemplate(t *testing.T) {
+	item := &Item{ID: "abc123", Description: "Fix \"quoted\" $thing\nbody"}
+	got, err := ExpandPRCommandTemplate(`gh pr create --head {{.Branch}} --title "{{.FirstLine}}"`, item, "wn-abc123-fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `gh pr create --head 'wn-abc123-fix' --title "Fix \"quoted\" \$thing"`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := ExpandPRCommandTemplate("{{.Nope}}", item, "br"); err == nil {
+		t.Error("unknown field should fail")
+	}
+}
+
+func TestPRURLFromOutput(t *testing.T) {
+	tests := []struct {
+		out  string
+		want string
+	}{
+		{"", ""},
+		{"no url here\n", ""},
+		{"https://github.com/o/r/pull/12\n", "https://github.com/o/r/pull/12"},
+		{"Creating pull request\nsee http://a/1 then\nhttps://github.com/o/r/pull/3\n", "https://github.com/o/r/pull/3"},
+	}
+	for _, tt := range tests {
+		if got := prURLFromOutput(tt.out); got != tt.want {
+			t.Errorf("prURLFromOutput(%q) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
+
 func TestExpandCommandTemplate(t *testing.T) {
 	got, err := ExpandCommandTemplate("echo {{.Prompt}}", "hello world", "abc", "/wt", "br", "")
 	if err != nil {
@@ -222,6 +383,24 @@ func TestExpandCommandTemplate_escapesItemIDWorktreeBranch(t *testing.T) {
 	}
 }
 
+func TestExpandCommandTemplate_env(t *testing.T) {
+	t.Setenv("WN_TEST_MODEL", `opus "4"; $(id)`)
+	tpl := `printf '%s|%s' {{env "WN_TEST_MODEL"}} {{env "WN_TEST_UNSET_VAR"}}`
+	got, err := ExpandCommandTemplate(tpl, "prompt", "abc", "/wt", "br", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", "-c", got)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("sh -c failed: %v.\nExpanded command: %s", err, got)
+	}
+	if want := `opus "4"; $(id)|`; stdout.String() != want {
+		t.Errorf("sh -c output = %q, want %q", stdout.String(), want)
+	}
+}
+
 func TestResolveBranchName(t *testing.T) {
 	item := &Item{ID: "abc123", Description: "Add feature"}
 	if got := resolveBranchName(item, ""); got != "wn-abc123-add-feature" {
@@ -311,7 +490,7 @@ func TestClaimNextItem_topoOrder(t *testing.T) {
 	put("first", "no deps", nil)
 	put("second", "depends on first", []string{"first"})
 
-	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "")
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem: %v", err)
 	}
@@ -349,7 +528,7 @@ func TestClaimNextItem_tagFilter(t *testing.T) {
 	put("c", "item c", []string{"agent"})
 
 	// No tag: should get first in topo order (a, b, c by id/order)
-	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "")
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem(no tag): %v", err)
 	}
@@ -364,7 +543,7 @@ func TestClaimNextItem_tagFilter(t *testing.T) {
 	})
 
 	// With tag "agent": only a and c are candidates; first in topo order is a
-	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "agent")
+	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "agent", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem(tag=agent): %v", err)
 	}
@@ -380,7 +559,7 @@ func TestClaimNextItem_tagFilter(t *testing.T) {
 	})
 
 	// With tag "agent" again: only c left in undone with that tag
-	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "agent")
+	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "agent", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem(tag=agent) 2nd: %v", err)
 	}
@@ -389,7 +568,7 @@ func TestClaimNextItem_tagFilter(t *testing.T) {
 	}
 
 	// Tag that no item has: no candidate (c is in progress; undone = b only, b has no tag)
-	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "nonexistent")
+	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "nonexistent", nil)
 	if err != nil {
 		t.Fatalf("ClaimNextItem(tag=nonexistent): %v", err)
 	}
@@ -398,6 +577,51 @@ func TestClaimNextItem_tagFilter(t *testing.T) {
 	}
 }
 
+func TestClaimNextItem_skipsHoldTag(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for i, it := range []*Item{
+		{ID: "a", Tags: []string{"agent", "hold"}},
+		{ID: "b", Tags: []string{"agent"}},
+		{ID: "c", Tags: []string{"paused"}},
+	} {
+		ord := i
+		it.Description, it.Created, it.Updated, it.Order = "item "+it.ID, now, now, &ord
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Default hold tag: a is next in order but held, so its successor b is claimed.
+	got, err := ClaimNextItem(store, root, 30*time.Minute, "", "agent", AgentExcludeTags(Settings{}))
+	if err != nil {
+		t.Fatalf("ClaimNextItem: %v", err)
+	}
+	if got == nil || got.ID != "b" {
+		t.Fatalf("ClaimNextItem with hold tag = %v, want item b", got)
+	}
+	if held, _ := store.Get("a"); !held.InProgressUntil.IsZero() {
+		t.Error("held item a should not be claimed")
+	}
+
+	// A custom hold tag replaces the default, and next.exclude_tags still applies.
+	settings := Settings{Agent: AgentSettings{HoldTag: "paused"}, Next: NextSettings{ExcludeTags: []string{"manual-only"}}}
+	if got := AgentExcludeTags(settings); !slices.Equal(got, []string{"manual-only", "paused"}) {
+		t.Errorf("AgentExcludeTags = %v, want [manual-only paused]", got)
+	}
+	got, err = ClaimNextItem(store, root, 30*time.Minute, "", "", AgentExcludeTags(settings))
+	if err != nil {
+		t.Fatalf("ClaimNextItem: %v", err)
+	}
+	if got == nil || got.ID != "a" {
+		t.Errorf("ClaimNextItem with hold_tag paused = %v, want item a", got)
+	}
+}
+
 func TestSetupItemWorktree_createsWorktreeAndBranchNote(t *testing.T) {
 	repoDir := t.TempDir()
 	setupGitRepo(t, repoDir)
@@ -612,3 +836,93 @@ func TestExpandCommandTemplate_noSessionID(t *testing.T) {
 		t.Errorf("expected no --resume when sessionID is empty, got %q", got)
 	}
 }
+
+func TestRunOneItem_setupCmd(t *testing.T) {
+	repoDir := t.TempDir()
+	setupGitRepo(t, repoDir)
+	store, err := NewFileStore(repoDir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, id := range []string{"aaa111", "bbb222"} {
+		if err := store.Put(&Item{ID: id, Description: "Task " + id, Created: now, Updated: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	worktreesBase := t.TempDir()
+	opts := AgentOrchOpts{Root: repoDir, ClaimFor: time.Hour, LeaveWorktree: true}
+	agentCmd := `test -f setup-done && touch agent-ran`
+
+	// Failing setup: the agent does not run, the claim is cleared, and the failure is logged.
+	if err := ClaimItem(store, repoDir, "aaa111", time.Hour, ""); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := store.Get("aaa111")
+	opts.SetupCmd = `exit 3`
+	if err := runOneItem(store, opts, item, repoDir, worktreesBase, filepath.Base(repoDir), "{{.Description}}", agentCmd); err == nil {
+		t.Fatal("runOneItem with failing setup should return an error")
+	}
+	got, _ := store.Get("aaa111")
+	if IsInProgress(got, time.Now().UTC()) || got.ReviewReady {
+		t.Errorf("after setup failure: in progress=%v review-ready=%v, want claim cleared only", IsInProgress(got, time.Now().UTC()), got.ReviewReady)
+	}
+	if last := got.Log[len(got.Log)-1]; last.Kind != "setup_failed" {
+		t.Errorf("last log kind = %q, want setup_failed", last.Kind)
+	}
+
+	// Successful setup runs in the worktree with WN_ROOT before the agent.
+	if err := ClaimItem(store, repoDir, "bbb222", time.Hour, ""); err != nil {
+		t.Fatal(err)
+	}
+	item, _ = store.Get("bbb222")
+	opts.SetupCmd = `printf %s "$WN_ROOT" > setup-done`
+	if err := runOneItem(store, opts, item, repoDir, worktreesBase, filepath.Base(repoDir), "{{.Description}}", agentCmd); err != nil {
+		t.Fatalf("runOneItem: %v", err)
+	}
+	wt := filepath.Join(worktreesBase, worktreeDirForBranch(filepath.Base(repoDir), resolveBranchName(item, "")))
+	if _, err := os.Stat(filepath.Join(wt, "agent-ran")); err != nil {
+		t.Errorf("agent did not run after setup: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wt, "setup-done")); string(data) != repoDir {
+		t.Errorf("setup WN_ROOT = %q, want %q", data, repoDir)
+	}
+	_ = RemoveWorktree(repoDir, wt, nil)
+}
+
+func TestRunOneItem_recordsAgentCmd(t *testing.T) {
+	repoDir := t.TempDir()
+	setupGitRepo(t, repoDir)
+	store, err := NewFileStore(repoDir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "abc123", Description: "Secret plan", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClaimItem(store, repoDir, "abc123", time.Hour, ""); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := store.Get("abc123")
+	agentCmd := `printf %s "{{.Prompt}}" > /dev/null`
+	opts := AgentOrchOpts{Root: repoDir, ClaimFor: time.Hour}
+	if err := runOneItem(store, opts, item, repoDir, t.TempDir(), filepath.Base(repoDir), "{{.Description}}", agentCmd); err != nil {
+		t.Fatalf("runOneItem: %v", err)
+	}
+	got, _ := store.Get("abc123")
+	idx := got.NoteIndexByName("agent-cmd")
+	if idx < 0 {
+		t.Fatal("agent-cmd note not set")
+	}
+	if body := got.Notes[idx].Body; body != agentCmd {
+		t.Errorf("agent-cmd note = %q, want the unexpanded template %q", body, agentCmd)
+	}
+	var started bool
+	for _, e := range got.Log {
+		started = started || e.Kind == "agent_started"
+	}
+	if !started {
+		t.Errorf("log has no agent_started entry: %+v", got.Log)
+	}
+}
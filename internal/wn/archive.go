@@ -20,7 +20,7 @@ func DefaultArchiveDir(root string) string {
 func ArchiveItem(store Store, id string, archiveDir string) (string, error) {
 	item, err := store.Get(id)
 	if err != nil {
-		return "", fmt.Errorf("item %s not found", id)
+		return "", err
 	}
 
 	// Collect prompt deps to include in the archive.
@@ -113,7 +113,7 @@ func TestArchiveItem_ArchivedFileIsValidExport(t *testing.T) {
 	if err != nil {
 		t.Fatalf("NewFileStore: %v", err)
 	}
-	if err := ImportAppend(store2, archivePath); err != nil {
+	if _, err := ImportAppend(store2, archivePath); err != nil {
 		t.Fatalf("ImportAppend from archive: %v", err)
 	}
 	got, err := store2.Get("arch03")
@@ -217,7 +217,7 @@ func TestArchiveItem_includesPromptDeps(t *testing.T) {
 	if err != nil {
 		t.Fatalf("NewFileStore2: %v", err)
 	}
-	if err := ImportAppend(store2, archivePath); err != nil {
+	if _, err := ImportAppend(store2, archivePath); err != nil {
 		t.Fatalf("ImportAppend: %v", err)
 	}
 	if _, err := store2.Get("arch05"); err != nil {
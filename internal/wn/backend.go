@@ -0,0 +1,177 @@
+package wn
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Storage backends for a .wn directory.
+const (
+	BackendFile   = "file"   // one JSON file per item under .wn/items (default)
+	BackendSQLite = "sqlite" // a single database at .wn/wn.db
+)
+
+const sqliteFileName = "wn.db"
+
+// errNoSQLite is returned when a SQLite store is needed but wn was built without it.
+var errNoSQLite = errors.New("this wn was built without SQLite support (rebuild with -tags sqlite)")
+
+// SQLitePath returns the path of the SQLite database under root.
+func SQLitePath(root string) string {
+	return filepath.Join(root, ".wn", sqliteFileName)
+}
+
+// StoreBackend reports which backend root uses: BackendSQLite when .wn/wn.db exists, else BackendFile.
+func StoreBackend(root string) string {
+	if _, err := os.Stat(SQLitePath(root)); err == nil {
+		return BackendSQLite
+	}
+	return BackendFile
+}
+
+// ValidateBackend checks a backend name; empty means BackendFile.
+func ValidateBackend(backend string) error {
+	switch backend {
+	case "", BackendFile, BackendSQLite:
+		return nil
+	}
+	return fmt.Errorf("invalid backend %q (use: file, sqlite)", backend)
+}
+
+// sqliteStores caches open SQLite stores by root so repeated OpenStore calls (e.g. one per MCP
+// request) share a database handle.
+var (
+	sqliteStoresMu sync.Mutex
+	sqliteStores   = make(map[string]Store)
+)
+
+// OpenStore returns the store for root using whichever backend it was initialized with.
+func OpenStore(root string) (Store, error) {
+	if StoreBackend(root) != BackendSQLite {
+		return NewFileStore(root)
+	}
+	sqliteStoresMu.Lock()
+	defer sqliteStoresMu.Unlock()
+	if s, ok := sqliteStores[root]; ok {
+		return s, nil
+	}
+	s, err := openSQLiteStore(root)
+	if err != nil {
+		return nil, err
+	}
+	sqliteStores[root] = s
+	return s, nil
+}
+
+// InitRootBackend is InitRoot for the given backend: BackendSQLite creates .wn/wn.db instead of
+// .wn/items. Idempotent; an existing store keeps its backend.
+func InitRootBackend(dir, backend string) error {
+	if err := ValidateBackend(backend); err != nil {
+		return err
+	}
+	if backend != BackendSQLite {
+		if StoreBackend(dir) == BackendSQLite {
+			return nil
+		}
+		return InitRoot(dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".wn", itemsDirName)); err == nil {
+		return nil
+	}
+	if !sqliteSupported {
+		return errNoSQLite
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".wn"), 0755); err != nil {
+		return err
+	}
+	s, err := openSQLiteStore(dir)
+	if err != nil {
+		return err
+	}
+	closeStore(s)
+	return writeWnGitignore(dir)
+}
+
+// forgetStore closes and drops a cached SQLite store for root (before its database is moved).
+func forgetStore(root string) {
+	sqliteStoresMu.Lock()
+	defer sqliteStoresMu.Unlock()
+	if s, ok := sqliteStores[root]; ok {
+		closeStore(s)
+		delete(sqliteStores, root)
+	}
+}
+
+// closeStore releases a store that holds resources (the SQLite store's database handle).
+func closeStore(s Store) {
+	if c, ok := s.(interface{ Close() error }); ok {
+		_ = c.Close()
+	}
+}
+
+// MigrateStore copies every item from root's current store into a new store of backend to,
+// then moves the old storage aside (to .wn/items.migrated-<time> or .wn/wn.db.migrated-<time>)
+// so the new store is the one OpenStore uses. Returns the number of items copied and the
+// backup path.
+func MigrateStore(root, to string) (int, string, error) {
+	if err := ValidateBackend(to); err != nil {
+		return 0, "", err
+	}
+	if to == "" {
+		to = BackendFile
+	}
+	from := StoreBackend(root)
+	if from == to {
+		return 0, "", fmt.Errorf("store already uses the %s backend", to)
+	}
+	if !sqliteSupported {
+		return 0, "", errNoSQLite
+	}
+	src, err := OpenStore(root)
+	if err != nil {
+		return 0, "", err
+	}
+	items, err := src.List()
+	forgetStore(root)
+	if err != nil {
+		return 0, "", err
+	}
+	stamp := time.Now().UTC().Format("20060102T150405")
+	var dst Store
+	var oldPath string
+	staging := filepath.Join(root, ".wn", itemsDirName+".migrating")
+	if to == BackendSQLite {
+		dst, err = openSQLiteStore(root)
+		oldPath = filepath.Join(root, ".wn", itemsDirName)
+	} else {
+		// Write items to a staging directory; it becomes .wn/items once the database is moved aside.
+		dst, err = newFileStoreIn(root, staging)
+		oldPath = SQLitePath(root)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	for _, it := range items {
+		if err := dst.Put(it); err != nil {
+			closeStore(dst)
+			return 0, "", fmt.Errorf("copy %s: %w", it.ID, err)
+		}
+	}
+	closeStore(dst)
+	backup := oldPath + ".migrated-" + stamp
+	if err := os.Rename(oldPath, backup); err != nil {
+		return 0, "", err
+	}
+	if to == BackendFile {
+		if err := os.Rename(staging, filepath.Join(root, ".wn", itemsDirName)); err != nil {
+			return 0, "", err
+		}
+	}
+	// The file store's index describes the old items directory.
+	_ = os.Remove(filepath.Join(root, ".wn", indexFileName))
+	return len(items), backup, nil
+}
@@ -0,0 +1,50 @@
+package wn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStoreBackend_defaultsToFile(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatal(err)
+	}
+	if got := StoreBackend(root); got != BackendFile {
+		t.Errorf("StoreBackend = %q, want file", got)
+	}
+	store, err := OpenStore(root)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if _, ok := store.(*fileStore); !ok {
+		t.Errorf("OpenStore returned %T, want *fileStore", store)
+	}
+}
+
+func TestValidateBackend(t *testing.T) {
+	for _, b := range []string{"", BackendFile, BackendSQLite} {
+		if err := ValidateBackend(b); err != nil {
+			t.Errorf("ValidateBackend(%q): %v", b, err)
+		}
+	}
+	if err := ValidateBackend("postgres"); err == nil {
+		t.Error("ValidateBackend(postgres) should fail")
+	}
+}
+
+func TestInitRootBackend_withoutSQLiteSupport(t *testing.T) {
+	if sqliteSupported {
+		t.Skip("built with SQLite support")
+	}
+	root := t.TempDir()
+	if err := InitRootBackend(root, BackendSQLite); !errors.Is(err, errNoSQLite) {
+		t.Errorf("InitRootBackend(sqlite) = %v, want errNoSQLite", err)
+	}
+	if err := InitRoot(root); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := MigrateStore(root, BackendSQLite); !errors.Is(err, errNoSQLite) {
+		t.Errorf("MigrateStore(sqlite) = %v, want errNoSQLite", err)
+	}
+}
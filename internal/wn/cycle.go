@@ -1,5 +1,14 @@
 package wn
 
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrSelfDependency is returned when an item would be made to depend on itself.
+var ErrSelfDependency = errors.New("an item cannot depend on itself")
+
 // WouldCreateCycle returns true if adding an edge from fromID to toID
 // would create a cycle in the graph of items.
 func WouldCreateCycle(items []*Item, fromID, toID string) bool {
@@ -34,3 +43,171 @@ func pathExists(adj map[string][]string, from, to string, seen map[string]bool)
 	}
 	return false
 }
+
+// CycleItems returns the sorted IDs of items that are part of a dependency cycle.
+// Dependencies on IDs not in items are ignored.
+func CycleItems(items []*Item) []string {
+	adj := make(map[string][]string)
+	for _, it := range items {
+		adj[it.ID] = it.DependsOn
+	}
+	var ids []string
+	for _, it := range items {
+		for _, dep := range it.DependsOn {
+			if _, ok := adj[dep]; !ok {
+				continue
+			}
+			if pathExists(adj, dep, it.ID, nil) {
+				ids = append(ids, it.ID)
+				break
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// DependencyCycles returns one chain per strongly connected group of items that depend on each
+// other, e.g. ["a", "b", "c", "a"] when a depends on b, b on c, and c on a. Each chain starts and
+// ends at the group's smallest id and is a shortest way around; chains are sorted by that id.
+// A group with several overlapping loops is reported once. Dependencies on IDs not in items are ignored.
+func DependencyCycles(items []*Item) [][]string {
+	adj := dependencyGraph(items)
+	var cycles [][]string
+	for _, comp := range stronglyConnected(adj) {
+		start := comp[0]
+		if len(comp) == 1 && !containsString(adj[start], start) {
+			continue
+		}
+		in := make(map[string]bool, len(comp))
+		for _, id := range comp {
+			in[id] = true
+		}
+		cycles = append(cycles, shortestLoop(adj, in, start))
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// CycleThrough returns the shortest dependency chain from id back to itself, e.g.
+// ["b", "c", "a", "b"], or nil if id is not part of a cycle.
+func CycleThrough(items []*Item, id string) []string {
+	adj := dependencyGraph(items)
+	if _, ok := adj[id]; !ok {
+		return nil
+	}
+	return shortestLoop(adj, nil, id)
+}
+
+// dependencyGraph maps each item id to its sorted dependencies that are also in items.
+func dependencyGraph(items []*Item) map[string][]string {
+	adj := make(map[string][]string, len(items))
+	for _, it := range items {
+		adj[it.ID] = nil
+	}
+	for _, it := range items {
+		for _, dep := range it.DependsOn {
+			if _, ok := adj[dep]; ok {
+				adj[it.ID] = append(adj[it.ID], dep)
+			}
+		}
+		sort.Strings(adj[it.ID])
+	}
+	return adj
+}
+
+// FormatCycle joins a DependencyCycles chain with arrows, e.g. "a → b → a".
+func FormatCycle(chain []string) string {
+	return strings.Join(chain, " → ")
+}
+
+// stronglyConnected returns the strongly connected components of adj (Tarjan), each sorted.
+func stronglyConnected(adj map[string][]string) [][]string {
+	ids := make([]string, 0, len(adj))
+	for id := range adj {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	index := make(map[string]int, len(adj))
+	low := make(map[string]int, len(adj))
+	onStack := make(map[string]bool, len(adj))
+	var stack []string
+	var comps [][]string
+	next := 0
+	var visit func(v string)
+	visit = func(v string) {
+		index[v] = next
+		low[v] = next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range adj[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] == index[v] {
+			var comp []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp = append(comp, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Strings(comp)
+			comps = append(comps, comp)
+		}
+	}
+	for _, id := range ids {
+		if _, seen := index[id]; !seen {
+			visit(id)
+		}
+	}
+	return comps
+}
+
+// shortestLoop returns the shortest path start → ... → start, using only nodes in in when
+// in is non-nil. Returns nil when there is no such path.
+func shortestLoop(adj map[string][]string, in map[string]bool, start string) []string {
+	parent := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range adj[v] {
+			if in != nil && !in[w] {
+				continue
+			}
+			if w == start {
+				chain := []string{start}
+				for n := v; n != start; n = parent[n] {
+					chain = append(chain, n)
+				}
+				for i, j := 1, len(chain)-1; i < j; i, j = i+1, j-1 {
+					chain[i], chain[j] = chain[j], chain[i]
+				}
+				return append(chain, start)
+			}
+			if _, seen := parent[w]; !seen {
+				parent[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
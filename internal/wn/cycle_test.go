@@ -38,3 +38,59 @@ func TestWouldCreateCycle(t *testing.T) {
 		t.Error("b -> a with existing cycle a->c->a should not create new cycle (no path a->b)")
 	}
 }
+
+func TestCycleItems(t *testing.T) {
+	now := time.Now().UTC()
+	mk := func(id string, deps ...string) *Item {
+		return &Item{ID: id, DependsOn: deps, Created: now, Updated: now}
+	}
+	items := []*Item{
+		mk("a", "b"),
+		mk("b", "a"),
+		mk("c", "a"),
+		mk("d", "d"),
+		mk("e", "missing"),
+	}
+	got := CycleItems(items)
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "d" {
+		t.Errorf("CycleItems = %v, want [a b d]", got)
+	}
+	if got := CycleItems([]*Item{mk("a", "b"), mk("b")}); len(got) != 0 {
+		t.Errorf("CycleItems(acyclic) = %v, want []", got)
+	}
+}
+
+func TestDependencyCycles(t *testing.T) {
+	now := time.Now().UTC()
+	mk := func(id string, deps ...string) *Item {
+		return &Item{ID: id, DependsOn: deps, Created: now, Updated: now}
+	}
+	items := []*Item{
+		mk("c", "a"),
+		mk("a", "b"),
+		mk("b", "c"),
+		mk("x", "y"),
+		mk("y", "x"),
+		mk("s", "s"),
+		mk("free", "a", "gone"),
+	}
+	got := DependencyCycles(items)
+	want := []string{"a → b → c → a", "s → s", "x → y → x"}
+	if len(got) != len(want) {
+		t.Fatalf("DependencyCycles = %v, want %v", got, want)
+	}
+	for i, chain := range got {
+		if FormatCycle(chain) != want[i] {
+			t.Errorf("cycle %d = %s, want %s", i, FormatCycle(chain), want[i])
+		}
+	}
+	if got := DependencyCycles([]*Item{mk("a", "b"), mk("b")}); len(got) != 0 {
+		t.Errorf("acyclic graph cycles = %v, want none", got)
+	}
+	if got := FormatCycle(CycleThrough(items, "b")); got != "b → c → a → b" {
+		t.Errorf("CycleThrough(b) = %s, want b → c → a → b", got)
+	}
+	if got := CycleThrough(items, "free"); got != nil {
+		t.Errorf("CycleThrough(free) = %v, want nil", got)
+	}
+}
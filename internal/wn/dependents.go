@@ -1,5 +1,10 @@
 package wn
 
+import (
+	"strings"
+	"time"
+)
+
 // Dependents returns the IDs of work items that depend on the given id
 // (i.e. items whose DependsOn contains id). Order is undefined.
 func Dependents(store Store, id string) ([]string, error) {
@@ -18,3 +23,44 @@ func Dependents(store Store, id string) ([]string, error) {
 	}
 	return out, nil
 }
+
+// UnfinishedDependencies returns the dependencies of item that are not done, in DependsOn
+// order. Dependencies that cannot be read (e.g. deleted items; wn doctor reports them) are skipped.
+func UnfinishedDependencies(store Store, item *Item) []*Item {
+	var out []*Item
+	for _, id := range item.DependsOn {
+		if dep, err := store.Get(id); err == nil && !dep.Done {
+			out = append(out, dep)
+		}
+	}
+	return out
+}
+
+// DescribeItems formats items as "id (first line)" joined by ", " for warnings and messages.
+func DescribeItems(items []*Item) string {
+	parts := make([]string, len(items))
+	for i, it := range items {
+		parts[i] = it.ID + " (" + FirstLine(it.Description) + ")"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ClearDependencies removes every dependency of the work item id in one update, logging
+// depend_removed for each removed edge. Returns the removed ids (none if it had no dependencies).
+func ClearDependencies(store Store, id string) ([]string, error) {
+	var removed []string
+	err := store.UpdateItem(id, func(it *Item) (*Item, error) {
+		removed = it.DependsOn
+		if len(removed) == 0 {
+			return nil, nil
+		}
+		now := time.Now().UTC()
+		for _, d := range removed {
+			it.Log = append(it.Log, LogEntry{At: now, Kind: "depend_removed", Msg: d})
+		}
+		it.DependsOn = nil
+		it.Updated = now
+		return it, nil
+	})
+	return removed, err
+}
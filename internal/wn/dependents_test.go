@@ -65,3 +65,27 @@ func TestDependents_NonexistentID(t *testing.T) {
 		t.Errorf("Dependents(nonexistent) = %v, want []", ids)
 	}
 }
+
+func TestUnfinishedDependencies(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "done01", Description: "finished", Done: true},
+		{ID: "open01", Description: "open one"},
+		{ID: "open02", Description: "open two\nbody"},
+		{ID: "main01", Description: "main", DependsOn: []string{"open02", "done01", "gone01", "open01"}},
+	} {
+		it.Created, it.Updated = now, now
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	main, _ := store.Get("main01")
+	deps := UnfinishedDependencies(store, main)
+	if got, want := DescribeItems(deps), "open02 (open two), open01 (open one)"; got != want {
+		t.Errorf("UnfinishedDependencies = %q, want %q", got, want)
+	}
+}
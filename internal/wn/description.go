@@ -1,6 +1,7 @@
 package wn
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -21,3 +22,87 @@ func PromptBody(s string) string {
 	}
 	return strings.TrimSpace(rest)
 }
+
+// SplitTaskList splits a plain-text task list into descriptions: one per non-empty line when sep
+// is "line", or one per paragraph (lines separated by blank lines) when sep is "blank". Each
+// description is trimmed of surrounding whitespace.
+func SplitTaskList(text, sep string) ([]string, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var out []string
+	switch sep {
+	case "line":
+		for _, l := range lines {
+			if l = strings.TrimSpace(l); l != "" {
+				out = append(out, l)
+			}
+		}
+	case "blank":
+		var para []string
+		for _, l := range append(lines, "") {
+			if strings.TrimSpace(l) != "" {
+				para = append(para, strings.TrimRight(l, " \t"))
+				continue
+			}
+			if len(para) > 0 {
+				out = append(out, strings.TrimSpace(strings.Join(para, "\n")))
+				para = nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid separator %q: must be line or blank", sep)
+	}
+	return out, nil
+}
+
+// Edit sources recorded as the Msg of an "updated" log entry. EditSourceMCP marks an edit by an
+// MCP client (an agent), followed by the claiming worker id when the item is claimed;
+// EditSourceCLI and EditSourceTUI mark edits by a person with wn edit and in the TUI.
+const (
+	EditSourceMCP = "via mcp"
+	EditSourceCLI = "via cli"
+	EditSourceTUI = "via tui"
+)
+
+// MCPEditLogMsg returns the Msg for an "updated" log entry written through MCP for it.
+func MCPEditLogMsg(it *Item) string {
+	if it.InProgressBy != "" {
+		return EditSourceMCP + " by " + it.InProgressBy
+	}
+	return EditSourceMCP
+}
+
+// LastDescriptionEdit returns the most recent "updated" log entry, which records a description
+// change, and false if the description was never edited after creation.
+func LastDescriptionEdit(it *Item) (LogEntry, bool) {
+	for i := len(it.Log) - 1; i >= 0; i-- {
+		if it.Log[i].Kind == "updated" {
+			return it.Log[i], true
+		}
+	}
+	return LogEntry{}, false
+}
+
+// DescribeEditSource says who made an edit from its log entry: the agent (with worker id when
+// known) for MCP edits, a human for CLI and TUI edits, and "unknown source" for entries written
+// before edits were marked.
+func DescribeEditSource(e LogEntry) string {
+	switch e.Msg {
+	case EditSourceCLI:
+		return "human (cli)"
+	case EditSourceTUI:
+		return "human (tui)"
+	}
+	if rest, ok := strings.CutPrefix(e.Msg, EditSourceMCP); ok {
+		if by := strings.TrimPrefix(rest, " by "); by != "" {
+			return "agent " + by + " (mcp)"
+		}
+		return "agent (mcp)"
+	}
+	return "unknown source"
+}
+
+// ExpandAddTemplate returns the editor buffer for a new item from the add_template setting, with
+// each {title} placeholder replaced by title (empty when the title is still to be typed).
+func ExpandAddTemplate(tpl, title string) string {
+	return strings.ReplaceAll(tpl, "{title}", title)
+}
@@ -1,6 +1,10 @@
 package wn
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestFirstLine(t *testing.T) {
 	tests := []struct {
@@ -37,3 +41,65 @@ func TestPromptBody(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitTaskList(t *testing.T) {
+	text := "fix login\r\n\n  write docs  \nwith examples\n\n\n\nship it\n"
+	tests := []struct {
+		sep  string
+		want []string
+	}{
+		{"line", []string{"fix login", "write docs", "with examples", "ship it"}},
+		{"blank", []string{"fix login", "write docs\nwith examples", "ship it"}},
+	}
+	for _, tt := range tests {
+		got, err := SplitTaskList(text, tt.sep)
+		if err != nil {
+			t.Fatalf("SplitTaskList(%s): %v", tt.sep, err)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("SplitTaskList(%s) = %q, want %q", tt.sep, got, tt.want)
+		}
+	}
+	if got, err := SplitTaskList("\n \n", "line"); err != nil || len(got) != 0 {
+		t.Errorf("blank text = %q, %v; want none", got, err)
+	}
+	if _, err := SplitTaskList(text, "comma"); err == nil {
+		t.Error("unknown separator should fail")
+	}
+}
+
+func TestLastDescriptionEdit(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	it := &Item{Log: []LogEntry{{At: t0, Kind: "created"}, {At: t0.Add(time.Hour), Kind: "tag_added", Msg: "x"}}}
+	if _, ok := LastDescriptionEdit(it); ok {
+		t.Error("LastDescriptionEdit on a never-edited item should return false")
+	}
+	it.Log = append(it.Log, LogEntry{At: t0.Add(2 * time.Hour), Kind: "updated"}, LogEntry{At: t0.Add(3 * time.Hour), Kind: "updated", Msg: MCPEditLogMsg(&Item{InProgressBy: "worker-1"})})
+	e, ok := LastDescriptionEdit(it)
+	if !ok || !e.At.Equal(t0.Add(3*time.Hour)) {
+		t.Fatalf("LastDescriptionEdit = %+v, %v; want the last updated entry", e, ok)
+	}
+	for _, tc := range []struct {
+		msg, want string
+	}{
+		{"", "unknown source"},
+		{EditSourceCLI, "human (cli)"},
+		{EditSourceTUI, "human (tui)"},
+		{EditSourceMCP, "agent (mcp)"},
+		{e.Msg, "agent worker-1 (mcp)"},
+	} {
+		if got := DescribeEditSource(LogEntry{Kind: "updated", Msg: tc.msg}); got != tc.want {
+			t.Errorf("DescribeEditSource(%q) = %q, want %q", tc.msg, got, tc.want)
+		}
+	}
+}
+
+func TestExpandAddTemplate(t *testing.T) {
+	tpl := "{title}\n\n## Acceptance criteria\n- [ ] \n"
+	if got, want := ExpandAddTemplate(tpl, ""), "\n\n## Acceptance criteria\n- [ ] \n"; got != want {
+		t.Errorf("ExpandAddTemplate(tpl, \"\") = %q, want %q", got, want)
+	}
+	if got := ExpandAddTemplate(tpl, "Fix login"); !strings.HasPrefix(got, "Fix login\n\n## Acceptance") {
+		t.Errorf("ExpandAddTemplate(tpl, title) = %q, want the title on the first line", got)
+	}
+}
@@ -0,0 +1,134 @@
+package wn
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DoctorIssue is one store integrity problem reported by Doctor.
+type DoctorIssue struct {
+	ID      string // item id; empty for meta issues
+	Kind    string // "missing_dependency", "self_dependency", "dangling_duplicate_of", "dangling_current_id", "dangling_previous_id", "cycle", "invalid_tag", "invalid_note_name"
+	Ref     string // the offending dependency id, tag, note name, or meta id
+	Detail  string
+	Fixable bool // true when DoctorFix can repair it
+}
+
+// Doctor scans the store and meta for integrity problems: dependencies on missing items or on
+// the item itself, duplicate-of notes pointing at missing items, dangling current/previous id in
+// meta, dependency cycles, and invalid tag or note names.
+func Doctor(store Store, root string) ([]DoctorIssue, error) {
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	meta, err := ReadMeta(root)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(items))
+	for _, it := range items {
+		exists[it.ID] = true
+	}
+	var issues []DoctorIssue
+	if meta.CurrentID != "" && !exists[meta.CurrentID] {
+		issues = append(issues, DoctorIssue{Kind: "dangling_current_id", Ref: meta.CurrentID, Detail: fmt.Sprintf("current task %s not found", meta.CurrentID), Fixable: true})
+	}
+	if meta.PreviousID != "" && !exists[meta.PreviousID] {
+		issues = append(issues, DoctorIssue{Kind: "dangling_previous_id", Ref: meta.PreviousID, Detail: fmt.Sprintf("previous task %s not found", meta.PreviousID), Fixable: true})
+	}
+	for _, it := range items {
+		for _, dep := range it.DependsOn {
+			if dep == it.ID {
+				issues = append(issues, DoctorIssue{ID: it.ID, Kind: "self_dependency", Ref: dep, Detail: "depends on itself", Fixable: true})
+				continue
+			}
+			if !exists[dep] {
+				issues = append(issues, DoctorIssue{ID: it.ID, Kind: "missing_dependency", Ref: dep, Detail: fmt.Sprintf("depends on missing item %s", dep), Fixable: true})
+			}
+		}
+		for _, tag := range it.Tags {
+			if err := ValidateTag(tag); err != nil {
+				issues = append(issues, DoctorIssue{ID: it.ID, Kind: "invalid_tag", Ref: tag, Detail: fmt.Sprintf("invalid tag %q: %v", tag, err)})
+			}
+		}
+		for _, n := range it.Notes {
+			if !ValidNoteName(n.Name) {
+				issues = append(issues, DoctorIssue{ID: it.ID, Kind: "invalid_note_name", Ref: n.Name, Detail: fmt.Sprintf("invalid note name %q", n.Name)})
+			}
+			if n.Name == NoteNameDuplicateOf && n.Body != "" && !exists[n.Body] {
+				issues = append(issues, DoctorIssue{ID: it.ID, Kind: "dangling_duplicate_of", Ref: n.Body, Detail: fmt.Sprintf("duplicate-of note points to missing item %s", n.Body)})
+			}
+		}
+	}
+	// Self-edges are reported above; leave them out so they do not also show as one-item cycles.
+	graph := make([]*Item, len(items))
+	for i, it := range items {
+		cp := *it
+		cp.DependsOn = slices.DeleteFunc(slices.Clone(it.DependsOn), func(d string) bool { return d == it.ID })
+		graph[i] = &cp
+	}
+	for _, id := range CycleItems(graph) {
+		issues = append(issues, DoctorIssue{ID: id, Kind: "cycle", Detail: "part of dependency cycle " + FormatCycle(CycleThrough(graph, id))})
+	}
+	return issues, nil
+}
+
+// DoctorFix repairs the fixable issues: removes dependency edges to missing items or to the item
+// itself and clears a dangling current or previous id in meta. Returns the number of issues fixed.
+func DoctorFix(store Store, root string, issues []DoctorIssue) (int, error) {
+	fixed := 0
+	drop := make(map[string][]string)
+	clearCurrent, clearPrevious := false, false
+	for _, is := range issues {
+		switch is.Kind {
+		case "missing_dependency", "self_dependency":
+			drop[is.ID] = append(drop[is.ID], is.Ref)
+		case "dangling_current_id":
+			clearCurrent = true
+		case "dangling_previous_id":
+			clearPrevious = true
+		}
+	}
+	for id, deps := range drop {
+		now := time.Now().UTC()
+		removed := 0
+		if err := store.UpdateItem(id, func(it *Item) (*Item, error) {
+			removed = 0
+			kept := it.DependsOn[:0]
+			for _, d := range it.DependsOn {
+				if slices.Contains(deps, d) {
+					it.Log = append(it.Log, LogEntry{At: now, Kind: "depend_removed", Msg: d})
+					removed++
+					continue
+				}
+				kept = append(kept, d)
+			}
+			it.DependsOn = kept
+			it.Updated = now
+			return it, nil
+		}); err != nil {
+			return fixed, err
+		}
+		fixed += removed
+	}
+	if clearCurrent || clearPrevious {
+		// One locked read-modify-write, without WithMetaLock's recording of the dangling current
+		// id as previous.
+		if err := withMetaLock(root, false, func(m Meta) (Meta, error) {
+			if clearCurrent && m.CurrentID != "" {
+				m.CurrentID = ""
+				fixed++
+			}
+			if clearPrevious && m.PreviousID != "" {
+				m.PreviousID = ""
+				fixed++
+			}
+			return m, nil
+		}); err != nil {
+			return fixed, err
+		}
+	}
+	return fixed, nil
+}
@@ -0,0 +1,145 @@
+package wn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoctor_cleanStore(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "a", Tags: []string{"ok"}, Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "bb2222", Description: "b", DependsOn: []string{"aa1111"}, Created: now, Updated: now})
+	if err := WriteMeta(root, Meta{CurrentID: "aa1111"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+	issues, err := Doctor(store, root)
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Doctor = %+v, want no issues", issues)
+	}
+}
+
+func TestDoctor_reportsAndFixes(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "a", DependsOn: []string{"gone01", "bb2222"}, Tags: []string{"bad tag"}, Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "bb2222", Description: "b", DependsOn: []string{"aa1111"}, Created: now, Updated: now,
+		Notes: []Note{{Name: NoteNameDuplicateOf, Created: now, Body: "gone02"}, {Name: "bad name", Created: now, Body: "x"}}})
+	if err := WriteMeta(root, Meta{CurrentID: "gone03", PreviousID: "aa1111"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+
+	issues, err := Doctor(store, root)
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	kinds := make(map[string]int)
+	for _, is := range issues {
+		kinds[is.Kind]++
+	}
+	want := map[string]int{
+		"dangling_current_id":   1,
+		"missing_dependency":    1,
+		"invalid_tag":           1,
+		"dangling_duplicate_of": 1,
+		"invalid_note_name":     1,
+		"cycle":                 2,
+	}
+	for k, n := range want {
+		if kinds[k] != n {
+			t.Errorf("Doctor kind %s count = %d, want %d (issues %+v)", k, kinds[k], n, issues)
+		}
+	}
+
+	fixed, err := DoctorFix(store, root, issues)
+	if err != nil {
+		t.Fatalf("DoctorFix: %v", err)
+	}
+	if fixed != 2 {
+		t.Errorf("DoctorFix fixed = %d, want 2", fixed)
+	}
+	a, _ := store.Get("aa1111")
+	if len(a.DependsOn) != 1 || a.DependsOn[0] != "bb2222" {
+		t.Errorf("after fix DependsOn = %v, want [bb2222]", a.DependsOn)
+	}
+	m, _ := ReadMeta(root)
+	if m.CurrentID != "" || m.PreviousID != "aa1111" {
+		t.Errorf("after fix meta = %+v, want CurrentID empty and PreviousID aa1111", m)
+	}
+}
+
+func TestDoctor_selfDependency(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "a", DependsOn: []string{"aa1111", "bb2222"}, Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "bb2222", Description: "b", Created: now, Updated: now})
+
+	issues, err := Doctor(store, root)
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Kind != "self_dependency" || issues[0].ID != "aa1111" || !issues[0].Fixable {
+		t.Fatalf("Doctor = %+v, want one fixable self_dependency on aa1111 (and no cycle)", issues)
+	}
+	fixed, err := DoctorFix(store, root, issues)
+	if err != nil || fixed != 1 {
+		t.Fatalf("DoctorFix = %d, %v; want 1", fixed, err)
+	}
+	a, _ := store.Get("aa1111")
+	if len(a.DependsOn) != 1 || a.DependsOn[0] != "bb2222" {
+		t.Errorf("after fix DependsOn = %v, want [bb2222]", a.DependsOn)
+	}
+}
+
+func TestDoctorFix_countsRemovedEdges(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "a", DependsOn: []string{"gone01"}, Created: now, Updated: now})
+	if err := WriteMeta(root, Meta{CurrentID: "gone02", PreviousID: "gone03"}); err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+	issues, err := Doctor(store, root)
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	// The same missing dependency reported twice removes one edge.
+	fixed, err := DoctorFix(store, root, append(issues, issues...))
+	if err != nil || fixed != 3 {
+		t.Fatalf("DoctorFix = %d, %v; want 3", fixed, err)
+	}
+	m, _ := ReadMeta(root)
+	if m.CurrentID != "" || m.PreviousID != "" {
+		t.Errorf("after fix meta = %+v, want both ids cleared", m)
+	}
+}
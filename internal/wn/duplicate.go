@@ -1,5 +1,13 @@
 package wn
 
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // MarkDuplicateOf marks the work item id as a duplicate of the work item originalID.
 // It sets status to closed and adds the standard note NoteNameDuplicateOf with body originalID
 // so the item leaves the active queue while preserving it for reference. Returns an error if
@@ -8,3 +16,215 @@ package wn
 func MarkDuplicateOf(store Store, id, originalID string) error {
 	return SetStatus(store, id, StatusClosed, StatusOpts{DuplicateOf: originalID})
 }
+
+// FlagDuplicateOf records that the work item id is a suspected duplicate of originalID without
+// changing its status: it adds the duplicate-of note and logs duplicate_of, leaving the item in
+// the queue for someone to review. Returns the same errors as MarkDuplicateOf.
+func FlagDuplicateOf(store Store, id, originalID string) error {
+	if _, err := store.Get(id); err != nil {
+		return err
+	}
+	if err := validateDuplicateOf(store, id, originalID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return store.UpdateItem(id, func(it *Item) (*Item, error) {
+		setDuplicateOfNote(it, originalID, now)
+		it.Updated = now
+		return it, nil
+	})
+}
+
+func validateDuplicateOf(store Store, id, originalID string) error {
+	if id == originalID {
+		return fmt.Errorf("cannot mark item as duplicate of itself")
+	}
+	if _, err := store.Get(originalID); err != nil {
+		return fmt.Errorf("original item %s not found", originalID)
+	}
+	return nil
+}
+
+// setDuplicateOfNote sets (or replaces) the duplicate-of note on it and logs duplicate_of.
+func setDuplicateOfNote(it *Item, originalID string, now time.Time) {
+	if it.Notes == nil {
+		it.Notes = []Note{}
+	}
+	if idx := it.NoteIndexByName(NoteNameDuplicateOf); idx >= 0 {
+		it.Notes[idx].Body = originalID
+	} else {
+		it.Notes = append(it.Notes, Note{Name: NoteNameDuplicateOf, Created: now, Body: originalID})
+	}
+	it.Log = append(it.Log, LogEntry{At: now, Kind: "duplicate_of", Msg: originalID})
+}
+
+// DuplicateMerge reports what MergeDuplicate moved from a duplicate onto its canonical item.
+type DuplicateMerge struct {
+	Notes        []MovedNote // notes copied onto the canonical item
+	SkippedNotes []string    // duplicate's notes already on the canonical item with the same body
+	Repointed    []string    // items whose dependency on the duplicate now points at the canonical item
+}
+
+// MovedNote is a note copied by MergeDuplicate. As is its name on the canonical item, which
+// differs from Name when the canonical item already had a different note by that name.
+type MovedNote struct {
+	Name string
+	As   string
+}
+
+// MergeDuplicate folds the work item dupID into canonicalID: copies the duplicate's notes onto
+// the canonical item (a name already in use there gets a "-<dupID>" suffix; identical notes are
+// skipped), repoints every item that depends on the duplicate to depend on the canonical item
+// instead, then closes the duplicate with a duplicate-of note (see MarkDuplicateOf). Nothing is
+// written if repointing a dependency would create a cycle.
+func MergeDuplicate(store Store, dupID, canonicalID string) (*DuplicateMerge, error) {
+	dup, err := store.Get(dupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDuplicateOf(store, dupID, canonicalID); err != nil {
+		return nil, err
+	}
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	// Apply the repointing to copies of the graph first so a cycle aborts before any write.
+	graph := make([]*Item, len(items))
+	var dependents []string
+	for i, it := range items {
+		cp := *it
+		if slices.Contains(it.DependsOn, dupID) {
+			dependents = append(dependents, it.ID)
+			cp.DependsOn = slices.DeleteFunc(slices.Clone(it.DependsOn), func(d string) bool { return d == dupID })
+		}
+		graph[i] = &cp
+	}
+	for _, id := range dependents {
+		if id != canonicalID && WouldCreateCycle(graph, id, canonicalID) {
+			return nil, fmt.Errorf("cannot repoint %s from %s to %s: circular dependency", id, dupID, canonicalID)
+		}
+		for _, it := range graph {
+			if it.ID == id && id != canonicalID && !slices.Contains(it.DependsOn, canonicalID) {
+				it.DependsOn = append(it.DependsOn, canonicalID)
+			}
+		}
+	}
+
+	res := &DuplicateMerge{Repointed: dependents}
+	now := time.Now().UTC()
+	if err := store.UpdateItem(canonicalID, func(it *Item) (*Item, error) {
+		for _, n := range dup.Notes {
+			if n.Name == NoteNameDuplicateOf {
+				continue
+			}
+			name := n.Name
+			if idx := it.NoteIndexByName(name); idx >= 0 {
+				if it.Notes[idx].Body == n.Body {
+					res.SkippedNotes = append(res.SkippedNotes, n.Name)
+					continue
+				}
+				name = mergedNoteName(it, n.Name, dupID)
+			}
+			it.Notes = append(it.Notes, Note{Name: name, Created: n.Created, Updated: n.Updated, Body: n.Body, History: n.History})
+			res.Notes = append(res.Notes, MovedNote{Name: n.Name, As: name})
+		}
+		it.Updated = now
+		it.Log = append(it.Log, LogEntry{At: now, Kind: "merged", Msg: dupID})
+		return it, nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, id := range dependents {
+		if err := store.UpdateItem(id, func(it *Item) (*Item, error) {
+			it.DependsOn = slices.DeleteFunc(it.DependsOn, func(d string) bool { return d == dupID })
+			it.Log = append(it.Log, LogEntry{At: now, Kind: "depend_removed", Msg: dupID})
+			if id != canonicalID && !slices.Contains(it.DependsOn, canonicalID) {
+				it.DependsOn = append(it.DependsOn, canonicalID)
+				it.Log = append(it.Log, LogEntry{At: now, Kind: "depend_added", Msg: canonicalID})
+			}
+			it.Updated = now
+			return it, nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := SetStatus(store, dupID, StatusClosed, StatusOpts{DoneMessage: "merged into " + canonicalID, DuplicateOf: canonicalID}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// mergedNoteName returns a name for the duplicate's note name that is free on it: name with a
+// "-<dupID>" suffix (then "-2", "-3", ...), trimmed to the 32-character note name limit.
+func mergedNoteName(it *Item, name, dupID string) string {
+	base := "-" + dupID
+	for i := 1; ; i++ {
+		suffix := base
+		if i > 1 {
+			suffix += "-" + strconv.Itoa(i)
+		}
+		cand := name[:min(len(name), 32-len(suffix))] + suffix
+		if it.NoteIndexByName(cand) < 0 {
+			return cand
+		}
+	}
+}
+
+// maxDuplicateChain bounds how many duplicate-of links DuplicateOfChain follows.
+const maxDuplicateChain = 10
+
+// DuplicateChain is the result of following duplicate-of notes from an item.
+type DuplicateChain struct {
+	IDs      []string // ids along the chain: the item's direct target first, the original last
+	Original *Item    // the item at the end of the chain; nil if that id is not in the store
+	Cycle    bool     // true if the chain loops back on itself or exceeds maxDuplicateChain links
+}
+
+// DuplicateOfChain follows item's duplicate-of note to the item it duplicates, and on through
+// further duplicate-of notes to the ultimate original. Returns nil if item is not a duplicate.
+func DuplicateOfChain(store Store, item *Item) *DuplicateChain {
+	next := duplicateOf(item)
+	if next == "" {
+		return nil
+	}
+	chain := &DuplicateChain{}
+	seen := map[string]bool{item.ID: true}
+	for next != "" {
+		if seen[next] || len(chain.IDs) == maxDuplicateChain {
+			chain.Cycle = true
+			return chain
+		}
+		seen[next] = true
+		chain.IDs = append(chain.IDs, next)
+		it, err := store.Get(next)
+		if err != nil {
+			chain.Original = nil
+			return chain
+		}
+		chain.Original = it
+		next = duplicateOf(it)
+	}
+	return chain
+}
+
+// duplicateOf returns the body of item's duplicate-of note, or "".
+func duplicateOf(item *Item) string {
+	if idx := item.NoteIndexByName(NoteNameDuplicateOf); idx >= 0 {
+		return strings.TrimSpace(item.Notes[idx].Body)
+	}
+	return ""
+}
+
+// String formats the chain as "bbb222 → ccc333 (first line of ccc333)", marking a missing
+// original as "(not found)" and a loop as "(cycle)".
+func (c *DuplicateChain) String() string {
+	s := strings.Join(c.IDs, " → ")
+	switch {
+	case c.Cycle:
+		return s + " (cycle)"
+	case c.Original == nil:
+		return s + " (not found)"
+	}
+	return s + " (" + FirstLine(c.Original.Description) + ")"
+}
@@ -1,6 +1,7 @@
 package wn
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -106,3 +107,147 @@ func TestMarkDuplicateOf_rejects_missing_item(t *testing.T) {
 		t.Error("MarkDuplicateOf with missing item should error")
 	}
 }
+
+func TestDuplicateOfChain(t *testing.T) {
+	dir := t.TempDir()
+	if err := InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	put := func(id, desc, dupOf string) *Item {
+		it := &Item{ID: id, Description: desc, Created: now, Updated: now}
+		if dupOf != "" {
+			it.Notes = []Note{{Name: NoteNameDuplicateOf, Created: now, Body: dupOf}}
+		}
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		return it
+	}
+	orig := put("ccc333", "the original\nbody", "")
+	mid := put("bbb222", "middle", "ccc333")
+	dup := put("aaa111", "duplicate", "bbb222")
+	gone := put("ddd444", "points nowhere", "zzz999")
+	loopA := put("eee555", "loop a", "fff666")
+	put("fff666", "loop b", "eee555")
+
+	if c := DuplicateOfChain(store, orig); c != nil {
+		t.Errorf("original: chain = %+v, want nil", c)
+	}
+	c := DuplicateOfChain(store, dup)
+	if c == nil || len(c.IDs) != 2 || c.IDs[1] != "ccc333" || c.Original == nil || c.Original.ID != "ccc333" || c.Cycle {
+		t.Fatalf("chain = %+v, want bbb222 → ccc333", c)
+	}
+	if got, want := c.String(), "bbb222 → ccc333 (the original)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := DuplicateOfChain(store, mid).String(), "ccc333 (the original)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := DuplicateOfChain(store, gone).String(), "zzz999 (not found)"; got != want {
+		t.Errorf("missing original: String() = %q, want %q", got, want)
+	}
+	c = DuplicateOfChain(store, loopA)
+	if c == nil || !c.Cycle {
+		t.Fatalf("loop: chain = %+v, want Cycle", c)
+	}
+	if got, want := c.String(), "fff666 (cycle)"; got != want {
+		t.Errorf("loop: String() = %q, want %q", got, want)
+	}
+}
+
+func TestMergeDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	if err := InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "canon1", Description: "canonical", Created: now, Updated: now, DependsOn: []string{"dup222"},
+			Notes: []Note{{Name: "branch", Created: now, Body: "wn-canon1"}, {Name: "context", Created: now, Body: "same"}}},
+		{ID: "dup222", Description: "duplicate", Created: now, Updated: now,
+			Notes: []Note{{Name: "branch", Created: now, Body: "wn-dup222"}, {Name: "context", Created: now, Body: "same"}, {Name: "pr-url", Created: now, Body: "https://example.com/1"}}},
+		{ID: "user33", Description: "depends on dup", Created: now, Updated: now, DependsOn: []string{"dup222"}},
+		{ID: "both44", Description: "depends on both", Created: now, Updated: now, DependsOn: []string{"dup222", "canon1"}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	res, err := MergeDuplicate(store, "dup222", "canon1")
+	if err != nil {
+		t.Fatalf("MergeDuplicate: %v", err)
+	}
+	wantNotes := []MovedNote{{Name: "branch", As: "branch-dup222"}, {Name: "pr-url", As: "pr-url"}}
+	if len(res.Notes) != len(wantNotes) || res.Notes[0] != wantNotes[0] || res.Notes[1] != wantNotes[1] {
+		t.Errorf("Notes = %v, want %v", res.Notes, wantNotes)
+	}
+	if len(res.SkippedNotes) != 1 || res.SkippedNotes[0] != "context" {
+		t.Errorf("SkippedNotes = %v, want [context]", res.SkippedNotes)
+	}
+	if len(res.Repointed) != 3 {
+		t.Errorf("Repointed = %v, want canon1, user33, both44", res.Repointed)
+	}
+
+	canon, _ := store.Get("canon1")
+	if len(canon.DependsOn) != 0 {
+		t.Errorf("canonical should no longer depend on its duplicate: %v", canon.DependsOn)
+	}
+	if idx := canon.NoteIndexByName("branch-dup222"); idx < 0 || canon.Notes[idx].Body != "wn-dup222" {
+		t.Errorf("canonical notes = %v", canon.Notes)
+	}
+	user, _ := store.Get("user33")
+	if len(user.DependsOn) != 1 || user.DependsOn[0] != "canon1" {
+		t.Errorf("user33 DependsOn = %v, want [canon1]", user.DependsOn)
+	}
+	both, _ := store.Get("both44")
+	if len(both.DependsOn) != 1 || both.DependsOn[0] != "canon1" {
+		t.Errorf("both44 DependsOn = %v, want [canon1]", both.DependsOn)
+	}
+	dup, _ := store.Get("dup222")
+	if !dup.Done || dup.DoneStatus != DoneStatusClosed {
+		t.Errorf("duplicate should be closed: Done=%v DoneStatus=%q", dup.Done, dup.DoneStatus)
+	}
+	if idx := dup.NoteIndexByName(NoteNameDuplicateOf); idx < 0 || dup.Notes[idx].Body != "canon1" {
+		t.Errorf("duplicate should have duplicate-of canon1: %v", dup.Notes)
+	}
+}
+
+func TestMergeDuplicate_rejects_cycle(t *testing.T) {
+	dir := t.TempDir()
+	if err := InitRoot(dir); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	// canon1 depends on user33, which depends on dup222: repointing user33 to canon1 loops.
+	for _, it := range []*Item{
+		{ID: "canon1", Description: "canonical", Created: now, Updated: now, DependsOn: []string{"user33"}},
+		{ID: "dup222", Description: "duplicate", Created: now, Updated: now},
+		{ID: "user33", Description: "depends on dup", Created: now, Updated: now, DependsOn: []string{"dup222"}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if _, err := MergeDuplicate(store, "dup222", "canon1"); err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Fatalf("MergeDuplicate = %v, want circular dependency error", err)
+	}
+	dup, _ := store.Get("dup222")
+	user, _ := store.Get("user33")
+	if dup.Done || len(user.DependsOn) != 1 || user.DependsOn[0] != "dup222" {
+		t.Errorf("rejected merge should write nothing: dup.Done=%v user33.DependsOn=%v", dup.Done, user.DependsOn)
+	}
+}
@@ -35,3 +35,40 @@ func ParseDurationWithDays(s string) (time.Duration, error) {
 	}
 	return d, nil
 }
+
+// ParseSince parses a cutoff given either as a duration before now ("7d", "36h") or as an
+// absolute time (RFC 3339, e.g. "2025-01-02T15:04:05Z", or a date "2025-01-02" in UTC).
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("since must not be empty")
+	}
+	if d, err := ParseDurationWithDays(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q: use a duration (e.g. 7d, 12h) or a timestamp (RFC 3339 or YYYY-MM-DD)", s)
+}
+
+// ParseUntil parses an absolute claim deadline: an RFC 3339 timestamp, or a wall-clock time
+// "HH:MM" today in now's location. The result must be after now.
+func ParseUntil(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	var t time.Time
+	if ts, err := time.Parse(time.RFC3339, s); err == nil {
+		t = ts
+	} else if hm, err := time.Parse("15:04", s); err == nil {
+		t = time.Date(now.Year(), now.Month(), now.Day(), hm.Hour(), hm.Minute(), 0, 0, now.Location())
+	} else {
+		return time.Time{}, fmt.Errorf("invalid until %q: use RFC 3339 (e.g. 2025-01-02T17:00:00Z) or HH:MM", s)
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("until %s is in the past", t.Format(time.RFC3339))
+	}
+	return t, nil
+}
@@ -1,6 +1,9 @@
 package wn
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestParseDurationWithDays_rejectsEmpty(t *testing.T) {
 	if _, err := ParseDurationWithDays(""); err == nil {
@@ -29,3 +32,56 @@ func TestParseDurationWithDays_supportsDaysSuffix(t *testing.T) {
 		t.Errorf("Hours = %v, want %v", got, want)
 	}
 }
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2025-03-01T08:30:00Z", time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC)},
+		{"2025-03-01", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil {
+			t.Fatalf("ParseSince(%q): %v", tt.in, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "yesterday", "2025-13-01"} {
+		if _, err := ParseSince(bad, now); err == nil {
+			t.Errorf("ParseSince(%q) want error", bad)
+		}
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	loc := time.FixedZone("test", -5*3600)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, loc)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"17:30", time.Date(2025, 3, 10, 17, 30, 0, 0, loc)},
+		{"2025-03-11T09:00:00Z", time.Date(2025, 3, 11, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseUntil(tt.in, now)
+		if err != nil {
+			t.Fatalf("ParseUntil(%q): %v", tt.in, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseUntil(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "5pm", "25:00", "09:00", "2025-03-01T08:30:00Z", "12:00"} {
+		if _, err := ParseUntil(bad, now); err == nil {
+			t.Errorf("ParseUntil(%q) want error", bad)
+		}
+	}
+}
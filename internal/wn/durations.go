@@ -0,0 +1,86 @@
+package wn
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ItemDuration is how long an item took, derived from its log: the time from its first
+// "in_progress" entry to its last "done" entry.
+type ItemDuration struct {
+	ID      string
+	Actual  time.Duration
+	Claimed time.Duration // duration requested by the first claim; 0 if it cannot be read from the log
+}
+
+// Over reports whether the item took longer than its first claim asked for.
+func (d ItemDuration) Over() bool {
+	return d.Claimed > 0 && d.Actual > d.Claimed
+}
+
+// ActualDuration returns the item's duration when its log has an "in_progress" entry followed
+// later by a "done" entry. Items never claimed, or not (yet) done, report false.
+func ActualDuration(it *Item) (ItemDuration, bool) {
+	var start *LogEntry
+	var end time.Time
+	for i := range it.Log {
+		e := &it.Log[i]
+		switch e.Kind {
+		case "in_progress":
+			if start == nil {
+				start = e
+			}
+		case "done":
+			if start != nil && !e.At.Before(start.At) {
+				end = e.At
+			}
+		}
+	}
+	if start == nil || end.IsZero() {
+		return ItemDuration{}, false
+	}
+	return ItemDuration{ID: it.ID, Actual: end.Sub(start.At), Claimed: claimedFor(*start)}, true
+}
+
+// claimedFor reads the requested claim length from an "in_progress" log message: a duration
+// ("30m") or "until <RFC 3339 time>".
+func claimedFor(e LogEntry) time.Duration {
+	if until, ok := strings.CutPrefix(e.Msg, "until "); ok {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return 0
+		}
+		return t.Sub(e.At)
+	}
+	d, err := time.ParseDuration(e.Msg)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// ItemDurations returns ActualDuration for every item that has one, longest first.
+func ItemDurations(items []*Item) []ItemDuration {
+	var out []ItemDuration
+	for _, it := range items {
+		if d, ok := ActualDuration(it); ok {
+			out = append(out, d)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Actual != out[j].Actual {
+			return out[i].Actual > out[j].Actual
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// FormatDuration renders d rounded to the minute (to the second under a minute), e.g. "2h5m0s".
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Minute).String()
+}
@@ -0,0 +1,51 @@
+package wn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActualDuration(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	done := &Item{ID: "aa1111", Log: []LogEntry{
+		{At: t0.Add(-time.Hour), Kind: "created"},
+		{At: t0, Kind: "in_progress", Msg: "1h"},
+		{At: t0.Add(30 * time.Minute), Kind: "in_progress", Msg: "1h"},
+		{At: t0.Add(90 * time.Minute), Kind: "done"},
+	}}
+	d, ok := ActualDuration(done)
+	if !ok || d.Actual != 90*time.Minute || d.Claimed != time.Hour || !d.Over() {
+		t.Errorf("ActualDuration = %+v, %v; want 1h30m actual, 1h claimed, over", d, ok)
+	}
+
+	until := &Item{ID: "bb2222", Log: []LogEntry{
+		{At: t0, Kind: "in_progress", Msg: "until " + t0.Add(2*time.Hour).Format(time.RFC3339)},
+		{At: t0.Add(time.Hour), Kind: "done"},
+	}}
+	if d, ok := ActualDuration(until); !ok || d.Claimed != 2*time.Hour || d.Over() {
+		t.Errorf("ActualDuration(until) = %+v, %v; want 2h claimed, not over", d, ok)
+	}
+
+	for _, it := range []*Item{
+		{ID: "cc3333", Log: []LogEntry{{At: t0, Kind: "done"}}},
+		{ID: "dd4444", Log: []LogEntry{{At: t0, Kind: "in_progress", Msg: "1h"}}},
+	} {
+		if _, ok := ActualDuration(it); ok {
+			t.Errorf("ActualDuration(%s) should report false", it.ID)
+		}
+	}
+
+	got := ItemDurations([]*Item{until, done})
+	if len(got) != 2 || got[0].ID != "aa1111" || got[1].ID != "bb2222" {
+		t.Errorf("ItemDurations = %+v, want longest first", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := FormatDuration(90*time.Minute + 20*time.Second); got != "1h30m0s" {
+		t.Errorf("FormatDuration = %q, want 1h30m0s", got)
+	}
+	if got := FormatDuration(42400 * time.Millisecond); got != "42s" {
+		t.Errorf("FormatDuration = %q, want 42s", got)
+	}
+}
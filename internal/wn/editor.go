@@ -2,38 +2,75 @@ package wn
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
-var ErrEditorUnset = errors.New("EDITOR is not set; use -m to provide a message or set EDITOR for interactive edit")
+var ErrEditorUnset = errors.New("no editor found; use -m to provide a message or set EDITOR for interactive edit")
 
-// RunEditorOnFile runs $EDITOR with the given file path. Returns ErrEditorUnset if EDITOR is not set.
+// ErrEditorAborted is returned (wrapped) when the editor exits with a non-zero status, e.g. :cq in
+// vim. Nothing is changed; callers report "aborted, no changes".
+var ErrEditorAborted = errors.New("aborted, no changes")
+
+// globalEditor is set by SetEditorCommand from settings editor; "" means not configured.
+var globalEditor string
+
+// SetEditorCommand sets the editor from settings (e.g. "code --wait"). See EditorCommand.
+func SetEditorCommand(editor string) {
+	globalEditor = strings.TrimSpace(editor)
+}
+
+// EditorCommand returns the editor command line: WN_EDITOR, then the settings editor, then EDITOR,
+// then vi (notepad on Windows). Returns ErrEditorUnset if none is configured and the default
+// editor is not on PATH.
+func EditorCommand() ([]string, error) {
+	for _, e := range []string{os.Getenv("WN_EDITOR"), globalEditor, os.Getenv("EDITOR")} {
+		if parts := splitEditorArgs(strings.TrimSpace(e)); len(parts) > 0 {
+			return parts, nil
+		}
+	}
+	def := "vi"
+	if runtime.GOOS == "windows" {
+		def = "notepad"
+	}
+	if _, err := exec.LookPath(def); err != nil {
+		return nil, ErrEditorUnset
+	}
+	return []string{def}, nil
+}
+
+// RunEditorOnFile runs the editor (see EditorCommand) on the given file path. Returns
+// ErrEditorUnset if there is no editor and ErrEditorAborted if the editor exits non-zero.
 func RunEditorOnFile(path string) error {
-	editor := os.Getenv("EDITOR")
-	editor = strings.TrimSpace(editor)
-	if editor == "" {
-		return ErrEditorUnset
+	parts, err := EditorCommand()
+	if err != nil {
+		return err
 	}
-	parts := splitEditorArgs(editor)
-	args := append(parts[1:], path)
-	cmd := exec.Command(parts[0], args...)
+	// The editor can be "vim" or "vim -f" or "code --wait"
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return EditorRunError(cmd.Run())
 }
 
-// EditWithEditor opens the user's $EDITOR with initial content and returns
-// the edited content. Returns ErrEditorUnset if EDITOR is not set.
-func EditWithEditor(initial string) (string, error) {
-	editor := os.Getenv("EDITOR")
-	editor = strings.TrimSpace(editor)
-	if editor == "" {
-		return "", ErrEditorUnset
+// EditorRunError maps the error from running an editor: a non-zero exit becomes ErrEditorAborted.
+func EditorRunError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%w (editor exited with status %d)", ErrEditorAborted, exitErr.ExitCode())
 	}
+	return err
+}
+
+// EditWithEditor opens the editor (see EditorCommand) with initial content and returns the
+// edited content. Returns ErrEditorUnset if there is no editor and ErrEditorAborted if the editor
+// exits non-zero, in which case the edit is discarded.
+func EditWithEditor(initial string) (string, error) {
 	f, err := os.CreateTemp("", "wn-edit-*.txt")
 	if err != nil {
 		return "", err
@@ -51,15 +88,7 @@ func EditWithEditor(initial string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	// EDITOR can be "vim" or "vim -f" or "code --wait"
-	parts := splitEditorArgs(editor)
-	bin := parts[0]
-	args := append(parts[1:], path)
-	cmd := exec.Command(bin, args...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	if err := RunEditorOnFile(path); err != nil {
 		return "", err
 	}
 	data, err := os.ReadFile(path)
@@ -1,16 +1,32 @@
 package wn
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestRunEditorOnFile_Unset(t *testing.T) {
-	orig := os.Getenv("EDITOR")
-	os.Unsetenv("EDITOR")
-	t.Cleanup(func() { os.Setenv("EDITOR", orig) })
+	clearEditorEnv(t, t.TempDir())
 	err := RunEditorOnFile("/tmp/any")
 	if err != ErrEditorUnset {
 		t.Errorf("err = %v, want ErrEditorUnset", err)
 	}
 }
+
+func TestRunEditorOnFile_AbortLeavesFile(t *testing.T) {
+	dir := t.TempDir()
+	clearEditorEnv(t, dir)
+	writeFakeEditor(t, dir, "vi", "exit 2")
+	path := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunEditorOnFile(path); !errors.Is(err, ErrEditorAborted) {
+		t.Fatalf("err = %v, want ErrEditorAborted from the default editor", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{}\n" {
+		t.Errorf("file = %q, want unchanged", data)
+	}
+}
@@ -1,7 +1,11 @@
 package wn
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -35,15 +39,86 @@ func TestSplitEditorArgs(t *testing.T) {
 	}
 }
 
+// clearEditorEnv unsets every editor source so the default (vi/notepad) is used, and points
+// PATH at dir so only fake editors written there are found.
+func clearEditorEnv(t *testing.T, dir string) {
+	t.Helper()
+	t.Setenv("WN_EDITOR", "")
+	t.Setenv("EDITOR", "")
+	t.Setenv("PATH", dir)
+	SetEditorCommand("")
+	t.Cleanup(func() { SetEditorCommand("") })
+}
+
+// writeFakeEditor writes an executable shell script to dir/name and returns its path.
+func writeFakeEditor(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor scripts need a unix shell")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
 func TestEditWithEditor_Unset(t *testing.T) {
-	orig := os.Getenv("EDITOR")
-	os.Unsetenv("EDITOR")
-	t.Cleanup(func() { os.Setenv("EDITOR", orig) })
+	clearEditorEnv(t, t.TempDir())
 	_, err := EditWithEditor("hello")
 	if err == nil {
-		t.Fatal("expected error when EDITOR unset")
+		t.Fatal("expected error when no editor is set and vi is not on PATH")
 	}
 	if err != ErrEditorUnset {
 		t.Errorf("err = %v, want ErrEditorUnset", err)
 	}
 }
+
+func TestEditWithEditor_FakeEditor(t *testing.T) {
+	dir := t.TempDir()
+	clearEditorEnv(t, dir)
+	t.Setenv("EDITOR", writeFakeEditor(t, dir, "ed.sh", `printf 'edited\n' >> "$1"`))
+	got, err := EditWithEditor("hello\n")
+	if err != nil {
+		t.Fatalf("EditWithEditor: %v", err)
+	}
+	if got != "hello\nedited" {
+		t.Errorf("EditWithEditor = %q, want %q", got, "hello\nedited")
+	}
+}
+
+func TestEditWithEditor_Aborted(t *testing.T) {
+	dir := t.TempDir()
+	clearEditorEnv(t, dir)
+	t.Setenv("EDITOR", writeFakeEditor(t, dir, "ed.sh", `printf 'half-written' > "$1"; exit 1`))
+	got, err := EditWithEditor("original")
+	if !errors.Is(err, ErrEditorAborted) {
+		t.Fatalf("err = %v, want ErrEditorAborted", err)
+	}
+	if got != "" {
+		t.Errorf("aborted edit returned %q, want nothing", got)
+	}
+}
+
+func TestEditorCommand_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	clearEditorEnv(t, dir)
+	writeFakeEditor(t, dir, "vi", "exit 0")
+	check := func(want string) {
+		t.Helper()
+		parts, err := EditorCommand()
+		if err != nil {
+			t.Fatalf("EditorCommand: %v", err)
+		}
+		if got := strings.Join(parts, " "); got != want {
+			t.Errorf("EditorCommand = %q, want %q", got, want)
+		}
+	}
+	check("vi")
+	t.Setenv("EDITOR", "nano")
+	check("nano")
+	SetEditorCommand("code --wait")
+	check("code --wait")
+	t.Setenv("WN_EDITOR", "hx")
+	check("hx")
+}
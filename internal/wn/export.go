@@ -1,8 +1,15 @@
 package wn
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -72,100 +79,355 @@ func ItemToExportItem(it *Item) *ExportItem {
 	return e
 }
 
-// exportDataWire is the structure written to export files (full attributes per item).
-type exportDataWire struct {
-	Version    int           `json:"version"`
-	ExportedAt time.Time     `json:"exported_at"`
-	Items      []*ExportItem `json:"items"`
-}
-
 // Export writes all items from the store to a single JSON file (or stdout if path is "").
+// Items are read and written one at a time (ids come from ListMeta), so memory use does not
+// grow with the size of the store. The document is compact (see ExportIndent).
 func Export(store Store, path string) error {
-	items, err := store.List()
+	return exportStore(store, path, false)
+}
+
+// ExportIndent is Export with the document indented for reading.
+func ExportIndent(store Store, path string) error {
+	return exportStore(store, path, true)
+}
+
+func exportStore(store Store, path string, indent bool) error {
+	entries, err := ListMeta(store)
 	if err != nil {
 		return err
 	}
-	return ExportItems(items, path)
+	return writeExportTo(path, func(w io.Writer) error {
+		return writeExport(w, len(entries), indent, func(i int) (*Item, error) {
+			return store.Get(entries[i].ID)
+		})
+	})
 }
 
 // ExportItems writes the given items to a single JSON file (or stdout if path is "").
 // Every item is written with all attributes (no omitempty). Callers can pass a filtered
 // subset of items from the store (e.g. by tag or status).
 func ExportItems(items []*Item, path string) error {
-	if items == nil {
-		items = []*Item{}
+	return exportItems(items, path, false)
+}
+
+// ExportItemsIndent is ExportItems with the document indented for reading.
+func ExportItemsIndent(items []*Item, path string) error {
+	return exportItems(items, path, true)
+}
+
+func exportItems(items []*Item, path string, indent bool) error {
+	return writeExportTo(path, func(w io.Writer) error {
+		return writeExport(w, len(items), indent, func(i int) (*Item, error) {
+			return items[i], nil
+		})
+	})
+}
+
+// writeExportTo runs write against a buffered writer on path (or stdout if path is ""). A file
+// is written to a temporary file next to path and renamed over it only when write succeeds, so
+// a failed export never leaves a truncated file in place of an earlier one.
+func writeExportTo(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		bw := bufio.NewWriter(os.Stdout)
+		if err := write(bw); err != nil {
+			return err
+		}
+		return bw.Flush()
 	}
-	wire := exportDataWire{
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	fail := func(err error) error {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if err := write(bw); err != nil {
+		return fail(err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fail(err)
+	}
+	if err := f.Chmod(0644); err != nil {
+		return fail(err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeExport streams an export document to w: the version/exported_at header is written by
+// hand, then each of the n items returned by item is encoded as it is fetched, so only one
+// item is held at a time. The output has the same shape as ExportData, on one line unless
+// indent is set (then laid out as json.MarshalIndent would with two-space indents).
+func writeExport(w io.Writer, n int, indent bool, item func(i int) (*Item, error)) error {
+	exportedAt, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	header, footer := `{"version":%d,"exported_at":%s,"items":[`, "]}"
+	if indent {
+		header, footer = "{\n  \"version\": %d,\n  \"exported_at\": %s,\n  \"items\": [", "\n  ]\n}\n"
+		if n == 0 {
+			footer = "]\n}\n"
+		}
+	}
+	if _, err := fmt.Fprintf(w, header, ExportSchemaVersion, exportedAt); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		it, err := item(i)
+		if err != nil {
+			return err
+		}
+		var data []byte
+		if indent {
+			data, err = json.MarshalIndent(ItemToExportItem(it), "    ", "  ")
+			data = append([]byte("\n    "), data...)
+		} else {
+			data, err = json.Marshal(ItemToExportItem(it))
+		}
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, footer)
+	return err
+}
+
+// ExportManifestName is the manifest file written alongside per-item files by ExportItemsSplit.
+const ExportManifestName = "manifest.json"
+
+// exportManifest lists the items in a split export directory.
+type exportManifest struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	IDs        []string  `json:"ids"`
+}
+
+// ExportItemsSplit writes each item to <dir>/<id>.json (all attributes, as in ExportItems)
+// plus a manifest.json listing the exported ids. dir is created if it does not exist.
+// One file per item keeps diffs small when the export is committed to version control.
+func ExportItemsSplit(items []*Item, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	manifest := exportManifest{
 		Version:    ExportSchemaVersion,
 		ExportedAt: time.Now().UTC(),
-		Items:      make([]*ExportItem, len(items)),
+		IDs:        make([]string, 0, len(items)),
 	}
-	for i, it := range items {
-		wire.Items[i] = ItemToExportItem(it)
+	for _, it := range items {
+		out, err := json.MarshalIndent(ItemToExportItem(it), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, it.ID+".json"), out, 0644); err != nil {
+			return err
+		}
+		manifest.IDs = append(manifest.IDs, it.ID)
 	}
-	out, err := json.Marshal(wire)
+	sort.Strings(manifest.IDs)
+	out, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
 		return err
 	}
-	if path == "" {
-		_, err = os.Stdout.Write(out)
-		return err
+	return os.WriteFile(filepath.Join(dir, ExportManifestName), out, 0644)
+}
+
+// readExportDir reads a split export directory: every <id>.json except the manifest is one
+// item, and its id must match the file name.
+func readExportDir(dir string) (*ExportData, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	exp := &ExportData{Version: ExportSchemaVersion}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".json" || name == ExportManifestName {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var it Item
+		if err := json.Unmarshal(data, &it); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if want := strings.TrimSuffix(name, ".json"); it.ID != want {
+			return nil, fmt.Errorf("%s: item id %q does not match file name", path, it.ID)
+		}
+		exp.Items = append(exp.Items, &it)
 	}
-	return os.WriteFile(path, out, 0644)
+	return exp, nil
 }
 
-// ImportReplace reads an export file and replaces all items in the store.
-// The store's root must already be initialized (.wn/items exists).
-func ImportReplace(store Store, path string) error {
+// readExport reads an export file and checks that every item has an id. Items are stored
+// under their own id verbatim so dependency references survive a round trip.
+// A directory is read as a split export (see ExportItemsSplit).
+func readExport(path string) (*ExportData, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return readExportDir(path)
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var exp ExportData
 	if err := json.Unmarshal(data, &exp); err != nil {
-		return err
+		return nil, err
+	}
+	for i, it := range exp.Items {
+		if it == nil || it.ID == "" {
+			return nil, fmt.Errorf("item %d in %s has no id", i, path)
+		}
 	}
+	return &exp, nil
+}
+
+// ValidateExport checks that path is a readable export file or split export directory whose
+// items all have ids, without touching any store.
+func ValidateExport(path string) error {
+	_, err := readExport(path)
+	return err
+}
+
+// danglingDeps returns a warning for each DependsOn id that is neither in the import set
+// nor in existing (the store's ids that remain after the import).
+func danglingDeps(imported []*Item, existing map[string]bool) []string {
+	inSet := make(map[string]bool, len(imported))
+	for _, it := range imported {
+		inSet[it.ID] = true
+	}
+	var warnings []string
+	for _, it := range imported {
+		for _, dep := range it.DependsOn {
+			if !inSet[dep] && !existing[dep] {
+				warnings = append(warnings, fmt.Sprintf("%s depends on %s, which is not in the import or the store", it.ID, dep))
+			}
+		}
+	}
+	return warnings
+}
+
+// tagImported adds tag to every imported item that does not already carry it. An empty tag
+// leaves the items unchanged.
+func tagImported(items []*Item, tag string) {
+	if tag == "" {
+		return
+	}
+	for _, it := range items {
+		if !slices.Contains(it.Tags, tag) {
+			it.Tags = append(it.Tags, tag)
+		}
+	}
+}
+
+// ImportOpts holds optional settings for ImportReplace and ImportAppend.
+type ImportOpts struct {
+	Tag string // added to every imported item, on top of the tags they carry (see wn import --tag)
+}
+
+// importOpts returns the first of opts, or the zero ImportOpts.
+func importOpts(opts []ImportOpts) ImportOpts {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return ImportOpts{}
+}
+
+// ImportReplace reads an export file and replaces all items in the store.
+// The store's root must already be initialized (.wn/items exists).
+// Returns warnings for dependencies on items missing from the import file.
+func ImportReplace(store Store, path string, opts ...ImportOpts) ([]string, error) {
+	exp, err := readExport(path)
+	if err != nil {
+		return nil, err
+	}
+	tagImported(exp.Items, importOpts(opts).Tag)
 	// Delete existing items
 	existing, err := store.List()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, it := range existing {
-		if err := store.Delete(it.ID); err != nil {
-			return err
+	err = WithIndexBatch(store, func() error {
+		for _, it := range existing {
+			if err := store.Delete(it.ID); err != nil {
+				return err
+			}
 		}
-	}
-	// Write new items
-	for _, it := range exp.Items {
-		if err := store.Put(it); err != nil {
-			return err
+		// Write new items
+		for _, it := range exp.Items {
+			if err := store.Put(it); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return danglingDeps(exp.Items, nil), nil
 }
 
 // ImportAppend reads an export file and adds or updates items in the store.
 // Items from the file are written with Put; same ID overwrites existing.
 // The store's root must already be initialized (.wn/items exists).
-func ImportAppend(store Store, path string) error {
-	data, err := os.ReadFile(path)
+// Returns warnings for dependencies on items in neither the import file nor the store.
+func ImportAppend(store Store, path string, opts ...ImportOpts) ([]string, error) {
+	exp, err := readExport(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var exp ExportData
-	if err := json.Unmarshal(data, &exp); err != nil {
-		return err
+	tagImported(exp.Items, importOpts(opts).Tag)
+	existing, err := store.List()
+	if err != nil {
+		return nil, err
 	}
-	for _, it := range exp.Items {
-		if err := store.Put(it); err != nil {
-			return err
+	ids := make(map[string]bool, len(existing))
+	for _, it := range existing {
+		ids[it.ID] = true
+	}
+	err = WithIndexBatch(store, func() error {
+		for _, it := range exp.Items {
+			if err := store.Put(it); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return danglingDeps(exp.Items, ids), nil
 }
 
-// StoreHasItems returns whether the store has at least one item.
+// StoreHasItems returns whether the store has at least one item. File and SQLite stores
+// answer as soon as one item is found, without loading any.
 func StoreHasItems(store Store) (bool, error) {
+	if h, ok := store.(interface{ hasItems() (bool, error) }); ok {
+		return h.hasItems()
+	}
 	items, err := store.List()
 	if err != nil {
 		return false, err
@@ -3,9 +3,15 @@ package wn
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -38,7 +44,7 @@ func TestExportImport(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err := ImportReplace(store2, path); err != nil {
+	if _, err := ImportReplace(store2, path); err != nil {
 		t.Fatalf("ImportReplace: %v", err)
 	}
 	got, err := store2.Get("abc123")
@@ -105,7 +111,7 @@ func TestImportReplace_FileNotFound(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = ImportReplace(store, filepath.Join(root, "nonexistent.json"))
+	_, err = ImportReplace(store, filepath.Join(root, "nonexistent.json"))
 	if err == nil {
 		t.Error("expected error for missing file")
 	}
@@ -159,6 +165,105 @@ func TestExport_Stdout(t *testing.T) {
 	}
 }
 
+// countingWriter discards its input, counting the bytes written.
+type countingWriter struct{ n int64 }
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+func TestWriteExport_StreamsItems(t *testing.T) {
+	const n = 5000
+	desc := strings.Repeat("x", 1024)
+	now := time.Now().UTC()
+	var before, peak runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var out countingWriter
+	err := writeExport(&out, n, false, func(i int) (*Item, error) {
+		if i == n-1 {
+			runtime.GC()
+			runtime.ReadMemStats(&peak)
+		}
+		return &Item{ID: fmt.Sprintf("s%05d", i), Description: desc, Created: now, Updated: now}, nil
+	})
+	if err != nil {
+		t.Fatalf("writeExport: %v", err)
+	}
+	if out.n < n*1024 {
+		t.Fatalf("wrote %d bytes, want at least %d", out.n, n*1024)
+	}
+	// Holding every encoded item would keep more than 5 MiB live by the last item.
+	const bound = 1 << 20
+	if peak.HeapAlloc > before.HeapAlloc && peak.HeapAlloc-before.HeapAlloc > bound {
+		t.Errorf("live heap grew by %d bytes while exporting %d items, want at most %d", peak.HeapAlloc-before.HeapAlloc, n, bound)
+	}
+}
+
+func TestWriteExportTo_failureKeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.json")
+	if err := os.WriteFile(path, []byte("previous export"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := writeExportTo(path, func(w io.Writer) error {
+		_, _ = io.WriteString(w, `{"version":1,"items":[`)
+		return errors.New("item read failed")
+	})
+	if err == nil {
+		t.Fatal("writeExportTo with a failing write = nil, want error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "previous export" {
+		t.Errorf("export file after failed write = %q, want the previous content", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+	if err := writeExportTo(path, func(w io.Writer) error { _, err := io.WriteString(w, "new"); return err }); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("export file mode = %v, want 0644", info.Mode().Perm())
+	}
+}
+
+func TestExportItemsIndent(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	exportedAt := regexp.MustCompile(`"exported_at": ?"[^"]*"`)
+	for _, items := range [][]*Item{
+		nil,
+		{{ID: "aaa111", Description: "one", Created: now, Updated: now, Tags: []string{"x"}}, {ID: "bbb222", Description: "two", Created: now, Updated: now}},
+	} {
+		dir := t.TempDir()
+		compactPath, prettyPath := filepath.Join(dir, "c.json"), filepath.Join(dir, "p.json")
+		if err := ExportItems(items, compactPath); err != nil {
+			t.Fatal(err)
+		}
+		if err := ExportItemsIndent(items, prettyPath); err != nil {
+			t.Fatal(err)
+		}
+		compact, _ := os.ReadFile(compactPath)
+		pretty, _ := os.ReadFile(prettyPath)
+		if bytes.Count(compact, []byte("\n")) != 0 {
+			t.Errorf("compact export has newlines:\n%s", compact)
+		}
+		var want bytes.Buffer
+		if err := json.Indent(&want, compact, "", "  "); err != nil {
+			t.Fatal(err)
+		}
+		want.WriteString("\n")
+		got := exportedAt.ReplaceAllString(string(pretty), `"exported_at": ""`)
+		if w := exportedAt.ReplaceAllString(want.String(), `"exported_at": ""`); got != w {
+			t.Errorf("indented export (%d items) =\n%s\nwant\n%s", len(items), got, w)
+		}
+	}
+}
+
 func TestImportReplace_InvalidJSON(t *testing.T) {
 	root := t.TempDir()
 	store, err := NewFileStore(root)
@@ -169,7 +274,7 @@ func TestImportReplace_InvalidJSON(t *testing.T) {
 	if err := os.WriteFile(path, []byte("not valid json"), 0644); err != nil {
 		t.Fatal(err)
 	}
-	err = ImportReplace(store, path)
+	_, err = ImportReplace(store, path)
 	if err == nil {
 		t.Error("expected error for invalid JSON")
 	}
@@ -275,7 +380,7 @@ func TestImportAppend_IntoEmptyStore(t *testing.T) {
 	}, path); err != nil {
 		t.Fatalf("ExportItems: %v", err)
 	}
-	if err := ImportAppend(store, path); err != nil {
+	if _, err := ImportAppend(store, path); err != nil {
 		t.Fatalf("ImportAppend: %v", err)
 	}
 	got, err := store.Get("aaa111")
@@ -304,7 +409,7 @@ func TestImportAppend_AddsToExisting(t *testing.T) {
 	}, path); err != nil {
 		t.Fatalf("ExportItems: %v", err)
 	}
-	if err := ImportAppend(store, path); err != nil {
+	if _, err := ImportAppend(store, path); err != nil {
 		t.Fatalf("ImportAppend: %v", err)
 	}
 	all, err := store.List()
@@ -340,7 +445,7 @@ func TestImportAppend_SameIDOverwrites(t *testing.T) {
 	}, path); err != nil {
 		t.Fatalf("ExportItems: %v", err)
 	}
-	if err := ImportAppend(store, path); err != nil {
+	if _, err := ImportAppend(store, path); err != nil {
 		t.Fatalf("ImportAppend: %v", err)
 	}
 	got, err := store.Get("abc123")
@@ -351,3 +456,187 @@ func TestImportAppend_SameIDOverwrites(t *testing.T) {
 		t.Errorf("description = %q, want new text", got.Description)
 	}
 }
+
+func TestImport_Tag(t *testing.T) {
+	now := time.Now().UTC()
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := ExportItems([]*Item{
+		{ID: "aaa111", Description: "tagged", Tags: []string{"bug"}, Created: now, Updated: now},
+		{ID: "bbb222", Description: "already batch", Tags: []string{"batch-1"}, Created: now, Updated: now},
+	}, path); err != nil {
+		t.Fatalf("ExportItems: %v", err)
+	}
+	for name, importFn := range map[string]func(Store, string, ...ImportOpts) ([]string, error){"append": ImportAppend, "replace": ImportReplace} {
+		store, err := NewFileStore(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := importFn(store, path, ImportOpts{Tag: "batch-1"}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		a, _ := store.Get("aaa111")
+		if !slices.Equal(a.Tags, []string{"bug", "batch-1"}) {
+			t.Errorf("%s: aaa111 tags = %v, want [bug batch-1]", name, a.Tags)
+		}
+		b, _ := store.Get("bbb222")
+		if !slices.Equal(b.Tags, []string{"batch-1"}) {
+			t.Errorf("%s: bbb222 tags = %v, want [batch-1] (no duplicate)", name, b.Tags)
+		}
+	}
+}
+
+func TestExportImport_RoundTripPreservesDependsOn(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "aaa111", Description: "first", Created: now, Updated: now, Log: []LogEntry{{At: now, Kind: "created"}}},
+		{ID: "bbb222", Description: "second", Created: now, Updated: now, DependsOn: []string{"aaa111"}, Log: []LogEntry{{At: now, Kind: "created"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := Export(store, path); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	store2, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings, err := ImportReplace(store2, path)
+	if err != nil {
+		t.Fatalf("ImportReplace: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+	got, err := store2.Get("bbb222")
+	if err != nil {
+		t.Fatalf("Get bbb222 after import: %v", err)
+	}
+	if len(got.DependsOn) != 1 || got.DependsOn[0] != "aaa111" {
+		t.Errorf("DependsOn = %v, want [aaa111]", got.DependsOn)
+	}
+	if _, err := store2.Get("aaa111"); err != nil {
+		t.Errorf("dependency aaa111 missing after import: %v", err)
+	}
+}
+
+func TestImport_WarnsOnDanglingDependsOn(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "old111", Description: "in store", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := ExportItems([]*Item{
+		{ID: "new222", Description: "ok", Created: now, Updated: now, DependsOn: []string{"old111"}},
+		{ID: "new333", Description: "dangling", Created: now, Updated: now, DependsOn: []string{"gone99"}},
+	}, path); err != nil {
+		t.Fatalf("ExportItems: %v", err)
+	}
+	warnings, err := ImportAppend(store, path)
+	if err != nil {
+		t.Fatalf("ImportAppend: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "new333") || !strings.Contains(warnings[0], "gone99") {
+		t.Errorf("append warnings = %v, want one about new333 -> gone99", warnings)
+	}
+	got, err := store.Get("new333")
+	if err != nil {
+		t.Fatalf("dangling item should still be imported: %v", err)
+	}
+	if len(got.DependsOn) != 1 || got.DependsOn[0] != "gone99" {
+		t.Errorf("DependsOn = %v, want [gone99] kept as-is", got.DependsOn)
+	}
+
+	// Replace drops old111 from the store, so new222's dependency is dangling too.
+	warnings, err = ImportReplace(store, path)
+	if err != nil {
+		t.Fatalf("ImportReplace: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("replace warnings = %v, want 2", warnings)
+	}
+}
+
+func TestImport_RejectsItemWithoutID(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "noid.json")
+	if err := os.WriteFile(path, []byte(`{"version":1,"items":[{"description":"no id"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportAppend(store, path); err == nil {
+		t.Fatal("ImportAppend with an id-less item should fail")
+	}
+	if has, _ := StoreHasItems(store); has {
+		t.Error("no items should be written when the import is rejected")
+	}
+}
+
+func TestExportItemsSplit_ImportDir(t *testing.T) {
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "bbb222", Description: "second", Created: now, Updated: now, DependsOn: []string{"aaa111"}},
+		{ID: "aaa111", Description: "first", Created: now, Updated: now},
+	}
+	dir := filepath.Join(t.TempDir(), "split")
+	if err := ExportItemsSplit(items, dir); err != nil {
+		t.Fatalf("ExportItemsSplit: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ExportManifestName))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var m exportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if m.Version != ExportSchemaVersion || strings.Join(m.IDs, ",") != "aaa111,bbb222" {
+		t.Errorf("manifest = %+v, want version %d and ids aaa111,bbb222", m, ExportSchemaVersion)
+	}
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings, err := ImportReplace(store, dir)
+	if err != nil {
+		t.Fatalf("ImportReplace dir: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+	got, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("imported %d items, want 2", len(got))
+	}
+}
+
+func TestImportDir_RejectsMismatchedFileName(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "aaa111.json"), []byte(`{"id":"zzz999","description":"x"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportAppend(store, dir); err == nil {
+		t.Fatal("ImportAppend should reject an item whose id does not match its file name")
+	}
+}
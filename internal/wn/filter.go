@@ -0,0 +1,126 @@
+package wn
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Filter is a parsed item filter expression (see ParseFilter). All terms must match.
+type Filter struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	key   string // "tag", "status", "overdue", "has", "text", "external"
+	value string
+}
+
+// filterStatuses are the values accepted by status:.
+var filterStatuses = []string{"undone", "done", "claimed", "review-ready", "closed", "suspend"}
+
+// ParseFilter parses a space-separated filter expression with AND semantics:
+//
+//	tag:X        item has tag X
+//	status:S     item status is S (undone, done, claimed, review-ready, closed, suspend)
+//	overdue      item is claimed but its claim has expired without being released
+//	has:NAME     item has a note named NAME
+//	text:SUB     item description contains SUB (case-insensitive)
+//	external:ID  item's external id is ID
+//
+// An empty expression matches every item. Unknown terms and invalid values are errors.
+func ParseFilter(expr string) (Filter, error) {
+	var f Filter
+	for _, tok := range strings.Fields(expr) {
+		key, value, hasValue := strings.Cut(tok, ":")
+		switch key {
+		case "tag":
+			if err := ValidateTag(value); err != nil {
+				return Filter{}, fmt.Errorf("filter %q: %w", tok, err)
+			}
+		case "status":
+			if !slices.Contains(filterStatuses, value) {
+				return Filter{}, fmt.Errorf("filter %q: status must be one of %s", tok, strings.Join(filterStatuses, ", "))
+			}
+		case "overdue":
+			if hasValue {
+				return Filter{}, fmt.Errorf("filter %q: overdue takes no value", tok)
+			}
+		case "has":
+			if !ValidNoteName(value) {
+				return Filter{}, fmt.Errorf("filter %q: invalid note name", tok)
+			}
+		case "text":
+			if value == "" {
+				return Filter{}, fmt.Errorf("filter %q: text needs a substring", tok)
+			}
+			value = strings.ToLower(value)
+		case "external":
+			if value == "" {
+				return Filter{}, fmt.Errorf("filter %q: external needs an id", tok)
+			}
+		default:
+			return Filter{}, fmt.Errorf("filter %q: unknown term (use tag:, status:, overdue, has:, text:, external:)", tok)
+		}
+		f.terms = append(f.terms, filterTerm{key: key, value: value})
+	}
+	return f, nil
+}
+
+// Matches reports whether the item satisfies every term of the filter at time now.
+func (f Filter) Matches(it *Item, now time.Time) bool {
+	for _, t := range f.terms {
+		if !t.matches(it, now) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t filterTerm) matches(it *Item, now time.Time) bool {
+	switch t.key {
+	case "tag":
+		return slices.Contains(it.Tags, t.value)
+	case "status":
+		return matchesStatus(it, t.value, now)
+	case "overdue":
+		return !it.Done && !it.InProgressUntil.IsZero() && !now.Before(it.InProgressUntil)
+	case "has":
+		return it.NoteIndexByName(t.value) >= 0
+	case "text":
+		return strings.Contains(strings.ToLower(it.Description), t.value)
+	case "external":
+		return it.ExternalID == t.value
+	}
+	return false
+}
+
+func matchesStatus(it *Item, status string, now time.Time) bool {
+	switch status {
+	case "undone":
+		return !it.Done
+	case "done":
+		return it.Done && (it.DoneStatus == "" || it.DoneStatus == DoneStatusDone)
+	case "closed":
+		return it.Done && it.DoneStatus == DoneStatusClosed
+	case "suspend":
+		return it.Done && it.DoneStatus == DoneStatusSuspend
+	case "claimed":
+		return !it.Done && IsInProgress(it, now)
+	case "review-ready":
+		return !it.Done && it.ReviewReady
+	}
+	return false
+}
+
+// FilterItems returns the items that match f at time now.
+func FilterItems(items []*Item, f Filter, now time.Time) []*Item {
+	var out []*Item
+	for _, it := range items {
+		if f.Matches(it, now) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
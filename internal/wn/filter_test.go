@@ -0,0 +1,136 @@
+package wn
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFilter_basicTerms(t *testing.T) {
+	now := time.Now().UTC()
+	tagged := &Item{ID: "a", Tags: []string{"backend"}}
+	expired := &Item{ID: "b", InProgressUntil: now.Add(-time.Minute)}
+	f, err := ParseFilter("tag:backend status:undone")
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	if !f.Matches(tagged, now) || f.Matches(expired, now) {
+		t.Error("tag:backend status:undone should match only the tagged item")
+	}
+	f, err = ParseFilter("overdue")
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	if f.Matches(tagged, now) || !f.Matches(expired, now) {
+		t.Error("overdue should match only the item with an expired claim")
+	}
+	f, err = ParseFilter("external:42")
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	if !f.Matches(&Item{ID: "c", ExternalID: "42"}, now) || f.Matches(&Item{ID: "d", ExternalID: "420"}, now) {
+		t.Error("external:42 should match only the item with external id 42")
+	}
+	for _, bad := range []string{"bogus:x", "status:nope", "overdue:yes", "tag:"} {
+		if _, err := ParseFilter(bad); err == nil {
+			t.Errorf("ParseFilter(%q) want error", bad)
+		}
+	}
+}
+
+func TestParseFilter_errors(t *testing.T) {
+	for _, expr := range []string{
+		"bogus",
+		"bogus:x",
+		"tag:",
+		"tag:bad/tag",
+		"status:",
+		"status:blocked",
+		"overdue:1",
+		"has:",
+		"has:bad name!",
+		"text:",
+		"external:",
+		"tag:ok bogus",
+	} {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("ParseFilter(%q) want error, got nil", expr)
+		}
+	}
+}
+
+func TestParseFilter_emptyMatchesAll(t *testing.T) {
+	f, err := ParseFilter("   ")
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	if !f.Matches(&Item{ID: "a"}, time.Now()) || !f.Matches(&Item{ID: "b", Done: true}, time.Now()) {
+		t.Error("empty filter should match every item")
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	now := time.Now().UTC()
+	items := map[string]*Item{
+		"undone":  {ID: "undone", Description: "Fix the Parser", Tags: []string{"backend", "bug"}},
+		"done":    {ID: "done", Description: "done thing", Done: true},
+		"done2":   {ID: "done2", Description: "done with status", Done: true, DoneStatus: DoneStatusDone},
+		"closed":  {ID: "closed", Description: "closed thing", Done: true, DoneStatus: DoneStatusClosed},
+		"suspend": {ID: "suspend", Description: "suspended", Done: true, DoneStatus: DoneStatusSuspend},
+		"claimed": {ID: "claimed", Description: "claimed", InProgressUntil: now.Add(time.Hour), Tags: []string{"backend"}},
+		"overdue": {ID: "overdue", Description: "stale", InProgressUntil: now.Add(-time.Hour)},
+		"review":  {ID: "review", Description: "review", ReviewReady: true, Notes: []Note{{Name: "branch", Body: "wn-x"}}},
+	}
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"status:undone", []string{"claimed", "overdue", "review", "undone"}},
+		{"status:done", []string{"done", "done2"}},
+		{"status:closed", []string{"closed"}},
+		{"status:suspend", []string{"suspend"}},
+		{"status:claimed", []string{"claimed"}},
+		{"status:review-ready", []string{"review"}},
+		{"overdue", []string{"overdue"}},
+		{"tag:backend", []string{"claimed", "undone"}},
+		{"tag:backend tag:bug", []string{"undone"}},
+		{"tag:backend status:claimed", []string{"claimed"}},
+		{"has:branch", []string{"review"}},
+		{"text:parser", []string{"undone"}},
+		{"text:THING", []string{"closed", "done"}},
+		{"text:thing status:closed", []string{"closed"}},
+		{"tag:nope", nil},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", tt.expr, err)
+		}
+		var got []string
+		for _, id := range []string{"claimed", "closed", "done", "done2", "overdue", "review", "suspend", "undone"} {
+			if f.Matches(items[id], now) {
+				got = append(got, id)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("ParseFilter(%q) matches %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFilterItems(t *testing.T) {
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "a", Tags: []string{"x"}},
+		{ID: "b"},
+		{ID: "c", Tags: []string{"x"}},
+	}
+	f, err := ParseFilter("tag:x")
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	got := FilterItems(items, f, now)
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "c" {
+		t.Errorf("FilterItems = %v, want [a c] in input order", got)
+	}
+}
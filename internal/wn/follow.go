@@ -0,0 +1,49 @@
+package wn
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultFollowPoll is how often FollowItemLog re-reads the item.
+const DefaultFollowPoll = 500 * time.Millisecond
+
+// FollowItemLog calls emit for each log entry of item id after the first from entries, then
+// re-reads the item every poll and emits entries appended since, until ctx is done (which
+// returns nil). The item is re-read through the store each time, so files replaced by an atomic
+// rename are picked up. If the log gets shorter (e.g. the item was replaced by an import), only
+// entries past the new length are emitted. Errors from emit, and read errors that persist
+// across two polls (e.g. the item was deleted), are returned.
+func FollowItemLog(ctx context.Context, store Store, id string, from int, poll time.Duration, emit func(LogEntry) error) error {
+	if poll <= 0 {
+		poll = DefaultFollowPoll
+	}
+	seen := from
+	failed := false
+	for {
+		it, err := store.Get(id)
+		if err != nil {
+			// A read can catch the file store mid-write; only give up if the next read fails too.
+			if failed {
+				return err
+			}
+			failed = true
+		} else {
+			failed = false
+			if len(it.Log) < seen {
+				seen = len(it.Log)
+			}
+			for _, e := range it.Log[seen:] {
+				if err := emit(e); err != nil {
+					return err
+				}
+			}
+			seen = len(it.Log)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+	}
+}
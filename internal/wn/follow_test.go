@@ -0,0 +1,62 @@
+package wn
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFollowItemLog(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "abc123", Description: "task", Created: now, Updated: now, Log: []LogEntry{{At: now, Kind: "created"}}}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan LogEntry, 10)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- FollowItemLog(ctx, store, "abc123", 1, 5*time.Millisecond, func(e LogEntry) error {
+			got <- e
+			return nil
+		})
+	}()
+	for _, msg := range []string{"one", "two"} {
+		err := store.UpdateItem("abc123", func(it *Item) (*Item, error) {
+			it.Log = append(it.Log, LogEntry{At: time.Now().UTC(), Kind: "note", Msg: msg})
+			return it, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-got:
+			if e.Msg != msg {
+				t.Errorf("followed entry = %q, want %q", e.Msg, msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", msg)
+		}
+	}
+	cancel()
+	if err := <-errc; err != nil {
+		t.Errorf("FollowItemLog after cancel = %v, want nil", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("unexpected extra entries: %d", len(got))
+	}
+}
+
+func TestFollowItemLog_missingItem(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := FollowItemLog(context.Background(), store, "nope00", 0, time.Millisecond, func(LogEntry) error { return nil }); err == nil {
+		t.Error("FollowItemLog on missing item = nil, want error")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 const itemsDirName = "items"
@@ -13,8 +14,11 @@ const itemsDirName = "items"
 // NewFileStore returns a file-based store at root (directory containing .wn).
 // It creates .wn/items if it does not exist.
 func NewFileStore(root string) (Store, error) {
-	wnDir := filepath.Join(root, ".wn")
-	itemsDir := filepath.Join(wnDir, itemsDirName)
+	return newFileStoreIn(root, filepath.Join(root, ".wn", itemsDirName))
+}
+
+// newFileStoreIn returns a file store for root that keeps item files in itemsDir.
+func newFileStoreIn(root, itemsDir string) (Store, error) {
 	if err := os.MkdirAll(itemsDir, 0755); err != nil {
 		return nil, err
 	}
@@ -24,6 +28,9 @@ func NewFileStore(root string) (Store, error) {
 type fileStore struct {
 	root     string
 	itemsDir string
+
+	batchMu sync.Mutex
+	batch   map[string]*IndexEntry // pending index changes inside WithIndexBatch; nil otherwise
 }
 
 func (s *fileStore) Root() string { return s.root }
@@ -55,22 +62,58 @@ func (s *fileStore) List() ([]*Item, error) {
 	return items, nil
 }
 
+// Count returns the number of item files without reading or decoding them.
+func (s *fileStore) Count() (int, error) {
+	return s.countItemFiles(-1)
+}
+
+func (s *fileStore) hasItems() (bool, error) {
+	n, err := s.countItemFiles(1)
+	return n > 0, err
+}
+
+// countItemFiles counts the item files in itemsDir, reading the directory in batches and
+// stopping once limit files are found (limit < 0 counts all).
+func (s *fileStore) countItemFiles(limit int) (int, error) {
+	d, err := os.Open(s.itemsDir)
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+	n := 0
+	for {
+		entries, err := d.ReadDir(64)
+		for _, e := range entries {
+			if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+				continue
+			}
+			n++
+			if n == limit {
+				return n, nil
+			}
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
 func (s *fileStore) Get(id string) (*Item, error) {
 	data, err := os.ReadFile(s.itemPath(id))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("item %s not found", id)
+			return nil, fmt.Errorf("item %s %w", id, ErrNotFound)
 		}
 		return nil, err
 	}
-	var item Item
-	if err := json.Unmarshal(data, &item); err != nil {
-		return nil, err
-	}
-	return &item, nil
+	return decodeItem(data)
 }
 
 func (s *fileStore) Put(item *Item) error {
+	stampItemSchema(item)
 	data, err := json.MarshalIndent(item, "", "  ")
 	if err != nil {
 		return err
@@ -91,8 +134,11 @@ func (s *fileStore) Put(item *Item) error {
 	if _, err := f.Seek(0, 0); err != nil {
 		return err
 	}
-	_, err = f.Write(data)
-	return err
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	entry := indexEntryFor(item)
+	return s.updateIndex(entry.ID, &entry)
 }
 
 // UpdateItem runs fn with the item under exclusive lock (read-modify-write).
@@ -101,7 +147,7 @@ func (s *fileStore) UpdateItem(id string, fn func(*Item) (*Item, error)) error {
 	f, err := os.OpenFile(path, os.O_RDWR, 0644)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("item %s not found", id)
+			return fmt.Errorf("item %s %w", id, ErrNotFound)
 		}
 		return err
 	}
@@ -117,17 +163,18 @@ func (s *fileStore) UpdateItem(id string, fn func(*Item) (*Item, error)) error {
 	if err != nil {
 		return err
 	}
-	var item Item
-	if err := json.Unmarshal(data, &item); err != nil {
+	item, err := decodeItem(data)
+	if err != nil {
 		return err
 	}
-	updated, err := fn(&item)
+	updated, err := fn(item)
 	if err != nil {
 		return err
 	}
 	if updated == nil {
 		return nil
 	}
+	stampItemSchema(updated)
 	data, err = json.MarshalIndent(updated, "", "  ")
 	if err != nil {
 		return err
@@ -138,8 +185,11 @@ func (s *fileStore) UpdateItem(id string, fn func(*Item) (*Item, error)) error {
 	if _, err := f.Seek(0, 0); err != nil {
 		return err
 	}
-	_, err = f.Write(data)
-	return err
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	entry := indexEntryFor(updated)
+	return s.updateIndex(entry.ID, &entry)
 }
 
 func (s *fileStore) Delete(id string) error {
@@ -147,7 +197,7 @@ func (s *fileStore) Delete(id string) error {
 	f, err := os.OpenFile(path, os.O_RDWR, 0644)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("item %s not found", id)
+			return fmt.Errorf("item %s %w", id, ErrNotFound)
 		}
 		return err
 	}
@@ -156,5 +206,8 @@ func (s *fileStore) Delete(id string) error {
 		return err
 	}
 	defer func() { _ = unlockFile(f) }()
-	return os.Remove(path)
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return s.updateIndex(id, nil)
 }
@@ -0,0 +1,490 @@
+package wn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPIURL is the GitHub REST API base used by ImportGitHubIssues unless overridden
+// (GITHUB_API_URL for GitHub Enterprise).
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// githubMaxRateWait is the longest ImportGitHubIssues sleeps for a rate limit to reset before
+// giving up; githubMaxRetries caps how often one page is retried after a rate limit.
+// githubHTTPTimeout bounds each request when no Client is given.
+const (
+	githubMaxRateWait = 2 * time.Minute
+	githubMaxRetries  = 3
+	githubHTTPTimeout = 30 * time.Second
+)
+
+var githubRepoRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// GitHubImportOpts configures ImportGitHubIssues.
+type GitHubImportOpts struct {
+	Repo          string // owner/repo
+	Label         string // optional; comma-separated labels that must all be present
+	State         string // open (default), closed, or all
+	Token         string // optional API token (GH_TOKEN); unauthenticated requests have low rate limits
+	BaseURL       string // API base URL; empty = DefaultGitHubAPIURL
+	Tag           string // optional tag added to every imported item (see wn import --tag)
+	LowercaseTags bool   // settings tag_lowercase, applied to tags made from labels
+	Client        *http.Client
+	Sleep         func(time.Duration) // waits out rate limits; nil = wait until the limit resets or ctx is done
+}
+
+// GitHubImportResult lists the ids of the items created and how many issues were skipped because
+// an item with the same external id already exists.
+type GitHubImportResult struct {
+	Added   []string
+	Skipped int
+}
+
+type githubIssue struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	HTMLURL     string `json:"html_url"`
+	State       string `json:"state"`
+	StateReason string `json:"state_reason"`
+	Labels      []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// GitHubExternalID returns the external id used for issue number of repo: "owner/repo#N", the
+// form GitHub itself uses for cross-repository references.
+func GitHubExternalID(repo string, number int) string {
+	return repo + "#" + strconv.Itoa(number)
+}
+
+// ImportGitHubIssues creates one item per issue of opts.Repo (pull requests are skipped), oldest
+// first. The first line of the description is the issue title and the rest is its body; labels
+// become tags (see GitHubLabelTag), and ExternalID/ExternalURL are set to GitHubExternalID and the
+// issue URL. Closed issues are imported as done, or closed when GitHub marks them not planned.
+// Issues whose external id is already in the store are skipped, so the import can be re-run to
+// pick up new issues. Results are paginated and rate limits are waited out for up to
+// githubMaxRateWait. The current task is unchanged.
+func ImportGitHubIssues(ctx context.Context, store Store, opts GitHubImportOpts) (GitHubImportResult, error) {
+	var res GitHubImportResult
+	if !githubRepoRegex.MatchString(opts.Repo) {
+		return res, fmt.Errorf("invalid repository %q: use owner/repo", opts.Repo)
+	}
+	state := opts.State
+	if state == "" {
+		state = "open"
+	}
+	if state != "open" && state != "closed" && state != "all" {
+		return res, fmt.Errorf("invalid state %q: use open, closed, or all", opts.State)
+	}
+	if opts.Tag != "" {
+		if err := ValidateTag(opts.Tag); err != nil {
+			return res, err
+		}
+	}
+	existing, err := store.List()
+	if err != nil {
+		return res, err
+	}
+	imported := make(map[string]bool)
+	for _, it := range existing {
+		if it.ExternalID != "" {
+			imported[it.ExternalID] = true
+		}
+	}
+	base := strings.TrimRight(opts.BaseURL, "/")
+	if base == "" {
+		base = DefaultGitHubAPIURL
+	}
+	q := url.Values{"state": {state}, "sort": {"created"}, "direction": {"asc"}, "per_page": {"100"}}
+	if opts.Label != "" {
+		q.Set("labels", opts.Label)
+	}
+	api := newGitHubAPI(base, opts.Token, opts.Client, opts.Sleep)
+	next := base + "/repos/" + opts.Repo + "/issues?" + q.Encode()
+	err = WithIndexBatch(store, func() error { return importGitHubPages(ctx, store, api, next, imported, opts, &res) })
+	return res, err
+}
+
+// importGitHubPages fetches issue pages starting at next and adds the issues not yet in
+// imported (see ImportGitHubIssues).
+func importGitHubPages(ctx context.Context, store Store, api githubAPI, next string, imported map[string]bool, opts GitHubImportOpts, res *GitHubImportResult) error {
+	var err error
+	for next != "" {
+		var issues []githubIssue
+		next, err = api.do(ctx, http.MethodGet, next, nil, &issues)
+		if err != nil {
+			return err
+		}
+		for _, is := range issues {
+			if is.PullRequest != nil {
+				continue
+			}
+			extID := GitHubExternalID(opts.Repo, is.Number)
+			if imported[extID] {
+				res.Skipped++
+				continue
+			}
+			id, err := GenerateID(store)
+			if err != nil {
+				return err
+			}
+			item := githubIssueItem(is, id, extID, opts, time.Now().UTC())
+			if err := store.Put(item); err != nil {
+				return err
+			}
+			imported[extID] = true
+			res.Added = append(res.Added, id)
+		}
+	}
+	return nil
+}
+
+// githubIssueItem builds the wn item for an issue (see ImportGitHubIssues).
+func githubIssueItem(is githubIssue, id, extID string, opts GitHubImportOpts, now time.Time) *Item {
+	desc := strings.TrimSpace(is.Title)
+	if body := strings.TrimSpace(strings.ReplaceAll(is.Body, "\r\n", "\n")); body != "" {
+		desc += "\n\n" + body
+	}
+	var tags []string
+	for _, l := range is.Labels {
+		if t, ok := GitHubLabelTag(l.Name, opts.LowercaseTags); ok && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	if opts.Tag != "" && !slices.Contains(tags, opts.Tag) {
+		tags = append(tags, opts.Tag)
+	}
+	item := &Item{
+		ID:          id,
+		Description: desc,
+		Created:     now,
+		Updated:     now,
+		Tags:        tags,
+		Log:         []LogEntry{{At: now, Kind: "created", Msg: "imported from " + is.HTMLURL}},
+		ExternalID:  extID,
+		ExternalURL: is.HTMLURL,
+	}
+	if is.State == "closed" {
+		item.Done = true
+		if is.StateReason == "not_planned" {
+			item.DoneStatus = DoneStatusClosed
+		}
+	}
+	return item
+}
+
+var githubLabelInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// GitHubLabelTag converts a GitHub label to a tag: runs of characters not allowed in tags
+// (spaces, colons, emoji, ...) become "-", and the result is cut to TagMaxLen. Returns false when
+// nothing usable is left.
+func GitHubLabelTag(label string, lowercase bool) (string, bool) {
+	t := strings.Trim(githubLabelInvalid.ReplaceAllString(strings.TrimSpace(label), "-"), "-")
+	if len(t) > TagMaxLen { // only ASCII is left
+		t = strings.TrimRight(t[:TagMaxLen], "-")
+	}
+	if lowercase {
+		t = strings.ToLower(t)
+	}
+	return t, ValidateTag(t) == nil
+}
+
+// githubAPI sends authenticated GitHub REST API requests, waiting out rate limits. The token is
+// only sent to the host of the API base, so a Link header pointing elsewhere can't collect it.
+type githubAPI struct {
+	host   string
+	token  string
+	client *http.Client
+	sleep  func(time.Duration)
+}
+
+func newGitHubAPI(base, token string, client *http.Client, sleep func(time.Duration)) githubAPI {
+	if client == nil {
+		client = &http.Client{Timeout: githubHTTPTimeout}
+	}
+	var host string
+	if u, err := url.Parse(base); err == nil {
+		host = u.Host
+	}
+	return githubAPI{host: host, token: token, client: client, sleep: sleep}
+}
+
+// wait sleeps for d (through the Sleep hook when set), returning early with ctx's error when
+// ctx is done.
+func (a githubAPI) wait(ctx context.Context, d time.Duration) error {
+	if a.sleep != nil {
+		a.sleep(d)
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// do sends a request with payload (if non-nil) as the JSON body, decodes a successful response
+// into v (if non-nil), and returns the URL of the next page from the Link header ("" on the last
+// page). A rate-limited request is retried after the limit resets when that is at most
+// githubMaxRateWait away.
+func (a githubAPI) do(ctx context.Context, method, u string, payload, v any) (string, error) {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return "", err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(data))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		req.Header.Set("User-Agent", "wn")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if a.token != "" && req.URL.Host == a.host {
+			req.Header.Set("Authorization", "Bearer "+a.token)
+		}
+		resp, err := a.client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+			if wait, ok := githubRateLimitWait(resp.Header, time.Now()); ok {
+				if wait > githubMaxRateWait || attempt >= githubMaxRetries {
+					return "", fmt.Errorf("GitHub rate limit exceeded; try again in %s or set GH_TOKEN", wait.Round(time.Second))
+				}
+				if err := a.wait(ctx, wait); err != nil {
+					return "", err
+				}
+				continue
+			}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			var apiErr struct {
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+				return "", fmt.Errorf("GitHub API: %s (%s)", apiErr.Message, resp.Status)
+			}
+			return "", fmt.Errorf("GitHub API: %s", resp.Status)
+		}
+		if v != nil {
+			if err := json.Unmarshal(body, v); err != nil {
+				return "", fmt.Errorf("GitHub API: %w", err)
+			}
+		}
+		return githubNextLink(resp.Header.Get("Link")), nil
+	}
+}
+
+// githubRateLimitWait returns how long to wait before retrying a rate-limited response: the
+// Retry-After seconds (secondary limits), or until X-RateLimit-Reset when no requests remain.
+func githubRateLimitWait(h http.Header, now time.Time) (time.Duration, bool) {
+	if s := h.Get("Retry-After"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			return time.Duration(n) * time.Second, true
+		}
+	}
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0) + time.Second, true
+		}
+	}
+	return 0, false
+}
+
+var githubLinkNext = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// githubNextLink returns the rel="next" URL of a Link header, or "".
+func githubNextLink(link string) string {
+	if m := githubLinkNext.FindStringSubmatch(link); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// GitHubSyncOpts configures SyncBackGitHub.
+type GitHubSyncOpts struct {
+	Token   string // API token (GH_TOKEN); required unless DryRun
+	BaseURL string // API base URL; empty = DefaultGitHubAPIURL
+	Comment bool   // post the item's done message as an issue comment before closing
+	DryRun  bool   // report what would be synced without calling the API or changing items
+	Client  *http.Client
+	Sleep   func(time.Duration) // waits out rate limits; nil = wait until the limit resets or ctx is done
+}
+
+// GitHubSyncResult is the outcome of syncing one item back to its issue.
+type GitHubSyncResult struct {
+	ItemID  string
+	Issue   string // owner/repo#N
+	Reason  string // GitHub state_reason: completed or not_planned
+	Comment string // done message posted as a comment, if any
+	Err     error  // nil when the issue was closed (or would be, with DryRun)
+}
+
+var (
+	githubExternalIDRegex = regexp.MustCompile(`^([A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+)#([0-9]+)$`)
+	githubIssuePathRegex  = regexp.MustCompile(`^/([A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+)/issues/([0-9]+)/?$`)
+)
+
+// githubIssueRef returns the repository and issue number an item was imported from: its
+// external id when it has the owner/repo#N form, else its external URL when that is an issue URL
+// on github.com or on the host of the API base (GitHub Enterprise). URLs on other hosts are
+// ignored so sync-back never closes an unrelated issue with the same owner/repo path.
+func githubIssueRef(it *Item, base string) (string, int, bool) {
+	m := githubExternalIDRegex.FindStringSubmatch(it.ExternalID)
+	if m == nil {
+		m = githubIssueURLMatch(it.ExternalURL, base)
+	}
+	if m == nil {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], n, true
+}
+
+// githubIssueURLMatch matches an issue URL whose host is github.com or the host of base,
+// returning the same submatches as githubExternalIDRegex.
+func githubIssueURLMatch(rawURL, base string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != "github.com" {
+		b, err := url.Parse(base)
+		if err != nil || host != strings.ToLower(b.Hostname()) {
+			return nil
+		}
+	}
+	return githubIssuePathRegex.FindStringSubmatch(u.Path)
+}
+
+// needsGitHubSync reports whether the item was marked done or closed in wn since its issue was
+// last closed by sync-back. Suspended items and items imported already closed (no done log
+// entry) are left alone.
+func needsGitHubSync(it *Item) bool {
+	if !it.Done || it.DoneStatus == DoneStatusSuspend {
+		return false
+	}
+	lastDone, lastSync := -1, -1
+	for i, e := range it.Log {
+		switch e.Kind {
+		case "done", "closed":
+			lastDone = i
+		case "github_closed":
+			lastSync = i
+		}
+	}
+	return lastDone > lastSync
+}
+
+// githubCommented reports whether the done message was already posted as a comment since the
+// item was last marked done, so a sync retried after a failed close does not post it twice.
+func githubCommented(it *Item) bool {
+	for i := len(it.Log) - 1; i >= 0; i-- {
+		switch it.Log[i].Kind {
+		case "github_commented":
+			return true
+		case "done", "closed":
+			return false
+		}
+	}
+	return false
+}
+
+// SyncBackGitHub closes the GitHub issue of every item imported from GitHub (see
+// githubIssueRef) that was marked done or closed in wn since its last sync: done items close the
+// issue as completed and closed items as not planned. With opts.Comment the item's done message
+// is posted first. A "github_closed" log entry records each sync so it is not repeated.
+// Failures are reported per item in the results and do not stop the others; the returned error
+// is for problems that prevent syncing at all.
+func SyncBackGitHub(ctx context.Context, store Store, opts GitHubSyncOpts) ([]GitHubSyncResult, error) {
+	if opts.Token == "" && !opts.DryRun {
+		return nil, fmt.Errorf("a GitHub token is required (set GH_TOKEN); use --dry-run to preview without one")
+	}
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(items, func(a, b *Item) int { return a.Created.Compare(b.Created) })
+	base := strings.TrimRight(opts.BaseURL, "/")
+	if base == "" {
+		base = DefaultGitHubAPIURL
+	}
+	api := newGitHubAPI(base, opts.Token, opts.Client, opts.Sleep)
+	var results []GitHubSyncResult
+	for _, it := range items {
+		repo, number, ok := githubIssueRef(it, base)
+		if !ok || !needsGitHubSync(it) {
+			continue
+		}
+		r := GitHubSyncResult{ItemID: it.ID, Issue: GitHubExternalID(repo, number), Reason: "completed"}
+		if it.DoneStatus == DoneStatusClosed {
+			r.Reason = "not_planned"
+		}
+		if opts.Comment && !githubCommented(it) {
+			r.Comment = strings.TrimSpace(it.DoneMessage)
+		}
+		if !opts.DryRun {
+			r.Err = closeGitHubIssue(ctx, api, store, it.ID, base, repo, number, r.Reason, r.Comment)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// closeGitHubIssue posts comment (if any) on the issue and closes it with reason, logging each
+// step on item id as it succeeds ("github_commented", then "github_closed").
+func closeGitHubIssue(ctx context.Context, api githubAPI, store Store, id, base, repo string, number int, reason, comment string) error {
+	issueURL := fmt.Sprintf("%s/repos/%s/issues/%d", base, repo, number)
+	issue := GitHubExternalID(repo, number)
+	logSync := func(kind string) error {
+		now := time.Now().UTC()
+		return store.UpdateItem(id, func(it *Item) (*Item, error) {
+			// Bookkeeping only: Updated is left alone so the item keeps its place in listings.
+			it.Log = append(it.Log, LogEntry{At: now, Kind: kind, Msg: issue})
+			return it, nil
+		})
+	}
+	if comment != "" {
+		if _, err := api.do(ctx, http.MethodPost, issueURL+"/comments", map[string]string{"body": comment}, nil); err != nil {
+			return fmt.Errorf("comment: %w", err)
+		}
+		if err := logSync("github_commented"); err != nil {
+			return err
+		}
+	}
+	if _, err := api.do(ctx, http.MethodPatch, issueURL, map[string]string{"state": "closed", "state_reason": reason}, nil); err != nil {
+		return err
+	}
+	return logSync("github_closed")
+}
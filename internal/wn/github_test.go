@@ -0,0 +1,257 @@
+package wn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestImportGitHubIssues(t *testing.T) {
+	var srv *httptest.Server
+	var limited bool
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q, want Bearer tok", got)
+		}
+		if r.URL.Path != "/repos/o/r/issues" || r.URL.Query().Get("labels") != "bug" || r.URL.Query().Get("state") != "all" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/issues?page=2&labels=bug&state=all>; rel="next", <%s/x>; rel="last"`, srv.URL, srv.URL))
+			fmt.Fprint(w, `[
+				{"number": 1, "title": "Crash on start", "body": "Steps:\r\n1. run", "html_url": "https://github.com/o/r/issues/1", "state": "open", "labels": [{"name": "bug"}, {"name": "good first issue"}]},
+				{"number": 2, "title": "A pull request", "html_url": "https://github.com/o/r/pull/2", "state": "open", "pull_request": {}}
+			]`)
+		case "2":
+			if !limited {
+				limited = true
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `[{"number": 3, "title": "Won't fix", "html_url": "https://github.com/o/r/issues/3", "state": "closed", "state_reason": "not_planned", "labels": [{"name": "bug"}]}]`)
+		}
+	}))
+	defer srv.Close()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slept time.Duration
+	opts := GitHubImportOpts{
+		Repo: "o/r", Label: "bug", State: "all", Token: "tok", BaseURL: srv.URL, Tag: "gh",
+		Sleep: func(d time.Duration) { slept += d },
+	}
+	res, err := ImportGitHubIssues(context.Background(), store, opts)
+	if err != nil {
+		t.Fatalf("ImportGitHubIssues: %v", err)
+	}
+	if len(res.Added) != 2 || res.Skipped != 0 {
+		t.Fatalf("result = %+v, want 2 added (the pull request skipped)", res)
+	}
+	if slept <= 0 || slept > time.Minute {
+		t.Errorf("slept %v for the rate limit, want about 30s", slept)
+	}
+	first, _ := store.Get(res.Added[0])
+	if first.Description != "Crash on start\n\nSteps:\n1. run" || first.ExternalID != "o/r#1" || first.ExternalURL != "https://github.com/o/r/issues/1" {
+		t.Errorf("first item = %q %q %q", first.Description, first.ExternalID, first.ExternalURL)
+	}
+	if !slices.Equal(first.Tags, []string{"bug", "good-first-issue", "gh"}) || first.Done {
+		t.Errorf("first item tags = %v done = %v, want [bug good-first-issue gh], undone", first.Tags, first.Done)
+	}
+	second, _ := store.Get(res.Added[1])
+	if !second.Done || second.DoneStatus != DoneStatusClosed || second.ExternalID != "o/r#3" {
+		t.Errorf("not-planned issue: done=%v status=%q external=%q, want closed o/r#3", second.Done, second.DoneStatus, second.ExternalID)
+	}
+
+	// A re-run skips issues already imported.
+	res, err = ImportGitHubIssues(context.Background(), store, opts)
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if len(res.Added) != 0 || res.Skipped != 2 {
+		t.Errorf("re-run result = %+v, want 0 added, 2 skipped", res)
+	}
+}
+
+func TestImportGitHubIssues_errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	}))
+	defer srv.Close()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []GitHubImportOpts{
+		{Repo: "not-a-repo"},
+		{Repo: "o/r", State: "merged"},
+		{Repo: "o/r", BaseURL: srv.URL},
+	} {
+		if _, err := ImportGitHubIssues(context.Background(), store, opts); err == nil {
+			t.Errorf("ImportGitHubIssues(%+v) want error", opts)
+		}
+	}
+}
+
+func TestImportGitHubIssues_tokenOnlyForBaseHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization sent to a foreign Link host: %q", got)
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/page2>; rel="next"`, other.URL))
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportGitHubIssues(context.Background(), store, GitHubImportOpts{Repo: "o/r", Token: "tok", BaseURL: srv.URL}); err != nil {
+		t.Fatalf("ImportGitHubIssues: %v", err)
+	}
+}
+
+func TestImportGitHubIssues_rateLimitWaitHonorsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = ImportGitHubIssues(ctx, store, GitHubImportOpts{Repo: "o/r", BaseURL: srv.URL})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ImportGitHubIssues during rate-limit wait = %v, want context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("rate-limit wait ignored cancellation, took %v", elapsed)
+	}
+}
+
+func TestGitHubLabelTag(t *testing.T) {
+	for _, tc := range []struct {
+		label string
+		want  string
+		ok    bool
+	}{
+		{"bug", "bug", true},
+		{"good first issue", "good-first-issue", true},
+		{"area: CLI", "area-CLI", true},
+		{"🚀", "", false},
+		{"a very long label name that goes past the tag limit", "a-very-long-label-name-that-goes", true},
+	} {
+		got, ok := GitHubLabelTag(tc.label, false)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("GitHubLabelTag(%q) = %q, %v; want %q, %v", tc.label, got, ok, tc.want, tc.ok)
+		}
+	}
+	if got, _ := GitHubLabelTag("Area: CLI", true); got != "area-cli" {
+		t.Errorf("lowercase label tag = %q, want area-cli", got)
+	}
+}
+
+func TestSyncBackGitHub(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		if r.URL.Path == "/repos/o/r/issues/9" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for i, it := range []*Item{
+		{ID: "done11", ExternalID: "o/r#1", Done: true, DoneMessage: "fixed in v2", Log: []LogEntry{{At: now, Kind: "done", Msg: "fixed in v2"}}},
+		{ID: "clos22", ExternalURL: "https://github.com/o/r/issues/2", Done: true, DoneStatus: DoneStatusClosed, Log: []LogEntry{{At: now, Kind: "closed"}}},
+		{ID: "fail99", ExternalID: "o/r#9", Done: true, DoneMessage: "wip", Log: []LogEntry{{At: now, Kind: "done"}}},
+		{ID: "other7", ExternalURL: "https://gitlab.example/o/r/issues/7", Done: true, Log: []LogEntry{{At: now, Kind: "done"}}},
+		{ID: "open33", ExternalID: "o/r#3"},
+		{ID: "imp444", ExternalID: "o/r#4", Done: true, Log: []LogEntry{{At: now, Kind: "created"}}},
+		{ID: "susp55", ExternalID: "o/r#5", Done: true, DoneStatus: DoneStatusSuspend, Log: []LogEntry{{At: now, Kind: "suspend"}}},
+		{ID: "local6", Done: true, Log: []LogEntry{{At: now, Kind: "done"}}},
+	} {
+		it.Description = it.ID
+		it.Created = now.Add(time.Duration(i) * time.Second)
+		it.Updated = it.Created
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := SyncBackGitHub(context.Background(), store, GitHubSyncOpts{BaseURL: srv.URL}); err == nil {
+		t.Error("SyncBackGitHub without a token should fail")
+	}
+	results, err := SyncBackGitHub(context.Background(), store, GitHubSyncOpts{BaseURL: srv.URL, DryRun: true, Comment: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(results) != 3 || len(requests) != 0 {
+		t.Fatalf("dry run: %d results, %d requests; want 3 results and no requests", len(results), len(requests))
+	}
+
+	results, err = SyncBackGitHub(context.Background(), store, GitHubSyncOpts{Token: "tok", BaseURL: srv.URL, Comment: true})
+	if err != nil {
+		t.Fatalf("SyncBackGitHub: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, fmt.Sprintf("%s %s %s %v", r.ItemID, r.Issue, r.Reason, r.Err != nil))
+	}
+	want := []string{"done11 o/r#1 completed false", "clos22 o/r#2 not_planned false", "fail99 o/r#9 completed true"}
+	if !slices.Equal(got, want) {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+	wantReqs := []string{
+		`POST /repos/o/r/issues/1/comments {"body":"fixed in v2"}`,
+		`PATCH /repos/o/r/issues/1 {"state":"closed","state_reason":"completed"}`,
+		`PATCH /repos/o/r/issues/2 {"state":"closed","state_reason":"not_planned"}`,
+		`POST /repos/o/r/issues/9/comments {"body":"wip"}`,
+		`PATCH /repos/o/r/issues/9 {"state":"closed","state_reason":"completed"}`,
+	}
+	if !slices.Equal(requests, wantReqs) {
+		t.Errorf("requests = %q, want %q", requests, wantReqs)
+	}
+
+	// Synced items are not synced again; the failed one is retried without re-posting its comment.
+	requests = nil
+	results, err = SyncBackGitHub(context.Background(), store, GitHubSyncOpts{Token: "tok", BaseURL: srv.URL, Comment: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ItemID != "fail99" {
+		t.Errorf("second sync results = %+v, want only fail99", results)
+	}
+	if want := []string{`PATCH /repos/o/r/issues/9 {"state":"closed","state_reason":"completed"}`}; !slices.Equal(requests, want) {
+		t.Errorf("retry requests = %q, want %q", requests, want)
+	}
+}
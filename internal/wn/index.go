@@ -0,0 +1,273 @@
+package wn
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	indexFileName = "index.json"
+	indexLockName = "index.lock"
+)
+
+// IndexEntry is the per-item summary kept in .wn/index.json so status and tag queries
+// can skip reading every item file.
+type IndexEntry struct {
+	ID      string    `json:"id"`
+	Done    bool      `json:"done"`
+	Updated time.Time `json:"updated"`
+	Tags    []string  `json:"tags,omitempty"`
+}
+
+type itemIndex struct {
+	Items map[string]IndexEntry `json:"items"`
+}
+
+func indexEntryFor(it *Item) IndexEntry {
+	return IndexEntry{ID: it.ID, Done: it.Done, Updated: it.Updated, Tags: it.Tags}
+}
+
+func (s *fileStore) indexPath() string {
+	return filepath.Join(s.root, ".wn", indexFileName)
+}
+
+// withIndexLock runs fn with exclusive lock on the index file.
+func (s *fileStore) withIndexLock(fn func() error) error {
+	lf, err := os.OpenFile(filepath.Join(s.root, ".wn", indexLockName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+	if err := lockFile(lf); err != nil {
+		return err
+	}
+	defer func() { _ = unlockFile(lf) }()
+	return fn()
+}
+
+func (s *fileStore) readIndex() (*itemIndex, os.FileInfo, error) {
+	path := s.indexPath()
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var idx itemIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, nil, err
+	}
+	if idx.Items == nil {
+		idx.Items = make(map[string]IndexEntry)
+	}
+	return &idx, fi, nil
+}
+
+func (s *fileStore) writeIndex(idx *itemIndex) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.indexPath())
+}
+
+// updateIndex sets the index entry for id, or removes it when entry is nil, when an index
+// exists. A missing or unreadable index is left alone; ListMeta rebuilds it on demand. Inside
+// WithIndexBatch the change is only recorded and written when the batch ends.
+func (s *fileStore) updateIndex(id string, entry *IndexEntry) error {
+	s.batchMu.Lock()
+	if s.batch != nil {
+		s.batch[id] = entry
+		s.batchMu.Unlock()
+		return nil
+	}
+	s.batchMu.Unlock()
+	return s.applyIndex(map[string]*IndexEntry{id: entry})
+}
+
+// applyIndex writes changes (nil entries are removals) to the index in one read-modify-write.
+func (s *fileStore) applyIndex(changes map[string]*IndexEntry) error {
+	return s.withIndexLock(func() error {
+		idx, _, err := s.readIndex()
+		if err != nil {
+			return nil
+		}
+		for id, e := range changes {
+			if e == nil {
+				delete(idx.Items, id)
+			} else {
+				idx.Items[id] = *e
+			}
+		}
+		return s.writeIndex(idx)
+	})
+}
+
+// WithIndexBatch runs fn with the file store's index updates held back and written once when
+// fn returns, so bulk writes such as import and migrate rewrite .wn/index.json once instead of
+// once per item. Other stores just run fn. The batch is written even when fn fails, so the index
+// matches whatever fn managed to write.
+func WithIndexBatch(store Store, fn func() error) error {
+	fs, ok := store.(*fileStore)
+	if !ok {
+		return fn()
+	}
+	fs.batchMu.Lock()
+	if fs.batch != nil {
+		// Already batching (nested call): the outer batch writes the changes.
+		fs.batchMu.Unlock()
+		return fn()
+	}
+	fs.batch = make(map[string]*IndexEntry)
+	fs.batchMu.Unlock()
+	err := fn()
+	fs.batchMu.Lock()
+	changes := fs.batch
+	fs.batch = nil
+	fs.batchMu.Unlock()
+	if len(changes) > 0 {
+		if ierr := fs.applyIndex(changes); err == nil {
+			err = ierr
+		}
+	}
+	return err
+}
+
+// freshIndex returns the index entries if the index exists and matches the item files:
+// same set of ids, and no item file modified after the index was written.
+func (s *fileStore) freshIndex() ([]IndexEntry, bool) {
+	idx, fi, err := s.readIndex()
+	if err != nil {
+		return nil, false
+	}
+	entries, err := os.ReadDir(s.itemsDir)
+	if err != nil {
+		return nil, false
+	}
+	n := 0
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		id := e.Name()[:len(e.Name())-len(".json")]
+		if _, ok := idx.Items[id]; !ok {
+			return nil, false
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(fi.ModTime()) {
+			return nil, false
+		}
+		n++
+	}
+	if n != len(idx.Items) {
+		return nil, false
+	}
+	return sortedIndexEntries(idx.Items), true
+}
+
+func (s *fileStore) reindex() ([]IndexEntry, error) {
+	items, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	idx := &itemIndex{Items: make(map[string]IndexEntry, len(items))}
+	for _, it := range items {
+		idx.Items[it.ID] = indexEntryFor(it)
+	}
+	if err := s.withIndexLock(func() error { return s.writeIndex(idx) }); err != nil {
+		return nil, err
+	}
+	return sortedIndexEntries(idx.Items), nil
+}
+
+func sortedIndexEntries(m map[string]IndexEntry) []IndexEntry {
+	out := make([]IndexEntry, 0, len(m))
+	for _, e := range m {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// ListMeta returns a summary (id, done, updated, tags) of every item, sorted by id. For a file
+// store it reads .wn/index.json, rebuilding it with a full scan when missing or stale (e.g. after
+// manual edits). Stores with their own index (SQLite) query it; others fall back to a full List.
+func ListMeta(store Store) ([]IndexEntry, error) {
+	if fs, ok := store.(*fileStore); ok {
+		if entries, fresh := fs.freshIndex(); fresh {
+			return entries, nil
+		}
+		return fs.reindex()
+	}
+	if ml, ok := store.(interface{ listMeta() ([]IndexEntry, error) }); ok {
+		return ml.listMeta()
+	}
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]IndexEntry, len(items))
+	for i, it := range items {
+		out[i] = indexEntryFor(it)
+	}
+	return out, nil
+}
+
+// Reindex rebuilds .wn/index.json from the item files. Returns the number of items indexed.
+func Reindex(store Store) (int, error) {
+	fs, ok := store.(*fileStore)
+	if !ok {
+		return 0, fmt.Errorf("store does not keep an index")
+	}
+	entries, err := fs.reindex()
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// listUndoneFromIndex loads only the items the index reports as undone.
+func listUndoneFromIndex(store Store) ([]*Item, error) {
+	entries, err := ListMeta(store)
+	if err != nil {
+		return nil, err
+	}
+	var items []*Item
+	for _, e := range entries {
+		if e.Done {
+			continue
+		}
+		it, err := store.Get(e.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !it.Done {
+			items = append(items, it)
+		}
+	}
+	return items, nil
+}
+
+// BlockedSetFromIndex is BlockedSet for a subset of items (e.g. undone items), using the
+// index for the done state of dependencies instead of loading every item.
+func BlockedSetFromIndex(store Store, items []*Item) (map[string]bool, error) {
+	entries, err := ListMeta(store)
+	if err != nil {
+		return nil, err
+	}
+	doneByID := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		doneByID[e.ID] = e.Done
+	}
+	return blockedSet(items, doneByID), nil
+}
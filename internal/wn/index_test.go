@@ -0,0 +1,158 @@
+package wn
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListMeta_buildsAndMaintainsIndex(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "a", Tags: []string{"x"}, Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "bb2222", Description: "b", Done: true, Created: now, Updated: now})
+
+	entries, err := ListMeta(store)
+	if err != nil {
+		t.Fatalf("ListMeta: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "aa1111" || entries[0].Done || !entries[1].Done {
+		t.Fatalf("ListMeta = %+v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".wn", "index.json")); err != nil {
+		t.Fatalf("index.json should exist after ListMeta: %v", err)
+	}
+
+	// Changes through the store keep the index current.
+	if err := store.UpdateItem("aa1111", func(it *Item) (*Item, error) {
+		it.Done = true
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Put(&Item{ID: "cc3333", Description: "c", Created: now, Updated: now})
+	if err := store.Delete("bb2222"); err != nil {
+		t.Fatal(err)
+	}
+	fs := store.(*fileStore)
+	entries, fresh := fs.freshIndex()
+	if !fresh {
+		t.Fatal("index should be fresh after store changes")
+	}
+	if len(entries) != 2 || entries[0].ID != "aa1111" || !entries[0].Done || entries[1].ID != "cc3333" {
+		t.Errorf("index after changes = %+v", entries)
+	}
+}
+
+func TestWithIndexBatch(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "a", Created: now, Updated: now})
+	if _, err := ListMeta(store); err != nil {
+		t.Fatal(err)
+	}
+	indexPath := filepath.Join(root, ".wn", "index.json")
+	before, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = WithIndexBatch(store, func() error {
+		_ = store.Put(&Item{ID: "bb2222", Description: "b", Created: now, Updated: now})
+		_ = store.Put(&Item{ID: "cc3333", Description: "c", Created: now, Updated: now})
+		if err := store.Delete("aa1111"); err != nil {
+			return err
+		}
+		if during, _ := os.ReadFile(indexPath); string(during) != string(before) {
+			t.Error("index rewritten inside the batch")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithIndexBatch: %v", err)
+	}
+	entries, fresh := store.(*fileStore).freshIndex()
+	if !fresh || len(entries) != 2 || entries[0].ID != "bb2222" || entries[1].ID != "cc3333" {
+		t.Errorf("index after batch = %+v (fresh %v), want bb2222, cc3333", entries, fresh)
+	}
+}
+
+func TestListMeta_staleIndexRebuilt(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "a", Created: now, Updated: now})
+	if _, err := Reindex(store); err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	// A manual edit (bypassing the store) marks the item done and adds a new file.
+	path := filepath.Join(root, ".wn", "items", "aa1111.json")
+	later := time.Now().Add(time.Second)
+	if err := os.WriteFile(path, []byte(`{"id":"aa1111","description":"a","done":true,"tags":[],"depends_on":[],"log":[]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ListMeta(store)
+	if err != nil {
+		t.Fatalf("ListMeta: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Done {
+		t.Errorf("ListMeta after manual edit = %+v, want aa1111 done", entries)
+	}
+	undone, err := UndoneItems(store)
+	if err != nil {
+		t.Fatalf("UndoneItems: %v", err)
+	}
+	if len(undone) != 0 {
+		t.Errorf("UndoneItems = %v, want none", undone)
+	}
+}
+
+func TestBlockedSetFromIndex(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	_ = store.Put(&Item{ID: "aa1111", Description: "done dep", Done: true, Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "bb2222", Description: "undone dep", Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "cc3333", Description: "on done", DependsOn: []string{"aa1111"}, Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "dd4444", Description: "on undone", DependsOn: []string{"bb2222"}, Created: now, Updated: now})
+	undone, err := ListableUndoneItems(store)
+	if err != nil {
+		t.Fatalf("ListableUndoneItems: %v", err)
+	}
+	blocked, err := BlockedSetFromIndex(store, undone)
+	if err != nil {
+		t.Fatalf("BlockedSetFromIndex: %v", err)
+	}
+	if len(blocked) != 1 || !blocked["dd4444"] {
+		t.Errorf("BlockedSetFromIndex = %v, want only dd4444", blocked)
+	}
+}
@@ -1,12 +1,44 @@
 package wn
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 )
 
-// InitRoot creates .wn and .wn/items under dir. Idempotent.
+// wnGitignore keeps wn's local caches and lock files, and the SQLite store's write-ahead log
+// files, out of git when .wn is committed.
+const wnGitignore = `# Local caches and locks, rebuilt by wn as needed.
+index.json
+index.json.tmp
+index.lock
+# SQLite write-ahead log files (sqlite backend).
+wn.db-wal
+wn.db-shm
+`
+
+// InitRoot creates .wn and .wn/items under dir, plus .wn/.gitignore for the index files.
+// Idempotent; an existing .wn/.gitignore is left alone.
 func InitRoot(dir string) error {
 	itemsDir := filepath.Join(dir, ".wn", "items")
-	return os.MkdirAll(itemsDir, 0755)
+	if err := os.MkdirAll(itemsDir, 0755); err != nil {
+		return err
+	}
+	return writeWnGitignore(dir)
+}
+
+// writeWnGitignore writes .wn/.gitignore under dir unless one exists.
+func writeWnGitignore(dir string) error {
+	f, err := os.OpenFile(filepath.Join(dir, ".wn", ".gitignore"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(wnGitignore); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
@@ -3,6 +3,7 @@ package wn
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -21,4 +22,19 @@ func TestInitRoot(t *testing.T) {
 	if err != nil || !info.IsDir() {
 		t.Fatalf(".wn/items not created or not dir: err=%v", err)
 	}
+	data, err := os.ReadFile(filepath.Join(dir, ".wn", ".gitignore"))
+	if err != nil || !strings.Contains(string(data), "index.json") || !strings.Contains(string(data), "index.lock") ||
+		!strings.Contains(string(data), "wn.db-wal") || !strings.Contains(string(data), "wn.db-shm") {
+		t.Errorf(".wn/.gitignore = %q, %v; want index.json, index.lock, and the SQLite wal/shm files ignored", data, err)
+	}
+	// A user's own .gitignore is kept on re-init.
+	if err := os.WriteFile(filepath.Join(dir, ".wn", ".gitignore"), []byte("custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitRoot(dir); err != nil {
+		t.Fatalf("second InitRoot: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".wn", ".gitignore")); string(data) != "custom\n" {
+		t.Errorf(".wn/.gitignore after re-init = %q, want unchanged", data)
+	}
 }
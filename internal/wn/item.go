@@ -29,7 +29,12 @@ type Item struct {
 	DependsOn       []string   `json:"depends_on"`
 	Order           *int       `json:"order,omitempty"` // optional backlog order when deps don't define it; lower = earlier
 	Log             []LogEntry `json:"log"`
-	Notes           []Note     `json:"notes,omitempty"` // attachments; listed ordered by Created
+	Notes           []Note     `json:"notes,omitempty"`          // attachments; listed ordered by Created
+	ExternalID      string     `json:"external_id,omitempty"`    // id in a source system (e.g. GitHub issue number)
+	ExternalURL     string     `json:"external_url,omitempty"`   // link to the item in the source system
+	SchemaVersion   int        `json:"schema_version,omitempty"` // stored item format (see ItemSchemaVersion); missing = 1
+
+	upgraded bool // read from an older schema version; MigrateItems rewrites it
 }
 
 // LogEntry records one event in an item's history.
@@ -49,14 +54,31 @@ const NoteNameClaudeSession = "claude-session"
 // NoteNameResponse is the note name used by wn respond to store the user's answer on a prompt item.
 const NoteNameResponse = "response"
 
+// NoteNamePRURL is the note name for the pull request URL recorded by wn do --pr-cmd.
+const NoteNamePRURL = "pr-url"
+
+// NoteNameMergedInto is the note name for the ref an item's branch was merged into, recorded by MarkMergedItems.
+const NoteNameMergedInto = "merged-into"
+
 // Note is an attachment on an item with a logical name (e.g. "pr-url", "issue-number").
 // Item.Notes are listed ordered by Created (oldest first).
 type Note struct {
-	Name    string    `json:"name"`
-	Created time.Time `json:"created"`
-	Body    string    `json:"body"`
+	Name    string        `json:"name"`
+	Created time.Time     `json:"created"`
+	Updated time.Time     `json:"updated,omitempty"` // last body change; zero if never changed
+	Body    string        `json:"body"`
+	History []NoteVersion `json:"history,omitempty"` // prior bodies, oldest first (settings note_history)
 }
 
+// NoteVersion is a prior body of a note, kept when settings note_history is on.
+type NoteVersion struct {
+	At   time.Time `json:"at"` // when this body was written
+	Body string    `json:"body"`
+}
+
+// NoteHistoryMax is the number of prior bodies kept per note when note history is on.
+const NoteHistoryMax = 10
+
 // ValidNoteName returns true if name is valid: alphanumeric, slash, underscore, or hyphen, 1–32 chars.
 func ValidNoteName(name string) bool {
 	if len(name) < 1 || len(name) > 32 {
@@ -66,6 +88,28 @@ func ValidNoteName(name string) bool {
 	return validName.MatchString(name)
 }
 
+// SetNoteBody replaces the body of the note at idx and records Updated. When keepHistory is
+// true and the body changes, the previous body is appended to History, dropping the oldest
+// versions beyond NoteHistoryMax.
+func (it *Item) SetNoteBody(idx int, body string, now time.Time, keepHistory bool) {
+	n := &it.Notes[idx]
+	if n.Body == body {
+		return
+	}
+	if keepHistory {
+		at := n.Updated
+		if at.IsZero() {
+			at = n.Created
+		}
+		n.History = append(n.History, NoteVersion{At: at, Body: n.Body})
+		if len(n.History) > NoteHistoryMax {
+			n.History = n.History[len(n.History)-NoteHistoryMax:]
+		}
+	}
+	n.Body = body
+	n.Updated = now
+}
+
 // NoteIndexByName returns the 0-based index of the first note with the given name, or -1 if not found.
 func (it *Item) NoteIndexByName(name string) int {
 	for i, n := range it.Notes {
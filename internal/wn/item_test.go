@@ -0,0 +1,47 @@
+package wn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetNoteBody(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	it := &Item{Notes: []Note{{Name: "status", Created: created, Body: "v0"}}}
+
+	// History off: body and Updated change, nothing kept
+	t1 := created.Add(time.Hour)
+	it.SetNoteBody(0, "v1", t1, false)
+	if n := it.Notes[0]; n.Body != "v1" || !n.Updated.Equal(t1) || len(n.History) != 0 {
+		t.Errorf("history off: note = %+v", n)
+	}
+
+	// History on: prior body kept with the time it was written
+	t2 := t1.Add(time.Hour)
+	it.SetNoteBody(0, "v2", t2, true)
+	n := it.Notes[0]
+	if n.Body != "v2" || len(n.History) != 1 || n.History[0].Body != "v1" || !n.History[0].At.Equal(t1) {
+		t.Errorf("history on: note = %+v", n)
+	}
+
+	// Same body is a no-op
+	it.SetNoteBody(0, "v2", t2.Add(time.Hour), true)
+	if n := it.Notes[0]; len(n.History) != 1 || !n.Updated.Equal(t2) {
+		t.Errorf("unchanged body: note = %+v", n)
+	}
+}
+
+func TestSetNoteBody_historyBounded(t *testing.T) {
+	now := time.Now().UTC()
+	it := &Item{Notes: []Note{{Name: "status", Created: now, Body: "b0"}}}
+	for i := 1; i <= NoteHistoryMax+3; i++ {
+		it.SetNoteBody(0, "b"+string(rune('a'+i)), now.Add(time.Duration(i)*time.Minute), true)
+	}
+	h := it.Notes[0].History
+	if len(h) != NoteHistoryMax {
+		t.Fatalf("len(History) = %d, want %d", len(h), NoteHistoryMax)
+	}
+	if h[0].Body != "b"+string(rune('a'+3)) {
+		t.Errorf("oldest kept = %q, want the oldest versions dropped first", h[0].Body)
+	}
+}
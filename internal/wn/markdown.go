@@ -0,0 +1,61 @@
+package wn
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ItemMarkdown loads the item's dependencies and renders it with RenderItemMarkdown.
+func ItemMarkdown(store Store, item *Item) (string, error) {
+	deps, missing, blocked, err := loadItemDeps(store, item)
+	if err != nil {
+		return "", err
+	}
+	return RenderItemMarkdown(item, deps, missing, blocked, time.Now().UTC()), nil
+}
+
+// RenderItemMarkdown returns item as a Markdown block for pasting into PR descriptions and
+// issues: the first description line as a heading, the rest of the description as is, the tags
+// as a bullet list, the dependencies as a checklist with their statuses, and the notes as a
+// definition list. missing lists dependency ids not found in the store.
+func RenderItemMarkdown(item *Item, deps []*Item, missing []string, blocked map[string]bool, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n", FirstLine(item.Description))
+	if _, body, _ := strings.Cut(item.Description, "\n"); strings.TrimSpace(body) != "" {
+		b.WriteString("\n" + strings.Trim(body, "\n") + "\n")
+	}
+	if len(item.Tags) > 0 {
+		b.WriteString("\n**Tags**\n\n")
+		for _, t := range item.Tags {
+			fmt.Fprintf(&b, "- %s\n", t)
+		}
+	}
+	if len(deps)+len(missing) > 0 {
+		b.WriteString("\n**Depends on**\n\n")
+		for _, dep := range deps {
+			check := " "
+			if dep.Done {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] `%s` %s (%s)\n", check, dep.ID, FirstLine(dep.Description), ItemListStatus(dep, now, blocked[dep.ID]))
+		}
+		for _, id := range missing {
+			fmt.Fprintf(&b, "- [ ] `%s` (not found)\n", id)
+		}
+	}
+	if len(item.Notes) > 0 {
+		b.WriteString("\n**Notes**\n")
+		for _, n := range item.Notes {
+			// Definition list: continuation lines are indented so they stay with the note.
+			lines := strings.Split(strings.TrimSpace(n.Body), "\n")
+			for i := 1; i < len(lines); i++ {
+				if strings.TrimSpace(lines[i]) != "" {
+					lines[i] = "    " + lines[i]
+				}
+			}
+			fmt.Fprintf(&b, "\n%s\n: %s\n", n.Name, strings.Join(lines, "\n"))
+		}
+	}
+	return b.String()
+}
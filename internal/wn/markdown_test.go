@@ -0,0 +1,32 @@
+package wn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderItemMarkdown(t *testing.T) {
+	now := time.Now().UTC()
+	item := &Item{
+		ID:          "top111",
+		Description: "Ship it\n\nSee **notes**.\n",
+		Tags:        []string{"web", "ui"},
+		DependsOn:   []string{"dep111", "dep222", "gone99"},
+		Notes:       []Note{{Name: "context", Created: now, Body: "first\n\nsecond"}},
+	}
+	deps := []*Item{
+		{ID: "dep111", Description: "done dep", Done: true},
+		{ID: "dep222", Description: "open dep"},
+	}
+	got := RenderItemMarkdown(item, deps, []string{"gone99"}, nil, now)
+	want := "### Ship it\n\nSee **notes**.\n" +
+		"\n**Tags**\n\n- web\n- ui\n" +
+		"\n**Depends on**\n\n- [x] `dep111` done dep (done)\n- [ ] `dep222` open dep (undone)\n- [ ] `gone99` (not found)\n" +
+		"\n**Notes**\n\ncontext\n: first\n\n    second\n"
+	if got != want {
+		t.Errorf("RenderItemMarkdown =\n%s\nwant\n%s", got, want)
+	}
+	if got := RenderItemMarkdown(&Item{ID: "x", Description: "Only title"}, nil, nil, nil, now); got != "### Only title\n" {
+		t.Errorf("title-only item = %q, want %q", got, "### Only title\n")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +14,8 @@ import (
 
 const mcpVersion = "0.1.0"
 
-// DefaultClaimDuration is used when claim "for" (MCP) or --for (CLI) is omitted so agents can renew without passing a duration.
+// DefaultClaimDuration is used when claim "for" (MCP) or --for (CLI) is omitted and settings default_claim
+// is unset, so agents can renew without passing a duration (see DefaultClaimFor).
 const DefaultClaimDuration = 1 * time.Hour
 
 // mcpFixedRoot, when set by SetMCPFixedRoot, locks the server to that project root:
@@ -26,7 +29,8 @@ func SetMCPFixedRoot(root string) {
 	mcpFixedRoot = root
 }
 
-// NewMCPServer returns an MCP server with wn tools registered (add, list, done, undone, desc, claim, release, next).
+// NewMCPServer returns an MCP server with wn tools registered (add, list, done, undone, desc, edit, claim, release, next).
+// It also registers the resources wn://items (the wn_list backlog) and wn://items/{id} (wn_item JSON), read from the fixed root or cwd.
 // Each tool accepts an optional "root" argument (used only when no fixed root is set). If the server was started with a fixed root (wn mcp /path or WN_ROOT), that path is used and request "root" is ignored.
 func NewMCPServer() *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{Name: "wn", Version: mcpVersion}, nil)
@@ -35,9 +39,13 @@ func NewMCPServer() *mcp.Server {
 		Name:        "wn_add",
 		Description: "Add a work item. Returns the new item's id. Pass optional depends_on (array of item IDs) to set dependencies when adding follow-up items so agentic queue order is preserved. Use tags (e.g. priority:high) and status suspend for prioritization.",
 	}, handleWnAdd)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "wn_add_many",
+		Description: "Add several work items in one call, e.g. to decompose a task into subtasks. Each entry has description and optional tags and depends_on; depends_on may name existing item IDs or other entries of the batch as #N (0-based index into items, e.g. #0 for the first). The whole batch is validated first (missing dependencies, dependency cycles) and nothing is added on error. Returns {\"ids\": [...]} in input order. Does not change the current task.",
+	}, handleWnAddMany)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_list",
-		Description: "List undone work items (includes both available-for-claim and review-ready; excludes in-progress). Returns a JSON array of objects with id, description (first line), tags, and status (undone or review-ready). Order: dependency order. Optionally filter by tag (e.g. tag 'priority:high'). Pass limit (max items to return), optional offset (skip N items), or cursor (item id to start after) for pagination and smaller context.",
+		Description: "List undone work items (includes both available-for-claim and review-ready; excludes in-progress). Returns a JSON array of objects with id, description (first line), tags, and status (undone or review-ready). Order: dependency order. Optionally filter by tag (e.g. tag 'priority:high'), or pass where with a filter expression (e.g. 'status:review-ready tag:needs-tests'); where starts from all items, so status: decides the state, and tag is ANDed with it. Pass limit (max items to return), optional offset (skip N items), or cursor (item id to start after) for pagination and smaller context.",
 	}, handleWnList)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_done",
@@ -49,11 +57,15 @@ func NewMCPServer() *mcp.Server {
 	}, handleWnUndone)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_desc",
-		Description: "Get the description (prompt-ready body) for a work item. If id is omitted, uses current task.",
+		Description: "Get the description (prompt-ready body) for a work item: for a multi-line description, only the lines after the title; for a one-liner, that line. Set full to get the entire description unchanged (title and body). Use wn_show for the whole item as JSON. If id is omitted, uses current task.",
 	}, handleWnDesc)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "wn_edit",
+		Description: "Replace a work item's description (first line is the title). Use to refine a vague item as you learn more; notes are unchanged. If id is omitted, uses current task.",
+	}, handleWnEdit)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_show",
-		Description: "Fetch full work item as JSON by id (tags, deps, notes, log, etc.). If id is omitted, uses current task.",
+		Description: "Fetch full work item as JSON by id (tags, deps, notes, log, etc.; duplicate_of resolves a duplicate to its original). If id is omitted, uses current task.",
 	}, handleWnShow)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_item",
@@ -61,7 +73,7 @@ func NewMCPServer() *mcp.Server {
 	}, handleWnItem)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_claim",
-		Description: "Mark a work item in progress for a duration. Item leaves the undone list until expiry or release. For is optional—when omitted, uses default (1h) so agents can renew (extend) without losing context.",
+		Description: "Mark a work item in progress for a duration. Item leaves the undone list until expiry or release. For is optional—when omitted, uses settings default_claim (1h if unset) so agents can renew (extend) without losing context. Pass until (RFC 3339 or HH:MM) instead of for to claim until an absolute time. If the item depends on items that are not done, the claim still succeeds and the result adds a warning listing them (no_warn omits it).",
 	}, handleWnClaim)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_release",
@@ -69,7 +81,7 @@ func NewMCPServer() *mcp.Server {
 	}, handleWnRelease)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_next",
-		Description: "Set the next available task as current and return its id and description. Next is chosen by dependency order. When tag is provided, return/set current to the next undone item that has that tag (dependency order). Enables getting the next agentic item without listing the full queue. Optionally pass claim_for (e.g. 30m) to atomically claim the item so concurrent workers don't double-assign.",
+		Description: "Set the next available task as current and return its id and description. Next is chosen by dependency order. When tag is provided, return/set current to the next undone item that has that tag (dependency order). exclude_tags skips items that have any of those tags, in addition to settings next.exclude_tags. Enables getting the next agentic item without listing the full queue. Optionally pass claim_for (e.g. 30m) to atomically claim the item so concurrent workers don't double-assign.",
 	}, handleWnNext)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_depend",
@@ -77,23 +89,39 @@ func NewMCPServer() *mcp.Server {
 	}, handleWnDepend)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_rmdepend",
-		Description: "Remove a dependency from a work item. If id is omitted, uses current task.",
+		Description: "Remove a dependency from a work item, or all of its dependencies with all: true. If id is omitted, uses current task.",
 	}, handleWnRmdepend)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "wn_tag",
+		Description: "Add a tag to a work item (e.g. needs-tests, priority:high) so other agents can pick it with wn_next tag. No change if the item already has the tag. If id is omitted, uses current task.",
+	}, handleWnTag)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "wn_untag",
+		Description: "Remove a tag from a work item. No change if the item does not have the tag. If id is omitted, uses current task.",
+	}, handleWnUntag)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "wn_order",
+		Description: "Set a work item's backlog order (lower = sooner; items without one use the default order) or clear it with unset. Pass exactly one of set or unset. Use with wn_add depends_on to control queue position. If id is omitted, uses current task.",
+	}, handleWnOrder)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_note_add",
-		Description: "Add or update a note on a work item by name. Note name: alphanumeric, slash, underscore, hyphen, 1–32 chars (e.g. pr-url, issue-number). If id is omitted, uses current task.",
+		Description: "Add or update a note on a work item by name. Note name: alphanumeric, slash, underscore, hyphen, 1–32 chars (e.g. pr-url, issue-number). If id is omitted, uses current task. Set append to add the body on a new line after an existing note's body instead of replacing it.",
 	}, handleWnNoteAdd)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_note_edit",
 		Description: "Edit an existing note's body on a work item by name. If id is omitted, uses current task.",
 	}, handleWnNoteEdit)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "wn_note_get",
+		Description: "Get one note's body by name. Returns JSON {name, body}; IsError if the item has no such note. If id is omitted, uses current task.",
+	}, handleWnNoteGet)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_note_rm",
 		Description: "Remove a note by name from a work item. If id is omitted, uses current task.",
 	}, handleWnNoteRm)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_duplicate",
-		Description: "Mark a work item as a duplicate of another. Sets status to closed and appends the standard note 'duplicate-of' with the original item's id so it leaves the queue. Id is the item to mark (omit for current task); on is the id of the canonical/original work item.",
+		Description: "Mark a work item as a duplicate of another. Sets status to closed and appends the standard note 'duplicate-of' with the original item's id so it leaves the queue. Id is the item to mark (omit for current task); on is the id of the canonical/original work item. Pass keep_open to only add the note and leave the item active, e.g. to flag a suspected duplicate for human review.",
 	}, handleWnDuplicate)
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "wn_prompt",
@@ -103,10 +131,56 @@ func NewMCPServer() *mcp.Server {
 		Name:        "wn_respond",
 		Description: "Respond to a prompt item: marks it done and stores the answer as a 'response' note, unblocking the parent item. id defaults to current task if omitted.",
 	}, handleWnRespond)
+	server.AddResource(&mcp.Resource{
+		URI:         "wn://items",
+		Name:        "wn backlog",
+		Description: "Undone work items in queue order, in the same JSON shape as wn_list (id, description first line, tags, status).",
+		MIMEType:    "application/json",
+	}, handleItemsResource)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "wn://items/{id}",
+		Name:        "wn item",
+		Description: "Full work item JSON by id, as wn_item returns it.",
+		MIMEType:    "application/json",
+	}, handleItemResource)
 
 	return server
 }
 
+// handleItemsResource serves wn://items: the wn_list output for the server's project.
+func handleItemsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	res, _, err := handleWnList(ctx, nil, wnListIn{})
+	return toolResultAsResource(req.Params.URI, res, err)
+}
+
+// handleItemResource serves wn://items/{id}: the wn_item output for that id.
+func handleItemResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	id := strings.TrimPrefix(req.Params.URI, "wn://items/")
+	if id == "" || strings.Contains(id, "/") {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	res, _, err := handleWnItem(ctx, nil, wnItemIn{ID: id})
+	return toolResultAsResource(req.Params.URI, res, err)
+}
+
+// toolResultAsResource wraps a tool's JSON text as resource contents; a tool error (e.g. item
+// not found) becomes a resource-not-found error.
+func toolResultAsResource(uri string, res *mcp.CallToolResult, err error) (*mcp.ReadResourceResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res.IsError {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	text := ""
+	if len(res.Content) > 0 {
+		if tc, ok := res.Content[0].(*mcp.TextContent); ok {
+			text = tc.Text
+		}
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: text}}}, nil
+}
+
 // getStoreWithRoot returns the store and root for the given project. When mcpFixedRoot is set (spawn-time guardrail), it is used and projectRoot from the request is ignored. Otherwise, if projectRoot is non-empty it is used (via FindRootFromDir); else FindRoot() (process cwd).
 func getStoreWithRoot(ctx context.Context, projectRoot string) (Store, string, error) {
 	var root string
@@ -128,7 +202,7 @@ func getStoreWithRoot(ctx context.Context, projectRoot string) (Store, string, e
 		}
 		return nil, "", fmt.Errorf("%s", msg)
 	}
-	store, err := NewFileStore(root)
+	store, err := OpenStore(root)
 	if err != nil {
 		return nil, "", err
 	}
@@ -139,6 +213,8 @@ type wnAddIn struct {
 	Description string   `json:"description" jsonschema:"Full description of the work item"`
 	Tags        []string `json:"tags,omitempty" jsonschema:"Optional tags"`
 	DependsOn   []string `json:"depends_on,omitempty" jsonschema:"Optional IDs this item will depend on (e.g. current task); preserves agentic queue order when adding follow-up items"`
+	ExternalID  string   `json:"external_id,omitempty" jsonschema:"Optional id of the item in a source system (e.g. GitHub issue number)"`
+	ExternalURL string   `json:"external_url,omitempty" jsonschema:"Optional URL of the item in a source system"`
 	Root        string   `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
 }
 
@@ -150,6 +226,11 @@ func handleWnAdd(ctx context.Context, req *mcp.CallToolRequest, in wnAddIn) (*mc
 	if in.Description == "" {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "error: description is required"}}, IsError: true}, nil, nil
 	}
+	settings, _ := ReadSettingsInRoot(root)
+	tags, err := NormalizeTags(in.Tags, settings.TagLowercase)
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+	}
 	id, err := GenerateID(store)
 	if err != nil {
 		return nil, nil, err
@@ -179,9 +260,11 @@ func handleWnAdd(ctx context.Context, req *mcp.CallToolRequest, in wnAddIn) (*mc
 		Description: in.Description,
 		Created:     now,
 		Updated:     now,
-		Tags:        in.Tags,
+		Tags:        tags,
 		DependsOn:   deps,
 		Log:         []LogEntry{{At: now, Kind: "created"}},
+		ExternalID:  strings.TrimSpace(in.ExternalID),
+		ExternalURL: strings.TrimSpace(in.ExternalURL),
 	}
 	for _, depID := range deps {
 		item.Log = append(item.Log, LogEntry{At: now, Kind: "depend_added", Msg: depID})
@@ -201,6 +284,103 @@ func handleWnAdd(ctx context.Context, req *mcp.CallToolRequest, in wnAddIn) (*mc
 	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(raw)}}}, out, nil
 }
 
+type wnAddManyEntry struct {
+	Description string   `json:"description" jsonschema:"Full description of the work item"`
+	Tags        []string `json:"tags,omitempty" jsonschema:"Optional tags"`
+	DependsOn   []string `json:"depends_on,omitempty" jsonschema:"Optional dependencies: existing item IDs, or #N for entry N of this batch (0-based)"`
+}
+
+type wnAddManyIn struct {
+	Items []wnAddManyEntry `json:"items" jsonschema:"Items to add, in order"`
+	Root  string           `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+}
+
+func handleWnAddMany(ctx context.Context, req *mcp.CallToolRequest, in wnAddManyIn) (*mcp.CallToolResult, any, error) {
+	store, root, err := getStoreWithRoot(ctx, in.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	errorResult := func(format string, args ...any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf(format, args...)}}, IsError: true}, nil, nil
+	}
+	if len(in.Items) == 0 {
+		return errorResult("error: items is required")
+	}
+	settings, _ := ReadSettingsInRoot(root)
+	ids := make([]string, len(in.Items))
+	index := make(map[string]string, len(in.Items)) // new id -> "#N", for error messages
+	for i := range in.Items {
+		for {
+			id, err := GenerateID(store)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, taken := index[id]; !taken {
+				ids[i] = id
+				index[id] = "#" + strconv.Itoa(i)
+				break
+			}
+		}
+	}
+	now := time.Now().UTC()
+	items := make([]*Item, len(in.Items))
+	for i, e := range in.Items {
+		if strings.TrimSpace(e.Description) == "" {
+			return errorResult("items[%d]: description is required", i)
+		}
+		tags, err := NormalizeTags(e.Tags, settings.TagLowercase)
+		if err != nil {
+			return errorResult("items[%d]: %v", i, err)
+		}
+		var deps []string
+		for _, ref := range uniqueStrings(e.DependsOn) {
+			depID := ref
+			if n, ok := strings.CutPrefix(ref, "#"); ok {
+				j, err := strconv.Atoi(n)
+				if err != nil || j < 0 || j >= len(ids) {
+					return errorResult("items[%d]: depends_on %s is not an entry of this batch", i, ref)
+				}
+				if j == i {
+					return errorResult("items[%d]: an item cannot depend on itself", i)
+				}
+				depID = ids[j]
+			} else if _, err := store.Get(ref); err != nil {
+				return errorResult("items[%d]: depends_on: item %s not found", i, ref)
+			}
+			deps = append(deps, depID)
+		}
+		item := &Item{
+			ID:          ids[i],
+			Description: e.Description,
+			Created:     now,
+			Updated:     now,
+			Tags:        tags,
+			DependsOn:   deps,
+			Log:         []LogEntry{{At: now, Kind: "created"}},
+		}
+		for _, depID := range deps {
+			item.Log = append(item.Log, LogEntry{At: now, Kind: "depend_added", Msg: depID})
+		}
+		items[i] = item
+	}
+	// Existing items cannot depend on new ones, so any cycle lies within the batch.
+	if cycles := DependencyCycles(items); len(cycles) > 0 {
+		chain := make([]string, len(cycles[0]))
+		for k, id := range cycles[0] {
+			chain[k] = index[id]
+		}
+		return errorResult("circular dependency detected in batch: %s", FormatCycle(chain))
+	}
+	for _, item := range items {
+		if err := store.Put(item); err != nil {
+			return nil, nil, err
+		}
+	}
+	out := map[string][]string{"ids": ids}
+	raw, _ := json.Marshal(out)
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(raw)}}}, out, nil
+}
+
 // uniqueStrings returns a copy of s with duplicate strings removed (order preserved).
 func uniqueStrings(s []string) []string {
 	if len(s) == 0 {
@@ -223,6 +403,7 @@ func uniqueStrings(s []string) []string {
 
 type wnListIn struct {
 	Tag    string `json:"tag,omitempty" jsonschema:"Filter by tag (optional)"`
+	Where  string `json:"where,omitempty" jsonschema:"Filter expression, space-separated AND of tag:X, status:S, overdue, has:NOTE, text:SUB (optional; when set, starts from all items instead of undone)"`
 	Limit  int    `json:"limit,omitempty" jsonschema:"Return at most N items (optional; no limit if 0 or omitted)"`
 	Offset int    `json:"offset,omitempty" jsonschema:"Skip first N items (optional)"`
 	Cursor string `json:"cursor,omitempty" jsonschema:"Start after this item id (optional; for key-set pagination)"`
@@ -247,9 +428,18 @@ func handleWnList(ctx context.Context, req *mcp.CallToolRequest, in wnListIn) (*
 		return nil, nil, err
 	}
 	blockedSet := BlockedSet(allItems)
-	items, err := ListableUndoneItems(store)
-	if err != nil {
-		return nil, nil, err
+	var items []*Item
+	if in.Where != "" {
+		where, err := ParseFilter(in.Where)
+		if err != nil {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+		}
+		items = FilterItems(allItems, where, time.Now().UTC())
+	} else {
+		items, err = ListableUndoneItems(store)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 	if in.Tag != "" {
 		filtered := items[:0]
@@ -369,6 +559,7 @@ func handleWnUndone(ctx context.Context, req *mcp.CallToolRequest, in wnUndoneIn
 
 type wnDescIn struct {
 	ID   string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
+	Full bool   `json:"full,omitempty" jsonschema:"Return the entire description including the title line (optional; default is body only)"`
 	Root string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
 }
 
@@ -390,26 +581,84 @@ func handleWnDesc(ctx context.Context, req *mcp.CallToolRequest, in wnDescIn) (*
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
 	}
 	body := PromptBody(item.Description)
+	if in.Full {
+		body = item.Description
+	}
 	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: body}}}, nil, nil
 }
 
+type wnEditIn struct {
+	ID          string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
+	Description string `json:"description" jsonschema:"New full description (first line is the title)"`
+	Root        string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+}
+
+func handleWnEdit(ctx context.Context, req *mcp.CallToolRequest, in wnEditIn) (*mcp.CallToolResult, any, error) {
+	store, root, err := getStoreWithRoot(ctx, in.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, err := ReadMeta(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := ResolveItemID(meta.CurrentID, in.ID)
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "no id provided and no current task"}}, IsError: true}, nil, nil
+	}
+	if strings.TrimSpace(in.Description) == "" {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "description is required and cannot be empty"}}, IsError: true}, nil, nil
+	}
+	err = store.UpdateItem(id, func(it *Item) (*Item, error) {
+		it.Description = in.Description
+		it.Updated = time.Now().UTC()
+		it.Log = append(it.Log, LogEntry{At: it.Updated, Kind: "updated", Msg: MCPEditLogMsg(it)})
+		return it, nil
+	})
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+	}
+	text := fmt.Sprintf("updated description of %s", id)
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+}
+
 // showOutput is the JSON shape for wn_show; all slice fields have no omitempty so agents always see tags, log, notes, depends_on.
 type showOutput struct {
-	ID              string     `json:"id"`
-	Description     string     `json:"description"`
-	Created         time.Time  `json:"created"`
-	Updated         time.Time  `json:"updated"`
-	Done            bool       `json:"done"`
-	DoneMessage     string     `json:"done_message,omitempty"`
-	ReviewReady     bool       `json:"review_ready,omitempty"`
-	PromptReady     bool       `json:"prompt_ready,omitempty"`
-	InProgressUntil time.Time  `json:"in_progress_until,omitempty"`
-	InProgressBy    string     `json:"in_progress_by,omitempty"`
-	Tags            []string   `json:"tags"`
-	DependsOn       []string   `json:"depends_on"`
-	Order           *int       `json:"order,omitempty"`
-	Log             []LogEntry `json:"log"`
-	Notes           []Note     `json:"notes"`
+	ID              string          `json:"id"`
+	Description     string          `json:"description"`
+	Created         time.Time       `json:"created"`
+	Updated         time.Time       `json:"updated"`
+	Done            bool            `json:"done"`
+	DoneMessage     string          `json:"done_message,omitempty"`
+	ReviewReady     bool            `json:"review_ready,omitempty"`
+	PromptReady     bool            `json:"prompt_ready,omitempty"`
+	InProgressUntil time.Time       `json:"in_progress_until,omitempty"`
+	InProgressBy    string          `json:"in_progress_by,omitempty"`
+	Tags            []string        `json:"tags"`
+	DependsOn       []string        `json:"depends_on"`
+	Order           *int            `json:"order,omitempty"`
+	Log             []LogEntry      `json:"log"`
+	Notes           []Note          `json:"notes"`
+	DuplicateOf     *duplicateOfOut `json:"duplicate_of,omitempty"` // wn_show only: resolved duplicate-of chain
+}
+
+// duplicateOfOut is the resolved duplicate-of chain in wn_show output.
+type duplicateOfOut struct {
+	Chain    []string `json:"chain"`           // ids from the direct target to the original
+	Original string   `json:"original"`        // last id of the chain
+	Title    string   `json:"title,omitempty"` // first line of the original; empty if not found
+	Cycle    bool     `json:"cycle,omitempty"` // chain loops or is too long to resolve
+}
+
+func newDuplicateOfOut(c *DuplicateChain) *duplicateOfOut {
+	if c == nil {
+		return nil
+	}
+	out := &duplicateOfOut{Chain: c.IDs, Original: c.IDs[len(c.IDs)-1], Cycle: c.Cycle}
+	if c.Original != nil && !c.Cycle {
+		out.Title = FirstLine(c.Original.Description)
+	}
+	return out
 }
 
 type wnShowIn struct {
@@ -452,6 +701,7 @@ func handleWnShow(ctx context.Context, req *mcp.CallToolRequest, in wnShowIn) (*
 		Log:             item.Log,
 		Notes:           item.Notes,
 	}
+	out.DuplicateOf = newDuplicateOfOut(DuplicateOfChain(store, item))
 	if out.Tags == nil {
 		out.Tags = []string{}
 	}
@@ -526,31 +776,45 @@ func handleWnItem(ctx context.Context, req *mcp.CallToolRequest, in wnItemIn) (*
 }
 
 type wnClaimIn struct {
-	ID   string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
-	For  string `json:"for,omitempty" jsonschema:"Duration (e.g. 30m, 1h). Optional; when omitted, uses default (1h) so agents can renew without losing context"`
-	By   string `json:"by,omitempty" jsonschema:"Optional worker id for logging"`
-	Root string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+	ID     string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
+	For    string `json:"for,omitempty" jsonschema:"Duration (e.g. 30m, 1h). Optional; when omitted, uses settings default_claim (or 1h) so agents can renew without losing context"`
+	Until  string `json:"until,omitempty" jsonschema:"Absolute deadline instead of a duration: RFC 3339 or HH:MM (today, local time). Must be in the future; mutually exclusive with for"`
+	By     string `json:"by,omitempty" jsonschema:"Optional worker id for logging (default: settings worker_id, else hostname)"`
+	NoWarn bool   `json:"no_warn,omitempty" jsonschema:"If true, omit the warning about dependencies that are not done"`
+	Root   string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
 }
 
 func handleWnClaim(ctx context.Context, req *mcp.CallToolRequest, in wnClaimIn) (*mcp.CallToolResult, any, error) {
-	var d time.Duration
-	if in.For == "" {
-		d = DefaultClaimDuration
-	} else {
-		var err error
-		d, err = time.ParseDuration(in.For)
+	store, root, err := getStoreWithRoot(ctx, in.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now().UTC()
+	var until time.Time
+	var forMsg string
+	switch {
+	case in.Until != "" && in.For != "":
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "use either 'for' or 'until', not both"}}, IsError: true}, nil, nil
+	case in.Until != "":
+		until, err = ParseUntil(in.Until, time.Now())
+		if err != nil {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+		}
+		until = until.UTC()
+		forMsg = "until " + until.Format(time.RFC3339)
+	case in.For != "":
+		d, err := time.ParseDuration(in.For)
 		if err != nil || d <= 0 {
 			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "invalid or non-positive duration for 'for'"}}, IsError: true}, nil, nil
 		}
-	}
-	forMsg := in.For
-	if forMsg == "" {
+		until = now.Add(d)
+		forMsg = in.For
+	default:
+		settings, _ := ReadSettingsInRoot(root)
+		d := DefaultClaimFor(settings)
+		until = now.Add(d)
 		forMsg = d.String()
 	}
-	store, root, err := getStoreWithRoot(ctx, in.Root)
-	if err != nil {
-		return nil, nil, err
-	}
 	meta, err := ReadMeta(root)
 	if err != nil {
 		return nil, nil, err
@@ -559,19 +823,29 @@ func handleWnClaim(ctx context.Context, req *mcp.CallToolRequest, in wnClaimIn)
 	if err != nil {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "no id provided and no current task"}}, IsError: true}, nil, nil
 	}
-	now := time.Now().UTC()
-	until := now.Add(d)
+	settings, _ := ReadSettingsInRoot(root)
+	by := ClaimWorker(settings, in.By)
+	var claimed Item
 	err = store.UpdateItem(id, func(it *Item) (*Item, error) {
 		it.InProgressUntil = until
-		it.InProgressBy = in.By
+		it.InProgressBy = by
 		it.Updated = now
 		it.Log = append(it.Log, LogEntry{At: now, Kind: "in_progress", Msg: forMsg})
+		claimed = *it
 		return it, nil
 	})
 	if err != nil {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
 	}
 	text := fmt.Sprintf("claimed %s for %s", id, forMsg)
+	if in.Until != "" {
+		text = fmt.Sprintf("claimed %s %s", id, forMsg)
+	}
+	if !in.NoWarn {
+		if deps := UnfinishedDependencies(store, &claimed); len(deps) > 0 {
+			text += "\nwarning: depends on items that are not done: " + DescribeItems(deps)
+		}
+	}
 	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
 }
 
@@ -610,10 +884,11 @@ func handleWnRelease(ctx context.Context, req *mcp.CallToolRequest, in wnRelease
 }
 
 type wnNextIn struct {
-	Root     string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
-	Tag      string `json:"tag,omitempty" jsonschema:"Optional tag; when set, return/set current to the next undone item that has this tag (dependency order)"`
-	ClaimFor string `json:"claim_for,omitempty" jsonschema:"If set, atomically claim the returned item for this duration (e.g. 30m, 1h)"`
-	ClaimBy  string `json:"claim_by,omitempty" jsonschema:"Optional worker id when claim_for is set"`
+	Root        string   `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+	Tag         string   `json:"tag,omitempty" jsonschema:"Optional tag; when set, return/set current to the next undone item that has this tag (dependency order)"`
+	ExcludeTags []string `json:"exclude_tags,omitempty" jsonschema:"Optional tags; skip items that have any of them (added to settings next.exclude_tags)"`
+	ClaimFor    string   `json:"claim_for,omitempty" jsonschema:"If set, atomically claim the returned item for this duration (e.g. 30m, 1h)"`
+	ClaimBy     string   `json:"claim_by,omitempty" jsonschema:"Optional worker id when claim_for is set (default: settings worker_id, else hostname)"`
 }
 
 func handleWnNext(ctx context.Context, req *mcp.CallToolRequest, in wnNextIn) (*mcp.CallToolResult, any, error) {
@@ -621,7 +896,8 @@ func handleWnNext(ctx context.Context, req *mcp.CallToolRequest, in wnNextIn) (*
 	if err != nil {
 		return nil, nil, err
 	}
-	next, err := NextUndoneItem(store, in.Tag)
+	settings, _ := ReadSettingsInRoot(root)
+	next, err := NextUndoneItemOrdered(store, in.Tag, NextExcludeTags(settings, in.ExcludeTags), NextOrdering(settings, settings.Next.StrictOrder))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -646,7 +922,7 @@ func handleWnNext(ctx context.Context, req *mcp.CallToolRequest, in wnNextIn) (*
 		until := now.Add(d)
 		err = store.UpdateItem(next.ID, func(it *Item) (*Item, error) {
 			it.InProgressUntil = until
-			it.InProgressBy = in.ClaimBy
+			it.InProgressBy = ClaimWorker(settings, in.ClaimBy)
 			it.Updated = now
 			it.Log = append(it.Log, LogEntry{At: now, Kind: "in_progress", Msg: in.ClaimFor})
 			return it, nil
@@ -685,6 +961,9 @@ func handleWnDepend(ctx context.Context, req *mcp.CallToolRequest, in wnDependIn
 	if in.On == "" {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "on (dependency id) is required"}}, IsError: true}, nil, nil
 	}
+	if in.On == id {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: ErrSelfDependency.Error()}}, IsError: true}, nil, nil
+	}
 	items, err := store.List()
 	if err != nil {
 		return nil, nil, err
@@ -712,7 +991,8 @@ func handleWnDepend(ctx context.Context, req *mcp.CallToolRequest, in wnDependIn
 
 type wnRmdependIn struct {
 	ID   string `json:"id,omitempty" jsonschema:"Work item id to remove dependency from; omit for current task"`
-	On   string `json:"on" jsonschema:"ID of the dependency to remove"`
+	On   string `json:"on,omitempty" jsonschema:"ID of the dependency to remove (omit with all)"`
+	All  bool   `json:"all,omitempty" jsonschema:"If true, remove every dependency of the item instead of one"`
 	Root string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
 }
 
@@ -729,6 +1009,20 @@ func handleWnRmdepend(ctx context.Context, req *mcp.CallToolRequest, in wnRmdepe
 	if err != nil {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "no id provided and no current task"}}, IsError: true}, nil, nil
 	}
+	if in.All {
+		if in.On != "" {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "cannot use on with all; choose one"}}, IsError: true}, nil, nil
+		}
+		removed, err := ClearDependencies(store, id)
+		if err != nil {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+		}
+		text := fmt.Sprintf("removed %d dependencies from %s", len(removed), id)
+		if len(removed) > 0 {
+			text += " (" + strings.Join(removed, ", ") + ")"
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+	}
 	if in.On == "" {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "on (dependency id to remove) is required"}}, IsError: true}, nil, nil
 	}
@@ -751,13 +1045,145 @@ func handleWnRmdepend(ctx context.Context, req *mcp.CallToolRequest, in wnRmdepe
 	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
 }
 
-type wnNoteAddIn struct {
+type wnTagIn struct {
 	ID   string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
-	Name string `json:"name" jsonschema:"Note name (alphanumeric, slash, underscore, hyphen, 1-32 chars)"`
-	Body string `json:"body" jsonschema:"Note text (add or update)"`
+	Tag  string `json:"tag" jsonschema:"Tag name (1-32 chars, no whitespace)"`
 	Root string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
 }
 
+func handleWnTag(ctx context.Context, req *mcp.CallToolRequest, in wnTagIn) (*mcp.CallToolResult, any, error) {
+	return updateTag(ctx, in, true)
+}
+
+func handleWnUntag(ctx context.Context, req *mcp.CallToolRequest, in wnTagIn) (*mcp.CallToolResult, any, error) {
+	return updateTag(ctx, in, false)
+}
+
+// updateTag adds or removes in.Tag on the item, logging tag_added or tag_removed when the
+// tags change.
+func updateTag(ctx context.Context, in wnTagIn, add bool) (*mcp.CallToolResult, any, error) {
+	store, root, err := getStoreWithRoot(ctx, in.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, err := ReadMeta(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := ResolveItemID(meta.CurrentID, in.ID)
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "no id provided and no current task"}}, IsError: true}, nil, nil
+	}
+	settings, _ := ReadSettingsInRoot(root)
+	// Removal matches the tag as given or normalized, so tags stored before normalization (or
+	// that no longer validate) can still be removed.
+	raw := strings.TrimSpace(in.Tag)
+	tag, err := NormalizeTag(in.Tag, settings.TagLowercase)
+	if err != nil {
+		if add || raw == "" {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+		}
+		tag = raw
+	}
+	match := func(t string) bool { return t == tag || t == raw }
+	changed := false
+	err = store.UpdateItem(id, func(it *Item) (*Item, error) {
+		kind := "tag_added"
+		if add {
+			if slices.Contains(it.Tags, tag) {
+				return it, nil
+			}
+			it.Tags = append(it.Tags, tag)
+		} else {
+			idx := slices.IndexFunc(it.Tags, match)
+			if idx < 0 {
+				return it, nil
+			}
+			tag = it.Tags[idx]
+			it.Tags = slices.DeleteFunc(it.Tags, match)
+			kind = "tag_removed"
+		}
+		it.Updated = time.Now().UTC()
+		it.Log = append(it.Log, LogEntry{At: it.Updated, Kind: kind, Msg: tag})
+		changed = true
+		return it, nil
+	})
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+	}
+	var text string
+	switch {
+	case changed && add:
+		text = fmt.Sprintf("tagged %s with %s", id, tag)
+	case changed:
+		text = fmt.Sprintf("removed tag %s from %s", tag, id)
+	case add:
+		text = fmt.Sprintf("%s already has tag %s", id, tag)
+	default:
+		text = fmt.Sprintf("%s does not have tag %s", id, tag)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+}
+
+type wnOrderIn struct {
+	ID    string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
+	Set   *int   `json:"set,omitempty" jsonschema:"Order to set (0 to the configured order_max; lower = sooner)"`
+	Unset bool   `json:"unset,omitempty" jsonschema:"Clear the order so the item uses the default order"`
+	Root  string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+}
+
+func handleWnOrder(ctx context.Context, req *mcp.CallToolRequest, in wnOrderIn) (*mcp.CallToolResult, any, error) {
+	if (in.Set == nil) == !in.Unset {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "pass exactly one of set or unset"}}, IsError: true}, nil, nil
+	}
+	store, root, err := getStoreWithRoot(ctx, in.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The request root may be another project than the one the server started in.
+	settings, _ := ReadSettingsInRoot(root)
+	maxOrder, defaultOrder, _ := OrderRange(settings)
+	if in.Set != nil && (*in.Set < 0 || *in.Set > maxOrder) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("order must be between 0 and %d", maxOrder)}}, IsError: true}, nil, nil
+	}
+	meta, err := ReadMeta(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := ResolveItemID(meta.CurrentID, in.ID)
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "no id provided and no current task"}}, IsError: true}, nil, nil
+	}
+	err = store.UpdateItem(id, func(it *Item) (*Item, error) {
+		it.Updated = time.Now().UTC()
+		if in.Unset {
+			it.Order = nil
+			it.Log = append(it.Log, LogEntry{At: it.Updated, Kind: "order_cleared"})
+			return it, nil
+		}
+		order := *in.Set
+		it.Order = &order
+		it.Log = append(it.Log, LogEntry{At: it.Updated, Kind: "order_set", Msg: strconv.Itoa(order)})
+		return it, nil
+	})
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
+	}
+	text := fmt.Sprintf("cleared order of %s (default %d)", id, defaultOrder)
+	if in.Set != nil {
+		text = fmt.Sprintf("set order of %s to %d", id, *in.Set)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+}
+
+type wnNoteAddIn struct {
+	ID     string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
+	Name   string `json:"name" jsonschema:"Note name (alphanumeric, slash, underscore, hyphen, 1-32 chars)"`
+	Body   string `json:"body" jsonschema:"Note text (add or update)"`
+	Append bool   `json:"append,omitempty" jsonschema:"When true and the note exists, append body on a new line instead of replacing it (optional)"`
+	Root   string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+}
+
 func handleWnNoteAdd(ctx context.Context, req *mcp.CallToolRequest, in wnNoteAddIn) (*mcp.CallToolResult, any, error) {
 	store, root, err := getStoreWithRoot(ctx, in.Root)
 	if err != nil {
@@ -778,14 +1204,17 @@ func handleWnNoteAdd(ctx context.Context, req *mcp.CallToolRequest, in wnNoteAdd
 	if trimmed == "" {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "body is required and cannot be empty"}}, IsError: true}, nil, nil
 	}
+	settings, _ := ReadSettingsInRoot(root)
 	now := time.Now().UTC()
 	err = store.UpdateItem(id, func(it *Item) (*Item, error) {
 		if it.Notes == nil {
 			it.Notes = []Note{}
 		}
 		idx := it.NoteIndexByName(in.Name)
-		if idx >= 0 {
-			it.Notes[idx].Body = trimmed
+		if idx >= 0 && in.Append {
+			it.SetNoteBody(idx, it.Notes[idx].Body+"\n"+trimmed, now, settings.NoteHistory)
+		} else if idx >= 0 {
+			it.SetNoteBody(idx, trimmed, now, settings.NoteHistory)
 		} else {
 			it.Notes = append(it.Notes, Note{Name: in.Name, Created: now, Body: trimmed})
 		}
@@ -826,13 +1255,15 @@ func handleWnNoteEdit(ctx context.Context, req *mcp.CallToolRequest, in wnNoteEd
 	if trimmed == "" {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "body is required and cannot be empty"}}, IsError: true}, nil, nil
 	}
+	settings, _ := ReadSettingsInRoot(root)
 	err = store.UpdateItem(id, func(it *Item) (*Item, error) {
 		idx := it.NoteIndexByName(in.Name)
 		if idx < 0 {
 			return nil, fmt.Errorf("no note named %q", in.Name)
 		}
-		it.Notes[idx].Body = trimmed
-		it.Updated = time.Now().UTC()
+		now := time.Now().UTC()
+		it.SetNoteBody(idx, trimmed, now, settings.NoteHistory)
+		it.Updated = now
 		return it, nil
 	})
 	if err != nil {
@@ -842,6 +1273,44 @@ func handleWnNoteEdit(ctx context.Context, req *mcp.CallToolRequest, in wnNoteEd
 	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
 }
 
+type wnNoteGetIn struct {
+	ID   string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
+	Name string `json:"name" jsonschema:"Note name to read"`
+	Root string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+}
+
+func handleWnNoteGet(ctx context.Context, req *mcp.CallToolRequest, in wnNoteGetIn) (*mcp.CallToolResult, any, error) {
+	store, root, err := getStoreWithRoot(ctx, in.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, err := ReadMeta(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := ResolveItemID(meta.CurrentID, in.ID)
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "no id provided and no current task"}}, IsError: true}, nil, nil
+	}
+	if in.Name == "" {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "name is required"}}, IsError: true}, nil, nil
+	}
+	item, err := store.Get(id)
+	if err != nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("item %s not found", id)}}, IsError: true}, nil, nil
+	}
+	idx := item.NoteIndexByName(in.Name)
+	if idx < 0 {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("item %s has no note named %q", id, in.Name)}}, IsError: true}, nil, nil
+	}
+	out := map[string]string{"name": item.Notes[idx].Name, "body": item.Notes[idx].Body}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(raw)}}}, nil, nil
+}
+
 type wnNoteRmIn struct {
 	ID   string `json:"id,omitempty" jsonschema:"Work item id; omit for current task"`
 	Name string `json:"name" jsonschema:"Note name to remove"`
@@ -881,9 +1350,10 @@ func handleWnNoteRm(ctx context.Context, req *mcp.CallToolRequest, in wnNoteRmIn
 }
 
 type wnDuplicateIn struct {
-	ID   string `json:"id,omitempty" jsonschema:"Work item id to mark as duplicate; omit for current task"`
-	On   string `json:"on" jsonschema:"ID of the canonical/original work item"`
-	Root string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
+	ID       string `json:"id,omitempty" jsonschema:"Work item id to mark as duplicate; omit for current task"`
+	On       string `json:"on" jsonschema:"ID of the canonical/original work item"`
+	KeepOpen bool   `json:"keep_open,omitempty" jsonschema:"If true, only add the duplicate-of note and leave the item's status unchanged (flag a suspected duplicate for review); default closes it"`
+	Root     string `json:"root,omitempty" jsonschema:"Optional project root path (directory containing .wn); if omitted, uses process cwd"`
 }
 
 func handleWnDuplicate(ctx context.Context, req *mcp.CallToolRequest, in wnDuplicateIn) (*mcp.CallToolResult, any, error) {
@@ -902,10 +1372,18 @@ func handleWnDuplicate(ctx context.Context, req *mcp.CallToolRequest, in wnDupli
 	if in.On == "" {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "on (original item id) is required"}}, IsError: true}, nil, nil
 	}
-	if err := SetStatus(store, id, StatusClosed, StatusOpts{DuplicateOf: in.On}); err != nil {
+	if in.KeepOpen {
+		err = FlagDuplicateOf(store, id, in.On)
+	} else {
+		err = SetStatus(store, id, StatusClosed, StatusOpts{DuplicateOf: in.On})
+	}
+	if err != nil {
 		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}, IsError: true}, nil, nil
 	}
 	text := fmt.Sprintf("marked %s as duplicate of %s", id, in.On)
+	if in.KeepOpen {
+		text += " (kept open)"
+	}
 	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -220,6 +221,76 @@ func TestMCP_wn_list_includes_review_ready(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_list_where(t *testing.T) {
+	dir := t.TempDir()
+	if err := InitRoot(dir); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, item := range []*Item{
+		{ID: "u1", Description: "undone", Tags: []string{"needs-tests"}, Created: now, Updated: now},
+		{ID: "rr1", Description: "review one", Tags: []string{"needs-tests"}, ReviewReady: true, Created: now, Updated: now},
+		{ID: "rr2", Description: "review two", Tags: []string{"other"}, ReviewReady: true, Created: now, Updated: now},
+		{ID: "d1", Description: "done", Tags: []string{"needs-tests"}, Done: true, Created: now, Updated: now},
+	} {
+		if err := store.Put(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	server := NewMCPServer()
+	serverSession, _ := server.Connect(ctx, serverTransport, nil)
+	defer func() { _ = serverSession.Wait() }()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	clientSession, _ := client.Connect(ctx, clientTransport, nil)
+	defer clientSession.Close()
+
+	tests := []struct {
+		args map[string]any
+		want []string
+	}{
+		{map[string]any{"where": "status:review-ready tag:needs-tests"}, []string{"rr1"}},
+		{map[string]any{"where": "status:done"}, []string{"d1"}},
+		{map[string]any{"where": "status:review-ready", "tag": "other"}, []string{"rr2"}},
+	}
+	for _, tt := range tests {
+		res, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "wn_list", Arguments: tt.args})
+		if err != nil {
+			t.Fatalf("CallTool wn_list %v: %v", tt.args, err)
+		}
+		var items []listItem
+		if err := json.Unmarshal([]byte(textContent(res)), &items); err != nil {
+			t.Fatalf("wn_list %v must return valid JSON: %v", tt.args, err)
+		}
+		var got []string
+		for _, it := range items {
+			got = append(got, it.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("wn_list %v = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	res, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "wn_list", Arguments: map[string]any{"where": "bogus:x"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_list: %v", err)
+	}
+	if !res.IsError || !strings.Contains(textContent(res), "unknown term") {
+		t.Errorf("wn_list invalid where: want error result, got %q", textContent(res))
+	}
+}
+
 // setupMCPSessionThreeItems creates a temp wn root with three items (aaa, bbb, ccc) in dependency order (Order 0,1,2).
 func setupMCPSessionThreeItems(t *testing.T) (context.Context, *mcp.ClientSession, func()) {
 	t.Helper()
@@ -372,6 +443,79 @@ func TestMCP_wn_add(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_add_many(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name: "wn_add_many",
+		Arguments: map[string]any{"items": []any{
+			map[string]any{"description": "design", "tags": []string{"plan"}},
+			map[string]any{"description": "build", "depends_on": []string{"#0", "abc123"}},
+			map[string]any{"description": "ship", "depends_on": []string{"#1"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CallTool wn_add_many: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("wn_add_many: %s", textContent(res))
+	}
+	var out struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.Unmarshal([]byte(textContent(res)), &out); err != nil || len(out.IDs) != 3 {
+		t.Fatalf("wn_add_many = %q, want 3 ids", textContent(res))
+	}
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	build, err := store.Get(out.IDs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if build.Description != "build" || len(build.DependsOn) != 2 || build.DependsOn[0] != out.IDs[0] || build.DependsOn[1] != "abc123" {
+		t.Errorf("second item = %+v, want build depending on %s and abc123", build, out.IDs[0])
+	}
+	if meta, _ := ReadMeta("."); meta.CurrentID != "abc123" {
+		t.Errorf("current = %q, want unchanged abc123", meta.CurrentID)
+	}
+}
+
+func TestMCP_wn_add_many_rejects_bad_batch(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	for name, items := range map[string][]any{
+		"cycle": {
+			map[string]any{"description": "a", "depends_on": []string{"#1"}},
+			map[string]any{"description": "b", "depends_on": []string{"#0"}},
+		},
+		"self":         {map[string]any{"description": "a", "depends_on": []string{"#0"}}},
+		"out of range": {map[string]any{"description": "a", "depends_on": []string{"#5"}}},
+		"missing dep":  {map[string]any{"description": "a", "depends_on": []string{"zzz999"}}},
+		"empty":        {map[string]any{"description": "ok"}, map[string]any{"description": " "}},
+		"invalid tag":  {map[string]any{"description": "a", "tags": []string{"bad tag"}}},
+		"empty batch":  {},
+	} {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_add_many", Arguments: map[string]any{"items": items}})
+		if err != nil {
+			t.Fatalf("%s: CallTool: %v", name, err)
+		}
+		if !res.IsError {
+			t.Errorf("%s: want IsError", name)
+		}
+	}
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := CountItems(store); n != 1 {
+		t.Errorf("items after rejected batches = %d, want 1 (nothing added)", n)
+	}
+}
+
 func TestMCP_wn_add_empty_description_is_error(t *testing.T) {
 	ctx, cs, cleanup := setupMCPSession(t)
 	defer cleanup()
@@ -392,6 +536,22 @@ func TestMCP_wn_add_empty_description_is_error(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_add_invalid_tag_is_error(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "wn_add",
+		Arguments: map[string]any{"description": "task", "tags": []string{"priority: high"}},
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if !res.IsError {
+		t.Error("wn_add with interior-space tag: want IsError true")
+	}
+}
+
 func TestMCP_wn_add_with_depends_on(t *testing.T) {
 	ctx, cs, _, cleanup := setupMCPSessionTwoItems(t, "aa1111", "bb2222")
 	defer cleanup()
@@ -476,6 +636,61 @@ func TestMCP_wn_desc(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_desc_full(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_desc", Arguments: map[string]any{"full": true}})
+	if err != nil {
+		t.Fatalf("CallTool wn_desc: %v", err)
+	}
+	if text := textContent(res); text != "first line\nbody for prompt" {
+		t.Errorf("wn_desc full = %q, want title and body", text)
+	}
+}
+
+func TestMCP_wn_edit(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_edit", Arguments: map[string]any{"description": "sharper title\nclearer body"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_edit: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("wn_edit: %s", textContent(res))
+	}
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := store.Get("abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Description != "sharper title\nclearer body" {
+		t.Errorf("description = %q, want edited description", item.Description)
+	}
+	if last := item.Log[len(item.Log)-1]; last.Kind != "updated" || last.Msg != EditSourceMCP {
+		t.Errorf("last log = %q %q, want updated %q", last.Kind, last.Msg, EditSourceMCP)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_edit", Arguments: map[string]any{"id": "abc123", "description": "  \n"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_edit: %v", err)
+	}
+	if !res.IsError {
+		t.Error("wn_edit with blank description should be an error")
+	}
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_edit", Arguments: map[string]any{"id": "zzz999", "description": "x"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_edit: %v", err)
+	}
+	if !res.IsError {
+		t.Error("wn_edit on a missing item should be an error")
+	}
+}
+
 func TestMCP_wn_show(t *testing.T) {
 	ctx, cs, cleanup := setupMCPSession(t)
 	defer cleanup()
@@ -512,6 +727,45 @@ func TestMCP_wn_show(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_show_duplicate_of(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "mid222", Description: "middle copy", Created: now, Updated: now, Notes: []Note{{Name: NoteNameDuplicateOf, Created: now, Body: "abc123"}}},
+		{ID: "dup111", Description: "latest copy", Created: now, Updated: now, Notes: []Note{{Name: NoteNameDuplicateOf, Created: now, Body: "mid222"}}},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_show", Arguments: map[string]any{"id": "dup111"}})
+	if err != nil || res.IsError {
+		t.Fatalf("wn_show: err=%v result=%s", err, textContent(res))
+	}
+	var out showOutput
+	if err := json.Unmarshal([]byte(textContent(res)), &out); err != nil {
+		t.Fatalf("wn_show result not valid JSON: %v", err)
+	}
+	d := out.DuplicateOf
+	if d == nil || len(d.Chain) != 2 || d.Original != "abc123" || d.Title != "first line" || d.Cycle {
+		t.Errorf("duplicate_of = %+v, want chain [mid222 abc123] ending at abc123 (first line)", d)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_show", Arguments: map[string]any{"id": "abc123"}})
+	if err != nil || res.IsError {
+		t.Fatalf("wn_show abc123: err=%v", err)
+	}
+	if strings.Contains(textContent(res), "duplicate_of") {
+		t.Errorf("wn_show of a non-duplicate should omit duplicate_of; got %s", textContent(res))
+	}
+}
+
 func TestMCP_wn_item(t *testing.T) {
 	ctx, cs, cleanup := setupMCPSession(t)
 	defer cleanup()
@@ -648,6 +902,108 @@ func TestMCP_wn_claim_and_release(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_claim_warnsOnUnfinishedDeps(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "dep001", Description: "prerequisite", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateItem("abc123", func(it *Item) (*Item, error) {
+		it.DependsOn = []string{"dep001"}
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_claim", Arguments: map[string]any{"id": "abc123", "for": "30m"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_claim: %v", err)
+	}
+	text := textContent(res)
+	if res.IsError || !strings.Contains(text, "claimed abc123") || !strings.Contains(text, "warning: depends on items that are not done: dep001 (prerequisite)") {
+		t.Errorf("wn_claim content = %q, want claim plus warning", text)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_claim", Arguments: map[string]any{"id": "abc123", "for": "30m", "no_warn": true}})
+	if err != nil {
+		t.Fatalf("CallTool wn_claim: %v", err)
+	}
+	if text := textContent(res); strings.Contains(text, "warning") {
+		t.Errorf("wn_claim no_warn content = %q, want no warning", text)
+	}
+}
+
+func TestMCP_wn_claim_omitted_for_uses_settings_default(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+	if err := os.WriteFile(filepath.Join(".wn", "settings.json"), []byte(`{"default_claim":"4h"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now().UTC()
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_claim", Arguments: map[string]any{"id": "abc123"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_claim: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("wn_claim: %s", textContent(res))
+	}
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get("abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.InProgressUntil.Before(before.Add(4*time.Hour)) || it.InProgressUntil.After(time.Now().UTC().Add(4*time.Hour)) {
+		t.Errorf("in_progress_until = %v, want about now+4h", it.InProgressUntil)
+	}
+}
+
+func TestMCP_wn_claim_until(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	deadline := time.Now().UTC().Add(2 * time.Hour).Truncate(time.Second)
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_claim", Arguments: map[string]any{"id": "abc123", "until": deadline.Format(time.RFC3339)}})
+	if err != nil {
+		t.Fatalf("CallTool wn_claim: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("wn_claim until: %s", textContent(res))
+	}
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get("abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.InProgressUntil.Equal(deadline) {
+		t.Errorf("in_progress_until = %v, want %v", it.InProgressUntil, deadline)
+	}
+
+	for _, args := range []map[string]any{
+		{"id": "abc123", "until": time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)},
+		{"id": "abc123", "until": deadline.Format(time.RFC3339), "for": "1h"},
+	} {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_claim", Arguments: args})
+		if err != nil {
+			t.Fatalf("CallTool wn_claim: %v", err)
+		}
+		if !res.IsError {
+			t.Errorf("wn_claim %v: want IsError true", args)
+		}
+	}
+}
+
 func TestMCP_wn_claim_invalid_duration(t *testing.T) {
 	ctx, cs, cleanup := setupMCPSession(t)
 	defer cleanup()
@@ -1104,6 +1460,71 @@ func TestMCP_fixed_root_guardrail(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_next_exclude_tags(t *testing.T) {
+	ctx, cs, dir, cleanup := setupMCPSessionTwoItems(t, "aa1111", "bb2222")
+	defer cleanup()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, tag := range map[string]string{"aa1111": "manual-only", "bb2222": "human"} {
+		if err := store.UpdateItem(id, func(it *Item) (*Item, error) {
+			it.Tags = []string{tag}
+			return it, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(ProjectSettingsPath(dir), []byte(`{"next": {"exclude_tags": ["manual-only"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_next", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool wn_next: %v", err)
+	}
+	if text := textContent(res); !strings.Contains(text, `"id":"bb2222"`) {
+		t.Errorf("wn_next with settings exclude_tags = %q, want bb2222", text)
+	}
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_next", Arguments: map[string]any{"exclude_tags": []string{"human"}}})
+	if err != nil {
+		t.Fatalf("CallTool wn_next exclude_tags: %v", err)
+	}
+	if text := textContent(res); !strings.Contains(text, `"id":null`) {
+		t.Errorf("wn_next excluding both tags = %q, want id null", text)
+	}
+}
+
+func TestMCP_wn_next_strictOrderSetting(t *testing.T) {
+	ctx, cs, dir, cleanup := setupMCPSessionTwoItems(t, "aa1111", "bb2222")
+	defer cleanup()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bb2222 was created first, so it leads unless strict order breaks the tie by id.
+	now := time.Now().UTC()
+	for id, created := range map[string]time.Time{"aa1111": now, "bb2222": now.Add(-time.Hour)} {
+		if err := store.UpdateItem(id, func(it *Item) (*Item, error) {
+			it.Created = created
+			return it, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(ProjectSettingsPath(dir), []byte(`{"next": {"strict_order": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_next", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool wn_next: %v", err)
+	}
+	if text := textContent(res); !strings.Contains(text, `"id":"aa1111"`) {
+		t.Errorf("wn_next with next.strict_order = %q, want aa1111", text)
+	}
+}
+
 // setupMCPSessionTwoItems creates a temp wn root with two items (id1, id2), current=id1.
 func setupMCPSessionTwoItems(t *testing.T, id1, id2 string) (context.Context, *mcp.ClientSession, string, func()) {
 	t.Helper()
@@ -1223,6 +1644,250 @@ func TestMCP_wn_depend_and_wn_rmdepend(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_depend_rejects_self(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_depend", Arguments: map[string]any{"id": "abc123", "on": "abc123"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_depend: %v", err)
+	}
+	if !res.IsError || !strings.Contains(textContent(res), "an item cannot depend on itself") {
+		t.Errorf("wn_depend onto itself = %q (IsError %v), want self-dependency error", textContent(res), res.IsError)
+	}
+}
+
+func TestMCP_wn_rmdepend_all(t *testing.T) {
+	ctx, cs, _, cleanup := setupMCPSessionTwoItems(t, "aa1111", "bb2222")
+	defer cleanup()
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "cc3333", Description: "third", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateItem("aa1111", func(it *Item) (*Item, error) {
+		it.DependsOn = []string{"bb2222", "cc3333"}
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_rmdepend", Arguments: map[string]any{"id": "aa1111", "all": true, "on": "bb2222"}})
+	if err != nil || !res.IsError {
+		t.Errorf("wn_rmdepend all with on should be an error result; err=%v", err)
+	}
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_rmdepend", Arguments: map[string]any{"id": "aa1111", "all": true}})
+	if err != nil || res.IsError {
+		t.Fatalf("wn_rmdepend all: err=%v result=%s", err, textContent(res))
+	}
+	if text := textContent(res); !strings.Contains(text, "removed 2 dependencies from aa1111 (bb2222, cc3333)") {
+		t.Errorf("wn_rmdepend all content = %q", text)
+	}
+	it, err := store.Get("aa1111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(it.DependsOn) != 0 {
+		t.Errorf("DependsOn after wn_rmdepend all = %v, want []", it.DependsOn)
+	}
+}
+func TestMCP_wn_tag_and_wn_untag(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	call := func(name string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("CallTool %s: %v", name, err)
+		}
+		return res
+	}
+	if res := call("wn_tag", map[string]any{"tag": "needs-tests"}); res.IsError {
+		t.Fatalf("wn_tag: %s", textContent(res))
+	}
+	call("wn_tag", map[string]any{"id": "abc123", "tag": "needs-tests"})
+	if res := call("wn_tag", map[string]any{"tag": "bad tag"}); !res.IsError {
+		t.Error("wn_tag with an invalid tag should be an error")
+	}
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, _ := store.Get("abc123")
+	if len(item.Tags) != 1 || item.Tags[0] != "needs-tests" {
+		t.Errorf("tags after wn_tag = %v, want [needs-tests] (no duplicate)", item.Tags)
+	}
+	if last := item.Log[len(item.Log)-1]; last.Kind != "tag_added" || last.Msg != "needs-tests" {
+		t.Errorf("last log = %+v, want tag_added needs-tests", last)
+	}
+
+	if res := call("wn_untag", map[string]any{"id": "abc123", "tag": "needs-tests"}); res.IsError {
+		t.Fatalf("wn_untag: %s", textContent(res))
+	}
+	item, _ = store.Get("abc123")
+	if len(item.Tags) != 0 {
+		t.Errorf("tags after wn_untag = %v, want none", item.Tags)
+	}
+	if last := item.Log[len(item.Log)-1]; last.Kind != "tag_removed" {
+		t.Errorf("last log kind = %q, want tag_removed", last.Kind)
+	}
+	if res := call("wn_untag", map[string]any{"id": "zzz999", "tag": "x"}); !res.IsError {
+		t.Error("wn_untag on a missing item should be an error")
+	}
+
+	// A tag stored before validation can still be removed.
+	if err := store.UpdateItem("abc123", func(it *Item) (*Item, error) {
+		it.Tags = []string{"old tag"}
+		return it, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if res := call("wn_untag", map[string]any{"id": "abc123", "tag": "old tag"}); res.IsError {
+		t.Fatalf("wn_untag legacy tag: %s", textContent(res))
+	}
+	if item, _ = store.Get("abc123"); len(item.Tags) != 0 {
+		t.Errorf("tags after wn_untag of a legacy tag = %v, want none", item.Tags)
+	}
+}
+
+func TestMCP_wn_order(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_order", Arguments: args})
+		if err != nil {
+			t.Fatalf("CallTool wn_order: %v", err)
+		}
+		return res
+	}
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := call(map[string]any{"set": 0}); res.IsError {
+		t.Fatalf("wn_order set 0: %s", textContent(res))
+	}
+	item, _ := store.Get("abc123")
+	if item.Order == nil || *item.Order != 0 {
+		t.Errorf("order after set 0 = %v, want 0", item.Order)
+	}
+	if last := item.Log[len(item.Log)-1]; last.Kind != "order_set" || last.Msg != "0" {
+		t.Errorf("last log = %+v, want order_set 0", last)
+	}
+	if res := call(map[string]any{"id": "abc123", "unset": true}); res.IsError {
+		t.Fatalf("wn_order unset: %s", textContent(res))
+	}
+	item, _ = store.Get("abc123")
+	if item.Order != nil {
+		t.Errorf("order after unset = %d, want nil", *item.Order)
+	}
+	if last := item.Log[len(item.Log)-1]; last.Kind != "order_cleared" {
+		t.Errorf("last log kind = %q, want order_cleared", last.Kind)
+	}
+	for _, args := range []map[string]any{
+		{},
+		{"set": 5, "unset": true},
+		{"set": MaxOrder + 1},
+		{"set": -1},
+	} {
+		if res := call(args); !res.IsError {
+			t.Errorf("wn_order %v should be an error", args)
+		}
+	}
+
+	// A request root uses that project's order range, not the server's.
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	other := t.TempDir()
+	if err := InitRoot(other); err != nil {
+		t.Fatal(err)
+	}
+	otherStore, err := NewFileStore(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if err := otherStore.Put(&Item{ID: "oth001", Description: "other", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(other, ".wn", "settings.json"), []byte(`{"order_max": 9, "order_default": 5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res := call(map[string]any{"id": "oth001", "set": 10, "root": other}); !res.IsError {
+		t.Errorf("wn_order set 10 with order_max 9 in root should be an error")
+	}
+	if res := call(map[string]any{"id": "oth001", "unset": true, "root": other}); res.IsError || !strings.Contains(textContent(res), "(default 5)") {
+		t.Errorf("wn_order unset in root = %q, want default 5", textContent(res))
+	}
+}
+
+func TestMCP_resources(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	res, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "wn://items"})
+	if err != nil {
+		t.Fatalf("ReadResource wn://items: %v", err)
+	}
+	var items []listItem
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &items); err != nil {
+		t.Fatalf("wn://items is not wn_list JSON: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != "abc123" || items[0].Description != "first line" {
+		t.Errorf("wn://items = %+v, want abc123", items)
+	}
+
+	res, err = cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "wn://items/abc123"})
+	if err != nil {
+		t.Fatalf("ReadResource wn://items/abc123: %v", err)
+	}
+	var item showOutput
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &item); err != nil || item.Description != "first line\nbody for prompt" {
+		t.Errorf("wn://items/abc123 = %+v, %v", item, err)
+	}
+	if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "wn://items/zzz999"}); err == nil {
+		t.Error("ReadResource of a missing item should fail")
+	}
+}
+
+func TestMCP_wn_note_add_append(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	for _, body := range []string{"first", "second"} {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "wn_note_add",
+			Arguments: map[string]any{"id": "abc123", "name": "status", "body": body, "append": true},
+		})
+		if err != nil {
+			t.Fatalf("CallTool wn_note_add: %v", err)
+		}
+		if res.IsError {
+			t.Fatalf("wn_note_add: %s", textContent(res))
+		}
+	}
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_show", Arguments: map[string]any{"id": "abc123"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_show: %v", err)
+	}
+	var show struct {
+		Notes []struct {
+			Name string `json:"name"`
+			Body string `json:"body"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(textContent(res)), &show); err != nil {
+		t.Fatalf("wn_show JSON: %v", err)
+	}
+	if len(show.Notes) != 1 || show.Notes[0].Body != "first\nsecond" {
+		t.Errorf("after append: notes = %v", show.Notes)
+	}
+}
+
 func TestMCP_wn_note_add_edit_rm(t *testing.T) {
 	ctx, cs, cleanup := setupMCPSession(t)
 	defer cleanup()
@@ -1304,6 +1969,49 @@ func TestMCP_wn_note_add_edit_rm(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_note_get(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "wn_note_add",
+		Arguments: map[string]any{"id": "abc123", "name": "branch", "body": "wn-abc123-feature"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool wn_note_add: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("wn_note_add: %s", textContent(res))
+	}
+
+	// Omitted id falls back to the current task (abc123)
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_note_get", Arguments: map[string]any{"name": "branch"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_note_get: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("wn_note_get: %s", textContent(res))
+	}
+	var got struct {
+		Name string `json:"name"`
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(textContent(res)), &got); err != nil {
+		t.Fatalf("wn_note_get JSON: %v", err)
+	}
+	if got.Name != "branch" || got.Body != "wn-abc123-feature" {
+		t.Errorf("wn_note_get = %+v", got)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "wn_note_get", Arguments: map[string]any{"id": "abc123", "name": "pr-url"}})
+	if err != nil {
+		t.Fatalf("CallTool wn_note_get (missing): %v", err)
+	}
+	if !res.IsError || !strings.Contains(textContent(res), "pr-url") {
+		t.Errorf("wn_note_get missing note: IsError=%v text=%q", res.IsError, textContent(res))
+	}
+}
+
 func TestMCP_wn_duplicate(t *testing.T) {
 	ctx, cs, cleanup := setupMCPSession(t)
 	defer cleanup()
@@ -1376,6 +2084,48 @@ func TestMCP_wn_duplicate(t *testing.T) {
 	}
 }
 
+func TestMCP_wn_duplicate_keep_open(t *testing.T) {
+	ctx, cs, cleanup := setupMCPSession(t)
+	defer cleanup()
+	store, err := NewFileStore(".")
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "dup111", Description: "suspected copy", Created: now, Updated: now}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "wn_duplicate",
+		Arguments: map[string]any{"id": "dup111", "on": "abc123", "keep_open": true},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("wn_duplicate keep_open: err=%v result=%s", err, textContent(res))
+	}
+	if text := textContent(res); !strings.Contains(text, "kept open") {
+		t.Errorf("wn_duplicate keep_open content = %q", text)
+	}
+	item, err := store.Get("dup111")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if item.Done {
+		t.Error("keep_open should leave the item undone")
+	}
+	if idx := item.NoteIndexByName(NoteNameDuplicateOf); idx < 0 || item.Notes[idx].Body != "abc123" {
+		t.Errorf("duplicate-of note missing or wrong: %v", item.Notes)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "wn_duplicate",
+		Arguments: map[string]any{"id": "dup111", "on": "dup111", "keep_open": true},
+	})
+	if err != nil || !res.IsError {
+		t.Errorf("wn_duplicate keep_open onto itself should be an error result; err=%v", err)
+	}
+}
+
 func TestMCP_wn_prompt_createsItemAndDep(t *testing.T) {
 	ctx, cs, cleanup := setupMCPSession(t)
 	defer cleanup()
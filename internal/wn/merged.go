@@ -10,20 +10,50 @@ import (
 // MarkMergedResult reports one review-ready item's outcome from MarkMergedItems.
 type MarkMergedResult struct {
 	ID     string
-	Status string // "marked", "skipped_no_branch", "skipped_not_merged", "skipped_error"
+	Status string // "marked", "skipped_no_branch", "skipped_not_merged", "skipped_squash_ambiguous", "skipped_error"
 	Reason string
+	// MergedInto is the ref recorded in the "merged-into" note (set when Status is "marked").
+	MergedInto string
+	// MergeBase is the merge-base commit SHA of the branch and intoRef (set when Status is "marked").
+	MergeBase string
 }
 
 var commitHashRe = regexp.MustCompile("^[0-9a-fA-F]{7,40}$")
 
-// MarkMergedItems checks all review-ready items, finds their "branch" note, and
-// marks done those whose branch has been merged into intoRef (empty = HEAD).
-// If dryRun is true, no changes are made. Returns results for each item checked.
-func MarkMergedItems(store Store, repoRoot, intoRef string, dryRun bool) ([]MarkMergedResult, error) {
-	items, err := ReviewReadyItems(store)
-	if err != nil {
-		return nil, err
+// MarkMergedOpts configures MarkMergedItems.
+type MarkMergedOpts struct {
+	IntoRef string // ref to check merged into; empty = HEAD
+	DryRun  bool   // report what would be marked without making changes
+	Tag     string // if set, only review-ready items with this tag are checked
+	ID      string // if set, only this item is checked (it must be review-ready)
+	Remote  string // if set, also check <Remote>/<branch> (e.g. "origin") when the local branch is missing or unmerged
+}
+
+// MarkMergedItems checks review-ready items (optionally narrowed by opts.Tag or opts.ID),
+// finds their "branch" note, and marks done those whose branch has been merged into
+// opts.IntoRef (empty = HEAD). Each marked item also gets a "merged-into" note naming
+// the ref and a "merged" log entry whose Msg is "merge-base <sha>".
+// If opts.DryRun is true, no changes are made. Returns results for each item checked.
+func MarkMergedItems(store Store, repoRoot string, opts MarkMergedOpts) ([]MarkMergedResult, error) {
+	intoRef, dryRun := opts.IntoRef, opts.DryRun
+	var items []*Item
+	if opts.ID != "" {
+		it, err := store.Get(opts.ID)
+		if err != nil {
+			return nil, fmt.Errorf("item %s: %w", opts.ID, err)
+		}
+		if it.Done || !it.ReviewReady {
+			return nil, fmt.Errorf("item %s is not review-ready", opts.ID)
+		}
+		items = []*Item{it}
+	} else {
+		var err error
+		items, err = ReviewReadyItems(store)
+		if err != nil {
+			return nil, err
+		}
 	}
+	items = FilterByTag(items, opts.Tag)
 	var results []MarkMergedResult
 	for _, it := range items {
 		idx := it.NoteIndexByName("branch")
@@ -36,36 +66,25 @@ func MarkMergedItems(store Store, repoRoot, intoRef string, dryRun bool) ([]Mark
 			results = append(results, MarkMergedResult{ID: it.ID, Status: "skipped_no_branch", Reason: "branch note empty"})
 			continue
 		}
-		merged, err := BranchMergedInto(repoRoot, branch, intoRef)
-		if err != nil {
-			// If the branch no longer exists (e.g. cleaned up after merge), fall back to a commit hash
-			// from a commit/commit-info note when available.
-			if strings.Contains(err.Error(), "does not exist") {
-				commitRef := commitRefFromNotes(it)
-				if commitRef == "" {
-					results = append(results, MarkMergedResult{
-						ID:     it.ID,
-						Status: "skipped_error",
-						Reason: fmt.Sprintf("branch %s does not exist and no commit note found", branch),
-					})
-					continue
-				}
-				merged, err = CommitMergedInto(repoRoot, commitRef, intoRef)
-				if err != nil {
-					results = append(results, MarkMergedResult{ID: it.ID, Status: "skipped_error", Reason: err.Error()})
-					continue
-				}
-			} else {
-				results = append(results, MarkMergedResult{ID: it.ID, Status: "skipped_error", Reason: err.Error()})
-				continue
-			}
+		ref, status, reason := findMergedRef(repoRoot, it, branch, intoRef, opts.Remote)
+		if status != "" {
+			results = append(results, MarkMergedResult{ID: it.ID, Status: status, Reason: reason})
+			continue
 		}
-		if !merged {
-			results = append(results, MarkMergedResult{ID: it.ID, Status: "skipped_not_merged", Reason: fmt.Sprintf("branch %s not merged", branch)})
+		base, err := MergeBase(repoRoot, ref, intoRef)
+		if err != nil {
+			results = append(results, MarkMergedResult{ID: it.ID, Status: "skipped_error", Reason: err.Error()})
 			continue
 		}
+		into := mergedIntoRef(repoRoot, intoRef)
 		if dryRun {
-			results = append(results, MarkMergedResult{ID: it.ID, Status: "marked", Reason: fmt.Sprintf("would mark done (branch %s merged)", branch)})
+			results = append(results, MarkMergedResult{
+				ID:         it.ID,
+				Status:     "marked",
+				Reason:     fmt.Sprintf("would mark done (branch %s merged into %s, merge base %s)", branch, into, shortSHA(base)),
+				MergedInto: into,
+				MergeBase:  base,
+			})
 			continue
 		}
 		now := time.Now().UTC()
@@ -79,17 +98,113 @@ func MarkMergedItems(store Store, repoRoot, intoRef string, dryRun bool) ([]Mark
 			item.DoneStatus = DoneStatusDone
 			item.ReviewReady = false
 			item.Updated = now
+			if idx := item.NoteIndexByName(NoteNameMergedInto); idx >= 0 {
+				item.Notes[idx].Body = into
+			} else {
+				item.Notes = append(item.Notes, Note{Name: NoteNameMergedInto, Created: now, Body: into})
+			}
+			item.Log = append(item.Log, LogEntry{At: now, Kind: "merged", Msg: "merge-base " + base})
 			item.Log = append(item.Log, LogEntry{At: now, Kind: "done", Msg: msg})
 			return item, nil
 		}); err != nil {
 			results = append(results, MarkMergedResult{ID: it.ID, Status: "skipped_error", Reason: err.Error()})
 			continue
 		}
-		results = append(results, MarkMergedResult{ID: it.ID, Status: "marked", Reason: msg})
+		results = append(results, MarkMergedResult{
+			ID:         it.ID,
+			Status:     "marked",
+			Reason:     fmt.Sprintf("%s (merge base %s)", msg, shortSHA(base)),
+			MergedInto: into,
+			MergeBase:  base,
+		})
 	}
 	return results, nil
 }
 
+// findMergedRef determines whether an item's branch has reached intoRef. It checks the local
+// branch, then <remote>/<branch> when remote is set, and when neither exists falls back to a
+// commit hash from a commit/commit-info note (e.g. branch cleaned up after merge). A ref whose
+// commits are all present in intoRef by content (git cherry) counts as merged. On success it
+// returns the merged ref and an empty status; otherwise a skip status and reason. A branch that
+// is not merged by ancestry but has some patches in intoRef, has a pr-url note, or was pushed
+// (it exists on the remote, or was deleted there or everywhere) is reported as
+// "skipped_squash_ambiguous" so it can be reviewed manually: a squash of several commits
+// matches none of them by content.
+func findMergedRef(repoRoot string, it *Item, branch, intoRef, remote string) (ref, status, reason string) {
+	var candidates []string
+	exists, err := BranchExists(repoRoot, branch)
+	if err != nil {
+		return "", "skipped_error", err.Error()
+	}
+	pushed := false
+	if exists {
+		candidates = append(candidates, "refs/heads/"+branch)
+		if pushed, err = UpstreamGone(repoRoot, branch); err != nil {
+			return "", "skipped_error", err.Error()
+		}
+	}
+	if remote != "" {
+		remoteRef := "refs/remotes/" + remote + "/" + branch
+		ok, err := RefExists(repoRoot, remoteRef)
+		if err != nil {
+			return "", "skipped_error", err.Error()
+		}
+		if ok {
+			candidates = append(candidates, remoteRef)
+			pushed = true
+		}
+	}
+	if len(candidates) == 0 {
+		pushed = true // deleted locally and on the remote
+		commitRef := commitRefFromNotes(it)
+		if commitRef == "" {
+			return "", "skipped_error", fmt.Sprintf("branch %s does not exist and no commit note found", branch)
+		}
+		candidates = append(candidates, commitRef)
+	}
+	for _, c := range candidates {
+		merged, err := CommitMergedInto(repoRoot, c, intoRef)
+		if err != nil {
+			return "", "skipped_error", err.Error()
+		}
+		if merged {
+			return c, "", ""
+		}
+	}
+	ref = candidates[0]
+	applied, total, err := CherryApplied(repoRoot, ref, intoRef)
+	if err != nil {
+		return "", "skipped_error", err.Error()
+	}
+	if total > 0 && applied == total {
+		return ref, "", ""
+	}
+	if applied > 0 || pushed || it.NoteIndexByName(NoteNamePRURL) >= 0 {
+		return "", "skipped_squash_ambiguous", fmt.Sprintf("branch %s not merged by ancestry (%d/%d commits found by content); may be squash-merged, review manually", branch, applied, total)
+	}
+	return "", "skipped_not_merged", fmt.Sprintf("branch %s not merged", branch)
+}
+
+// mergedIntoRef returns the ref name to record in the "merged-into" note: intoRef when
+// given, otherwise the current branch name (or "HEAD" when detached or unknown).
+func mergedIntoRef(repoRoot, intoRef string) string {
+	if intoRef != "" && intoRef != "HEAD" {
+		return intoRef
+	}
+	if b, err := CurrentBranchInDir(repoRoot); err == nil && b != "" {
+		return b
+	}
+	return "HEAD"
+}
+
+// shortSHA abbreviates a commit SHA for display.
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
 // commitRefFromNotes attempts to extract a commit hash from well-known notes ("commit" or "commit-info").
 // It returns the first token of the note body when it looks like a hex SHA (7-40 chars), or "" otherwise.
 func commitRefFromNotes(it *Item) string {
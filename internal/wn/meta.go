@@ -48,6 +48,12 @@ func WriteMeta(root string, m Meta) error {
 // WithMetaLock runs fn with exclusive lock on meta; fn receives current Meta and returns the updated Meta to write.
 // Use for read-modify-write of meta (e.g. setting CurrentID) so concurrent callers are serialized.
 func WithMetaLock(root string, fn func(Meta) (Meta, error)) error {
+	return withMetaLock(root, true, fn)
+}
+
+// withMetaLock is WithMetaLock; trackPrevious controls whether a replaced CurrentID is recorded
+// as PreviousID (callers that set both fields themselves pass false).
+func withMetaLock(root string, trackPrevious bool, fn func(Meta) (Meta, error)) error {
 	dir := filepath.Join(root, ".wn")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -72,7 +78,7 @@ func WithMetaLock(root string, fn func(Meta) (Meta, error)) error {
 		return err
 	}
 	// Auto-track previous: when CurrentID changes and there was a prior value, record it.
-	if updated.CurrentID != m.CurrentID && m.CurrentID != "" {
+	if trackPrevious && updated.CurrentID != m.CurrentID && m.CurrentID != "" {
 		updated.PreviousID = m.CurrentID
 	}
 	return WriteMeta(root, updated)
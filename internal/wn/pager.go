@@ -0,0 +1,75 @@
+package wn
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// DefaultPager is used when neither WN_PAGER nor PAGER is set.
+const DefaultPager = "less -FRX"
+
+// PagerCommand returns the pager command line: WN_PAGER, then PAGER, then DefaultPager.
+func PagerCommand() string {
+	if p := strings.TrimSpace(os.Getenv("WN_PAGER")); p != "" {
+		return p
+	}
+	if p := strings.TrimSpace(os.Getenv("PAGER")); p != "" {
+		return p
+	}
+	return DefaultPager
+}
+
+// IsTerminal reports whether f is connected to a terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(f.Fd())
+}
+
+// TerminalWidth returns the width in columns of the terminal f is connected to, or 0 when f is
+// not a terminal or its size cannot be determined.
+func TerminalWidth(f *os.File) int {
+	if !IsTerminal(f) {
+		return 0
+	}
+	w, _, err := term.GetSize(f.Fd())
+	if err != nil || w <= 0 {
+		return 0
+	}
+	return w
+}
+
+// needsPaging reports whether out has more lines than a terminal of the given height can show.
+func needsPaging(out string, height int) bool {
+	if height <= 0 {
+		return false
+	}
+	return strings.Count(strings.TrimSuffix(out, "\n"), "\n")+1 > height
+}
+
+// PageOutput writes out to stdout, through PagerCommand when stdout is a terminal and out is
+// taller than it. If the pager cannot be started, out is written directly.
+func PageOutput(out string) error {
+	height := 0
+	if IsTerminal(os.Stdout) {
+		if _, h, err := term.GetSize(os.Stdout.Fd()); err == nil {
+			height = h
+		}
+	}
+	if !needsPaging(out, height) {
+		_, err := io.WriteString(os.Stdout, out)
+		return err
+	}
+	parts := splitEditorArgs(PagerCommand())
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Stdin = strings.NewReader(out)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		_, err := io.WriteString(os.Stdout, out)
+		return err
+	}
+	return cmd.Wait()
+}
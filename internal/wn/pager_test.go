@@ -0,0 +1,37 @@
+package wn
+
+import "testing"
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("WN_PAGER", "")
+	t.Setenv("PAGER", "")
+	if got := PagerCommand(); got != DefaultPager {
+		t.Errorf("PagerCommand() = %q, want %q", got, DefaultPager)
+	}
+	t.Setenv("PAGER", "more")
+	if got := PagerCommand(); got != "more" {
+		t.Errorf("PagerCommand() with PAGER = %q, want more", got)
+	}
+	t.Setenv("WN_PAGER", "less -R")
+	if got := PagerCommand(); got != "less -R" {
+		t.Errorf("PagerCommand() with WN_PAGER = %q, want WN_PAGER to win", got)
+	}
+}
+
+func TestNeedsPaging(t *testing.T) {
+	tests := []struct {
+		out    string
+		height int
+		want   bool
+	}{
+		{"a\nb\n", 2, false},
+		{"a\nb\nc\n", 2, true},
+		{"a\nb\nc", 2, true},
+		{"a\nb\nc\n", 0, false},
+	}
+	for _, tt := range tests {
+		if got := needsPaging(tt.out, tt.height); got != tt.want {
+			t.Errorf("needsPaging(%q, %d) = %v, want %v", tt.out, tt.height, got, tt.want)
+		}
+	}
+}
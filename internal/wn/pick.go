@@ -79,12 +79,33 @@ func pickMulti(items []*Item, suffix func(*Item) string) ([]string, error) {
 	return pickMultiNumbered(items, suffix)
 }
 
+// fzfPreviewArgs returns fzf flags that show the highlighted item in a preview window by
+// running this wn binary's "show" on the id before the first ":" of the line. Returns nil
+// when the executable path is unknown, so fzf runs without a preview.
+func fzfPreviewArgs() []string {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	preview := shellQuote(exe)
+	if cliRootOverride != "" {
+		preview += " --root " + shellQuote(cliRootOverride)
+	}
+	preview += " show {1}"
+	return []string{"--delimiter", ":", "--preview", preview, "--preview-window", "right,60%,wrap"}
+}
+
+// shellQuote single-quotes s for use in a POSIX shell command line.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func pickFzf(items []*Item) (string, error) {
 	lines := make([]string, len(items))
 	for i, it := range items {
 		lines[i] = fmt.Sprintf("%s: %s", it.ID, FirstLine(it.Description))
 	}
-	cmd := exec.Command("fzf", "--no-multi")
+	cmd := exec.Command("fzf", append([]string{"--no-multi"}, fzfPreviewArgs()...)...)
 	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n"))
 	cmd.Stderr = os.Stderr
 	out, err := cmd.Output()
@@ -132,7 +153,7 @@ func pickMultiFzf(items []*Item, suffix func(*Item) string) ([]string, error) {
 		}
 		lines[i] = line
 	}
-	cmd := exec.Command("fzf", "--multi")
+	cmd := exec.Command("fzf", append([]string{"--multi"}, fzfPreviewArgs()...)...)
 	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n"))
 	cmd.Stderr = os.Stderr
 	out, err := cmd.Output()
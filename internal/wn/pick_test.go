@@ -2,6 +2,7 @@ package wn
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -185,3 +186,18 @@ func TestPickMultiInteractive_NumberedChoice(t *testing.T) {
 		t.Errorf("ids = %v, want [aa, bb]", ids)
 	}
 }
+
+func TestFzfPreviewArgs(t *testing.T) {
+	SetCLIRootOverride("/tmp/it's here")
+	t.Cleanup(func() { SetCLIRootOverride("") })
+	args := fzfPreviewArgs()
+	if len(args) == 0 {
+		t.Fatal("fzfPreviewArgs returned no args")
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"--delimiter :", "--preview", " show {1}", `--root '/tmp/it'\''s here'`} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("fzfPreviewArgs = %q, want it to contain %q", joined, want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package wn
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 // ItemListStatus returns the display status for list/JSON output.
 // Possible values: "undone", "blocked", "claimed", "review", "done", "closed", "suspend".
@@ -39,8 +43,13 @@ func BlockedSet(allItems []*Item) map[string]bool {
 	for _, it := range allItems {
 		doneByID[it.ID] = it.Done
 	}
+	return blockedSet(allItems, doneByID)
+}
+
+// blockedSet returns the blocked ids among items given the done state of every known item.
+func blockedSet(items []*Item, doneByID map[string]bool) map[string]bool {
 	blocked := make(map[string]bool)
-	for _, it := range allItems {
+	for _, it := range items {
 		if it.Done || it.ReviewReady || it.PromptReady {
 			continue
 		}
@@ -84,7 +93,7 @@ func IsAvailableUndone(it *Item, now time.Time) bool {
 // Excludes review-ready and in-progress; clears expired in-progress lazily.
 func UndoneItems(store Store) ([]*Item, error) {
 	now := time.Now().UTC()
-	items, err := store.List()
+	items, err := listUndoneFromIndex(store)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +131,7 @@ func UndoneItems(store Store) ([]*Item, error) {
 
 // ReviewReadyItems returns all items that are undone and review-ready (excluded from next/claim).
 func ReviewReadyItems(store Store) ([]*Item, error) {
-	items, err := store.List()
+	items, err := listUndoneFromIndex(store)
 	if err != nil {
 		return nil, err
 	}
@@ -152,26 +161,76 @@ func FilterByTag(items []*Item, tag string) []*Item {
 	return filtered
 }
 
+// ExcludeByTags returns items that have none of the tags in exclude. If exclude is empty, returns items unchanged.
+func ExcludeByTags(items []*Item, exclude []string) []*Item {
+	if len(exclude) == 0 {
+		return items
+	}
+	filtered := make([]*Item, 0, len(items))
+	for _, it := range items {
+		if !slices.ContainsFunc(it.Tags, func(t string) bool { return slices.Contains(exclude, t) }) {
+			filtered = append(filtered, it)
+		}
+	}
+	return filtered
+}
+
 // NextUndoneItem returns the first undone item in dependency order, optionally filtered by tag.
 // If tag is non-empty, only items with that tag are considered. Returns nil if none.
 func NextUndoneItem(store Store, tag string) (*Item, error) {
+	return NextUndoneItemOrdered(store, tag, nil, nil)
+}
+
+// NextUndoneItemOrdered is NextUndoneItem that also skips items with any tag in exclude, with a
+// choice of ordering (see NextOrdering). A nil order uses TopoOrder.
+func NextUndoneItemOrdered(store Store, tag string, exclude []string, order func([]*Item) ([]*Item, bool)) (*Item, error) {
 	undone, err := UndoneItems(store)
 	if err != nil {
 		return nil, err
 	}
-	undone = FilterByTag(undone, tag)
-	ordered, acyclic := TopoOrder(undone)
+	undone = ExcludeByTags(FilterByTag(undone, tag), exclude)
+	if order == nil {
+		order = TopoOrder
+	}
+	ordered, acyclic := order(undone)
 	if !acyclic || len(ordered) == 0 {
 		return nil, nil
 	}
 	return ordered[0], nil
 }
 
+// NextOrdering returns the ordering used to pick the next item: TopoOrderStrict when strict,
+// TopoOrderSorted with the configured sort when next.uses_sort is set and sort is valid, and
+// TopoOrder otherwise.
+func NextOrdering(settings Settings, strict bool) func([]*Item) ([]*Item, bool) {
+	if strict {
+		return TopoOrderStrict
+	}
+	if settings.Next.UsesSort {
+		if spec := SortSpecFromSettings(settings); len(spec) > 0 {
+			return func(items []*Item) ([]*Item, bool) { return TopoOrderSorted(items, spec) }
+		}
+	}
+	return TopoOrder
+}
+
+// NextExcludeTags returns the tags that keep items out of next selection: settings
+// next.exclude_tags plus extra (e.g. from --exclude-tag), without duplicates.
+func NextExcludeTags(settings Settings, extra []string) []string {
+	var out []string
+	for _, t := range append(slices.Clone(settings.Next.ExcludeTags), extra...) {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
 // ListableUndoneItems returns all undone items (including review-ready) for list/export.
 // Clears expired in-progress lazily. Used by wn list (default/--undone), export --undone, and MCP wn_list. For pick/next/claim use UndoneItems (available only); for list --review-ready use ReviewReadyItems.
 func ListableUndoneItems(store Store) ([]*Item, error) {
 	now := time.Now().UTC()
-	items, err := store.List()
+	items, err := listUndoneFromIndex(store)
 	if err != nil {
 		return nil, err
 	}
@@ -203,3 +262,71 @@ func ListableUndoneItems(store Store) ([]*Item, error) {
 	}
 	return result, nil
 }
+
+// TagStat counts the items carrying one tag by display status (see ItemListStatus).
+type TagStat struct {
+	Tag     string `json:"tag"`
+	Total   int    `json:"total"`
+	Undone  int    `json:"undone"`
+	Blocked int    `json:"blocked"`
+	Claimed int    `json:"claimed"`
+	Review  int    `json:"review"`
+	Prompt  int    `json:"prompt"`
+	Done    int    `json:"done"`
+	Closed  int    `json:"closed"`
+	Suspend int    `json:"suspend"`
+}
+
+// Counts returns the status counts in ItemListStatus order, paired with the status names.
+func (s TagStat) Counts() ([]string, []int) {
+	return []string{"undone", "blocked", "claimed", "review", "prompt", "done", "closed", "suspend"},
+		[]int{s.Undone, s.Blocked, s.Claimed, s.Review, s.Prompt, s.Done, s.Closed, s.Suspend}
+}
+
+// TagStats returns per-tag status counts for items, most items first (ties by tag name).
+// allItems should be the complete list of items so blocked state is accurate. Untagged items
+// are not counted.
+func TagStats(allItems []*Item, now time.Time) []TagStat {
+	blocked := BlockedSet(allItems)
+	byTag := make(map[string]*TagStat)
+	for _, it := range allItems {
+		status := ItemListStatus(it, now, blocked[it.ID])
+		for _, tag := range it.Tags {
+			s := byTag[tag]
+			if s == nil {
+				s = &TagStat{Tag: tag}
+				byTag[tag] = s
+			}
+			s.Total++
+			switch status {
+			case "undone":
+				s.Undone++
+			case "blocked":
+				s.Blocked++
+			case "claimed":
+				s.Claimed++
+			case "review":
+				s.Review++
+			case "prompt":
+				s.Prompt++
+			case "done":
+				s.Done++
+			case "closed":
+				s.Closed++
+			case "suspend":
+				s.Suspend++
+			}
+		}
+	}
+	out := make([]TagStat, 0, len(byTag))
+	for _, s := range byTag {
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b TagStat) int {
+		if a.Total != b.Total {
+			return b.Total - a.Total
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+	return out
+}
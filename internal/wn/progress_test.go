@@ -1,6 +1,7 @@
 package wn
 
 import (
+	"slices"
 	"testing"
 	"time"
 )
@@ -357,6 +358,61 @@ func TestNextUndoneItem_withTag(t *testing.T) {
 	}
 }
 
+func TestNextUndoneItemOrdered_exclude(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	for i, it := range []*Item{
+		{ID: "aaa", Tags: []string{"manual-only"}},
+		{ID: "bbb", Tags: []string{"agent", "human"}},
+		{ID: "ccc", Tags: []string{"agent"}},
+	} {
+		ord := i
+		it.Description, it.Created, it.Updated, it.Order = "item "+it.ID, now, now, &ord
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put %s: %v", it.ID, err)
+		}
+	}
+	for _, tt := range []struct {
+		tag     string
+		exclude []string
+		want    string
+	}{
+		{"", nil, "aaa"},
+		{"", []string{"manual-only"}, "bbb"},
+		{"", []string{"manual-only", "human"}, "ccc"},
+		{"agent", []string{"human"}, "ccc"},
+		{"", []string{"manual-only", "agent"}, ""},
+	} {
+		next, err := NextUndoneItemOrdered(store, tt.tag, tt.exclude, nil)
+		if err != nil {
+			t.Fatalf("NextUndoneItemOrdered: %v", err)
+		}
+		got := ""
+		if next != nil {
+			got = next.ID
+		}
+		if got != tt.want {
+			t.Errorf("NextUndoneItemOrdered(tag %q, exclude %v) = %q, want %q", tt.tag, tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestNextExcludeTags(t *testing.T) {
+	s := Settings{Next: NextSettings{ExcludeTags: []string{"manual-only", "blocked-on-legal"}}}
+	got := NextExcludeTags(s, []string{" manual-only", "human", ""})
+	want := []string{"manual-only", "blocked-on-legal", "human"}
+	if !slices.Equal(got, want) {
+		t.Errorf("NextExcludeTags = %v, want %v", got, want)
+	}
+	if got := NextExcludeTags(Settings{}, nil); len(got) != 0 {
+		t.Errorf("NextExcludeTags with nothing set = %v, want none", got)
+	}
+}
+
 func TestItemListStatus_promptReady(t *testing.T) {
 	now := time.Now().UTC()
 	future := now.Add(time.Hour)
@@ -447,3 +503,28 @@ func TestBlockedSet_promptReadyItemNotBlocked(t *testing.T) {
 		t.Error("BlockedSet: prompt-ready item 'aaa' should not be in blocked set")
 	}
 }
+
+func TestTagStats(t *testing.T) {
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "a", Tags: []string{"backend", "api"}},
+		{ID: "b", Tags: []string{"backend"}, Done: true},
+		{ID: "c", Tags: []string{"backend"}, DependsOn: []string{"a"}},
+		{ID: "d", Tags: []string{"ui"}, Done: true, DoneStatus: DoneStatusClosed},
+		{ID: "e", Tags: []string{"api"}, InProgressUntil: now.Add(time.Hour)},
+		{ID: "f"},
+	}
+	got := TagStats(items, now)
+	want := []TagStat{
+		{Tag: "backend", Total: 3, Undone: 1, Blocked: 1, Done: 1},
+		{Tag: "api", Total: 2, Undone: 1, Claimed: 1},
+		{Tag: "ui", Total: 1, Closed: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("TagStats = %+v, want %+v", got, want)
+	}
+	names, counts := want[0].Counts()
+	if len(names) != len(counts) || names[0] != "undone" || counts[1] != 1 {
+		t.Errorf("Counts = %v, %v", names, counts)
+	}
+}
@@ -1,6 +1,9 @@
 package wn
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
 
 // HasDescriptionBody reports whether the description has content after the first line
 // (i.e. more than a title-only one-liner).
@@ -18,3 +21,38 @@ func PromptContent(description string) string {
 	}
 	return description
 }
+
+// PromptEllipsis marks a prompt cut short by TruncatePrompt.
+const PromptEllipsis = "…"
+
+// PromptTooLong reports whether prompt is longer than max characters (runes). A max of 0 or
+// less means no limit.
+func PromptTooLong(prompt string, max int) bool {
+	return max > 0 && utf8.RuneCountInString(prompt) > max
+}
+
+// TruncatePrompt shortens prompt to at most max characters (runes), ellipsis included. It cuts
+// at the last line break or sentence end (". ", "! ", "? ") that fits, falling back to a hard
+// cut when there is none, and appends PromptEllipsis. Prompts within max are returned as is.
+func TruncatePrompt(prompt string, max int) string {
+	if !PromptTooLong(prompt, max) {
+		return prompt
+	}
+	runes := []rune(prompt)
+	keep := max - utf8.RuneCountInString(PromptEllipsis)
+	if keep <= 0 {
+		return string(runes[:max])
+	}
+	cut := keep
+	for i := keep - 1; i > 0; i-- {
+		if runes[i] == '\n' {
+			cut = i
+			break
+		}
+		if (runes[i-1] == '.' || runes[i-1] == '!' || runes[i-1] == '?') && runes[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRight(string(runes[:cut]), " \t\n") + PromptEllipsis
+}
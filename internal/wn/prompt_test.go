@@ -38,3 +38,31 @@ func TestPromptContent(t *testing.T) {
 		}
 	}
 }
+
+func TestTruncatePrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		max    int
+		want   string
+	}{
+		{"no limit", "a long prompt", 0, "a long prompt"},
+		{"fits", "short", 5, "short"},
+		{"line boundary", "first line\nsecond line", 15, "first line…"},
+		{"sentence boundary", "One. Two three four.", 12, "One.…"},
+		{"latest boundary wins", "A. B.\nC. D.", 10, "A. B.\nC.…"},
+		{"hard cut", "abcdefghij", 5, "abcd…"},
+		{"counts runes", "héllo wörld", 6, "héllo…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncatePrompt(tt.prompt, tt.max)
+			if got != tt.want {
+				t.Errorf("TruncatePrompt(%q, %d) = %q, want %q", tt.prompt, tt.max, got, tt.want)
+			}
+			if tt.max > 0 && PromptTooLong(got, tt.max) {
+				t.Errorf("TruncatePrompt(%q, %d) = %q is still over the limit", tt.prompt, tt.max, got)
+			}
+		})
+	}
+}
@@ -10,12 +10,31 @@ import (
 
 var ErrNoRoot = errors.New("wn root not found: no .wn directory in current or parent directories")
 
+var cliRootOverride string
+
+// SetCLIRootOverride sets the directory FindRootForCLI tries first (the global --root flag).
+// Empty clears the override.
+func SetCLIRootOverride(dir string) {
+	cliRootOverride = dir
+}
+
 // FindRootForCLI resolves the wn project root for CLI use. Tries in order:
-//  1. WN_ROOT env var (set e.g. by agent-orch for subagents)
-//  2. Walk up from cwd looking for .wn
-//  3. Git worktree detection: if cwd is a linked worktree, find the main
+//  1. The --root override (SetCLIRootOverride)
+//  2. WN_DIR env var (explicit project directory, e.g. in monorepos or CI)
+//  3. WN_ROOT env var (set e.g. by agent-orch for subagents)
+//  4. Walk up from cwd looking for .wn
+//  5. Git worktree detection: if cwd is a linked worktree, find the main
 //     repo via git rev-parse --git-common-dir and look for .wn there
+//
+// An explicit directory (1-3) may be the project root or any path under it; it does not
+// fall back to the cwd search when no .wn is found there.
 func FindRootForCLI() (string, error) {
+	if cliRootOverride != "" {
+		return FindRootFromDir(cliRootOverride)
+	}
+	if r := os.Getenv("WN_DIR"); r != "" {
+		return FindRootFromDir(r)
+	}
 	if r := os.Getenv("WN_ROOT"); r != "" {
 		return FindRootFromDir(r)
 	}
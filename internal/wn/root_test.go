@@ -207,3 +207,40 @@ func TestFindRootFromDir_Empty(t *testing.T) {
 		t.Errorf("FindRootFromDir(\"\") err = %v, want ErrNoRoot", err)
 	}
 }
+
+func TestFindRootForCLI_Precedence(t *testing.T) {
+	mk := func() string {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, ".wn", "items"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		norm, _ := filepath.EvalSymlinks(dir)
+		return norm
+	}
+	flagDir, wnDir, wnRoot := mk(), mk(), mk()
+	t.Setenv("WN_ROOT", wnRoot)
+	t.Setenv("WN_DIR", wnDir)
+	SetCLIRootOverride(flagDir)
+	t.Cleanup(func() { SetCLIRootOverride("") })
+
+	check := func(want string) {
+		t.Helper()
+		got, err := FindRootForCLI()
+		if err != nil {
+			t.Fatalf("FindRootForCLI: %v", err)
+		}
+		if got != want {
+			t.Errorf("FindRootForCLI = %q, want %q", got, want)
+		}
+	}
+	check(flagDir)
+	SetCLIRootOverride("")
+	check(wnDir)
+	t.Setenv("WN_DIR", "")
+	check(wnRoot)
+
+	SetCLIRootOverride(t.TempDir())
+	if _, err := FindRootForCLI(); err == nil {
+		t.Error("FindRootForCLI with --root pointing at a dir without .wn should fail")
+	}
+}
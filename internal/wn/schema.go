@@ -0,0 +1,65 @@
+package wn
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ItemSchemaVersion is the schema version of stored items. Bump it together with a new entry in
+// itemMigrations when a field change needs existing items rewritten.
+const ItemSchemaVersion = 1
+
+// itemMigrations[v-1] upgrades an item from schema version v to v+1. Items written before
+// versioning have no schema_version and are read as version 1.
+var itemMigrations []func(*Item)
+
+// decodeItem parses a stored item and upgrades it to ItemSchemaVersion in memory, marking it
+// so MigrateItems knows to rewrite it. Items from a newer wn are rejected rather than risk
+// dropping fields on the next write.
+func decodeItem(data []byte) (*Item, error) {
+	var item Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	if item.SchemaVersion > ItemSchemaVersion {
+		return nil, fmt.Errorf("item %s has schema version %d but this wn supports up to %d; upgrade wn", item.ID, item.SchemaVersion, ItemSchemaVersion)
+	}
+	if item.SchemaVersion < ItemSchemaVersion {
+		v := max(item.SchemaVersion, 1)
+		for ; v < ItemSchemaVersion; v++ {
+			itemMigrations[v-1](&item)
+		}
+		item.SchemaVersion = v
+		item.upgraded = true
+	}
+	return &item, nil
+}
+
+// stampItemSchema sets the schema version written with item.
+func stampItemSchema(item *Item) {
+	item.SchemaVersion = ItemSchemaVersion
+	item.upgraded = false
+}
+
+// MigrateItems rewrites every item stored with an older schema version (including items from
+// before versioning) at ItemSchemaVersion. Returns the number of items rewritten.
+func MigrateItems(store Store) (int, error) {
+	items, err := store.List()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	err = WithIndexBatch(store, func() error {
+		for _, it := range items {
+			if !it.upgraded {
+				continue
+			}
+			if err := store.UpdateItem(it.ID, func(it *Item) (*Item, error) { return it, nil }); err != nil {
+				return fmt.Errorf("migrate %s: %w", it.ID, err)
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
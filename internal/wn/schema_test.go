@@ -0,0 +1,58 @@
+package wn
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrateItems(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "cur001", Description: "current", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	// An item file written before schema versioning.
+	legacy := filepath.Join(dir, ".wn", "items", "old001.json")
+	if err := os.WriteFile(legacy, []byte(`{"id":"old001","description":"legacy","created":"2025-01-01T00:00:00Z","updated":"2025-01-01T00:00:00Z","done":false,"tags":null,"depends_on":null,"log":null}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	it, err := store.Get("old001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.SchemaVersion != ItemSchemaVersion {
+		t.Errorf("legacy item read with SchemaVersion %d, want %d", it.SchemaVersion, ItemSchemaVersion)
+	}
+
+	n, err := MigrateItems(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("MigrateItems = %d, want 1 (only the legacy item)", n)
+	}
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"schema_version": 1`) {
+		t.Errorf("migrated file has no schema_version:\n%s", data)
+	}
+	if n, err := MigrateItems(store); err != nil || n != 0 {
+		t.Errorf("second MigrateItems = %d, %v; want 0, nil", n, err)
+	}
+}
+
+func TestDecodeItem_newerVersion(t *testing.T) {
+	_, err := decodeItem([]byte(`{"id":"new001","schema_version":99}`))
+	if err == nil || !strings.Contains(err.Error(), "upgrade wn") {
+		t.Errorf("decodeItem(newer) error = %v, want upgrade hint", err)
+	}
+}
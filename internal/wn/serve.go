@@ -0,0 +1,147 @@
+package wn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultServeAddr is the listen address for wn serve: localhost only, since the API is
+// unauthenticated.
+const DefaultServeAddr = "127.0.0.1:8099"
+
+// serveFilterKeys are the query parameters of GET /items that map to filter terms (see ParseFilter).
+var serveFilterKeys = []string{"tag", "status", "has", "text"}
+
+// NewHTTPHandler returns the wn serve JSON API for the project at root:
+//
+//	GET  /items              list items, as wn_list (query: tag, status, has, text, overdue, where, limit, offset, cursor)
+//	GET  /items/{id}         full item, as wn_item
+//	POST /items/{id}/done    mark done, as wn_done (optional JSON body {"message": "..."}); returns the item
+//
+// POST requires Content-Type application/json and rejects a foreign Origin, so a browser page
+// can't complete items through a simple cross-site form post.
+//
+// Handlers call the MCP tool handlers so both interfaces share one implementation.
+func NewHTTPHandler(root string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items", func(w http.ResponseWriter, r *http.Request) {
+		in, err := listInFromQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		in.Root = root
+		res, _, err := handleWnList(r.Context(), nil, in)
+		writeToolResult(w, res, err, http.StatusBadRequest)
+	})
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		res, _, err := handleWnItem(r.Context(), nil, wnItemIn{ID: r.PathValue("id"), Root: root})
+		writeToolResult(w, res, err, http.StatusNotFound)
+	})
+	mux.HandleFunc("POST /items/{id}/done", func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) {
+			http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+			return
+		}
+		if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		var body struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		id := r.PathValue("id")
+		store, _, err := getStoreWithRoot(r.Context(), root)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := store.Get(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		// The item exists, so a tool error means it can't be completed as it stands.
+		res, _, err := handleWnDone(r.Context(), nil, wnDoneIn{ID: id, Message: body.Message, Root: root})
+		if err != nil || res.IsError {
+			writeToolResult(w, res, err, http.StatusConflict)
+			return
+		}
+		res, _, err = handleWnItem(r.Context(), nil, wnItemIn{ID: id, Root: root})
+		writeToolResult(w, res, err, http.StatusNotFound)
+	})
+	return mux
+}
+
+// sameOrigin reports whether r carries no Origin header (curl, scripts) or one naming this
+// server's own host, so a web page on another origin can't POST to the API from a browser.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// listInFromQuery builds wn_list input from GET /items query parameters. tag, status, has, and
+// text (each repeatable) and overdue become filter terms ANDed with where; invalid terms are
+// reported by wn_list.
+func listInFromQuery(r *http.Request) (wnListIn, error) {
+	q := r.URL.Query()
+	var terms []string
+	if w := strings.TrimSpace(q.Get("where")); w != "" {
+		terms = append(terms, w)
+	}
+	for _, key := range serveFilterKeys {
+		for _, v := range q[key] {
+			terms = append(terms, key+":"+v)
+		}
+	}
+	if q.Has("overdue") {
+		terms = append(terms, "overdue")
+	}
+	in := wnListIn{Where: strings.Join(terms, " "), Cursor: q.Get("cursor")}
+	for key, dst := range map[string]*int{"limit": &in.Limit, "offset": &in.Offset} {
+		if v := q.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return in, fmt.Errorf("%s must be a non-negative integer, got %q", key, v)
+			}
+			*dst = n
+		}
+	}
+	return in, nil
+}
+
+// writeToolResult writes an MCP tool result as an HTTP response: the result text as JSON on
+// success, the text with errStatus when the tool reported an error, and 500 for handler errors.
+func writeToolResult(w http.ResponseWriter, res *mcp.CallToolResult, err error, errStatus int) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var text string
+	for _, c := range res.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			text += tc.Text
+		}
+	}
+	if res.IsError {
+		http.Error(w, text, errStatus)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = io.WriteString(w, text+"\n")
+}
@@ -0,0 +1,132 @@
+package wn
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "aaa111", Description: "api task\nbody", Tags: []string{"api"}, Created: now, Updated: now},
+		{ID: "bbb222", Description: "ui task", Tags: []string{"ui"}, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(NewHTTPHandler(root))
+	defer srv.Close()
+
+	getList := func(query string) []listItemOut {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/items" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /items%s: status %d", query, resp.StatusCode)
+		}
+		var out []listItemOut
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return out
+	}
+	if got := getList(""); len(got) != 2 {
+		t.Errorf("GET /items = %v, want 2 items", got)
+	}
+	if got := getList("?tag=api"); len(got) != 1 || got[0].ID != "aaa111" {
+		t.Errorf("GET /items?tag=api = %v, want aaa111", got)
+	}
+	if got := getList("?where=text:ui"); len(got) != 1 || got[0].ID != "bbb222" {
+		t.Errorf("GET /items?where=text:ui = %v, want bbb222", got)
+	}
+	for _, query := range []string{"?status=bogus", "?limit=x"} {
+		resp, err := http.Get(srv.URL + "/items" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET /items%s: status %d, want 400", query, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/items/aaa111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item showOutput
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil || item.Description != "api task\nbody" {
+		t.Errorf("GET /items/aaa111 = %+v, %v", item, err)
+	}
+	resp.Body.Close()
+	resp, err = http.Get(srv.URL + "/items/zzz999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /items/zzz999: status %d, want 404", resp.StatusCode)
+	}
+
+	resp, err = http.Post(srv.URL+"/items/bbb222/done", "application/json", strings.NewReader(`{"message":"shipped"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item = showOutput{}
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil || !item.Done || item.DoneMessage != "shipped" {
+		t.Errorf("POST /items/bbb222/done = %+v, %v; want done with message", item, err)
+	}
+	resp.Body.Close()
+	if got := getList(""); len(got) != 1 || got[0].ID != "aaa111" {
+		t.Errorf("GET /items after done = %v, want aaa111 only", got)
+	}
+	resp, err = http.Post(srv.URL+"/items/zzz999/done", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST /items/zzz999/done: status %d, want 404", resp.StatusCode)
+	}
+
+	resp, err = http.Post(srv.URL+"/items/aaa111/done", "text/plain", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("POST done as text/plain: status %d, want 415", resp.StatusCode)
+	}
+	for origin, want := range map[string]int{"https://evil.example": http.StatusForbidden, srv.URL: http.StatusOK} {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/items/aaa111/done", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("POST done with Origin %s: status %d, want %d", origin, resp.StatusCode, want)
+		}
+	}
+}
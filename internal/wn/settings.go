@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // RunnerConfig defines an agent command profile (cmd template, optional prompt override, worktree behavior).
@@ -16,19 +17,33 @@ type RunnerConfig struct {
 
 // Settings is the user's wn configuration (e.g. ~/.config/wn/settings.json).
 type Settings struct {
-	Sort     string                  `json:"sort,omitempty"`     // e.g. "updated:desc,priority,tags"
-	Picker   string                  `json:"picker,omitempty"`   // interactive picker: "fzf", "numbered", or "" (auto-detect)
-	Runners  map[string]RunnerConfig `json:"runners,omitempty"`  // named agent profiles, e.g. "claude", "cursor"
-	Next     NextSettings            `json:"next,omitempty"`     // defaults for next-item selection
-	Worktree WorktreeSettings        `json:"worktree,omitempty"` // defaults for worktree setup
-	Agent    AgentSettings           `json:"agent,omitempty"`    // defaults for agent runs (wn do, wn launch)
-	Cleanup  CleanupSettings         `json:"cleanup,omitempty"`  // options for cleanup subcommands
-	Show     ShowSettings            `json:"show,omitempty"`     // defaults for wn show / bare wn
+	Sort         string                  `json:"sort,omitempty"`          // e.g. "updated:desc,priority,tags"
+	Picker       string                  `json:"picker,omitempty"`        // interactive picker: "fzf", "numbered", or "" (auto-detect)
+	Timezone     string                  `json:"timezone,omitempty"`      // zone for displayed timestamps: IANA name, "local", or "" (UTC)
+	Editor       string                  `json:"editor,omitempty"`        // editor command, e.g. "code --wait" (WN_EDITOR overrides; else EDITOR, else vi)
+	AddTemplate  string                  `json:"add_template,omitempty"`  // initial editor buffer for wn add without -m; {title} is replaced (see ExpandAddTemplate)
+	TagLowercase bool                    `json:"tag_lowercase,omitempty"` // lowercase tags when they are added
+	NoteHistory  bool                    `json:"note_history,omitempty"`  // keep prior note bodies on edit (see NoteHistoryMax)
+	DefaultClaim string                  `json:"default_claim,omitempty"` // claim duration when --for/for is omitted, e.g. "4h" (default 1h)
+	Backend      string                  `json:"backend,omitempty"`       // storage for wn init: "file" (default) or "sqlite"
+	WorkerID     string                  `json:"worker_id,omitempty"`     // InProgressBy for claims made without --by (default: hostname)
+	OrderMax     *int                    `json:"order_max,omitempty"`     // highest allowed item order (default MaxOrder)
+	OrderDefault *int                    `json:"order_default,omitempty"` // order for items without one (default DefaultOrder)
+	Runners      map[string]RunnerConfig `json:"runners,omitempty"`       // named agent profiles, e.g. "claude", "cursor"
+	Next         NextSettings            `json:"next,omitempty"`          // defaults for next-item selection
+	Worktree     WorktreeSettings        `json:"worktree,omitempty"`      // defaults for worktree setup
+	Agent        AgentSettings           `json:"agent,omitempty"`         // defaults for agent runs (wn do, wn launch)
+	Cleanup      CleanupSettings         `json:"cleanup,omitempty"`       // options for cleanup subcommands
+	Show         ShowSettings            `json:"show,omitempty"`          // defaults for wn show / bare wn
 }
 
 // NextSettings controls how the next work item is selected.
 type NextSettings struct {
-	Tag string `json:"tag,omitempty"` // only consider items that have this tag, e.g. "agent"
+	Tag         string   `json:"tag,omitempty"`          // only consider items that have this tag, e.g. "agent"
+	StrictOrder bool     `json:"strict_order,omitempty"` // sort ready items by Order then id, ignoring created (see TopoOrderStrict)
+	UsesSort    bool     `json:"uses_sort,omitempty"`    // break ties within a dependency round by the sort setting (see TopoOrderSorted)
+	AutoClaim   string   `json:"autoclaim,omitempty"`    // bare wn next also claims for this duration, e.g. "2h" (--claim overrides, --no-claim skips)
+	ExcludeTags []string `json:"exclude_tags,omitempty"` // skip items with any of these tags, e.g. ["manual-only"]
 }
 
 // WorktreeSettings controls worktree creation.
@@ -43,10 +58,14 @@ type WorktreeSettings struct {
 // AgentSettings controls agent execution (wn do, wn launch).
 // Durations are strings parseable by time.ParseDuration (e.g. "2h", "30m").
 type AgentSettings struct {
-	Default       string `json:"default,omitempty"`        // default runner name for wn do (sync)
-	DefaultLaunch string `json:"default_launch,omitempty"` // default runner name for wn launch (async)
-	Delay         string `json:"delay,omitempty"`          // delay between runs in loop mode, e.g. "5m"
-	Poll          string `json:"poll,omitempty"`           // poll interval when queue empty, e.g. "60s"
+	Default        string `json:"default,omitempty"`          // default runner name for wn do (sync)
+	DefaultLaunch  string `json:"default_launch,omitempty"`   // default runner name for wn launch (async)
+	Delay          string `json:"delay,omitempty"`            // delay between runs in loop mode, e.g. "5m"
+	Poll           string `json:"poll,omitempty"`             // poll interval when queue empty, e.g. "60s"
+	CommitTpl      string `json:"commit_tpl,omitempty"`       // commit message template for wn do, e.g. "feat: {{.FirstLine}} ({{.ItemID}})"
+	SetupCmd       string `json:"setup_cmd,omitempty"`        // command template run in the worktree before the agent, e.g. "npm ci"
+	HoldTag        string `json:"hold_tag,omitempty"`         // agents never claim items with this tag (default DefaultHoldTag)
+	PromptMaxChars int    `json:"prompt_max_chars,omitempty"` // warn when an expanded prompt is longer than this (0 = no limit)
 }
 
 // ShowSettings holds user-level defaults for the show command and bare 'wn [id]'.
@@ -92,6 +111,55 @@ func ResolveLaunchRunner(settings Settings, name string) (RunnerConfig, error) {
 	return r, nil
 }
 
+// DefaultClaimFor returns the claim duration to use when none is given: settings default_claim
+// when it parses as a positive duration, otherwise DefaultClaimDuration.
+func DefaultClaimFor(settings Settings) time.Duration {
+	if settings.DefaultClaim != "" {
+		if d, err := time.ParseDuration(settings.DefaultClaim); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultClaimDuration
+}
+
+// ClaimWorker returns the worker id to record on a claim: by when given, else settings worker_id,
+// else the machine hostname ("" if it cannot be determined).
+func ClaimWorker(settings Settings, by string) string {
+	if by != "" {
+		return by
+	}
+	if settings.WorkerID != "" {
+		return settings.WorkerID
+	}
+	host, _ := os.Hostname()
+	return host
+}
+
+// OrderRange returns the order scale configured by order_max and order_default, using MaxOrder
+// and DefaultOrder for unset fields. An invalid combination returns the built-in range with an
+// error describing the problem.
+func OrderRange(settings Settings) (maxOrder, defaultOrder int, err error) {
+	maxOrder, defaultOrder = MaxOrder, DefaultOrder
+	if settings.OrderMax != nil {
+		maxOrder = *settings.OrderMax
+	}
+	if settings.OrderDefault != nil {
+		defaultOrder = *settings.OrderDefault
+	}
+	if maxOrder < 0 || defaultOrder < 0 || defaultOrder > maxOrder {
+		return MaxOrder, DefaultOrder, fmt.Errorf("invalid order range: order_default %d must be between 0 and order_max %d", defaultOrder, maxOrder)
+	}
+	return maxOrder, defaultOrder, nil
+}
+
+// ApplyOrderRange sets the active order range (SetOrderRange) from settings (see OrderRange).
+// An invalid range keeps the built-in one and returns the error so the caller can warn.
+func ApplyOrderRange(settings Settings) error {
+	maxOrder, defaultOrder, err := OrderRange(settings)
+	_ = SetOrderRange(maxOrder, defaultOrder)
+	return err
+}
+
 // SettingsPath returns the path to the user's wn settings file.
 // If WN_CONFIG_DIR is set, it is used instead of the OS config dir (useful for tests).
 func SettingsPath() (string, error) {
@@ -111,6 +179,7 @@ func ProjectSettingsPath(root string) string {
 }
 
 // MergeSettings overlays project onto user. Non-empty project fields override user; empty project fields leave user values.
+// ReadSettingsInRoot additionally applies booleans the project file sets to false explicitly.
 // Runners are merged by key: project runners override same-named user runners; unique keys from each are kept.
 func MergeSettings(user, project Settings) Settings {
 	out := user
@@ -120,6 +189,36 @@ func MergeSettings(user, project Settings) Settings {
 	if project.Picker != "" {
 		out.Picker = project.Picker
 	}
+	if project.Editor != "" {
+		out.Editor = project.Editor
+	}
+	if project.AddTemplate != "" {
+		out.AddTemplate = project.AddTemplate
+	}
+	if project.Timezone != "" {
+		out.Timezone = project.Timezone
+	}
+	if project.TagLowercase {
+		out.TagLowercase = true
+	}
+	if project.NoteHistory {
+		out.NoteHistory = true
+	}
+	if project.DefaultClaim != "" {
+		out.DefaultClaim = project.DefaultClaim
+	}
+	if project.Backend != "" {
+		out.Backend = project.Backend
+	}
+	if project.WorkerID != "" {
+		out.WorkerID = project.WorkerID
+	}
+	if project.OrderMax != nil {
+		out.OrderMax = project.OrderMax
+	}
+	if project.OrderDefault != nil {
+		out.OrderDefault = project.OrderDefault
+	}
 	out.Runners = mergeRunners(user.Runners, project.Runners)
 	out.Next = mergeNext(user.Next, project.Next)
 	out.Worktree = mergeWorktree(user.Worktree, project.Worktree)
@@ -148,6 +247,18 @@ func mergeNext(user, project NextSettings) NextSettings {
 	if project.Tag != "" {
 		out.Tag = project.Tag
 	}
+	if project.StrictOrder {
+		out.StrictOrder = true
+	}
+	if project.UsesSort {
+		out.UsesSort = true
+	}
+	if project.AutoClaim != "" {
+		out.AutoClaim = project.AutoClaim
+	}
+	if len(project.ExcludeTags) > 0 {
+		out.ExcludeTags = project.ExcludeTags
+	}
 	return out
 }
 
@@ -182,6 +293,18 @@ func mergeAgent(user, project AgentSettings) AgentSettings {
 	if project.Poll != "" {
 		out.Poll = project.Poll
 	}
+	if project.CommitTpl != "" {
+		out.CommitTpl = project.CommitTpl
+	}
+	if project.SetupCmd != "" {
+		out.SetupCmd = project.SetupCmd
+	}
+	if project.HoldTag != "" {
+		out.HoldTag = project.HoldTag
+	}
+	if project.PromptMaxChars != 0 {
+		out.PromptMaxChars = project.PromptMaxChars
+	}
 	return out
 }
 
@@ -219,12 +342,45 @@ func ReadSettingsInRoot(root string) (Settings, error) {
 	if root == "" {
 		return user, nil
 	}
-	projectPath := ProjectSettingsPath(root)
-	project, err := readSettingsFromPath(projectPath)
+	data, err := os.ReadFile(ProjectSettingsPath(root))
 	if err != nil {
 		return user, nil
 	}
-	return MergeSettings(user, project), nil
+	var project Settings
+	var bools projectBools
+	if json.Unmarshal(data, &project) != nil || json.Unmarshal(data, &bools) != nil {
+		return user, nil
+	}
+	merged := MergeSettings(user, project)
+	bools.apply(&merged)
+	return merged, nil
+}
+
+// projectBools holds the boolean keys a project settings file sets explicitly. MergeSettings
+// cannot tell false from unset, so these let a project turn off a user-level true.
+type projectBools struct {
+	TagLowercase *bool `json:"tag_lowercase"`
+	NoteHistory  *bool `json:"note_history"`
+	Next         struct {
+		StrictOrder *bool `json:"strict_order"`
+		UsesSort    *bool `json:"uses_sort"`
+	} `json:"next"`
+}
+
+func (b projectBools) apply(s *Settings) {
+	for _, f := range []struct {
+		v   *bool
+		dst *bool
+	}{
+		{b.TagLowercase, &s.TagLowercase},
+		{b.NoteHistory, &s.NoteHistory},
+		{b.Next.StrictOrder, &s.Next.StrictOrder},
+		{b.Next.UsesSort, &s.Next.UsesSort},
+	} {
+		if f.v != nil {
+			*f.dst = *f.v
+		}
+	}
 }
 
 // readSettingsFromPath reads settings from a specific path (for tests).
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestReadSettings_missingFile(t *testing.T) {
@@ -165,6 +166,26 @@ func TestMergeSettings_picker(t *testing.T) {
 	}
 }
 
+func TestMergeSettings_addTemplate(t *testing.T) {
+	user := Settings{AddTemplate: "{title}\n\nuser"}
+	if merged := MergeSettings(user, Settings{AddTemplate: "{title}\n\nproject"}); merged.AddTemplate != "{title}\n\nproject" {
+		t.Errorf("AddTemplate = %q, want the project template", merged.AddTemplate)
+	}
+	if merged := MergeSettings(user, Settings{}); merged.AddTemplate != user.AddTemplate {
+		t.Errorf("AddTemplate = %q, want the user template preserved", merged.AddTemplate)
+	}
+}
+
+func TestMergeSettings_promptMaxChars(t *testing.T) {
+	user := Settings{Agent: AgentSettings{PromptMaxChars: 4000}}
+	if merged := MergeSettings(user, Settings{Agent: AgentSettings{PromptMaxChars: 2000}}); merged.Agent.PromptMaxChars != 2000 {
+		t.Errorf("PromptMaxChars = %d, want the project value 2000", merged.Agent.PromptMaxChars)
+	}
+	if merged := MergeSettings(user, Settings{}); merged.Agent.PromptMaxChars != 4000 {
+		t.Errorf("PromptMaxChars = %d, want the user value 4000", merged.Agent.PromptMaxChars)
+	}
+}
+
 func TestMergeSettings_showDefaultFields(t *testing.T) {
 	user := Settings{Show: ShowSettings{DefaultFields: "title,body"}}
 	project := Settings{Show: ShowSettings{DefaultFields: "title,body,deps"}}
@@ -226,6 +247,47 @@ func TestReadSettingsInRoot_withProjectFile_mergesProjectOverUser(t *testing.T)
 	}
 }
 
+func TestReadSettingsInRoot_projectWinsPerKey(t *testing.T) {
+	userDir := t.TempDir()
+	t.Setenv("WN_CONFIG_DIR", userDir)
+	if err := os.WriteFile(filepath.Join(userDir, "settings.json"), []byte(`{
+  "tag_lowercase": true,
+  "note_history": true,
+  "runners": {"claude": {"cmd": "claude"}},
+  "agent": {"default": "claude"},
+  "worktree": {"branch_prefix": "me/", "claim": "1h"},
+  "next": {"strict_order": true, "tag": "agent"}
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".wn"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ProjectSettingsPath(root), []byte(`{
+  "tag_lowercase": false,
+  "runners": {"cursor": {"cmd": "cursor agent"}},
+  "agent": {"default": "cursor"},
+  "worktree": {"branch_prefix": "proj/"},
+  "next": {"strict_order": false}
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadSettingsInRoot(root)
+	if err != nil {
+		t.Fatalf("ReadSettingsInRoot: %v", err)
+	}
+	if s.TagLowercase || s.Next.StrictOrder {
+		t.Errorf("explicit project false should win: tag_lowercase=%v next.strict_order=%v", s.TagLowercase, s.Next.StrictOrder)
+	}
+	if !s.NoteHistory || s.Next.Tag != "agent" || s.Worktree.Claim != "1h" {
+		t.Errorf("keys unset in project should keep user values: %+v", s)
+	}
+	if s.Agent.Default != "cursor" || s.Worktree.BranchPrefix != "proj/" || len(s.Runners) != 2 {
+		t.Errorf("project values should override user: agent.default=%q branch_prefix=%q runners=%v", s.Agent.Default, s.Worktree.BranchPrefix, s.Runners)
+	}
+}
+
 func TestProjectSettingsPath(t *testing.T) {
 	got := ProjectSettingsPath("/foo/bar")
 	want := filepath.Join("/foo/bar", ".wn", "settings.json")
@@ -394,3 +456,90 @@ func TestMergeSettings_agentDefaultMerged(t *testing.T) {
 		t.Errorf("Agent.Delay = %q, want 5m (from user)", merged.Agent.Delay)
 	}
 }
+
+func TestMergeSettings_agentCommitTpl(t *testing.T) {
+	user := Settings{Agent: AgentSettings{CommitTpl: "wn {{.ItemID}}"}}
+	merged := MergeSettings(user, Settings{})
+	if merged.Agent.CommitTpl != "wn {{.ItemID}}" {
+		t.Errorf("Agent.CommitTpl = %q, want user value preserved", merged.Agent.CommitTpl)
+	}
+	merged = MergeSettings(user, Settings{Agent: AgentSettings{CommitTpl: "feat: {{.FirstLine}}"}})
+	if merged.Agent.CommitTpl != "feat: {{.FirstLine}}" {
+		t.Errorf("Agent.CommitTpl = %q, want project override", merged.Agent.CommitTpl)
+	}
+}
+
+func TestMergeSettings_nextStrictOrder(t *testing.T) {
+	merged := MergeSettings(Settings{}, Settings{Next: NextSettings{StrictOrder: true}})
+	if !merged.Next.StrictOrder {
+		t.Error("Next.StrictOrder = false, want project true")
+	}
+	merged = MergeSettings(Settings{Next: NextSettings{StrictOrder: true, Tag: "agent"}}, Settings{})
+	if !merged.Next.StrictOrder || merged.Next.Tag != "agent" {
+		t.Errorf("Next = %+v, want user values preserved", merged.Next)
+	}
+}
+
+func TestMergeSettings_tagLowercase(t *testing.T) {
+	if !MergeSettings(Settings{}, Settings{TagLowercase: true}).TagLowercase {
+		t.Error("TagLowercase = false, want project true")
+	}
+	if !MergeSettings(Settings{TagLowercase: true}, Settings{}).TagLowercase {
+		t.Error("TagLowercase = false, want user true preserved")
+	}
+}
+
+func TestDefaultClaimFor(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    time.Duration
+	}{
+		{"", DefaultClaimDuration},
+		{"4h", 4 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"bogus", DefaultClaimDuration},
+		{"-1h", DefaultClaimDuration},
+	}
+	for _, tt := range tests {
+		if got := DefaultClaimFor(Settings{DefaultClaim: tt.setting}); got != tt.want {
+			t.Errorf("DefaultClaimFor(%q) = %v, want %v", tt.setting, got, tt.want)
+		}
+	}
+	if got := MergeSettings(Settings{DefaultClaim: "2h"}, Settings{DefaultClaim: "4h"}).DefaultClaim; got != "4h" {
+		t.Errorf("merged DefaultClaim = %q, want project 4h", got)
+	}
+}
+
+func TestApplyOrderRange(t *testing.T) {
+	defer func() { _ = SetOrderRange(MaxOrder, DefaultOrder) }()
+	nine, five, twenty := 9, 5, 20
+	merged := MergeSettings(Settings{OrderMax: &nine}, Settings{OrderDefault: &five})
+	if err := ApplyOrderRange(merged); err != nil {
+		t.Fatalf("ApplyOrderRange: %v", err)
+	}
+	if OrderMax() != 9 || OrderDefault() != 5 {
+		t.Errorf("order range = %d/%d, want 9/5", OrderMax(), OrderDefault())
+	}
+	if err := ApplyOrderRange(Settings{OrderMax: &nine, OrderDefault: &twenty}); err == nil {
+		t.Error("ApplyOrderRange with order_default > order_max should fail")
+	}
+	if OrderMax() != MaxOrder || OrderDefault() != DefaultOrder {
+		t.Errorf("invalid range should fall back to %d/%d, got %d/%d", MaxOrder, DefaultOrder, OrderMax(), OrderDefault())
+	}
+	if err := ApplyOrderRange(Settings{}); err != nil || OrderMax() != MaxOrder || OrderDefault() != DefaultOrder {
+		t.Errorf("empty settings should restore %d/%d, got %d/%d (%v)", MaxOrder, DefaultOrder, OrderMax(), OrderDefault(), err)
+	}
+}
+
+func TestClaimWorker(t *testing.T) {
+	if got := ClaimWorker(Settings{WorkerID: "box-7"}, "agent-2"); got != "agent-2" {
+		t.Errorf("ClaimWorker with explicit by = %q, want agent-2", got)
+	}
+	if got := ClaimWorker(Settings{WorkerID: "box-7"}, ""); got != "box-7" {
+		t.Errorf("ClaimWorker with worker_id = %q, want box-7", got)
+	}
+	host, _ := os.Hostname()
+	if got := ClaimWorker(Settings{}, ""); got != host {
+		t.Errorf("ClaimWorker fallback = %q, want hostname %q", got, host)
+	}
+}
@@ -0,0 +1,195 @@
+package wn
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SettingsProblem is one issue found by CheckSettingsFile. Path is the JSON path of the
+// offending key (e.g. "agent.delay", "runners.claude.cmd"); empty for the file as a whole.
+type SettingsProblem struct {
+	Path    string
+	Msg     string
+	Warning bool // true for issues wn tolerates at runtime (unknown keys)
+}
+
+func (p SettingsProblem) String() string {
+	level := "error"
+	if p.Warning {
+		level = "warning"
+	}
+	if p.Path == "" {
+		return level + ": " + p.Msg
+	}
+	return level + ": " + p.Path + ": " + p.Msg
+}
+
+// CheckSettingsFile validates the settings file at path: JSON syntax, durations, templates,
+// enumerated values, and keys wn does not know (which ReadSettings silently ignores). A
+// missing file has no problems. Problems are sorted by path.
+func CheckSettingsFile(path string) ([]SettingsProblem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []SettingsProblem{{Msg: "invalid JSON: " + err.Error()}}, nil
+	}
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return []SettingsProblem{{Msg: err.Error()}}, nil
+	}
+	problems := unknownSettingsKeys(raw, reflect.TypeOf(s), "")
+	problems = append(problems, CheckSettings(s)...)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return problems, nil
+}
+
+// CheckSettings validates the values of s (see CheckSettingsFile).
+func CheckSettings(s Settings) []SettingsProblem {
+	var problems []SettingsProblem
+	add := func(path, format string, args ...any) {
+		problems = append(problems, SettingsProblem{Path: path, Msg: fmt.Sprintf(format, args...)})
+	}
+	for path, v := range map[string]string{
+		"default_claim":  s.DefaultClaim,
+		"worktree.claim": s.Worktree.Claim,
+		"agent.delay":    s.Agent.Delay,
+		"agent.poll":     s.Agent.Poll,
+		"next.autoclaim": s.Next.AutoClaim,
+	} {
+		if v == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(v); err != nil {
+			add(path, "invalid duration %q (use e.g. 30m, 2h)", v)
+		} else if d <= 0 {
+			add(path, "duration %q must be positive", v)
+		}
+	}
+	if v := s.Cleanup.CloseDoneItemsAge; v != "" {
+		if _, err := ParseDurationWithDays(v); err != nil {
+			add("cleanup.close_done_items_age", "invalid duration %q (use e.g. 30d, 48h)", v)
+		}
+	}
+	if s.Sort != "" {
+		if _, err := ParseSortSpec(s.Sort); err != nil {
+			add("sort", "%v", err)
+		}
+	}
+	switch s.Picker {
+	case "", "fzf", "numbered":
+	default:
+		add("picker", "unknown picker %q (use fzf or numbered)", s.Picker)
+	}
+	if _, err := ParseTimezone(s.Timezone); err != nil {
+		add("timezone", "%v", err)
+	}
+	if s.Backend != "" {
+		if err := ValidateBackend(s.Backend); err != nil {
+			add("backend", "%v", err)
+		}
+	}
+	maxOrder, defaultOrder := MaxOrder, DefaultOrder
+	if s.OrderMax != nil {
+		maxOrder = *s.OrderMax
+	}
+	if s.OrderDefault != nil {
+		defaultOrder = *s.OrderDefault
+	}
+	if maxOrder < 0 || defaultOrder < 0 || defaultOrder > maxOrder {
+		add("order_default", "must be between 0 and order_max (%d), got %d", maxOrder, defaultOrder)
+	}
+	for name, r := range s.Runners {
+		if r.Cmd == "" {
+			add("runners."+name+".cmd", "runner has no cmd")
+		} else if _, err := ExpandCommandTemplate(r.Cmd, "", "", "", "", ""); err != nil {
+			add("runners."+name+".cmd", "invalid template: %v", err)
+		}
+		if r.Prompt != "" {
+			if _, err := ExpandPromptTemplate(r.Prompt, &Item{ID: "abc123", Description: "sample"}, "", ""); err != nil {
+				add("runners."+name+".prompt", "invalid template: %v", err)
+			}
+		}
+	}
+	for path, name := range map[string]string{"agent.default": s.Agent.Default, "agent.default_launch": s.Agent.DefaultLaunch} {
+		if _, ok := s.Runners[name]; name != "" && !ok {
+			add(path, "runner %q not found in runners", name)
+		}
+	}
+	if s.Agent.CommitTpl != "" {
+		if err := ValidateCommitTemplate(s.Agent.CommitTpl); err != nil {
+			add("agent.commit_tpl", "invalid template: %v", err)
+		}
+	}
+	if s.Agent.SetupCmd != "" {
+		if _, err := ExpandCommandTemplate(s.Agent.SetupCmd, "", "", "", "", ""); err != nil {
+			add("agent.setup_cmd", "invalid template: %v", err)
+		}
+	}
+	if s.Agent.HoldTag != "" {
+		if err := ValidateTag(s.Agent.HoldTag); err != nil {
+			add("agent.hold_tag", "%v", err)
+		}
+	}
+	if s.Agent.PromptMaxChars < 0 {
+		add("agent.prompt_max_chars", "must not be negative, got %d", s.Agent.PromptMaxChars)
+	}
+	if s.Show.DefaultFields != "" {
+		for _, f := range strings.Split(s.Show.DefaultFields, ",") {
+			switch strings.TrimSpace(f) {
+			case "title", "body", "status", "deps", "notes", "log":
+			default:
+				add("show.default_fields", "unknown field %q (use title, body, status, deps, notes, log)", strings.TrimSpace(f))
+			}
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return problems
+}
+
+// unknownSettingsKeys returns a warning for each key in raw that has no matching json field in
+// t (a struct type), recursing into nested objects and maps of structs (runners).
+func unknownSettingsKeys(raw map[string]any, t reflect.Type, prefix string) []SettingsProblem {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+	var problems []SettingsProblem
+	for key, v := range raw {
+		path := prefix + key
+		ft, ok := fields[key]
+		if !ok {
+			problems = append(problems, SettingsProblem{Path: path, Msg: "unknown key (ignored)", Warning: true})
+			continue
+		}
+		obj, isObj := v.(map[string]any)
+		if !isObj {
+			continue
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			problems = append(problems, unknownSettingsKeys(obj, ft, path+".")...)
+		case ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.Struct:
+			for name, ev := range obj {
+				if eobj, ok := ev.(map[string]any); ok {
+					problems = append(problems, unknownSettingsKeys(eobj, ft.Elem(), path+"."+name+".")...)
+				}
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,70 @@
+package wn
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSettingsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	data := `{
+  "default_claim": "2hours",
+  "picker": "skim",
+  "timezone": "Mars/Olympus",
+  "sort": "updated:desc",
+  "colour": "blue",
+  "runners": {
+    "claude": {"cmd": "claude -p \"{{.Prompt}\"", "timeout": "1h"},
+    "ok": {"cmd": "agent {{.ItemID}}", "prompt": "{{.Description}}"}
+  },
+  "agent": {"default": "missing", "poll": "60s", "delay": "-5m", "commit_tpl": "{{.Nope}}"},
+  "show": {"default_fields": "title,bogus"}
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := CheckSettingsFile(path)
+	if err != nil {
+		t.Fatalf("CheckSettingsFile: %v", err)
+	}
+	got := make(map[string]SettingsProblem)
+	for _, p := range problems {
+		got[p.Path] = p
+	}
+	for _, path := range []string{"default_claim", "picker", "timezone", "runners.claude.cmd", "agent.default", "agent.delay", "agent.commit_tpl", "show.default_fields"} {
+		if p, ok := got[path]; !ok || p.Warning {
+			t.Errorf("want error at %s, got %+v", path, p)
+		}
+	}
+	for _, path := range []string{"colour", "runners.claude.timeout"} {
+		if p, ok := got[path]; !ok || !p.Warning {
+			t.Errorf("want unknown-key warning at %s, got %+v", path, p)
+		}
+	}
+	for _, path := range []string{"sort", "agent.poll", "runners.ok.cmd", "runners.ok.prompt"} {
+		if p, ok := got[path]; ok {
+			t.Errorf("unexpected problem at %s: %s", path, p)
+		}
+	}
+	if len(problems) != 10 {
+		t.Errorf("got %d problems, want 10: %v", len(problems), problems)
+	}
+}
+
+func TestCheckSettingsFile_missingAndInvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	problems, err := CheckSettingsFile(filepath.Join(dir, "none.json"))
+	if err != nil || len(problems) != 0 {
+		t.Errorf("missing file = %v, %v; want no problems", problems, err)
+	}
+	path := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(path, []byte(`{"sort": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	problems, err = CheckSettingsFile(path)
+	if err != nil || len(problems) != 1 || !strings.Contains(problems[0].Msg, "invalid JSON") {
+		t.Errorf("invalid JSON = %v, %v; want one invalid JSON problem", problems, err)
+	}
+}
@@ -1,37 +1,107 @@
 package wn
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+)
 
-// DefaultOrder is the effective priority when Item.Order is nil (lower = higher priority).
+// DefaultOrder is the built-in effective priority when Item.Order is nil (lower = higher priority).
 // Use order values > DefaultOrder (e.g. 100+) to place items below default-priority items.
+// Settings order_default overrides it (see SetOrderRange).
 const DefaultOrder = 99
 
-// MaxOrder is the maximum allowed order value (0..MaxOrder). Values above this are rejected when setting order.
+// MaxOrder is the built-in maximum order value (0..MaxOrder). Settings order_max overrides it.
 const MaxOrder = 255
 
-// ValidOrder reports whether n is a valid order value (0 <= n <= MaxOrder).
+// orderMax and orderDefault are the active order range, set by SetOrderRange.
+var (
+	orderMax     = MaxOrder
+	orderDefault = DefaultOrder
+)
+
+// SetOrderRange sets the order scale for all subsequent order validation and sorting:
+// valid orders are 0..maxOrder, and items without an Order sort as defaultOrder.
+func SetOrderRange(maxOrder, defaultOrder int) error {
+	if maxOrder < 0 || defaultOrder < 0 || defaultOrder > maxOrder {
+		return fmt.Errorf("invalid order range: order_default %d must be between 0 and order_max %d", defaultOrder, maxOrder)
+	}
+	orderMax, orderDefault = maxOrder, defaultOrder
+	return nil
+}
+
+// OrderMax returns the active maximum order value (MaxOrder unless set by SetOrderRange).
+func OrderMax() int { return orderMax }
+
+// OrderDefault returns the active order for items without one (DefaultOrder unless set by SetOrderRange).
+func OrderDefault() int { return orderDefault }
+
+// ValidOrder reports whether n is a valid order value (0 <= n <= OrderMax()).
 func ValidOrder(n int) bool {
-	return n >= 0 && n <= MaxOrder
+	return n >= 0 && n <= orderMax
+}
+
+// ShiftOrder returns order moved by delta (negative = higher priority), treating nil as
+// OrderDefault() and clamping the result to 0..OrderMax().
+func ShiftOrder(order *int, delta int) int {
+	n := orderDefault
+	if order != nil {
+		n = *order
+	}
+	return min(max(n+delta, 0), orderMax)
 }
 
 // orderKey returns the effective sort key for an item: lower = earlier in backlog.
-// Nil Order uses DefaultOrder so items can use order > DefaultOrder to sort lower than default.
+// Nil Order uses OrderDefault() so items can use a higher order to sort lower than default.
 func orderKey(it *Item) int {
 	if it.Order == nil {
-		return DefaultOrder
+		return orderDefault
 	}
 	return *it.Order
 }
 
 // TopoOrder returns items in dependency order: prerequisites first.
-// Among items that are ready in the same round, Order is used as tiebreaker
-// (lower Order = earlier). Items with no Order use DefaultOrder (99); use order > DefaultOrder to place items lower.
+// Items are placed in rounds: each round holds every item whose dependencies were all placed
+// in earlier rounds. Within a round, ties break by Order (lower = earlier; no Order uses
+// OrderDefault(), 99 unless configured, so use a higher order to place items lower), then Created (older
+// first), then ID. The result is therefore the same on every run for the same items,
+// regardless of input order.
 // If there is a cycle, the second return value is false and order is undefined.
 func TopoOrder(items []*Item) ([]*Item, bool) {
-	byID := make(map[string]*Item)
-	for _, it := range items {
-		byID[it.ID] = it
+	return topoOrderTiers(items, func(ready []*Item) []*Item {
+		sort.Slice(ready, func(i, j int) bool {
+			return topoTierLess(ready[i], ready[j])
+		})
+		return ready
+	})
+}
+
+// topoTierLess orders items within a TopoOrder round by (Order, Created, ID).
+func topoTierLess(a, b *Item) bool {
+	if ka, kb := orderKey(a), orderKey(b); ka != kb {
+		return ka < kb
+	}
+	if !a.Created.Equal(b.Created) {
+		return a.Created.Before(b.Created)
+	}
+	return a.ID < b.ID
+}
+
+// TopoOrderSorted returns items in dependency order like TopoOrder, but orders each ready
+// round by spec (see ApplySort) instead of by Order alone. An empty spec is TopoOrder.
+// For example, with spec "created:desc" and two independent items, the newer one comes first
+// even if the older one has a lower Order.
+func TopoOrderSorted(items []*Item, spec []SortOption) ([]*Item, bool) {
+	if len(spec) == 0 {
+		return TopoOrder(items)
 	}
+	return topoOrderTiers(items, func(ready []*Item) []*Item {
+		return ApplySort(ready, spec)
+	})
+}
+
+// topoOrderTiers repeatedly takes every item whose dependencies are all placed, orders that
+// round with sortTier, and appends it. Returns false if a cycle (or missing dependency) stalls it.
+func topoOrderTiers(items []*Item, sortTier func(ready []*Item) []*Item) ([]*Item, bool) {
 	var result []*Item
 	added := make(map[string]bool)
 	for len(result) < len(items) {
@@ -54,13 +124,52 @@ func TopoOrder(items []*Item) ([]*Item, bool) {
 		if len(ready) == 0 {
 			return result, false
 		}
-		sort.Slice(ready, func(i, j int) bool {
-			return orderKey(ready[i]) < orderKey(ready[j])
-		})
-		for _, it := range ready {
+		for _, it := range sortTier(ready) {
 			result = append(result, it)
 			added[it.ID] = true
 		}
 	}
 	return result, true
 }
+
+// TopoOrderStrict returns items in dependency order like TopoOrder, but treats Order as a
+// strict priority: at each step the ready item (all dependencies placed) with the lowest
+// (Order, ID) is taken, ignoring created time. An item that becomes ready therefore goes
+// ahead of any remaining ready item with a higher Order, even one from an earlier round.
+// For example, with B (order 10, created first), A (order 10), and C (order 0) depending on A,
+// TopoOrder yields B, A, C while TopoOrderStrict yields A, C, B.
+// If there is a cycle, the second return value is false and order is undefined.
+func TopoOrderStrict(items []*Item) ([]*Item, bool) {
+	less := func(a, b *Item) bool {
+		if ka, kb := orderKey(a), orderKey(b); ka != kb {
+			return ka < kb
+		}
+		return a.ID < b.ID
+	}
+	var result []*Item
+	added := make(map[string]bool)
+	for len(result) < len(items) {
+		var best *Item
+		for _, it := range items {
+			if added[it.ID] {
+				continue
+			}
+			ok := true
+			for _, dep := range it.DependsOn {
+				if !added[dep] {
+					ok = false
+					break
+				}
+			}
+			if ok && (best == nil || less(it, best)) {
+				best = it
+			}
+		}
+		if best == nil {
+			return result, false
+		}
+		result = append(result, best)
+		added[best.ID] = true
+	}
+	return result, true
+}
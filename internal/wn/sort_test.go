@@ -1,6 +1,7 @@
 package wn
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -113,6 +114,56 @@ func TestTopoOrder_OrderAboveDefault(t *testing.T) {
 	}
 }
 
+func TestTopoOrderStrict_OrderThenID(t *testing.T) {
+	// b and a share order 10 (b is older); c (order 0) depends on a.
+	// TopoOrder: b, a (created breaks the tie), then c. Strict: a (id breaks the tie), then c,
+	// which is ready and outranks b.
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "b", Order: orderVal(10), Created: now.Add(-time.Hour), Updated: now},
+		{ID: "a", Order: orderVal(10), Created: now, Updated: now},
+		{ID: "c", DependsOn: []string{"a"}, Order: orderVal(0), Created: now, Updated: now},
+	}
+	ordered, acyclic := TopoOrder(items)
+	if !acyclic || ordered[0].ID != "b" || ordered[1].ID != "a" || ordered[2].ID != "c" {
+		t.Errorf("TopoOrder = %v (expected b, a, c)", ordered)
+	}
+	ordered, acyclic = TopoOrderStrict(items)
+	if !acyclic {
+		t.Fatal("expected acyclic")
+	}
+	if ordered[0].ID != "a" || ordered[1].ID != "c" || ordered[2].ID != "b" {
+		t.Errorf("TopoOrderStrict = %v (expected a, c, b)", ordered)
+	}
+}
+
+func TestTopoOrderStrict_TiesByID(t *testing.T) {
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "c", Created: now.Add(-time.Hour), Updated: now},
+		{ID: "a", Created: now, Updated: now},
+		{ID: "b", Created: now.Add(-2 * time.Hour), Updated: now},
+	}
+	ordered, acyclic := TopoOrderStrict(items)
+	if !acyclic {
+		t.Fatal("expected acyclic")
+	}
+	if ordered[0].ID != "a" || ordered[1].ID != "b" || ordered[2].ID != "c" {
+		t.Errorf("TopoOrderStrict = %v (expected a, b, c)", ordered)
+	}
+}
+
+func TestTopoOrderStrict_Cycle(t *testing.T) {
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "a", DependsOn: []string{"b"}, Created: now, Updated: now},
+		{ID: "b", DependsOn: []string{"a"}, Created: now, Updated: now},
+	}
+	if _, acyclic := TopoOrderStrict(items); acyclic {
+		t.Error("expected cycle to be reported")
+	}
+}
+
 func TestValidOrder(t *testing.T) {
 	for _, n := range []int{0, 1, 99, 255} {
 		if !ValidOrder(n) {
@@ -125,3 +176,111 @@ func TestValidOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestTopoOrderSorted(t *testing.T) {
+	// Same items, different within-tier tiebreaker: TopoOrder uses Order, the sort spec uses created desc.
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "old", Order: orderVal(1), Created: now.Add(-time.Hour), Updated: now},
+		{ID: "new", Order: orderVal(2), Created: now, Updated: now},
+		{ID: "dep", DependsOn: []string{"new"}, Order: orderVal(0), Created: now.Add(time.Hour), Updated: now},
+	}
+	byOrder, _ := TopoOrder(items)
+	if byOrder[0].ID != "old" {
+		t.Errorf("TopoOrder first = %s, want old", byOrder[0].ID)
+	}
+	spec, err := ParseSortSpec("created:desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, acyclic := TopoOrderSorted(items, spec)
+	if !acyclic {
+		t.Fatal("expected acyclic")
+	}
+	// dep is newest but still waits for its dependency.
+	if got := sorted[0].ID + "," + sorted[1].ID + "," + sorted[2].ID; got != "new,old,dep" {
+		t.Errorf("TopoOrderSorted = %s, want new,old,dep", got)
+	}
+	if empty, _ := TopoOrderSorted(items, nil); empty[0].ID != "old" {
+		t.Errorf("TopoOrderSorted with empty spec first = %s, want old (TopoOrder)", empty[0].ID)
+	}
+}
+
+func TestTopoOrder_DeterministicTies(t *testing.T) {
+	// Independent items: ties break by Order, then Created (older first), then ID,
+	// and the result does not depend on input order.
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "e", Created: now},
+		{ID: "d", Created: now},
+		{ID: "c", Created: now.Add(-time.Hour)},
+		{ID: "b", Order: orderVal(5), Created: now.Add(time.Hour)},
+		{ID: "a", Created: now.Add(time.Minute)},
+	}
+	want := "b,c,d,e,a"
+	for i := 0; i < len(items); i++ {
+		rotated := append(append([]*Item(nil), items[i:]...), items[:i]...)
+		ordered, acyclic := TopoOrder(rotated)
+		if !acyclic {
+			t.Fatal("expected acyclic")
+		}
+		ids := make([]string, len(ordered))
+		for j, it := range ordered {
+			ids[j] = it.ID
+		}
+		if got := strings.Join(ids, ","); got != want {
+			t.Errorf("rotation %d: TopoOrder = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestShiftOrder(t *testing.T) {
+	five, top := 5, MaxOrder
+	tests := []struct {
+		order *int
+		delta int
+		want  int
+	}{
+		{nil, -1, DefaultOrder - 1},
+		{nil, 3, DefaultOrder + 3},
+		{&five, -10, 0},
+		{&top, 1, MaxOrder},
+		{&five, 2, 7},
+	}
+	for _, tt := range tests {
+		if got := ShiftOrder(tt.order, tt.delta); got != tt.want {
+			t.Errorf("ShiftOrder(%v, %d) = %d, want %d", tt.order, tt.delta, got, tt.want)
+		}
+	}
+}
+
+func TestSetOrderRange(t *testing.T) {
+	defer func() { _ = SetOrderRange(MaxOrder, DefaultOrder) }()
+	if err := SetOrderRange(9, 10); err == nil {
+		t.Error("SetOrderRange(9, 10) should fail: default above max")
+	}
+	if err := SetOrderRange(9, 5); err != nil {
+		t.Fatalf("SetOrderRange(9, 5): %v", err)
+	}
+	if ValidOrder(10) || !ValidOrder(9) {
+		t.Error("ValidOrder should accept 0..9 only")
+	}
+	if got := ShiftOrder(nil, 1); got != 6 {
+		t.Errorf("ShiftOrder(nil, 1) = %d, want 6", got)
+	}
+	if got := ShiftOrder(nil, 10); got != 9 {
+		t.Errorf("ShiftOrder(nil, 10) = %d, want clamp to 9", got)
+	}
+	// Items without an order sort as 5: after order 4, before order 6.
+	four, six := 4, 6
+	now := time.Now().UTC()
+	items := []*Item{
+		{ID: "c", Order: &six, Created: now},
+		{ID: "b", Created: now},
+		{ID: "a", Order: &four, Created: now},
+	}
+	got, _ := TopoOrder(items)
+	if ids := got[0].ID + got[1].ID + got[2].ID; ids != "abc" {
+		t.Errorf("TopoOrder = %s, want abc", ids)
+	}
+}
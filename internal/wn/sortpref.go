@@ -1,7 +1,9 @@
 package wn
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -13,8 +15,8 @@ type SortOption struct {
 }
 
 // ParseSortSpec parses a comma-separated sort spec like "updated:desc,priority,tags".
-// Each term may be "key" (asc) or "key:asc" or "key:desc". Valid keys: created, updated, priority, alpha, tags.
-// Returns nil, nil for empty string.
+// Each term may be "key" (asc) or "key:asc" or "key:desc". Valid keys: created, updated, priority, alpha, tags;
+// "order" is accepted as an alias for priority. Returns nil, nil for empty string.
 func ParseSortSpec(s string) ([]SortOption, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -37,19 +39,23 @@ func ParseSortSpec(s string) ([]SortOption, error) {
 		default:
 			return nil, fmt.Errorf("invalid sort direction %q", dir)
 		}
+		if key == "order" {
+			key = "priority"
+		}
 		switch key {
 		case "created", "updated", "priority", "alpha", "tags":
 			out = append(out, SortOption{Key: key, Desc: desc})
 		default:
-			return nil, fmt.Errorf("invalid sort key %q (use created, updated, priority, alpha, tags)", key)
+			return nil, fmt.Errorf("invalid sort key %q (use created, updated, priority (or order), alpha, tags)", key)
 		}
 	}
 	return out, nil
 }
 
-// ApplySort sorts items in place by the given spec (primary key, then tiebreakers).
+// ApplySort sorts items by the given spec (primary key, then tiebreakers), returning a new slice.
 // Nil or empty spec returns items unchanged. "priority" uses Item.Order (lower = earlier when asc).
 // "tags" sorts by a canonical tag string so items with same tags are adjacent (group by tags).
+// Items equal on every key are ordered by id, so the result does not depend on the input order.
 func ApplySort(items []*Item, spec []SortOption) []*Item {
 	if len(spec) == 0 || len(items) == 0 {
 		return items
@@ -57,52 +63,42 @@ func ApplySort(items []*Item, spec []SortOption) []*Item {
 	// Copy so we don't mutate caller's slice order in-place
 	result := make([]*Item, len(items))
 	copy(result, items)
-	sort.Slice(result, func(i, j int) bool {
+	slices.SortStableFunc(result, func(a, b *Item) int {
 		for _, opt := range spec {
-			less := compareByKey(result[i], result[j], opt.Key, opt.Desc)
-			if less {
-				return true
+			c := compareByKey(a, b, opt.Key)
+			if opt.Desc {
+				c = -c
 			}
-			if compareByKey(result[j], result[i], opt.Key, opt.Desc) {
-				return false
+			if c != 0 {
+				return c
 			}
 		}
-		return result[i].ID < result[j].ID
+		return cmp.Compare(a.ID, b.ID)
 	})
 	return result
 }
 
-func compareByKey(a, b *Item, key string, desc bool) bool {
-	var less bool
+// compareByKey compares a and b by one sort key in ascending order (-1, 0, or +1). Equal keys
+// compare 0 in both directions so later keys in the spec decide.
+func compareByKey(a, b *Item, key string) int {
 	switch key {
 	case "created":
-		less = a.Created.Before(b.Created)
+		return a.Created.Compare(b.Created)
 	case "updated":
-		less = a.Updated.Before(b.Updated)
+		return a.Updated.Compare(b.Updated)
 	case "priority":
-		less = orderLess(a.Order, b.Order)
+		return cmp.Compare(orderKeyFromPtr(a.Order), orderKeyFromPtr(b.Order))
 	case "alpha":
-		less = FirstLine(a.Description) < FirstLine(b.Description)
+		return cmp.Compare(FirstLine(a.Description), FirstLine(b.Description))
 	case "tags":
-		less = tagsKey(a.Tags) < tagsKey(b.Tags)
-	default:
-		less = a.ID < b.ID
-	}
-	if desc {
-		return !less
+		return cmp.Compare(tagsKey(a.Tags), tagsKey(b.Tags))
 	}
-	return less
-}
-
-func orderLess(a, b *int) bool {
-	va := orderKeyFromPtr(a)
-	vb := orderKeyFromPtr(b)
-	return va < vb
+	return cmp.Compare(a.ID, b.ID)
 }
 
 func orderKeyFromPtr(p *int) int {
 	if p == nil {
-		return DefaultOrder
+		return orderDefault
 	}
 	return *p
 }
@@ -1,6 +1,9 @@
 package wn
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -22,6 +25,8 @@ func TestParseSortSpec(t *testing.T) {
 			{Key: "tags", Desc: false},
 		}, false},
 		{"alpha", "alpha", []SortOption{{Key: "alpha", Desc: false}}, false},
+		{"order alias", "order:desc", []SortOption{{Key: "priority", Desc: true}}, false},
+		{"mixed case and spaces", " Created:DESC , tags ", []SortOption{{Key: "created", Desc: true}, {Key: "tags", Desc: false}}, false},
 		{"invalid key", "invalid", nil, true},
 		{"invalid direction", "created:invalid", nil, true},
 	}
@@ -64,6 +69,86 @@ func TestApplySort(t *testing.T) {
 	}
 }
 
+func TestApplySort_keys(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1, t2 := t0.Add(time.Hour), t0.Add(2*time.Hour)
+	// Every key ties two pairs, so a second key (or the id tiebreak) decides within each pair.
+	items := []*Item{
+		{ID: "p", Created: t0, Updated: t1, Order: sortprefOrderVal(2), Description: "beta", Tags: []string{"x"}},
+		{ID: "q", Created: t0, Updated: t2, Order: sortprefOrderVal(1), Description: "alpha", Tags: []string{"y"}},
+		{ID: "r", Created: t1, Updated: t1, Order: sortprefOrderVal(1), Description: "beta", Tags: []string{"x"}},
+		{ID: "s", Created: t1, Updated: t2, Order: sortprefOrderVal(2), Description: "alpha"},
+	}
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"created", "pqrs"},
+		{"created:desc", "rspq"},
+		{"updated", "prqs"},
+		{"updated:desc", "qspr"},
+		{"priority", "qrps"},
+		{"priority:desc", "psqr"},
+		{"alpha", "qspr"},
+		{"alpha:desc", "prqs"},
+		{"tags", "sprq"},
+		{"tags:desc", "qprs"},
+		{"created,updated:desc", "qpsr"},
+		{"created:desc,updated", "rspq"},
+		{"updated:desc,priority:desc", "sqpr"},
+		{"priority,alpha:desc", "rqps"},
+		{"tags,created:desc", "srpq"},
+		{"alpha:desc,tags:desc", "prqs"},
+		{"order:desc,created", "psqr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec, err := ParseSortSpec(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			forward := strings.Join(ids(ApplySort(items, spec)), "")
+			if forward != tt.want {
+				t.Errorf("ApplySort(%q) = %s, want %s", tt.spec, forward, tt.want)
+			}
+			reversed := make([]*Item, len(items))
+			for i, it := range items {
+				reversed[len(items)-1-i] = it
+			}
+			if got := strings.Join(ids(ApplySort(reversed, spec)), ""); got != tt.want {
+				t.Errorf("ApplySort(%q) on reversed input = %s, want %s", tt.spec, got, tt.want)
+			}
+		})
+	}
+	if got := strings.Join(ids(items), ""); got != "pqrs" {
+		t.Errorf("ApplySort mutated the input slice: %s", got)
+	}
+}
+
+func TestApplySort_idTiebreak(t *testing.T) {
+	// Many items share created/updated timestamps; every spec must still give one fixed order.
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var items []*Item
+	for i := 0; i < 40; i++ {
+		// Insert ids out of order (stride 7 through 40 visits every index once).
+		id := fmt.Sprintf("id%02d", i*7%40)
+		items = append(items, &Item{ID: id, Description: "same", Created: now, Updated: now})
+	}
+	var want []string
+	for i := 0; i < 40; i++ {
+		want = append(want, fmt.Sprintf("id%02d", i))
+	}
+	for _, s := range []string{"created", "updated:desc", "priority:desc,alpha", "tags:desc,created:desc"} {
+		spec, err := ParseSortSpec(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(ApplySort(items, spec)); !slices.Equal(got, want) {
+			t.Errorf("ApplySort(%q) = %v, want ids ascending", s, got)
+		}
+	}
+}
+
 func TestApplySort_created_asc(t *testing.T) {
 	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	items := []*Item{
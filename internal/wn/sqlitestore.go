@@ -0,0 +1,216 @@
+//go:build sqlite
+
+package wn
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSupported reports whether this build includes the SQLite backend (build tag sqlite).
+const sqliteSupported = true
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS items (
+	id      TEXT PRIMARY KEY,
+	done    INTEGER NOT NULL,
+	updated TEXT NOT NULL,
+	data    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS items_done ON items(done);
+CREATE TABLE IF NOT EXISTS item_tags (
+	id  TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (id, tag)
+);
+CREATE INDEX IF NOT EXISTS item_tags_tag ON item_tags(tag);
+`
+
+// sqliteStore keeps every item as a JSON document in .wn/wn.db, with the done flag, updated
+// time, and tags in indexed columns for ListMeta.
+type sqliteStore struct {
+	root string
+	db   *sql.DB
+}
+
+// openSQLiteStore opens (creating if needed) the SQLite store at root/.wn/wn.db.
+func openSQLiteStore(root string) (Store, error) {
+	q := url.Values{}
+	q.Add("_pragma", "busy_timeout(10000)")
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	db, err := sql.Open("sqlite", "file:"+SQLitePath(root)+"?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", SQLitePath(root), err)
+	}
+	return &sqliteStore{root: root, db: db}, nil
+}
+
+func (s *sqliteStore) Root() string { return s.root }
+
+// Close releases the database handle.
+func (s *sqliteStore) Close() error { return s.db.Close() }
+
+func (s *sqliteStore) List() ([]*Item, error) {
+	rows, err := s.db.Query(`SELECT data FROM items ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Item
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		item, err := decodeItem([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *sqliteStore) Get(id string) (*Item, error) {
+	return getSQLiteItem(s.db.QueryRow(`SELECT data FROM items WHERE id = ?`, id), id)
+}
+
+func getSQLiteItem(row *sql.Row, id string) (*Item, error) {
+	var data string
+	if err := row.Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("item %s %w", id, ErrNotFound)
+		}
+		return nil, err
+	}
+	return decodeItem([]byte(data))
+}
+
+func (s *sqliteStore) Put(item *Item) error {
+	return s.withTx(func(tx *sql.Conn) error { return putSQLiteItem(tx, item) })
+}
+
+// UpdateItem runs fn with the item inside a write transaction (read-modify-write).
+func (s *sqliteStore) UpdateItem(id string, fn func(*Item) (*Item, error)) error {
+	return s.withTx(func(tx *sql.Conn) error {
+		item, err := getSQLiteItem(tx.QueryRowContext(context.Background(), `SELECT data FROM items WHERE id = ?`, id), id)
+		if err != nil {
+			return err
+		}
+		updated, err := fn(item)
+		if err != nil || updated == nil {
+			return err
+		}
+		return putSQLiteItem(tx, updated)
+	})
+}
+
+func (s *sqliteStore) Delete(id string) error {
+	return s.withTx(func(tx *sql.Conn) error {
+		res, err := tx.ExecContext(context.Background(), `DELETE FROM items WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("item %s %w", id, ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// Count returns the number of items without decoding them.
+func (s *sqliteStore) Count() (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&n)
+	return n, err
+}
+
+func (s *sqliteStore) hasItems() (bool, error) {
+	var has bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM items)`).Scan(&has)
+	return has, err
+}
+
+// listMeta returns the ListMeta summary from the indexed columns without decoding items.
+func (s *sqliteStore) listMeta() ([]IndexEntry, error) {
+	rows, err := s.db.Query(`SELECT i.id, i.done, i.updated, t.tag FROM items i LEFT JOIN item_tags t ON t.id = i.id ORDER BY i.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []IndexEntry
+	for rows.Next() {
+		var e IndexEntry
+		var updated string
+		var tag sql.NullString
+		if err := rows.Scan(&e.ID, &e.Done, &updated, &tag); err != nil {
+			return nil, err
+		}
+		if n := len(out); n > 0 && out[n-1].ID == e.ID {
+			out[n-1].Tags = append(out[n-1].Tags, tag.String)
+			continue
+		}
+		e.Updated, _ = time.Parse(time.RFC3339Nano, updated)
+		if tag.Valid {
+			e.Tags = []string{tag.String}
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// withTx runs fn on one connection inside BEGIN IMMEDIATE, so concurrent writers (other wn
+// processes) wait on the database lock instead of interleaving read-modify-write cycles.
+func (s *sqliteStore) withTx(fn func(*sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return err
+	}
+	if err := fn(conn); err != nil {
+		_, _ = conn.ExecContext(ctx, `ROLLBACK`)
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `COMMIT`)
+	return err
+}
+
+func putSQLiteItem(conn *sql.Conn, item *Item) error {
+	stampItemSchema(item)
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx,
+		`INSERT INTO items (id, done, updated, data) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET done = excluded.done, updated = excluded.updated, data = excluded.data`,
+		item.ID, item.Done, item.Updated.UTC().Format(time.RFC3339Nano), string(data)); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM item_tags WHERE id = ?`, item.ID); err != nil {
+		return err
+	}
+	for _, tag := range item.Tags {
+		if _, err := conn.ExecContext(ctx, `INSERT OR IGNORE INTO item_tags (id, tag) VALUES (?, ?)`, item.ID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !sqlite
+
+package wn
+
+// sqliteSupported reports whether this build includes the SQLite backend (build tag sqlite).
+const sqliteSupported = false
+
+func openSQLiteStore(root string) (Store, error) {
+	return nil, errNoSQLite
+}
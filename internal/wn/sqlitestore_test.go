@@ -0,0 +1,130 @@
+//go:build sqlite
+
+package wn
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStore_PutGetUpdateDelete(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRootBackend(root, BackendSQLite); err != nil {
+		t.Fatalf("InitRootBackend: %v", err)
+	}
+	if got := StoreBackend(root); got != BackendSQLite {
+		t.Fatalf("StoreBackend = %q, want sqlite", got)
+	}
+	store, err := OpenStore(root)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	defer forgetStore(root)
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "bb2222", Description: "second", Tags: []string{"api", "ui"}, Created: now, Updated: now},
+		{ID: "aa1111", Description: "first", Done: true, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	items, err := store.List()
+	if err != nil || len(items) != 2 || items[0].ID != "aa1111" {
+		t.Fatalf("List = %v, %v; want aa1111, bb2222", items, err)
+	}
+	if err := store.UpdateItem("bb2222", func(it *Item) (*Item, error) {
+		it.Tags = []string{"api"}
+		it.Description = "second, edited"
+		return it, nil
+	}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	got, err := store.Get("bb2222")
+	if err != nil || got.Description != "second, edited" {
+		t.Errorf("Get after update = %+v, %v", got, err)
+	}
+	meta, err := ListMeta(store)
+	if err != nil {
+		t.Fatalf("ListMeta: %v", err)
+	}
+	if len(meta) != 2 || !meta[0].Done || len(meta[1].Tags) != 1 || meta[1].Tags[0] != "api" {
+		t.Errorf("ListMeta = %+v", meta)
+	}
+	if err := store.UpdateItem("zz9999", func(it *Item) (*Item, error) { return it, nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateItem on a missing id = %v, want ErrNotFound", err)
+	}
+	if n, err := CountItems(store); err != nil || n != 2 {
+		t.Errorf("CountItems = %d, %v; want 2", n, err)
+	}
+	if has, err := StoreHasItems(store); err != nil || !has {
+		t.Errorf("StoreHasItems = %v, %v; want true", has, err)
+	}
+	if err := store.Delete("aa1111"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get("aa1111"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("aa1111"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMigrateStore_roundTrip(t *testing.T) {
+	root := t.TempDir()
+	fs, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if err := fs.Put(&Item{ID: "aa1111", Description: "keep me", DependsOn: []string{"bb2222"}, Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Put(&Item{ID: "bb2222", Description: "dep", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, backup, err := MigrateStore(root, BackendSQLite)
+	if err != nil || n != 2 {
+		t.Fatalf("MigrateStore(sqlite) = %d, %v", n, err)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("backup %s: %v", backup, err)
+	}
+	if StoreBackend(root) != BackendSQLite {
+		t.Fatal("store should be sqlite after migrate")
+	}
+	store, err := OpenStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get("aa1111")
+	if err != nil || got.Description != "keep me" || len(got.DependsOn) != 1 {
+		t.Errorf("migrated item = %+v, %v", got, err)
+	}
+
+	// Export and import work against the SQLite store.
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	if err := Export(store, exportPath); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if _, err := ImportReplace(store, exportPath); err != nil {
+		t.Fatalf("ImportReplace: %v", err)
+	}
+
+	if _, _, err := MigrateStore(root, BackendFile); err != nil {
+		t.Fatalf("MigrateStore(file): %v", err)
+	}
+	if StoreBackend(root) != BackendFile {
+		t.Fatal("store should be file after migrating back")
+	}
+	fs, _ = OpenStore(root)
+	items, err := fs.List()
+	if err != nil || len(items) != 2 {
+		t.Errorf("file store after round trip: %d items, %v", len(items), err)
+	}
+}
@@ -50,11 +50,8 @@ func SetStatus(store Store, id, status string, opts StatusOpts) error {
 		return err
 	}
 	if status == StatusClosed && opts.DuplicateOf != "" {
-		if id == opts.DuplicateOf {
-			return fmt.Errorf("cannot mark item as duplicate of itself")
-		}
-		if _, err := store.Get(opts.DuplicateOf); err != nil {
-			return fmt.Errorf("original item %s not found", opts.DuplicateOf)
+		if err := validateDuplicateOf(store, id, opts.DuplicateOf); err != nil {
+			return err
 		}
 	}
 	now := time.Now().UTC()
@@ -122,16 +119,7 @@ func SetStatus(store Store, id, status string, opts StatusOpts) error {
 			it.Updated = now
 			it.Log = append(it.Log, LogEntry{At: now, Kind: "closed", Msg: opts.DoneMessage})
 			if opts.DuplicateOf != "" {
-				if it.Notes == nil {
-					it.Notes = []Note{}
-				}
-				idx := it.NoteIndexByName(NoteNameDuplicateOf)
-				if idx >= 0 {
-					it.Notes[idx].Body = opts.DuplicateOf
-				} else {
-					it.Notes = append(it.Notes, Note{Name: NoteNameDuplicateOf, Created: now, Body: opts.DuplicateOf})
-				}
-				it.Log = append(it.Log, LogEntry{At: now, Kind: "duplicate_of", Msg: opts.DuplicateOf})
+				setDuplicateOfNote(it, opts.DuplicateOf, now)
 			}
 		case StatusSuspend:
 			it.Done = true
@@ -1,5 +1,10 @@
 package wn
 
+import "errors"
+
+// ErrNotFound is wrapped by Store Get, UpdateItem, and Delete when the item does not exist.
+var ErrNotFound = errors.New("not found")
+
 // Store abstracts persistence for work items.
 type Store interface {
 	List() ([]*Item, error)
@@ -9,3 +14,16 @@ type Store interface {
 	Delete(id string) error
 	Root() string
 }
+
+// CountItems returns the number of items in the store. File and SQLite stores count without
+// loading items; other stores fall back to a full List.
+func CountItems(store Store) (int, error) {
+	if c, ok := store.(interface{ Count() (int, error) }); ok {
+		return c.Count()
+	}
+	items, err := store.List()
+	if err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
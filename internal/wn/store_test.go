@@ -1,6 +1,8 @@
 package wn
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
@@ -56,8 +58,14 @@ func TestFileStore_PutGetListDelete(t *testing.T) {
 		t.Fatalf("Delete: %v", err)
 	}
 	_, err = store.Get("abc123")
-	if err == nil {
-		t.Error("Get after Delete should error")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.UpdateItem("abc123", func(it *Item) (*Item, error) { return it, nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateItem after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("abc123"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
 	}
 	items, _ = store.List()
 	if len(items) != 0 {
@@ -135,6 +143,81 @@ func TestFileStore_ListSkipsNonJsonAndSubdirs(t *testing.T) {
 	}
 }
 
+func TestCountItems(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	if n, err := CountItems(store); err != nil || n != 0 {
+		t.Errorf("CountItems(empty) = %d, %v; want 0", n, err)
+	}
+	now := time.Now().UTC()
+	for _, id := range []string{"aa1111", "bb2222", "cc3333"} {
+		if err := store.Put(&Item{ID: id, Created: now, Updated: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	itemsDir := filepath.Join(root, ".wn", "items")
+	if err := os.WriteFile(filepath.Join(itemsDir, "readme.txt"), []byte("ignore"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(itemsDir, "subdir.json"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := CountItems(store); err != nil || n != 3 {
+		t.Errorf("CountItems = %d, %v; want 3 (non-item entries skipped)", n, err)
+	}
+	if n, err := CountItems(&fullStore{root: root}); err != nil || n != 0 {
+		t.Errorf("CountItems(fallback) = %d, %v; want 0 from List", n, err)
+	}
+}
+
+// benchmarkStore returns a file store holding n items.
+func benchmarkStore(b *testing.B, n int) Store {
+	b.Helper()
+	store, err := NewFileStore(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for i := 0; i < n; i++ {
+		it := &Item{ID: fmt.Sprintf("b%05d", i), Description: "benchmark item", Created: now, Updated: now}
+		if err := store.Put(it); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return store
+}
+
+func BenchmarkStoreHasItems(b *testing.B) {
+	store := benchmarkStore(b, 5000)
+	for b.Loop() {
+		if has, err := StoreHasItems(store); err != nil || !has {
+			b.Fatalf("StoreHasItems = %v, %v", has, err)
+		}
+	}
+}
+
+func BenchmarkCountItems(b *testing.B) {
+	store := benchmarkStore(b, 5000)
+	for b.Loop() {
+		if n, err := CountItems(store); err != nil || n != 5000 {
+			b.Fatalf("CountItems = %d, %v", n, err)
+		}
+	}
+}
+
+// BenchmarkStoreList is the full load that StoreHasItems and CountItems avoid.
+func BenchmarkStoreList(b *testing.B) {
+	store := benchmarkStore(b, 5000)
+	for b.Loop() {
+		if _, err := store.List(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestFileStore_GetInvalidJSON(t *testing.T) {
 	root := t.TempDir()
 	store, err := NewFileStore(root)
@@ -2,7 +2,10 @@ package wn
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -31,3 +34,60 @@ func ValidateTag(tag string) error {
 	}
 	return nil
 }
+
+// NormalizeTag trims surrounding whitespace from tag, lowercases it when lowercase is true
+// (settings tag_lowercase), and validates the result. Interior spaces are rejected.
+func NormalizeTag(tag string, lowercase bool) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if lowercase {
+		tag = strings.ToLower(tag)
+	}
+	if err := ValidateTag(tag); err != nil {
+		return "", fmt.Errorf("tag %q: %w", tag, err)
+	}
+	return tag, nil
+}
+
+// NormalizeTags applies NormalizeTag to each tag and drops duplicates, keeping first occurrences in order.
+func NormalizeTags(tags []string, lowercase bool) ([]string, error) {
+	var out []string
+	for _, t := range tags {
+		n, err := NormalizeTag(t, lowercase)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// RelatedItems returns the undone items among items that share at least one tag with item,
+// excluding item itself, ranked by number of shared tags (most first) and then by id.
+func RelatedItems(items []*Item, item *Item) []*Item {
+	shared := make(map[string]int)
+	var out []*Item
+	for _, it := range items {
+		if it.ID == item.ID || it.Done {
+			continue
+		}
+		n := 0
+		for _, t := range it.Tags {
+			if slices.Contains(item.Tags, t) {
+				n++
+			}
+		}
+		if n > 0 {
+			shared[it.ID] = n
+			out = append(out, it)
+		}
+	}
+	slices.SortFunc(out, func(a, b *Item) int {
+		if shared[a.ID] != shared[b.ID] {
+			return shared[b.ID] - shared[a.ID]
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out
+}
@@ -1,6 +1,9 @@
 package wn
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestValidateTag(t *testing.T) {
 	tests := []struct {
@@ -36,3 +39,66 @@ func TestValidateTag_TooLong(t *testing.T) {
 		t.Errorf("ValidateTag(33 chars) = %v", err)
 	}
 }
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag       string
+		lowercase bool
+		want      string
+		ok        bool
+	}{
+		{"backend", false, "backend", true},
+		{"  backend\t", false, "backend", true},
+		{"Backend", false, "Backend", true},
+		{" Backend ", true, "backend", true},
+		{"priority: high", false, "", false},
+		{"a b", true, "", false},
+		{"   ", false, "", false},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeTag(tt.tag, tt.lowercase)
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("NormalizeTag(%q, %v) = %q, %v; want %q", tt.tag, tt.lowercase, got, err, tt.want)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("NormalizeTag(%q, %v) = %q, want error", tt.tag, tt.lowercase, got)
+		}
+	}
+}
+
+func TestNormalizeTags_dedupes(t *testing.T) {
+	got, err := NormalizeTags([]string{"Backend", " backend", "api"}, true)
+	if err != nil {
+		t.Fatalf("NormalizeTags: %v", err)
+	}
+	if len(got) != 2 || got[0] != "backend" || got[1] != "api" {
+		t.Errorf("NormalizeTags = %v, want [backend api]", got)
+	}
+	if _, err := NormalizeTags([]string{"ok", "not ok"}, false); err == nil {
+		t.Error("NormalizeTags with interior space should fail")
+	}
+}
+
+func TestRelatedItems(t *testing.T) {
+	item := &Item{ID: "aaa111", Tags: []string{"api", "backend", "auth"}}
+	items := []*Item{
+		item,
+		{ID: "bbb222", Tags: []string{"api"}},
+		{ID: "ccc333", Tags: []string{"backend", "auth"}},
+		{ID: "ddd444", Tags: []string{"frontend"}},
+		{ID: "eee555", Tags: []string{"api", "auth"}, Done: true},
+		{ID: "abc000", Tags: []string{"auth"}},
+	}
+	got := RelatedItems(items, item)
+	var ids []string
+	for _, it := range got {
+		ids = append(ids, it.ID)
+	}
+	want := []string{"ccc333", "abc000", "bbb222"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("RelatedItems = %v, want %v", ids, want)
+	}
+	if got := RelatedItems(items, &Item{ID: "zzz999"}); len(got) != 0 {
+		t.Errorf("RelatedItems of untagged item = %d items, want none", len(got))
+	}
+}
@@ -0,0 +1,41 @@
+package wn
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// displayLocation is set by SetDisplayTimezone; timestamps are stored in UTC and shown in UTC by default.
+var displayLocation = time.UTC
+
+// ParseTimezone resolves a timezone setting: "" or "UTC" is UTC, "local" is the system zone,
+// and anything else is an IANA name such as "America/Chicago".
+func ParseTimezone(name string) (*time.Location, error) {
+	switch strings.TrimSpace(name) {
+	case "", "UTC":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: use an IANA name (e.g. America/Chicago), local, or UTC", name)
+	}
+	return loc, nil
+}
+
+// SetDisplayTimezone sets the zone used by DisplayTime for human-readable output (see ParseTimezone).
+func SetDisplayTimezone(name string) error {
+	loc, err := ParseTimezone(name)
+	if err != nil {
+		return err
+	}
+	displayLocation = loc
+	return nil
+}
+
+// DisplayTime returns t in the display time zone. Storage and JSON output stay in UTC.
+func DisplayTime(t time.Time) time.Time {
+	return t.In(displayLocation)
+}
@@ -0,0 +1,49 @@
+package wn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimezone(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want *time.Location
+	}{
+		{"", time.UTC},
+		{"UTC", time.UTC},
+		{"local", time.Local},
+	} {
+		loc, err := ParseTimezone(tc.name)
+		if err != nil || loc != tc.want {
+			t.Errorf("ParseTimezone(%q) = %v, %v; want %v", tc.name, loc, err, tc.want)
+		}
+	}
+	loc, err := ParseTimezone("America/Chicago")
+	if err != nil || loc.String() != "America/Chicago" {
+		t.Errorf("ParseTimezone(America/Chicago) = %v, %v", loc, err)
+	}
+	if _, err := ParseTimezone("Mars/Olympus"); err == nil {
+		t.Error("ParseTimezone(Mars/Olympus) should fail")
+	}
+}
+
+func TestDisplayTime(t *testing.T) {
+	defer func() { _ = SetDisplayTimezone("") }()
+	at := time.Date(2026, 1, 15, 18, 30, 0, 0, time.UTC)
+	if got := DisplayTime(at).Format("15:04"); got != "18:30" {
+		t.Errorf("default DisplayTime = %s, want 18:30 (UTC)", got)
+	}
+	if err := SetDisplayTimezone("America/Chicago"); err != nil {
+		t.Fatalf("SetDisplayTimezone: %v", err)
+	}
+	if got := DisplayTime(at).Format("15:04"); got != "12:30" {
+		t.Errorf("DisplayTime in America/Chicago = %s, want 12:30", got)
+	}
+	if err := SetDisplayTimezone("nowhere"); err == nil {
+		t.Error("SetDisplayTimezone(nowhere) should fail")
+	}
+	if got := DisplayTime(at).Format("15:04"); got != "12:30" {
+		t.Errorf("failed SetDisplayTimezone should keep the zone; got %s", got)
+	}
+}
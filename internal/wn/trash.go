@@ -0,0 +1,136 @@
+package wn
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const trashDirName = "trash"
+
+// TrashEntry is an item moved to .wn/trash by TrashItem.
+type TrashEntry struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	Item      *Item     `json:"item"`
+}
+
+// TrashDir returns the trash directory path for the given root.
+func TrashDir(root string) string {
+	return filepath.Join(root, ".wn", trashDirName)
+}
+
+func trashPath(root, id string) string {
+	return filepath.Join(TrashDir(root), id+".json")
+}
+
+// TrashItem saves the item to .wn/trash/<id>.json with a deleted-at timestamp, then deletes it
+// from the store. It can be brought back with RestoreTrashItem. The trash is outside .wn/items,
+// so List and the index never see trashed items.
+func TrashItem(store Store, id string) error {
+	item, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(TrashDir(store.Root()), 0755); err != nil {
+		return fmt.Errorf("create trash directory: %w", err)
+	}
+	data, err := json.MarshalIndent(TrashEntry{DeletedAt: time.Now().UTC(), Item: item}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := trashPath(store.Root(), id)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write trash file: %w", err)
+	}
+	if err := store.Delete(id); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("delete item from store: %w", err)
+	}
+	return nil
+}
+
+// ListTrash returns the trashed items, most recently deleted first.
+func ListTrash(root string) ([]TrashEntry, error) {
+	entries, err := os.ReadDir(TrashDir(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []TrashEntry
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(TrashDir(root), e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var te TrashEntry
+		if err := json.Unmarshal(data, &te); err != nil {
+			return nil, fmt.Errorf("trash %s: %w", e.Name(), err)
+		}
+		if te.Item == nil {
+			continue
+		}
+		out = append(out, te)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeletedAt.Equal(out[j].DeletedAt) {
+			return out[i].DeletedAt.After(out[j].DeletedAt)
+		}
+		return out[i].Item.ID < out[j].Item.ID
+	})
+	return out, nil
+}
+
+// RestoreTrashItem moves a trashed item back into the store. It fails if the id is not in the
+// trash or an item with that id already exists in the store.
+func RestoreTrashItem(store Store, id string) (*Item, error) {
+	path := trashPath(store.Root(), id)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("item %s is not in the trash", id)
+		}
+		return nil, err
+	}
+	var te TrashEntry
+	if err := json.Unmarshal(data, &te); err != nil {
+		return nil, err
+	}
+	if te.Item == nil {
+		return nil, fmt.Errorf("trash entry %s has no item", id)
+	}
+	if _, err := store.Get(id); err == nil {
+		return nil, fmt.Errorf("item %s already exists; remove it before restoring", id)
+	}
+	now := time.Now().UTC()
+	te.Item.Updated = now
+	te.Item.Log = append(te.Item.Log, LogEntry{At: now, Kind: "restored", Msg: "from trash"})
+	if err := store.Put(te.Item); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	return te.Item, nil
+}
+
+// EmptyTrash permanently deletes every trashed item. Returns the number removed.
+func EmptyTrash(root string) (int, error) {
+	entries, err := ListTrash(root)
+	if err != nil {
+		return 0, err
+	}
+	for _, te := range entries {
+		if err := os.Remove(trashPath(root, te.Item.ID)); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,95 @@
+package wn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrashItem_RestoreAndEmpty(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, it := range []*Item{
+		{ID: "aaa111", Description: "first", Created: now, Updated: now},
+		{ID: "bbb222", Description: "second", Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := TrashItem(store, "aaa111"); err != nil {
+		t.Fatalf("TrashItem: %v", err)
+	}
+	if _, err := store.Get("aaa111"); err == nil {
+		t.Error("trashed item should be gone from the store")
+	}
+	items, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Errorf("List after trash = %d items, want 1", len(items))
+	}
+	trash, err := ListTrash(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].Item.ID != "aaa111" || trash[0].DeletedAt.IsZero() {
+		t.Fatalf("ListTrash = %+v, want aaa111 with deleted-at", trash)
+	}
+
+	got, err := RestoreTrashItem(store, "aaa111")
+	if err != nil {
+		t.Fatalf("RestoreTrashItem: %v", err)
+	}
+	if got.Description != "first" {
+		t.Errorf("restored description = %q, want first", got.Description)
+	}
+	if _, err := store.Get("aaa111"); err != nil {
+		t.Errorf("restored item missing from store: %v", err)
+	}
+	if _, err := RestoreTrashItem(store, "aaa111"); err == nil {
+		t.Error("restoring an id that is not in the trash should fail")
+	}
+
+	if err := TrashItem(store, "aaa111"); err != nil {
+		t.Fatal(err)
+	}
+	if err := TrashItem(store, "bbb222"); err != nil {
+		t.Fatal(err)
+	}
+	n, err := EmptyTrash(root)
+	if err != nil {
+		t.Fatalf("EmptyTrash: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("EmptyTrash = %d, want 2", n)
+	}
+	if trash, _ := ListTrash(root); len(trash) != 0 {
+		t.Errorf("trash after empty = %v, want none", trash)
+	}
+}
+
+func TestRestoreTrashItem_RefusesExistingID(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	if err := store.Put(&Item{ID: "aaa111", Description: "first", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := TrashItem(store, "aaa111"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(&Item{ID: "aaa111", Description: "new with same id", Created: now, Updated: now}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreTrashItem(store, "aaa111"); err == nil {
+		t.Error("restore over an existing item should fail")
+	}
+}
@@ -0,0 +1,285 @@
+package wn
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ErrNoBrowser is returned by OpenInBrowser when no opener command is available.
+var ErrNoBrowser = errors.New("no browser opener found")
+
+// BrowserCommand returns the command used to open a file in the default browser: WN_BROWSER
+// when set, else open (macOS), cmd /c start (Windows), or xdg-open.
+func BrowserCommand() []string {
+	if b := strings.TrimSpace(os.Getenv("WN_BROWSER")); b != "" {
+		return splitEditorArgs(b)
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"open"}
+	case "windows":
+		return []string{"cmd", "/c", "start", ""}
+	}
+	return []string{"xdg-open"}
+}
+
+// OpenInBrowser opens path with BrowserCommand without waiting for the browser to exit.
+// Returns ErrNoBrowser if the opener is not on PATH.
+func OpenInBrowser(path string) error {
+	parts := BrowserCommand()
+	bin, err := exec.LookPath(parts[0])
+	if err != nil {
+		return ErrNoBrowser
+	}
+	cmd := exec.Command(bin, append(parts[1:], path)...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// WriteItemHTML renders the item as a standalone HTML page in a temporary file and returns
+// its path. Dependencies are included as sections of the same page so their links work
+// without a server.
+func WriteItemHTML(store Store, item *Item) (string, error) {
+	deps, missing, blocked, err := loadItemDeps(store, item)
+	if err != nil {
+		return "", err
+	}
+	page := RenderItemHTML(item, deps, missing, blocked, time.Now().UTC())
+	f, err := os.CreateTemp("", "wn-"+item.ID+"-*.html")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(page); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// loadItemDeps returns the item's dependencies, the ids of those not found in the store, and
+// the blocked set for the item and its dependencies.
+func loadItemDeps(store Store, item *Item) (deps []*Item, missing []string, blocked map[string]bool, err error) {
+	for _, id := range item.DependsOn {
+		dep, getErr := store.Get(id)
+		if getErr != nil {
+			missing = append(missing, id)
+			continue
+		}
+		deps = append(deps, dep)
+	}
+	blocked, err = BlockedSetFromIndex(store, append([]*Item{item}, deps...))
+	return deps, missing, blocked, err
+}
+
+type webItem struct {
+	ID          string
+	Title       string
+	Status      string
+	Tags        []string
+	Description template.HTML
+	Deps        []webDep
+	Notes       []webNote
+	Log         []LogEntry
+}
+
+type webDep struct {
+	ID      string
+	Title   string
+	Status  string
+	Missing bool
+}
+
+type webNote struct {
+	Name    string
+	Created time.Time
+	Body    template.HTML
+}
+
+// RenderItemHTML returns the HTML page for item: the first description line as title, the rest
+// of the description and the notes rendered from Markdown, the log, and dependencies linking
+// to their own sections below the item. missing lists dependency ids not found in the store.
+func RenderItemHTML(item *Item, deps []*Item, missing []string, blocked map[string]bool, now time.Time) string {
+	top := toWebItem(item, deps, missing, blocked, now)
+	sections := make([]webItem, len(deps))
+	for i, dep := range deps {
+		sections[i] = toWebItem(dep, nil, nil, blocked, now)
+	}
+	var b strings.Builder
+	_ = itemPageTemplate.Execute(&b, struct {
+		Item webItem
+		Deps []webItem
+	}{top, sections})
+	return b.String()
+}
+
+func toWebItem(it *Item, deps []*Item, missing []string, blocked map[string]bool, now time.Time) webItem {
+	_, body, _ := strings.Cut(it.Description, "\n")
+	w := webItem{
+		ID:          it.ID,
+		Title:       FirstLine(it.Description),
+		Status:      ItemListStatus(it, now, blocked[it.ID]),
+		Tags:        it.Tags,
+		Description: MarkdownToHTML(body),
+		Log:         it.Log,
+	}
+	for _, dep := range deps {
+		w.Deps = append(w.Deps, webDep{ID: dep.ID, Title: FirstLine(dep.Description), Status: ItemListStatus(dep, now, blocked[dep.ID])})
+	}
+	for _, id := range missing {
+		w.Deps = append(w.Deps, webDep{ID: id, Missing: true})
+	}
+	for _, n := range it.Notes {
+		w.Notes = append(w.Notes, webNote{Name: n.Name, Created: n.Created, Body: MarkdownToHTML(n.Body)})
+	}
+	return w
+}
+
+var itemPageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"when": func(t time.Time) string { return t.Local().Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>[{{.Item.ID}}] {{.Item.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+code { background: #f4f4f4; }
+.meta { color: #666; }
+.tag { background: #e8e8f8; border-radius: 3px; padding: 0 0.3em; margin-right: 0.3em; }
+table { border-collapse: collapse; }
+td { padding: 0.1em 0.8em 0.1em 0; vertical-align: top; }
+section { border-top: 1px solid #ccc; margin-top: 2em; }
+</style>
+</head>
+<body>
+{{template "item" .Item}}
+{{range .Deps}}<section id="item-{{.ID}}">
+{{template "item" .}}
+</section>
+{{end}}</body>
+</html>
+{{define "item"}}<h1>[{{.ID}}] {{.Title}}</h1>
+<p class="meta">{{.Status}}{{range .Tags}} <span class="tag">#{{.}}</span>{{end}}</p>
+<div class="description">
+{{.Description}}</div>
+{{if .Deps}}<h2>Depends on</h2>
+<ul>
+{{range .Deps}}{{if .Missing}}<li>{{.ID}} (not found)</li>
+{{else}}<li><a href="#item-{{.ID}}">{{.ID}}</a> {{.Title}} ({{.Status}})</li>
+{{end}}{{end}}</ul>
+{{end}}{{if .Notes}}<h2>Notes</h2>
+{{range .Notes}}<h3>{{.Name}} <span class="meta">{{when .Created}}</span></h3>
+{{.Body}}{{end}}{{end}}{{if .Log}}<h2>Log</h2>
+<table>
+{{range .Log}}<tr><td>{{when .At}}</td><td>{{.Kind}}</td><td>{{.Msg}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}`))
+
+var (
+	mdHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdBullet  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	mdNumber  = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	mdBold    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdLink    = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// MarkdownToHTML renders the common subset of Markdown used in item descriptions and notes:
+// headings, paragraphs, bullet and numbered lists, fenced code blocks, inline code, bold, and
+// http(s) links. Everything else is shown as escaped text.
+func MarkdownToHTML(src string) template.HTML {
+	var b strings.Builder
+	var para []string
+	list := ""
+	flushPara := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + mdInline(strings.Join(para, "\n")) + "</p>\n")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			b.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			flushPara()
+			closeList()
+			b.WriteString("<pre><code>")
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				b.WriteString(html.EscapeString(lines[i]) + "\n")
+			}
+			b.WriteString("</code></pre>\n")
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flushPara()
+			closeList()
+			continue
+		}
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			flushPara()
+			closeList()
+			tag := fmt.Sprintf("h%d", min(len(m[1])+1, 6)) // the item title is the page's h1
+			b.WriteString("<" + tag + ">" + mdInline(m[2]) + "</" + tag + ">\n")
+			continue
+		}
+		if m := mdBullet.FindStringSubmatch(line); m != nil {
+			flushPara()
+			openList("ul")
+			b.WriteString("<li>" + mdInline(m[1]) + "</li>\n")
+			continue
+		}
+		if m := mdNumber.FindStringSubmatch(line); m != nil {
+			flushPara()
+			openList("ol")
+			b.WriteString("<li>" + mdInline(m[1]) + "</li>\n")
+			continue
+		}
+		closeList()
+		para = append(para, line)
+	}
+	flushPara()
+	closeList()
+	return template.HTML(b.String())
+}
+
+// mdInline escapes s and renders inline code spans, bold, and links.
+func mdInline(s string) string {
+	parts := strings.Split(s, "`")
+	for i, p := range parts {
+		if i%2 == 1 && i < len(parts)-1 {
+			parts[i] = "<code>" + html.EscapeString(p) + "</code>"
+			continue
+		}
+		p = html.EscapeString(p)
+		p = mdBold.ReplaceAllString(p, "<strong>$1</strong>")
+		p = mdLink.ReplaceAllString(p, `<a href="$2">$1</a>`)
+		if i%2 == 1 {
+			p = "`" + p // unmatched backtick
+		}
+		parts[i] = p
+	}
+	return strings.Join(parts, "")
+}
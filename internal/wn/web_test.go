@@ -0,0 +1,78 @@
+package wn
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	src := "# Plan\n\nRun `go test` and **check** <output>.\n\n- one\n- [docs](https://example.com/a?b=1&c=2)\n\n1. first\n\n```\nif a < b {}\n```"
+	got := string(MarkdownToHTML(src))
+	for _, want := range []string{
+		"<h2>Plan</h2>",
+		"<p>Run <code>go test</code> and <strong>check</strong> &lt;output&gt;.</p>",
+		"<ul>\n<li>one</li>\n<li><a href=\"https://example.com/a?b=1&amp;c=2\">docs</a></li>\n</ul>",
+		"<ol>\n<li>first</li>\n</ol>",
+		"<pre><code>if a &lt; b {}\n</code></pre>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("MarkdownToHTML missing %q in:\n%s", want, got)
+		}
+	}
+	if got := string(MarkdownToHTML("[x](javascript:alert(1)) a`b")); strings.Contains(got, "<a ") || !strings.Contains(got, "a`b") {
+		t.Errorf("MarkdownToHTML should not link non-http URLs and keep a lone backtick: %s", got)
+	}
+}
+
+func TestWriteItemHTML(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	dep := &Item{ID: "dep111", Description: "the dependency", Created: now, Updated: now}
+	item := &Item{
+		ID:          "top111",
+		Description: "Ship <it>\n\nSee **notes**.",
+		Tags:        []string{"web"},
+		DependsOn:   []string{"dep111", "gone99"},
+		Notes:       []Note{{Name: "context", Created: now, Body: "- a point"}},
+		Log:         []LogEntry{{At: now, Kind: "created"}},
+		Created:     now,
+		Updated:     now,
+	}
+	for _, it := range []*Item{dep, item} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path, err := WriteItemHTML(store, item)
+	if err != nil {
+		t.Fatalf("WriteItemHTML: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+	for _, want := range []string{
+		"<title>[top111] Ship &lt;it&gt;</title>",
+		"<strong>notes</strong>",
+		`<a href="#item-dep111">dep111</a> the dependency (undone)`,
+		"gone99 (not found)",
+		`<section id="item-dep111">`,
+		"<h3>context",
+		"<li>a point</li>",
+		"<td>created</td>",
+		`<span class="tag">#web</span>`,
+		"blocked",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package wn
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// WhyReport explains an item's status and why `wn next` does or does not pick it.
+type WhyReport struct {
+	ID      string
+	Status  string   // display status, as from ItemListStatus
+	IsNext  bool     // true when `wn next` (no tag) would pick this item
+	NextID  string   // id `wn next` (no tag) would pick, if any
+	Reasons []string // why the item is not next; empty when IsNext
+}
+
+// ExplainNext reports why the item with the given id is or is not what `wn next` would pick.
+// It uses the same predicates as UndoneItems, BlockedSet, and NextUndoneItemOrdered with the
+// store's next settings: done state, review-ready, prompt-ready, active claim, next.exclude_tags,
+// unfinished dependencies, and position in the next ordering (see NextOrdering).
+func ExplainNext(store Store, id string) (*WhyReport, error) {
+	item, err := store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	all, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	byID := make(map[string]*Item, len(all))
+	for _, it := range all {
+		byID[it.ID] = it
+	}
+	blocked := BlockedSet(all)
+	rep := &WhyReport{ID: id, Status: ItemListStatus(item, now, blocked[id])}
+
+	settings, _ := ReadSettingsInRoot(store.Root())
+	exclude := NextExcludeTags(settings, nil)
+	order := NextOrdering(settings, settings.Next.StrictOrder)
+	next, err := NextUndoneItemOrdered(store, "", exclude, order)
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		rep.NextID = next.ID
+		if next.ID == id {
+			rep.IsNext = true
+			return rep, nil
+		}
+	}
+
+	if item.Done {
+		rep.Reasons = append(rep.Reasons, fmt.Sprintf("item is %s", rep.Status))
+		return rep, nil
+	}
+	if item.ReviewReady {
+		rep.Reasons = append(rep.Reasons, "review-ready: excluded from next and claim until marked done or undone")
+	}
+	if item.PromptReady {
+		rep.Reasons = append(rep.Reasons, "prompt-ready: waiting for a response (wn respond)")
+	}
+	if IsInProgress(item, now) {
+		holder := ""
+		if item.InProgressBy != "" {
+			holder = " by " + item.InProgressBy
+		}
+		remaining := item.InProgressUntil.Sub(now).Round(time.Second)
+		rep.Reasons = append(rep.Reasons, fmt.Sprintf("claimed%s until %s (%s remaining)", holder, item.InProgressUntil.Local().Format(time.RFC3339), remaining))
+	}
+	for _, tag := range item.Tags {
+		if slices.Contains(exclude, tag) {
+			rep.Reasons = append(rep.Reasons, fmt.Sprintf("has tag %s, excluded by next.exclude_tags", tag))
+		}
+	}
+	for _, depID := range item.DependsOn {
+		dep, ok := byID[depID]
+		if !ok {
+			rep.Reasons = append(rep.Reasons, fmt.Sprintf("depends on %s (not found)", depID))
+			continue
+		}
+		if !dep.Done {
+			rep.Reasons = append(rep.Reasons, fmt.Sprintf("depends on %s (%s)", depID, ItemListStatus(dep, now, blocked[depID])))
+		}
+	}
+	if len(rep.Reasons) > 0 {
+		return rep, nil
+	}
+
+	undone, err := UndoneItems(store)
+	if err != nil {
+		return nil, err
+	}
+	ordered, acyclic := order(ExcludeByTags(undone, exclude))
+	for i, it := range ordered {
+		if it.ID == id {
+			rep.Reasons = append(rep.Reasons, fmt.Sprintf("behind %d item(s) in dependency/order sequence", i))
+			return rep, nil
+		}
+	}
+	if !acyclic {
+		rep.Reasons = append(rep.Reasons, "dependency order could not be resolved (a cycle, or a dependency outside the available set)")
+	}
+	return rep, nil
+}
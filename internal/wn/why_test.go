@@ -0,0 +1,93 @@
+package wn
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplainNext(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	first := 1
+	for _, it := range []*Item{
+		{ID: "aa1111", Description: "first", Order: &first, Created: now, Updated: now},
+		{ID: "bb2222", Description: "second", Created: now, Updated: now},
+		{ID: "cc3333", Description: "blocked", DependsOn: []string{"bb2222"}, Created: now, Updated: now},
+		{ID: "dd4444", Description: "review", ReviewReady: true, Created: now, Updated: now},
+		{ID: "ee5555", Description: "claimed", InProgressUntil: now.Add(time.Hour), InProgressBy: "agent-1", Created: now, Updated: now},
+		{ID: "ff6666", Description: "done", Done: true, Created: now, Updated: now},
+	} {
+		if err := store.Put(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		id     string
+		isNext bool
+		want   string
+	}{
+		{"aa1111", true, ""},
+		{"bb2222", false, "behind 1 item"},
+		{"cc3333", false, "depends on bb2222 (undone)"},
+		{"dd4444", false, "review-ready"},
+		{"ee5555", false, "claimed by agent-1"},
+		{"ff6666", false, "item is done"},
+	}
+	for _, tt := range tests {
+		rep, err := ExplainNext(store, tt.id)
+		if err != nil {
+			t.Fatalf("ExplainNext(%s): %v", tt.id, err)
+		}
+		if rep.IsNext != tt.isNext {
+			t.Errorf("ExplainNext(%s).IsNext = %v, want %v", tt.id, rep.IsNext, tt.isNext)
+		}
+		if tt.want != "" && !strings.Contains(strings.Join(rep.Reasons, "\n"), tt.want) {
+			t.Errorf("ExplainNext(%s).Reasons = %v, want one containing %q", tt.id, rep.Reasons, tt.want)
+		}
+		if !tt.isNext && rep.NextID != "aa1111" {
+			t.Errorf("ExplainNext(%s).NextID = %q, want aa1111", tt.id, rep.NextID)
+		}
+	}
+
+	if _, err := ExplainNext(store, "zz9999"); err == nil {
+		t.Error("ExplainNext(missing) want error, got nil")
+	}
+}
+
+func TestExplainNext_usesNextSettings(t *testing.T) {
+	t.Setenv("WN_CONFIG_DIR", t.TempDir())
+	root := t.TempDir()
+	if err := InitRoot(root); err != nil {
+		t.Fatalf("InitRoot: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".wn", "settings.json"), []byte(`{"next": {"exclude_tags": ["manual"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	now := time.Now().UTC()
+	first := 1
+	_ = store.Put(&Item{ID: "aa1111", Description: "manual", Order: &first, Tags: []string{"manual"}, Created: now, Updated: now})
+	_ = store.Put(&Item{ID: "bb2222", Description: "agent", Created: now, Updated: now})
+
+	rep, err := ExplainNext(store, "aa1111")
+	if err != nil {
+		t.Fatalf("ExplainNext: %v", err)
+	}
+	if rep.IsNext || rep.NextID != "bb2222" || len(rep.Reasons) != 1 || !strings.Contains(rep.Reasons[0], "next.exclude_tags") {
+		t.Errorf("ExplainNext(aa1111) = %+v, want not next, next bb2222, excluded by next.exclude_tags", rep)
+	}
+}
@@ -67,6 +67,48 @@ func CommitMergedInto(mainRoot, commitHash, intoRef string) (bool, error) {
 	return true, nil
 }
 
+// CherryApplied reports how many of ref's commits not reachable from intoRef already have an
+// equivalent patch in intoRef (git cherry), e.g. after a rebase or cherry-pick merge.
+// intoRef may be empty for HEAD. Returns applied and total commit counts.
+func CherryApplied(mainRoot, ref, intoRef string) (applied, total int, err error) {
+	if intoRef == "" {
+		intoRef = "HEAD"
+	}
+	cmd := exec.Command("git", "cherry", intoRef, ref)
+	cmd.Dir = mainRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("git cherry: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		total++
+		if strings.HasPrefix(line, "-") {
+			applied++
+		}
+	}
+	return applied, total, nil
+}
+
+// MergeBase returns the full SHA of the best common ancestor of ref and intoRef
+// (git merge-base). intoRef may be empty for HEAD. For a ref already merged into
+// intoRef this is the ref's own tip commit.
+func MergeBase(mainRoot, ref, intoRef string) (string, error) {
+	if intoRef == "" {
+		intoRef = "HEAD"
+	}
+	cmd := exec.Command("git", "merge-base", ref, intoRef)
+	cmd.Dir = mainRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git merge-base: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // BranchExists returns true if the branch exists in the repo at mainRoot.
 func BranchExists(mainRoot, branchName string) (bool, error) {
 	cmd := exec.Command("git", "rev-parse", "--verify", "refs/heads/"+branchName)
@@ -81,6 +123,40 @@ func BranchExists(mainRoot, branchName string) (bool, error) {
 	return true, nil
 }
 
+// RefExists returns true if ref (e.g. "refs/remotes/origin/feature") resolves in the repo at mainRoot.
+func RefExists(mainRoot, ref string) (bool, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref)
+	cmd.Dir = mainRoot
+	err := cmd.Run()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("git rev-parse: %w", err)
+	}
+	return true, nil
+}
+
+// UpstreamGone reports whether local branch branchName tracks an upstream that no longer exists,
+// e.g. a remote branch deleted after its pull request was merged.
+func UpstreamGone(mainRoot, branchName string) (bool, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(upstream:track)", "refs/heads/"+branchName)
+	cmd.Dir = mainRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("git for-each-ref: %w", err)
+	}
+	return strings.TrimSpace(string(out)) == "[gone]", nil
+}
+
+// IsGitWorkTree reports whether dir is inside a git work tree.
+func IsGitWorkTree(dir string) bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
 // DefaultBranch returns the default branch name for the repo at mainRoot (e.g. "main" or "master").
 func DefaultBranch(mainRoot string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
@@ -181,6 +257,18 @@ func CommitWorktreeChanges(worktreePath, message string, audit io.Writer) error
 	return nil
 }
 
+// PushBranch pushes branchName to remote and sets it as the upstream (git push -u).
+// Run from worktreePath; audit is written to with the git command (can be nil).
+func PushBranch(worktreePath, remote, branchName string, audit io.Writer) error {
+	auditLog(audit, "git push -u %s %s (Dir=%s)", remote, branchName, worktreePath)
+	cmd := exec.Command("git", "push", "-u", remote, branchName)
+	cmd.Dir = worktreePath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git push: %w\n%s", err, out)
+	}
+	return nil
+}
+
 // RemoveWorktree removes the worktree at worktreePath. mainRoot is the repo root (where .git is).
 // audit is written to with the git command (can be nil).
 func RemoveWorktree(mainRoot, worktreePath string, audit io.Writer) error {
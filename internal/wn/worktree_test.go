@@ -32,6 +32,17 @@ func TestDefaultBranch(t *testing.T) {
 	}
 }
 
+func TestIsGitWorkTree(t *testing.T) {
+	dir := t.TempDir()
+	if IsGitWorkTree(dir) {
+		t.Skip("temp dir is inside a git work tree")
+	}
+	setupGitRepo(t, dir)
+	if !IsGitWorkTree(dir) {
+		t.Error("IsGitWorkTree = false after git init, want true")
+	}
+}
+
 func TestEnsureWorktree_newBranch(t *testing.T) {
 	dir := t.TempDir()
 	setupGitRepo(t, dir)
@@ -137,6 +148,30 @@ func TestCommitWorktreeChanges_noChanges(t *testing.T) {
 	}
 }
 
+func TestPushBranch(t *testing.T) {
+	remote := t.TempDir()
+	execIn(t, remote, "git", "init", "--bare")
+	dir := t.TempDir()
+	setupGitRepo(t, dir)
+	execIn(t, dir, "git", "remote", "add", "origin", remote)
+	execIn(t, dir, "git", "checkout", "-b", "wn-push-test")
+	var audit bytes.Buffer
+	if err := PushBranch(dir, "origin", "wn-push-test", &audit); err != nil {
+		t.Fatalf("PushBranch: %v", err)
+	}
+	cmd := exec.Command("git", "rev-parse", "--verify", "refs/heads/wn-push-test")
+	cmd.Dir = remote
+	if err := cmd.Run(); err != nil {
+		t.Errorf("branch not found on remote after push: %v", err)
+	}
+	if !strings.Contains(audit.String(), "git push -u origin wn-push-test") {
+		t.Errorf("audit log = %q, want git push command", audit.String())
+	}
+	if err := PushBranch(dir, "nosuchremote", "wn-push-test", nil); err == nil {
+		t.Error("PushBranch to unknown remote should fail")
+	}
+}
+
 func TestRemoveWorktree(t *testing.T) {
 	dir := t.TempDir()
 	setupGitRepo(t, dir)
@@ -236,6 +271,87 @@ func TestBranchMergedInto(t *testing.T) {
 	}
 }
 
+func TestMergeBase(t *testing.T) {
+	dir := t.TempDir()
+	setupGitRepo(t, dir)
+	def, err := DefaultBranch(dir)
+	if err != nil {
+		t.Fatalf("DefaultBranch: %v", err)
+	}
+	execIn(t, dir, "git", "checkout", "-b", "wn-abc-feature")
+	writeFile(t, filepath.Join(dir, "feature.txt"), "feature work")
+	execIn(t, dir, "git", "add", "feature.txt")
+	execIn(t, dir, "git", "commit", "-m", "add feature")
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse: %v", err)
+	}
+	tip := strings.TrimSpace(string(out))
+	execIn(t, dir, "git", "checkout", def)
+	execIn(t, dir, "git", "merge", "--no-ff", "wn-abc-feature", "-m", "merge feature")
+
+	base, err := MergeBase(dir, "refs/heads/wn-abc-feature", "")
+	if err != nil {
+		t.Fatalf("MergeBase: %v", err)
+	}
+	if base != tip {
+		t.Errorf("MergeBase = %q, want branch tip %q", base, tip)
+	}
+	if _, err := MergeBase(dir, "refs/heads/nonexistent", def); err == nil {
+		t.Error("MergeBase(nonexistent) want error, got nil")
+	}
+}
+
+func TestCherryApplied(t *testing.T) {
+	dir := t.TempDir()
+	setupGitRepo(t, dir)
+	def, err := DefaultBranch(dir)
+	if err != nil {
+		t.Fatalf("DefaultBranch: %v", err)
+	}
+	execIn(t, dir, "git", "checkout", "-b", "wn-abc-feature")
+	writeFile(t, filepath.Join(dir, "a.txt"), "a")
+	execIn(t, dir, "git", "add", "a.txt")
+	execIn(t, dir, "git", "commit", "-m", "add a")
+	writeFile(t, filepath.Join(dir, "b.txt"), "b")
+	execIn(t, dir, "git", "add", "b.txt")
+	execIn(t, dir, "git", "commit", "-m", "add b")
+	execIn(t, dir, "git", "checkout", def)
+	writeFile(t, filepath.Join(dir, "main.txt"), "main")
+	execIn(t, dir, "git", "add", "main.txt")
+	execIn(t, dir, "git", "commit", "-m", "main work")
+
+	applied, total, err := CherryApplied(dir, "refs/heads/wn-abc-feature", "")
+	if err != nil {
+		t.Fatalf("CherryApplied: %v", err)
+	}
+	if applied != 0 || total != 2 {
+		t.Errorf("CherryApplied before pick = %d/%d, want 0/2", applied, total)
+	}
+
+	execIn(t, dir, "git", "cherry-pick", "wn-abc-feature~1")
+	applied, total, err = CherryApplied(dir, "refs/heads/wn-abc-feature", "")
+	if err != nil {
+		t.Fatalf("CherryApplied: %v", err)
+	}
+	if applied != 1 || total != 2 {
+		t.Errorf("CherryApplied after one pick = %d/%d, want 1/2", applied, total)
+	}
+}
+
+func TestRefExists(t *testing.T) {
+	dir := t.TempDir()
+	setupGitRepo(t, dir)
+	ok, err := RefExists(dir, "HEAD")
+	if err != nil || !ok {
+		t.Errorf("RefExists(HEAD) = %v, %v; want true, nil", ok, err)
+	}
+	ok, err = RefExists(dir, "refs/remotes/origin/nope")
+	if err != nil || ok {
+		t.Errorf("RefExists(missing) = %v, %v; want false, nil", ok, err)
+	}
+}
+
 func TestWorktreePathForBranch(t *testing.T) {
 	dir := t.TempDir()
 	setupGitRepo(t, dir)